@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"github.com/op/go-logging"
+	"slices"
+	"sync"
+	"time"
+)
+
+var log = logging.MustGetLogger("WarmUp")
+
+// Warmer fills the API schedule cache, so that the current week schedule
+// is cached before the users open it after the restart.
+//
+// Should be created via NewWarmer.
+type Warmer struct {
+	api api2.Api
+	// workers is the number of the groups fetched at once
+	workers int
+	// ttl is how old the cached week can be to be left as is.
+	// Older weeks are requested again, so they don't expire
+	// right after the start. Zero means the API cache expiration is used
+	ttl time.Duration
+}
+
+// NewWarmer creates a new instance of Warmer that fills the cache of api.
+func NewWarmer(api api2.Api, workers int, ttl time.Duration) *Warmer {
+	return &Warmer{api: api, workers: max(workers, 1), ttl: ttl}
+}
+
+// WarmUp fetches the current week schedule of the groups to the cache.
+// Up to the workers groups are fetched at once.
+//
+// Stops when ctx is done. Returns the errors of the groups that
+// could not be fetched, along with ctx error if it's done.
+func (w *Warmer) WarmUp(ctx context.Context, groups []int) error {
+	now := utils.NowFor(nil)
+	weekStart := now.AddDate(0, 0, -(int(now.Weekday())+6)%7)
+	dateStart := weekStart.Format(time.DateOnly)
+	dateEnd := weekStart.AddDate(0, 0, 6).Format(time.DateOnly)
+
+	log.Infof("Warming up the schedule of %d groups from %s to %s", len(groups), dateStart, dateEnd)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	queue := make(chan int)
+	for i := 0; i < min(w.workers, len(groups)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for groupId := range queue {
+				if err := w.warmUpGroup(groupId, dateStart, dateEnd); err != nil {
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("group %d: %w", groupId, err))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, groupId := range groups {
+		// Select picks a random ready case, so the done ctx is checked first
+		if ctx.Err() == nil {
+			select {
+			case queue <- groupId:
+				continue
+			case <-ctx.Done():
+			}
+		}
+
+		mu.Lock()
+		errs = append(errs, ctx.Err())
+		mu.Unlock()
+		break
+	}
+	close(queue)
+	wg.Wait()
+
+	log.Infof("Schedule warm-up completed, %d errors", len(errs))
+	return errors.Join(errs...)
+}
+
+// warmUpGroup fetches the group schedule, if it's not cached
+// or the cached one is older than the ttl
+func (w *Warmer) warmUpGroup(groupId int, dateStart string, dateEnd string) error {
+	ages, ok := w.api.(api2.ScheduleAgeProvider)
+	expirer, ok2 := w.api.(api2.ScheduleExpirer)
+	if w.ttl > 0 && ok && ok2 {
+		cachedAt, err := ages.GroupScheduleCachedAt(groupId, dateStart, dateEnd)
+		if err != nil {
+			return err
+		}
+		if !cachedAt.IsZero() {
+			if time.Since(cachedAt) < w.ttl {
+				return nil
+			}
+			if err := expirer.ExpireGroupSchedule(groupId, dateStart, dateEnd); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := w.api.GetGroupSchedule(groupId, dateStart, dateEnd)
+	return err
+}
+
+// ChatGroups returns the distinct groups selected in the accessible chats, sorted by id
+func ChatGroups(chatRepo data.ChatRepository) ([]int, error) {
+	chats, err := chatRepo.GetAccessibleChats()
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]int, 0, len(chats))
+	for _, chat := range chats {
+		if chat.GroupId != -1 {
+			groups = append(groups, chat.GroupId)
+		}
+	}
+
+	slices.Sort(groups)
+	return slices.Compact(groups), nil
+}
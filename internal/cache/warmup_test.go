@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package cache
+
+import (
+	"context"
+	"errors"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// warmupApi is the cached API with the given cache times of the groups
+type warmupApi struct {
+	api2.Api
+	cachedAt map[int]time.Time
+
+	mu      sync.Mutex
+	fetched []int
+	expired []int
+}
+
+func (a *warmupApi) GetGroupSchedule(groupId int, _ string, _ string) (api2.Schedule, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.fetched = append(a.fetched, groupId)
+	return api2.Schedule{}, nil
+}
+
+func (a *warmupApi) GroupScheduleCachedAt(groupId int, _ string, _ string) (time.Time, error) {
+	return a.cachedAt[groupId], nil
+}
+
+func (a *warmupApi) ExpireGroupSchedule(groupId int, _ string, _ string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.expired = append(a.expired, groupId)
+	return nil
+}
+
+func TestWarmUp(t *testing.T) {
+	tests := []struct {
+		name        string
+		ttl         time.Duration
+		wantFetched []int
+		wantExpired []int
+	}{
+		{"ttl", 30 * time.Minute, []int{1, 3}, []int{3}},
+		{"no ttl", 0, []int{1, 2, 3}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &warmupApi{cachedAt: map[int]time.Time{
+				2: time.Now().Add(-10 * time.Minute),
+				3: time.Now().Add(-40 * time.Minute),
+			}}
+
+			if err := NewWarmer(api, 2, tt.ttl).WarmUp(context.Background(), []int{1, 2, 3}); err != nil {
+				t.Fatal(err)
+			}
+
+			slices.Sort(api.fetched)
+			if !slices.Equal(api.fetched, tt.wantFetched) {
+				t.Errorf("fetched groups %v, want %v", api.fetched, tt.wantFetched)
+			}
+			if !slices.Equal(api.expired, tt.wantExpired) {
+				t.Errorf("expired groups %v, want %v", api.expired, tt.wantExpired)
+			}
+		})
+	}
+}
+
+func TestWarmUpStopsOnDoneContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	api := &warmupApi{}
+	err := NewWarmer(api, 1, 0).WarmUp(ctx, []int{1, 2, 3})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("WarmUp() error = %v, want context.Canceled", err)
+	}
+	if len(api.fetched) != 0 {
+		t.Errorf("groups %v are fetched after the context is done", api.fetched)
+	}
+}
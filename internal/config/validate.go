@@ -24,8 +24,12 @@ package config
 
 import (
 	"fmt"
+	"github.com/cubicbyte/dteubot/pkg/api"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // IncorrectEnvVariableError is an error that is returned when
@@ -80,6 +84,13 @@ func ValidateEnv() error {
 			return &IncorrectEnvVariableError{"POSTGRES_SSL"}
 		}
 
+	case "sqlite":
+		if os.Getenv("SQLITE_FILE") == "" {
+			if err := os.Setenv("SQLITE_FILE", "dteubot.sqlite"); err != nil {
+				return err
+			}
+		}
+
 	case "file":
 		// Do nothing
 
@@ -113,6 +124,200 @@ func ValidateEnv() error {
 		return &IncorrectEnvVariableError{"API_CACHE_EXPIRES"}
 	}
 
+	if os.Getenv("API_RETRY_ATTEMPTS") == "" {
+		if err := os.Setenv("API_RETRY_ATTEMPTS", "3"); err != nil {
+			return err
+		}
+	}
+	retryAttempts, err := strconv.Atoi(os.Getenv("API_RETRY_ATTEMPTS"))
+	if err != nil || retryAttempts < 0 {
+		return &IncorrectEnvVariableError{"API_RETRY_ATTEMPTS"}
+	}
+
+	if os.Getenv("API_BREAKER_ERROR_RATE") == "" {
+		if err := os.Setenv("API_BREAKER_ERROR_RATE", "0.5"); err != nil {
+			return err
+		}
+	}
+	errorRate, err := strconv.ParseFloat(os.Getenv("API_BREAKER_ERROR_RATE"), 64)
+	if err != nil || errorRate < 0 || errorRate > 1 {
+		return &IncorrectEnvVariableError{"API_BREAKER_ERROR_RATE"}
+	}
+
+	if os.Getenv("CALL_SCHEDULE") != "" {
+		if _, err := api.ParseCallSchedule(os.Getenv("CALL_SCHEDULE")); err != nil {
+			return &IncorrectEnvVariableError{"CALL_SCHEDULE"}
+		}
+	}
+
+	if os.Getenv("CALENDAR_EXPORT_DAYS") == "" {
+		if err := os.Setenv("CALENDAR_EXPORT_DAYS", "30"); err != nil {
+			return err
+		}
+	}
+	calendarExportDays, err := strconv.ParseInt(os.Getenv("CALENDAR_EXPORT_DAYS"), 10, 64)
+	if err != nil || calendarExportDays <= 0 {
+		return &IncorrectEnvVariableError{"CALENDAR_EXPORT_DAYS"}
+	}
+
+	if os.Getenv("SCHEDULE_CHANGES_INTERVAL") == "" {
+		if err := os.Setenv("SCHEDULE_CHANGES_INTERVAL", "30"); err != nil {
+			return err
+		}
+	}
+	scheduleChangesInterval, err := strconv.ParseInt(os.Getenv("SCHEDULE_CHANGES_INTERVAL"), 10, 64)
+	if err != nil || scheduleChangesInterval <= 0 {
+		return &IncorrectEnvVariableError{"SCHEDULE_CHANGES_INTERVAL"}
+	}
+
+	if os.Getenv("STATS_RETENTION_DAYS") == "" {
+		if err := os.Setenv("STATS_RETENTION_DAYS", "90"); err != nil {
+			return err
+		}
+	}
+	statsRetention, err := strconv.ParseInt(os.Getenv("STATS_RETENTION_DAYS"), 10, 64)
+	if err != nil || statsRetention <= 0 {
+		return &IncorrectEnvVariableError{"STATS_RETENTION_DAYS"}
+	}
+
+	if os.Getenv("SHUTDOWN_TIMEOUT") == "" {
+		if err := os.Setenv("SHUTDOWN_TIMEOUT", "10"); err != nil {
+			return err
+		}
+	}
+	shutdownTimeout, err := strconv.ParseInt(os.Getenv("SHUTDOWN_TIMEOUT"), 10, 64)
+	if err != nil || shutdownTimeout <= 0 {
+		return &IncorrectEnvVariableError{"SHUTDOWN_TIMEOUT"}
+	}
+
+	if os.Getenv("UPDATE_TIMEOUT") == "" {
+		if err := os.Setenv("UPDATE_TIMEOUT", "30"); err != nil {
+			return err
+		}
+	}
+	updateTimeout, err := strconv.ParseInt(os.Getenv("UPDATE_TIMEOUT"), 10, 64)
+	if err != nil || updateTimeout <= 0 {
+		return &IncorrectEnvVariableError{"UPDATE_TIMEOUT"}
+	}
+
+	if os.Getenv("UPDATE_WORKERS") == "" {
+		if err := os.Setenv("UPDATE_WORKERS", "50"); err != nil {
+			return err
+		}
+	}
+	updateWorkers, err := strconv.ParseInt(os.Getenv("UPDATE_WORKERS"), 10, 64)
+	if err != nil || updateWorkers <= 0 {
+		return &IncorrectEnvVariableError{"UPDATE_WORKERS"}
+	}
+
+	if os.Getenv("WARMUP_WORKERS") == "" {
+		if err := os.Setenv("WARMUP_WORKERS", "5"); err != nil {
+			return err
+		}
+	}
+	warmupWorkers, err := strconv.ParseInt(os.Getenv("WARMUP_WORKERS"), 10, 64)
+	if err != nil || warmupWorkers < 0 {
+		return &IncorrectEnvVariableError{"WARMUP_WORKERS"}
+	}
+
+	if os.Getenv("WARMUP_CACHE_TTL") == "" {
+		if err := os.Setenv("WARMUP_CACHE_TTL", "1800"); err != nil {
+			return err
+		}
+	}
+	warmupCacheTTL, err := strconv.ParseInt(os.Getenv("WARMUP_CACHE_TTL"), 10, 64)
+	if err != nil || warmupCacheTTL < 0 {
+		return &IncorrectEnvVariableError{"WARMUP_CACHE_TTL"}
+	}
+
+	if os.Getenv("RATE_LIMIT_BURST") == "" {
+		if err := os.Setenv("RATE_LIMIT_BURST", "5"); err != nil {
+			return err
+		}
+	}
+	burst, err := strconv.ParseInt(os.Getenv("RATE_LIMIT_BURST"), 10, 64)
+	if err != nil || burst < 0 {
+		return &IncorrectEnvVariableError{"RATE_LIMIT_BURST"}
+	}
+
+	if os.Getenv("RATE_LIMIT_RATE") == "" {
+		if err := os.Setenv("RATE_LIMIT_RATE", "1"); err != nil {
+			return err
+		}
+	}
+	rate, err := strconv.ParseFloat(os.Getenv("RATE_LIMIT_RATE"), 64)
+	if err != nil || rate <= 0 {
+		return &IncorrectEnvVariableError{"RATE_LIMIT_RATE"}
+	}
+
+	if os.Getenv("CHAT_RATE_LIMIT_BURST") == "" {
+		if err := os.Setenv("CHAT_RATE_LIMIT_BURST", "10"); err != nil {
+			return err
+		}
+	}
+	chatBurst, err := strconv.ParseInt(os.Getenv("CHAT_RATE_LIMIT_BURST"), 10, 64)
+	if err != nil || chatBurst < 0 {
+		return &IncorrectEnvVariableError{"CHAT_RATE_LIMIT_BURST"}
+	}
+
+	if os.Getenv("CHAT_RATE_LIMIT_RATE") == "" {
+		if err := os.Setenv("CHAT_RATE_LIMIT_RATE", "3"); err != nil {
+			return err
+		}
+	}
+	chatRate, err := strconv.ParseFloat(os.Getenv("CHAT_RATE_LIMIT_RATE"), 64)
+	if err != nil || chatRate <= 0 {
+		return &IncorrectEnvVariableError{"CHAT_RATE_LIMIT_RATE"}
+	}
+
+	if os.Getenv("RATE_LIMIT_ADMIN_BURST") == "" {
+		if err := os.Setenv("RATE_LIMIT_ADMIN_BURST", "20"); err != nil {
+			return err
+		}
+	}
+	adminBurst, err := strconv.ParseInt(os.Getenv("RATE_LIMIT_ADMIN_BURST"), 10, 64)
+	if err != nil || adminBurst < 0 {
+		return &IncorrectEnvVariableError{"RATE_LIMIT_ADMIN_BURST"}
+	}
+
+	if os.Getenv("RATE_LIMIT_ADMIN_RATE") == "" {
+		if err := os.Setenv("RATE_LIMIT_ADMIN_RATE", "5"); err != nil {
+			return err
+		}
+	}
+	adminRate, err := strconv.ParseFloat(os.Getenv("RATE_LIMIT_ADMIN_RATE"), 64)
+	if err != nil || adminRate <= 0 {
+		return &IncorrectEnvVariableError{"RATE_LIMIT_ADMIN_RATE"}
+	}
+
+	if os.Getenv("BROADCAST_DELAY") == "" {
+		if err := os.Setenv("BROADCAST_DELAY", "40"); err != nil {
+			return err
+		}
+	}
+	broadcastDelay, err := strconv.ParseInt(os.Getenv("BROADCAST_DELAY"), 10, 64)
+	if err != nil || broadcastDelay <= 0 {
+		return &IncorrectEnvVariableError{"BROADCAST_DELAY"}
+	}
+
+	if os.Getenv("SCHEDULE_SNAPSHOTS_FILE") == "" {
+		if err := os.Setenv("SCHEDULE_SNAPSHOTS_FILE", "schedule_snapshots.json"); err != nil {
+			return err
+		}
+	}
+
+	if os.Getenv("BROADCAST_PROGRESS_FILE") == "" {
+		if err := os.Setenv("BROADCAST_PROGRESS_FILE", "broadcast.json"); err != nil {
+			return err
+		}
+	}
+
+	if os.Getenv("ADDED_HOLIDAYS_FILE") == "" {
+		if err := os.Setenv("ADDED_HOLIDAYS_FILE", "holidays.json"); err != nil {
+			return err
+		}
+	}
+
 	if os.Getenv("LOG_CHAT_ID") != "" {
 		_, err = strconv.ParseInt(os.Getenv("LOG_CHAT_ID"), 10, 64)
 		if err != nil {
@@ -120,11 +325,130 @@ func ValidateEnv() error {
 		}
 	}
 
+	if os.Getenv("METRICS_PORT") != "" {
+		port, err := strconv.ParseUint(os.Getenv("METRICS_PORT"), 10, 16)
+		if err != nil || port == 0 {
+			return &IncorrectEnvVariableError{"METRICS_PORT"}
+		}
+	}
+
+	switch os.Getenv("UPDATES_MODE") {
+	case "":
+		if err := os.Setenv("UPDATES_MODE", "polling"); err != nil {
+			return err
+		}
+		fallthrough
+
+	case "polling":
+		// Webhook must not be set while polling, Telegram rejects getUpdates then
+		if os.Getenv("WEBHOOK_URL") != "" {
+			return &IncorrectEnvVariableError{"WEBHOOK_URL"}
+		}
+
+	case "webhook":
+		webhookUrl, err := url.Parse(os.Getenv("WEBHOOK_URL"))
+		if err != nil || webhookUrl.Scheme != "https" || webhookUrl.Host == "" || !isValidWebhookPort(webhookUrl.Port()) {
+			return &IncorrectEnvVariableError{"WEBHOOK_URL"}
+		}
+
+		// Set default listen address
+		if os.Getenv("WEBHOOK_LISTEN") == "" {
+			if err := os.Setenv("WEBHOOK_LISTEN", ":8443"); err != nil {
+				return err
+			}
+		}
+
+		if !isValidWebhookSecret(os.Getenv("WEBHOOK_SECRET")) {
+			return &IncorrectEnvVariableError{"WEBHOOK_SECRET"}
+		}
+
+		// Certificate is used along with its key
+		if (os.Getenv("WEBHOOK_CERT") == "") != (os.Getenv("WEBHOOK_KEY") == "") {
+			return &IncorrectEnvVariableError{"WEBHOOK_KEY"}
+		}
+
+		switch os.Getenv("WEBHOOK_DELETE") {
+		case "":
+			if err := os.Setenv("WEBHOOK_DELETE", "false"); err != nil {
+				return err
+			}
+		case "true", "false":
+		default:
+			return &IncorrectEnvVariableError{"WEBHOOK_DELETE"}
+		}
+
+	default:
+		return &IncorrectEnvVariableError{"UPDATES_MODE"}
+	}
+
+	if os.Getenv("WEBAPP_URL") != "" {
+		webAppUrl, err := url.Parse(os.Getenv("WEBAPP_URL"))
+		if err != nil || webAppUrl.Scheme != "https" || webAppUrl.Host == "" {
+			return &IncorrectEnvVariableError{"WEBAPP_URL"}
+		}
+	}
+
+	if os.Getenv("WEBAPP_PORT") != "" {
+		port, err := strconv.ParseUint(os.Getenv("WEBAPP_PORT"), 10, 16)
+		if err != nil || port == 0 || os.Getenv("WEBAPP_URL") == "" {
+			return &IncorrectEnvVariableError{"WEBAPP_PORT"}
+		}
+	}
+
+	if os.Getenv("SEMESTER_START") != "" {
+		_, err = time.Parse(time.DateOnly, os.Getenv("SEMESTER_START"))
+		if err != nil {
+			return &IncorrectEnvVariableError{"SEMESTER_START"}
+		}
+	}
+
+	if os.Getenv("ADMIN_IDS") != "" {
+		for _, id := range strings.Split(os.Getenv("ADMIN_IDS"), ",") {
+			_, err = strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+			if err != nil {
+				return &IncorrectEnvVariableError{"ADMIN_IDS"}
+			}
+		}
+	}
+
 	switch os.Getenv("LOG_LEVEL") {
 	case "DEBUG", "INFO", "WARNING", "ERROR", "CRITICAL", "DISABLED", "":
 	default:
 		return &IncorrectEnvVariableError{"LOG_LEVEL"}
 	}
 
+	switch os.Getenv("LOG_FORMAT") {
+	case "text", "json", "":
+	default:
+		return &IncorrectEnvVariableError{"LOG_FORMAT"}
+	}
+
 	return nil
 }
+
+// isValidWebhookPort checks if the webhook URL port is
+// one of the ports Telegram sends the updates to
+func isValidWebhookPort(port string) bool {
+	switch port {
+	case "", "443", "80", "88", "8443":
+		return true
+	default:
+		return false
+	}
+}
+
+// isValidWebhookSecret checks if the webhook secret token is empty or
+// 1-256 characters of A-Z, a-z, 0-9, _ and -, as Telegram requires
+func isValidWebhookSecret(secret string) bool {
+	if len(secret) > 256 {
+		return false
+	}
+
+	for _, c := range secret {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -28,17 +28,88 @@ import (
 	"time"
 )
 
+// DefaultReminderOffset is the default number of minutes
+// before the class start to send the class reminder.
+const DefaultReminderOffset = 15
+
+// DefaultMorningScheduleTime is the default time to send the morning schedule
+const DefaultMorningScheduleTime = "07:00"
+
+// DefaultEveningScheduleTime is the default time to send the next day schedule
+const DefaultEveningScheduleTime = "20:00"
+
+// MaxSavedGroups is the maximum number of groups a chat can save
+// to quickly switch between them
+const MaxSavedGroups = 5
+
 // Chat is a struct that contains all the chat settings
 type Chat struct {
-	Id                          int64     `db:"id" json:"id"`
-	GroupId                     int       `db:"group_id" json:"groupId"`
-	LanguageCode                string    `db:"lang_code" json:"languageCode"`
-	ClassesNotification15m      bool      `db:"cl_notif_15m" json:"clNotif15m"`
-	ClassesNotification1m       bool      `db:"cl_notif_1m" json:"clNotif1m"`
-	ClassesNotificationNextPart bool      `db:"cl_notif_next_part" json:"clNotifNextPart"`
-	SeenSettings                bool      `db:"seen_settings" json:"seenSettings"`
-	Accessible                  bool      `db:"accessible" json:"accessible"`
-	Created                     time.Time `db:"created" json:"created"`
+	Id                          int64           `db:"id" json:"id"`
+	GroupId                     int             `db:"group_id" json:"groupId"`
+	LanguageCode                string          `db:"lang_code" json:"languageCode"`
+	Timezone                    string          `db:"timezone" json:"timezone"`
+	SavedGroups                 GroupRefs       `db:"saved_groups" json:"savedGroups"`
+	ClassesNotification15m      bool            `db:"cl_notif_15m" json:"clNotif15m"`
+	ClassesNotification1m       bool            `db:"cl_notif_1m" json:"clNotif1m"`
+	ClassesNotificationNextPart bool            `db:"cl_notif_next_part" json:"clNotifNextPart"`
+	ClassesReminder             bool            `db:"cl_reminder" json:"clReminder"`
+	ReminderOffset              int             `db:"reminder_offset" json:"reminderOffset"`
+	SnoozedClass                string          `db:"snoozed_class" json:"snoozedClass"`
+	MorningSchedule             bool            `db:"morning_schedule" json:"morningSchedule"`
+	MorningScheduleTime         string          `db:"morning_schedule_time" json:"morningScheduleTime"`
+	MorningScheduleSent         string          `db:"morning_schedule_sent" json:"morningScheduleSent"`
+	MorningScheduleClaimed      int64           `db:"morning_schedule_claimed" json:"morningScheduleClaimed"`
+	EveningSchedule             bool            `db:"evening_schedule" json:"eveningSchedule"`
+	EveningScheduleTime         string          `db:"evening_schedule_time" json:"eveningScheduleTime"`
+	EveningScheduleSent         string          `db:"evening_schedule_sent" json:"eveningScheduleSent"`
+	EveningScheduleClaimed      int64           `db:"evening_schedule_claimed" json:"eveningScheduleClaimed"`
+	DailyScheduleEmpty          bool            `db:"daily_schedule_empty" json:"dailyScheduleEmpty"`
+	TeacherSearchQuery          string          `db:"teacher_search_query" json:"teacherSearchQuery"`
+	GroupSearchQuery            string          `db:"group_search_query" json:"groupSearchQuery"`
+	NotifyChanges               bool            `db:"notify_changes" json:"notifyChanges"`
+	HideEmptyLessons            bool            `db:"hide_empty_lessons" json:"hideEmptyLessons"`
+	HiddenLessons               HiddenLessons   `db:"hidden_lessons" json:"hiddenLessons"`
+	StrikeHiddenLessons         bool            `db:"strike_hidden_lessons" json:"strikeHiddenLessons"`
+	LessonReminders             LessonReminders `db:"lesson_reminders" json:"lessonReminders"`
+	NavStack                    NavStack        `db:"nav_stack" json:"navStack"`
+	CleanupPages                bool            `db:"cleanup_pages" json:"cleanupPages"`
+	LastPageId                  int64           `db:"last_page_id" json:"lastPageId"`
+	SettingsLocked              bool            `db:"settings_locked" json:"settingsLocked"`
+	SeenSettings                bool            `db:"seen_settings" json:"seenSettings"`
+	Accessible                  bool            `db:"accessible" json:"accessible"`
+	Created                     time.Time       `db:"created" json:"created"`
+}
+
+// Groups returns the chat group followed by the saved groups.
+// The chats that have not saved any groups have only their group in the list.
+func (c *Chat) Groups() GroupRefs {
+	if c.GroupId == -1 {
+		return c.SavedGroups
+	}
+	return c.SavedGroups.WithFirst(c.GroupId)
+}
+
+// SelectSavedGroup makes the group the chat group. The previous chat group
+// is saved if there is space left, so the chat can switch back to it.
+func (c *Chat) SelectSavedGroup(groupId int) {
+	if c.GroupId != -1 && c.SavedGroups.Index(c.GroupId) == -1 && len(c.SavedGroups) < MaxSavedGroups {
+		c.SavedGroups = append(GroupRefs{{Id: c.GroupId}}, c.SavedGroups...)
+	}
+	c.GroupId = groupId
+}
+
+// RemoveSavedGroup removes the group from the saved groups. If it's the chat group,
+// the first one of the remaining saved groups becomes the chat group.
+//
+// Returns true if the chat group is changed.
+func (c *Chat) RemoveSavedGroup(groupId int) bool {
+	c.SavedGroups = c.SavedGroups.Remove(groupId)
+	if groupId != c.GroupId || len(c.SavedGroups) == 0 {
+		return false
+	}
+
+	c.GroupId = c.SavedGroups[0].Id
+	return true
 }
 
 // ChatRepository is an interface for working with chat data.
@@ -51,6 +122,32 @@ type ChatRepository interface {
 	GetChatsWithEnabled15mNotification() ([]*Chat, error)
 	// GetChatsWithEnabled1mNotification returns all chats with enabled 1m notifications.
 	GetChatsWithEnabled1mNotification() ([]*Chat, error)
+	// GetChatsWithEnabledReminder returns all chats with enabled class reminders.
+	GetChatsWithEnabledReminder() ([]*Chat, error)
+	// GetChatsWithEnabledMorningSchedule returns all chats with enabled morning schedule.
+	GetChatsWithEnabledMorningSchedule() ([]*Chat, error)
+	// GetChatsWithEnabledEveningSchedule returns all chats with enabled evening schedule.
+	GetChatsWithEnabledEveningSchedule() ([]*Chat, error)
+	// GetChatsWithEnabledChangesNotification returns all chats with enabled schedule changes notifications.
+	GetChatsWithEnabledChangesNotification() ([]*Chat, error)
+	// GetChatsWithLessonReminders returns all chats with pending lesson reminders.
+	GetChatsWithLessonReminders() ([]*Chat, error)
+	// GetAccessibleChats returns all chats the bot can send messages to.
+	GetAccessibleChats() ([]*Chat, error)
+	// GetAllChats returns all chats.
+	GetAllChats() ([]*Chat, error)
+	// ClaimMorningSchedule marks the morning schedule as being sent on the given date.
+	//
+	// Returns false if it was already sent on the date, or claimed less than
+	// timeout ago, e.g. by another bot instance. The claim expires after timeout,
+	// so the schedule is sent again if the bot stopped before CompleteMorningSchedule.
+	ClaimMorningSchedule(id int64, date string, timeout time.Duration) (bool, error)
+	// CompleteMorningSchedule marks the morning schedule as sent on the given date.
+	CompleteMorningSchedule(id int64, date string) error
+	// ClaimEveningSchedule is like ClaimMorningSchedule, but for the evening schedule.
+	ClaimEveningSchedule(id int64, date string, timeout time.Duration) (bool, error)
+	// CompleteEveningSchedule marks the evening schedule as sent on the given date.
+	CompleteEveningSchedule(id int64, date string) error
 }
 
 // NewChat creates a new instance of Chat.
@@ -59,9 +156,34 @@ func NewChat(id int64) *Chat {
 		Id:                          id,
 		GroupId:                     -1,
 		LanguageCode:                os.Getenv("DEFAULT_LANG"),
+		Timezone:                    "",
+		SavedGroups:                 GroupRefs{},
 		ClassesNotification15m:      false,
 		ClassesNotification1m:       false,
 		ClassesNotificationNextPart: false,
+		ClassesReminder:             false,
+		ReminderOffset:              DefaultReminderOffset,
+		SnoozedClass:                "",
+		MorningSchedule:             false,
+		MorningScheduleTime:         DefaultMorningScheduleTime,
+		MorningScheduleSent:         "",
+		MorningScheduleClaimed:      0,
+		EveningSchedule:             false,
+		EveningScheduleTime:         DefaultEveningScheduleTime,
+		EveningScheduleSent:         "",
+		EveningScheduleClaimed:      0,
+		DailyScheduleEmpty:          false,
+		TeacherSearchQuery:          "",
+		GroupSearchQuery:            "",
+		NotifyChanges:               false,
+		HideEmptyLessons:            true,
+		HiddenLessons:               HiddenLessons{},
+		StrikeHiddenLessons:         false,
+		LessonReminders:             LessonReminders{},
+		NavStack:                    NavStack{},
+		CleanupPages:                id > 0,
+		LastPageId:                  0,
+		SettingsLocked:              false,
 		SeenSettings:                false,
 		Accessible:                  true,
 		Created:                     time.Now(),
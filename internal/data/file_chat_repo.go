@@ -25,14 +25,21 @@ package data
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // FileChatRepository implements ChatRepository interface using file system.
 //
 // Should be created via NewFileChatRepository.
 type FileChatRepository struct {
+	// mu guards the files, so that the chat is not read
+	// while it's written by the concurrent update handler
+	mu  sync.RWMutex
 	dir string
 }
 
@@ -46,8 +53,34 @@ func NewFileChatRepository(dir string) (ChatRepository, error) {
 }
 
 func (r *FileChatRepository) GetById(id int64) (*Chat, error) {
-	chat := new(Chat)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
+	return r.getById(id)
+}
+
+// Update writes the chat. The claims of the daily schedules are kept, like
+// in the SQL repositories, so the chat read before the claim doesn't drop it.
+func (r *FileChatRepository) Update(chat *Chat) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.getById(chat.Id)
+	if err != nil {
+		return err
+	}
+	if stored != nil {
+		claims := *chat
+		claims.MorningScheduleClaimed = stored.MorningScheduleClaimed
+		claims.EveningScheduleClaimed = stored.EveningScheduleClaimed
+		chat = &claims
+	}
+
+	return r.update(chat)
+}
+
+// getById reads the chat without locking the files
+func (r *FileChatRepository) getById(id int64) (*Chat, error) {
 	file, err := os.Open(r.getChatFile(id))
 	if errors.Is(err, os.ErrNotExist) {
 		return nil, nil
@@ -58,57 +91,180 @@ func (r *FileChatRepository) GetById(id int64) (*Chat, error) {
 
 	defer file.Close()
 
-	err = json.NewDecoder(file).Decode(chat)
-	if err != nil {
-		return nil, err
-	}
-
-	return chat, nil
+	return decodeChat(file)
 }
 
-func (r *FileChatRepository) Update(chat *Chat) error {
-	file, err := os.OpenFile(r.getChatFile(chat.Id), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+// update writes the chat without locking the files.
+//
+// The chat is written to a temporary file that replaces the chat file,
+// so the bot crashed while writing doesn't leave the chat file corrupted.
+func (r *FileChatRepository) update(chat *Chat) error {
+	path := r.getChatFile(chat.Id)
+	file, err := os.CreateTemp(r.dir, strconv.FormatInt(chat.Id, 10)+".*.tmp")
 	if err != nil {
 		return err
 	}
+	defer os.Remove(file.Name())
 
-	defer file.Close()
-
-	err = json.NewEncoder(file).Encode(chat)
-	if err != nil {
+	if err := json.NewEncoder(file).Encode(chat); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
 		return err
 	}
 
-	return nil
+	return os.Rename(file.Name(), path)
 }
 
 func (r *FileChatRepository) GetChatsWithEnabled15mNotification() ([]*Chat, error) {
-	// Iterate over all files in the directory
-	files, err := os.ReadDir(r.dir)
-	if err != nil {
-		return nil, err
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.readChats(func(chat *Chat) bool {
+		return chat.ClassesNotification15m
+	})
+}
+
+func (r *FileChatRepository) GetChatsWithEnabled1mNotification() ([]*Chat, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.readChats(func(chat *Chat) bool {
+		return chat.ClassesNotification1m
+	})
+}
+
+func (r *FileChatRepository) GetChatsWithEnabledReminder() ([]*Chat, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.readChats(func(chat *Chat) bool {
+		return chat.ClassesReminder
+	})
+}
+
+func (r *FileChatRepository) GetChatsWithEnabledMorningSchedule() ([]*Chat, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.readChats(func(chat *Chat) bool {
+		return chat.MorningSchedule
+	})
+}
+
+func (r *FileChatRepository) GetChatsWithEnabledEveningSchedule() ([]*Chat, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.readChats(func(chat *Chat) bool {
+		return chat.EveningSchedule
+	})
+}
+
+func (r *FileChatRepository) GetChatsWithEnabledChangesNotification() ([]*Chat, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.readChats(func(chat *Chat) bool {
+		return chat.NotifyChanges
+	})
+}
+
+func (r *FileChatRepository) GetChatsWithLessonReminders() ([]*Chat, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.readChats(func(chat *Chat) bool {
+		return len(chat.LessonReminders) != 0 && chat.Accessible
+	})
+}
+
+func (r *FileChatRepository) GetAccessibleChats() ([]*Chat, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.readChats(func(chat *Chat) bool {
+		return chat.Accessible
+	})
+}
+
+func (r *FileChatRepository) GetAllChats() ([]*Chat, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.readChats(nil)
+}
+
+func (r *FileChatRepository) ClaimMorningSchedule(id int64, date string, timeout time.Duration) (bool, error) {
+	return r.claimSchedule(id, date, timeout, morningSchedule)
+}
+
+func (r *FileChatRepository) CompleteMorningSchedule(id int64, date string) error {
+	return r.completeSchedule(id, date, morningSchedule)
+}
+
+func (r *FileChatRepository) ClaimEveningSchedule(id int64, date string, timeout time.Duration) (bool, error) {
+	return r.claimSchedule(id, date, timeout, eveningSchedule)
+}
+
+func (r *FileChatRepository) CompleteEveningSchedule(id int64, date string) error {
+	return r.completeSchedule(id, date, eveningSchedule)
+}
+
+// scheduleFields returns the pointers to the sent date and the claim time
+// of the chat daily schedule, see morningSchedule and eveningSchedule
+type scheduleFields func(chat *Chat) (sent *string, claimed *int64)
+
+func morningSchedule(chat *Chat) (*string, *int64) {
+	return &chat.MorningScheduleSent, &chat.MorningScheduleClaimed
+}
+
+func eveningSchedule(chat *Chat) (*string, *int64) {
+	return &chat.EveningScheduleSent, &chat.EveningScheduleClaimed
+}
+
+// claimSchedule claims the daily schedule as one compare-and-set under the write
+// lock, so only one of the concurrent claims succeeds: the claim time is set only
+// if the schedule is not sent on the date and is not claimed less than timeout ago.
+func (r *FileChatRepository) claimSchedule(id int64, date string, timeout time.Duration, fields scheduleFields) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	chat, err := r.getById(id)
+	if err != nil || chat == nil {
+		return false, err
 	}
 
-	// Create slice of chats
-	chats := make([]*Chat, 0, len(files))
+	now := time.Now()
+	sent, claimed := fields(chat)
+	if *sent == date || *claimed > now.Add(-timeout).Unix() {
+		return false, nil
+	}
 
-	for _, file := range files {
-		// Read chat from file
-		chat, err := readChatFile(r.dir + "/" + file.Name())
-		if err != nil {
-			return nil, err
-		}
+	*claimed = now.Unix()
+	return true, r.update(chat)
+}
 
-		// Append chat to slice
-		if chat.ClassesNotification15m {
-			chats = append(chats, chat)
-		}
+// completeSchedule marks the daily schedule as sent on the date
+func (r *FileChatRepository) completeSchedule(id int64, date string, fields scheduleFields) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	chat, err := r.getById(id)
+	if err != nil || chat == nil {
+		return err
 	}
 
-	return chats, nil
+	sent, _ := fields(chat)
+	*sent = date
+	return r.update(chat)
 }
 
-func (r *FileChatRepository) GetChatsWithEnabled1mNotification() ([]*Chat, error) {
+// readChats reads the chats of all the chat files that match the filter.
+// nil filter matches all the chats. Other files in the directory,
+// like the ones left by the editors, are skipped. Must be called with r.mu locked.
+func (r *FileChatRepository) readChats(filter func(chat *Chat) bool) ([]*Chat, error) {
 	// Iterate over all files in the directory
 	files, err := os.ReadDir(r.dir)
 	if err != nil {
@@ -119,14 +275,18 @@ func (r *FileChatRepository) GetChatsWithEnabled1mNotification() ([]*Chat, error
 	chats := make([]*Chat, 0, len(files))
 
 	for _, file := range files {
+		if file.IsDir() || !isChatFile(file.Name()) {
+			// Not a chat file
+			continue
+		}
+
 		// Read chat from file
 		chat, err := readChatFile(r.dir + "/" + file.Name())
 		if err != nil {
 			return nil, err
 		}
 
-		// Append chat to slice
-		if chat.ClassesNotification1m {
+		if filter == nil || filter(chat) {
 			chats = append(chats, chat)
 		}
 	}
@@ -134,6 +294,16 @@ func (r *FileChatRepository) GetChatsWithEnabled1mNotification() ([]*Chat, error
 	return chats, nil
 }
 
+// isChatFile checks if the file is named like the chat file, "<chat id>.json"
+func isChatFile(name string) bool {
+	id, found := strings.CutSuffix(name, ".json")
+	if !found {
+		return false
+	}
+	_, err := strconv.ParseInt(id, 10, 64)
+	return err == nil
+}
+
 // getChatFile returns a path to a file with chat data.
 func (r *FileChatRepository) getChatFile(id int64) string {
 	return r.dir + "/" + strconv.FormatInt(id, 10) + ".json"
@@ -147,9 +317,14 @@ func readChatFile(path string) (*Chat, error) {
 	}
 	defer file.Close()
 
-	chat := new(Chat)
-	err = json.NewDecoder(file).Decode(chat)
-	if err != nil {
+	return decodeChat(file)
+}
+
+// decodeChat decodes the chat file. The files written before the
+// breaks setting was added keep the breaks hidden, as they were.
+func decodeChat(r io.Reader) (*Chat, error) {
+	chat := &Chat{HideEmptyLessons: true}
+	if err := json.NewDecoder(r).Decode(chat); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,154 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestFileChatRepo(t *testing.T, dir string, ids ...int64) ChatRepository {
+	repo, err := NewFileChatRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		if err := repo.Update(NewChat(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repo
+}
+
+func TestFileChatRepositoryConcurrentClaims(t *testing.T) {
+	repo := newTestFileChatRepo(t, t.TempDir(), 1)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	claims := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			claimed, err := repo.ClaimMorningSchedule(1, "2024-10-28", time.Hour)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if claimed {
+				mu.Lock()
+				claims++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if claims != 1 {
+		t.Errorf("schedule is claimed %d times, want once", claims)
+	}
+}
+
+func TestFileChatRepositoryUpdateKeepsClaim(t *testing.T) {
+	repo := newTestFileChatRepo(t, t.TempDir(), 1)
+
+	// The chat is read by the update handler before the notifier claims it
+	stale, err := repo.GetById(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed, err := repo.ClaimMorningSchedule(1, "2024-10-28", time.Hour); err != nil || !claimed {
+		t.Fatalf("ClaimMorningSchedule = %t, %v, want true", claimed, err)
+	}
+
+	stale.GroupId = 1234
+	if err := repo.Update(stale); err != nil {
+		t.Fatal(err)
+	}
+
+	if claimed, err := repo.ClaimMorningSchedule(1, "2024-10-28", time.Hour); err != nil || claimed {
+		t.Errorf("ClaimMorningSchedule after the update = %t, %v, want false", claimed, err)
+	}
+	chat, err := repo.GetById(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat.GroupId != 1234 {
+		t.Errorf("chat group is %d, want 1234", chat.GroupId)
+	}
+}
+
+// TestFileChatRepositoryCrashBeforeComplete simulates the bot crashed after it
+// picked the chats and before it marked all of them as sent. After the restart
+// every chat must get exactly one message.
+func TestFileChatRepositoryCrashBeforeComplete(t *testing.T) {
+	const date = "2024-10-28"
+	dir := t.TempDir()
+	ids := []int64{1, 2, 3, 4}
+	sent := make(map[int64]int)
+
+	// deliver claims and sends the schedule to the chats like the notifier does.
+	// It stops after sending crashAfter messages, if it's not negative.
+	deliver := func(repo ChatRepository, timeout time.Duration, crashAfter int) {
+		for _, id := range ids {
+			claimed, err := repo.ClaimMorningSchedule(id, date, timeout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !claimed {
+				continue
+			}
+			if crashAfter == 0 {
+				// Crashed between "picked chat" and "marked sent"
+				return
+			}
+			crashAfter--
+
+			sent[id]++
+			if err := repo.CompleteMorningSchedule(id, date); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	deliver(newTestFileChatRepo(t, dir, ids...), time.Hour, 2)
+
+	// Restarted, the claim of the crashed run is not expired yet,
+	// so only the chat that was not picked gets the schedule
+	restarted := newTestFileChatRepo(t, dir)
+	deliver(restarted, time.Hour, -1)
+	if sent[3] != 0 || sent[4] != 1 {
+		t.Fatalf("chats 3 and 4 got %d and %d messages before the claim expired, want 0 and 1", sent[3], sent[4])
+	}
+
+	// The claims expired, so the rest of the chats get the schedule
+	deliver(restarted, -time.Second, -1)
+	deliver(restarted, -time.Second, -1)
+
+	for _, id := range ids {
+		if sent[id] != 1 {
+			t.Errorf("chat %d got %d messages, want 1", id, sent[id])
+		}
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+)
+
+// FileUsageStatsRepository implements UsageStatsRepository interface
+// by storing all the counters in a json file.
+// The counters are kept in memory and the file is rewritten on every change.
+//
+// Should be created via NewFileUsageStatsRepository.
+type FileUsageStatsRepository struct {
+	// mu guards the file, so it's not written by two flushes at once
+	mu     sync.Mutex
+	file   string
+	memory UsageStatsRepository
+}
+
+// NewFileUsageStatsRepository creates a new instance of FileUsageStatsRepository.
+// The counters are loaded from the file, if it exists.
+func NewFileUsageStatsRepository(file string) (UsageStatsRepository, error) {
+	r := &FileUsageStatsRepository{file: file, memory: NewMemoryUsageStatsRepository()}
+
+	content, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var counters []UsageCounter
+	if err := json.Unmarshal(content, &counters); err != nil {
+		return nil, err
+	}
+	if err := r.memory.AddCounters(counters); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *FileUsageStatsRepository) AddCounters(counters []UsageCounter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.memory.AddCounters(counters); err != nil {
+		return err
+	}
+	return r.save()
+}
+
+func (r *FileUsageStatsRepository) GetCounters(from string) ([]UsageCounter, error) {
+	return r.memory.GetCounters(from)
+}
+
+func (r *FileUsageStatsRepository) DeleteCountersBefore(date string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.memory.DeleteCountersBefore(date); err != nil {
+		return err
+	}
+	return r.save()
+}
+
+// save writes all the counters to the file.
+// The file is replaced atomically, so it is not corrupted if the bot is killed.
+// Must be called with the mutex locked.
+func (r *FileUsageStatsRepository) save() error {
+	counters, err := r.memory.GetCounters("")
+	if err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(counters)
+	if err != nil {
+		return err
+	}
+	tmpFile := r.file + ".tmp"
+	if err := os.WriteFile(tmpFile, content, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, r.file)
+}
@@ -27,12 +27,17 @@ import (
 	"errors"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
 )
 
 // FileUserRepository implements UserRepository interface by storing data in files.
 //
 // Should be created via NewFileUserRepository.
 type FileUserRepository struct {
+	// mu guards the files, so that the user is not read
+	// while it's written by the concurrent update handler
+	mu  sync.RWMutex
 	dir string
 }
 
@@ -46,6 +51,9 @@ func NewFileUserRepository(dir string) (UserRepository, error) {
 }
 
 func (r *FileUserRepository) GetById(id int64) (*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	user := new(User)
 
 	file, err := os.Open(r.getUserFile(id))
@@ -67,6 +75,9 @@ func (r *FileUserRepository) GetById(id int64) (*User, error) {
 }
 
 func (r *FileUserRepository) Update(user *User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	file, err := os.OpenFile(r.getUserFile(user.Id), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
 	if err != nil {
 		return err
@@ -82,6 +93,34 @@ func (r *FileUserRepository) Update(user *User) error {
 	return nil
 }
 
+func (r *FileUserRepository) GetAllUsers() ([]*User, error) {
+	// Iterate over all files in the directory
+	files, err := os.ReadDir(r.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*User, 0, len(files))
+
+	for _, file := range files {
+		id, err := strconv.ParseInt(strings.TrimSuffix(file.Name(), ".json"), 10, 64)
+		if err != nil {
+			// Not a user file
+			continue
+		}
+
+		user, err := r.GetById(id)
+		if err != nil {
+			return nil, err
+		}
+		if user != nil {
+			users = append(users, user)
+		}
+	}
+
+	return users, nil
+}
+
 // getUserFile returns a path to the file with user data.
 func (r *FileUserRepository) getUserFile(id int64) string {
 	return r.dir + "/" + strconv.FormatInt(id, 10) + ".json"
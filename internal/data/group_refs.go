@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// GroupRef is a group saved by the chat
+type GroupRef struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// GroupRefs is a list of saved groups.
+// It's stored in the database as JSON.
+type GroupRefs []GroupRef
+
+// Index returns the index of the group in the list, or -1 if it's not saved
+func (g GroupRefs) Index(groupId int) int {
+	for i, group := range g {
+		if group.Id == groupId {
+			return i
+		}
+	}
+	return -1
+}
+
+// Remove returns the list without the group
+func (g GroupRefs) Remove(groupId int) GroupRefs {
+	i := g.Index(groupId)
+	if i == -1 {
+		return g
+	}
+	return append(g[:i:i], g[i+1:]...)
+}
+
+// WithFirst returns the list with the group moved or added to the start,
+// e.g. to show the current chat group before the saved ones
+func (g GroupRefs) WithFirst(groupId int) GroupRefs {
+	first := GroupRef{Id: groupId}
+	if i := g.Index(groupId); i != -1 {
+		first = g[i]
+	}
+
+	return append(GroupRefs{first}, g.Remove(groupId)...)
+}
+
+// Value implements driver.Valuer
+func (g GroupRefs) Value() (driver.Value, error) {
+	if g == nil {
+		return "[]", nil
+	}
+
+	b, err := json.Marshal(g)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (g *GroupRefs) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*g = GroupRefs{}
+		return nil
+	case []byte:
+		return json.Unmarshal(src, g)
+	case string:
+		return json.Unmarshal([]byte(src), g)
+	default:
+		return fmt.Errorf("unsupported type for GroupRefs: %T", src)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// MaxHiddenLessons is the maximum number of lessons a chat can hide
+const MaxHiddenLessons = 20
+
+// HiddenLesson is a lesson the chat doesn't attend.
+// Id is the same for all the lesson occurrences, see pages.GetLessonId.
+type HiddenLesson struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Teacher string `json:"teacher"`
+}
+
+// HiddenLessons is a list of hidden lessons.
+// It's stored in the database as JSON.
+type HiddenLessons []HiddenLesson
+
+// Index returns the index of the lesson in the list, or -1 if it's not hidden
+func (l HiddenLessons) Index(id string) int {
+	for i, lesson := range l {
+		if lesson.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Remove returns the list without the lesson
+func (l HiddenLessons) Remove(id string) HiddenLessons {
+	i := l.Index(id)
+	if i == -1 {
+		return l
+	}
+	return append(l[:i:i], l[i+1:]...)
+}
+
+// Value implements driver.Valuer
+func (l HiddenLessons) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (l *HiddenLessons) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*l = HiddenLessons{}
+		return nil
+	case []byte:
+		return json.Unmarshal(src, l)
+	case string:
+		return json.Unmarshal([]byte(src), l)
+	default:
+		return fmt.Errorf("unsupported type for HiddenLessons: %T", src)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"sync"
+	"time"
+)
+
+// InputStateTTL is how long the bot waits for the input in the chat.
+// After that, messages of the chat are not treated as the input anymore.
+const InputStateTTL = 5 * time.Minute
+
+// InputState is a kind of the text message the bot waits for in the chat
+type InputState string
+
+const (
+	// InputNone means the bot doesn't wait for any input
+	InputNone InputState = ""
+	// InputGroupSearch means the next message is the name of the group to search
+	InputGroupSearch InputState = "group_search"
+	// InputTeacherSearch means the next messages are the teacher surname to search.
+	// Every message changes the query until the search button is pressed
+	InputTeacherSearch InputState = "teacher_search"
+	// InputTimezone means the next message is the name of the chat timezone
+	InputTimezone InputState = "timezone"
+)
+
+// InputStates keeps the pending input states of the chats.
+//
+// States are kept in memory only, because they live just a few minutes.
+// Should be created via NewInputStates.
+type InputStates struct {
+	// TTL is how long the state is kept after it was set
+	TTL time.Duration
+
+	mu     sync.Mutex
+	states map[int64]inputState
+}
+
+type inputState struct {
+	State   InputState
+	Expires time.Time
+}
+
+// NewInputStates creates a new instance of InputStates.
+func NewInputStates(ttl time.Duration) *InputStates {
+	return &InputStates{
+		TTL:    ttl,
+		states: make(map[int64]inputState),
+	}
+}
+
+// Set sets the input the bot waits for in the chat.
+// Also removes the expired states of all the chats.
+func (s *InputStates) Set(chatId int64, state InputState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, st := range s.states {
+		if now.After(st.Expires) {
+			delete(s.states, id)
+		}
+	}
+
+	s.states[chatId] = inputState{State: state, Expires: now.Add(s.TTL)}
+}
+
+// Get returns the input the bot waits for in the chat,
+// or InputNone if there is no such input or it has expired.
+func (s *InputStates) Get(chatId int64) InputState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.states[chatId]
+	if !ok || time.Now().After(st.Expires) {
+		return InputNone
+	}
+
+	return st.State
+}
+
+// Clear stops waiting for the input in the chat.
+func (s *InputStates) Clear(chatId int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.states, chatId)
+}
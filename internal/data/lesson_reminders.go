@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// MaxLessonReminders is the maximum number of pending lesson reminders of a chat
+const MaxLessonReminders = 10
+
+// LessonReminder is a one-shot reminder about a single lesson,
+// set from the lesson details page
+type LessonReminder struct {
+	GroupId int `json:"groupId"`
+	// Date is the lesson date, in time.DateOnly format
+	Date   string `json:"date"`
+	Number int    `json:"number"`
+	Name   string `json:"name"`
+	// Offset is the number of minutes before the lesson start
+	Offset int `json:"offset"`
+	// Time is when to send the reminder, in unix seconds
+	Time int64 `json:"time"`
+}
+
+// LessonReminders is a list of pending lesson reminders.
+// It's stored in the database as JSON.
+type LessonReminders []LessonReminder
+
+// Index returns the index of the lesson reminder in the list, or -1 if there is no such reminder
+func (r LessonReminders) Index(groupId int, date string, number int) int {
+	for i, reminder := range r {
+		if reminder.GroupId == groupId && reminder.Date == date && reminder.Number == number {
+			return i
+		}
+	}
+	return -1
+}
+
+// Remove returns the list without the lesson reminder
+func (r LessonReminders) Remove(groupId int, date string, number int) LessonReminders {
+	i := r.Index(groupId, date, number)
+	if i == -1 {
+		return r
+	}
+	return append(r[:i:i], r[i+1:]...)
+}
+
+// Value implements driver.Valuer
+func (r LessonReminders) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (r *LessonReminders) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*r = LessonReminders{}
+		return nil
+	case []byte:
+		return json.Unmarshal(src, r)
+	case string:
+		return json.Unmarshal([]byte(src), r)
+	default:
+		return fmt.Errorf("unsupported type for LessonReminders: %T", src)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryChatRepository implements ChatRepository interface in memory.
+// The chats are lost when the bot stops, so it's meant for the tests
+// that don't need a real database.
+//
+// Should be created via NewMemoryChatRepository.
+type MemoryChatRepository struct {
+	mu    sync.Mutex
+	chats map[int64]*Chat
+}
+
+// NewMemoryChatRepository creates a new instance of MemoryChatRepository.
+func NewMemoryChatRepository() ChatRepository {
+	return &MemoryChatRepository{chats: make(map[int64]*Chat)}
+}
+
+func (r *MemoryChatRepository) GetById(id int64) (*Chat, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	chat, ok := r.chats[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return copyChat(chat), nil
+}
+
+func (r *MemoryChatRepository) Update(chat *Chat) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.chats[chat.Id] = copyChat(chat)
+	return nil
+}
+
+func (r *MemoryChatRepository) GetChatsWithEnabled15mNotification() ([]*Chat, error) {
+	return r.filter(func(chat *Chat) bool {
+		return chat.ClassesNotification15m && chat.Accessible && chat.GroupId != -1
+	}), nil
+}
+
+func (r *MemoryChatRepository) GetChatsWithEnabled1mNotification() ([]*Chat, error) {
+	return r.filter(func(chat *Chat) bool {
+		return chat.ClassesNotification1m && chat.Accessible && chat.GroupId != -1
+	}), nil
+}
+
+func (r *MemoryChatRepository) GetChatsWithEnabledReminder() ([]*Chat, error) {
+	return r.filter(func(chat *Chat) bool {
+		return chat.ClassesReminder && chat.Accessible && chat.GroupId != -1
+	}), nil
+}
+
+func (r *MemoryChatRepository) GetChatsWithEnabledMorningSchedule() ([]*Chat, error) {
+	return r.filter(func(chat *Chat) bool {
+		return chat.MorningSchedule && chat.Accessible && chat.GroupId != -1
+	}), nil
+}
+
+func (r *MemoryChatRepository) GetChatsWithEnabledEveningSchedule() ([]*Chat, error) {
+	return r.filter(func(chat *Chat) bool {
+		return chat.EveningSchedule && chat.Accessible && chat.GroupId != -1
+	}), nil
+}
+
+func (r *MemoryChatRepository) GetChatsWithEnabledChangesNotification() ([]*Chat, error) {
+	return r.filter(func(chat *Chat) bool {
+		return chat.NotifyChanges && chat.Accessible && chat.GroupId != -1
+	}), nil
+}
+
+func (r *MemoryChatRepository) GetChatsWithLessonReminders() ([]*Chat, error) {
+	return r.filter(func(chat *Chat) bool {
+		return len(chat.LessonReminders) != 0 && chat.Accessible
+	}), nil
+}
+
+func (r *MemoryChatRepository) GetAccessibleChats() ([]*Chat, error) {
+	return r.filter(func(chat *Chat) bool {
+		return chat.Accessible
+	}), nil
+}
+
+func (r *MemoryChatRepository) GetAllChats() ([]*Chat, error) {
+	return r.filter(func(chat *Chat) bool {
+		return true
+	}), nil
+}
+
+func (r *MemoryChatRepository) ClaimMorningSchedule(id int64, date string, timeout time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	chat, ok := r.chats[id]
+	if !ok || chat.MorningScheduleSent == date || chat.MorningScheduleClaimed > now.Add(-timeout).Unix() {
+		return false, nil
+	}
+
+	chat.MorningScheduleClaimed = now.Unix()
+	return true, nil
+}
+
+func (r *MemoryChatRepository) CompleteMorningSchedule(id int64, date string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if chat, ok := r.chats[id]; ok {
+		chat.MorningScheduleSent = date
+	}
+	return nil
+}
+
+func (r *MemoryChatRepository) ClaimEveningSchedule(id int64, date string, timeout time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	chat, ok := r.chats[id]
+	if !ok || chat.EveningScheduleSent == date || chat.EveningScheduleClaimed > now.Add(-timeout).Unix() {
+		return false, nil
+	}
+
+	chat.EveningScheduleClaimed = now.Unix()
+	return true, nil
+}
+
+func (r *MemoryChatRepository) CompleteEveningSchedule(id int64, date string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if chat, ok := r.chats[id]; ok {
+		chat.EveningScheduleSent = date
+	}
+	return nil
+}
+
+// filter returns the copies of the chats for which keep returns true, ordered by id
+func (r *MemoryChatRepository) filter(keep func(chat *Chat) bool) []*Chat {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	chats := make([]*Chat, 0)
+	for _, chat := range r.chats {
+		if keep(chat) {
+			chats = append(chats, copyChat(chat))
+		}
+	}
+
+	sort.Slice(chats, func(i, j int) bool {
+		return chats[i].Id < chats[j].Id
+	})
+	return chats
+}
+
+// copyChat returns a copy of the chat, so the stored chat
+// isn't changed until Update is called
+func copyChat(chat *Chat) *Chat {
+	c := *chat
+	c.SavedGroups = append(GroupRefs{}, chat.SavedGroups...)
+	c.HiddenLessons = append(HiddenLessons{}, chat.HiddenLessons...)
+	c.LessonReminders = append(LessonReminders{}, chat.LessonReminders...)
+	c.NavStack = append(NavStack{}, chat.NavStack...)
+	return &c
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import "sync"
+
+// MemoryUsageStatsRepository implements UsageStatsRepository interface in memory.
+// The counters are lost when the bot stops, so it's meant for the tests
+// that don't need a real database.
+//
+// Should be created via NewMemoryUsageStatsRepository.
+type MemoryUsageStatsRepository struct {
+	mu       sync.Mutex
+	counters map[usageKey]int
+}
+
+// NewMemoryUsageStatsRepository creates a new instance of MemoryUsageStatsRepository.
+func NewMemoryUsageStatsRepository() UsageStatsRepository {
+	return &MemoryUsageStatsRepository{counters: make(map[usageKey]int)}
+}
+
+func (r *MemoryUsageStatsRepository) AddCounters(counters []UsageCounter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, counter := range counters {
+		r.counters[usageKey{Date: counter.Date, Kind: counter.Kind, Key: counter.Key}] += counter.Count
+	}
+	return nil
+}
+
+func (r *MemoryUsageStatsRepository) GetCounters(from string) ([]UsageCounter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counters := make([]UsageCounter, 0, len(r.counters))
+	for key, count := range r.counters {
+		if key.Date >= from {
+			counters = append(counters, UsageCounter{Date: key.Date, Kind: key.Kind, Key: key.Key, Count: count})
+		}
+	}
+
+	// Map order is random
+	sortUsageCounters(counters)
+	return counters, nil
+}
+
+func (r *MemoryUsageStatsRepository) DeleteCountersBefore(date string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key := range r.counters {
+		if key.Date < date {
+			delete(r.counters, key)
+		}
+	}
+	return nil
+}
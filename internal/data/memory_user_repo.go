@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"sort"
+	"sync"
+)
+
+// MemoryUserRepository implements UserRepository interface in memory.
+// The users are lost when the bot stops, so it's meant for the tests
+// that don't need a real database.
+//
+// Should be created via NewMemoryUserRepository.
+type MemoryUserRepository struct {
+	mu    sync.Mutex
+	users map[int64]*User
+}
+
+// NewMemoryUserRepository creates a new instance of MemoryUserRepository.
+func NewMemoryUserRepository() UserRepository {
+	return &MemoryUserRepository{users: make(map[int64]*User)}
+}
+
+func (r *MemoryUserRepository) GetById(id int64) (*User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, nil
+	}
+
+	u := *user
+	return &u, nil
+}
+
+func (r *MemoryUserRepository) Update(user *User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u := *user
+	r.users[user.Id] = &u
+	return nil
+}
+
+func (r *MemoryUserRepository) GetAllUsers() ([]*User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := make([]*User, 0, len(r.users))
+	for _, user := range r.users {
+		u := *user
+		users = append(users, &u)
+	}
+
+	// Map order is random
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Id < users[j].Id
+	})
+	return users, nil
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"embed"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed sql/migrations/*.sql sql/sqlite/migrations/*.sql
+var migrations embed.FS
+
+// migrationsLockId is a postgres advisory lock id, so the bot
+// instances started at the same time don't apply the migrations twice
+const migrationsLockId = 4242
+
+// Migrate applies the PostgreSQL schema migrations that are not applied yet.
+//
+// Migrations are the sql/migrations/NNN_name.sql files, applied in order
+// of their numbers. Every migration is applied in a separate transaction.
+// Returns the number of applied migrations.
+func Migrate(db *sqlx.DB) (int, error) {
+	return migrate(db, "sql/migrations", true)
+}
+
+// MigrateSQLite applies the SQLite schema migrations that are not applied yet.
+//
+// Same as Migrate, but the migrations are the sql/sqlite/migrations/NNN_name.sql files.
+func MigrateSQLite(db *sqlx.DB) (int, error) {
+	return migrate(db, "sql/sqlite/migrations", false)
+}
+
+// migrate applies the migrations from the dir.
+// If lock is true, the postgres advisory lock is held while applying a migration.
+func migrate(db *sqlx.DB, dir string, lock bool) (int, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INT NOT NULL,
+    applied TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (version)
+)`)
+	if err != nil {
+		return 0, err
+	}
+
+	files, err := migrations.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name() < files[j].Name()
+	})
+
+	appliedCount := 0
+	for _, file := range files {
+		version, err := strconv.Atoi(strings.SplitN(file.Name(), "_", 2)[0])
+		if err != nil {
+			return appliedCount, fmt.Errorf("invalid migration name %s: %w", file.Name(), err)
+		}
+
+		query, err := migrations.ReadFile(path.Join(dir, file.Name()))
+		if err != nil {
+			return appliedCount, err
+		}
+
+		applied, err := applyMigration(db, version, string(query), lock)
+		if err != nil {
+			return appliedCount, fmt.Errorf("error applying migration %s: %w", file.Name(), err)
+		}
+		if applied {
+			appliedCount++
+		}
+	}
+
+	return appliedCount, nil
+}
+
+// applyMigration applies the migration if it's not applied yet.
+// Returns true if the migration was applied.
+func applyMigration(db *sqlx.DB, version int, query string, lock bool) (bool, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Lock is released when the transaction ends
+	if lock {
+		if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", migrationsLockId); err != nil {
+			return false, err
+		}
+	}
+
+	var count int
+	if err := tx.Get(&count, tx.Rebind("SELECT COUNT(*) FROM schema_migrations WHERE version = ?"), version); err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(query); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(tx.Rebind("INSERT INTO schema_migrations (version) VALUES (?)"), version); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// MaxNavDepth is the maximum number of pages the back button can return through
+const MaxNavDepth = 5
+
+// NavEntry is a page opened with the button, that the back button returns to
+type NavEntry struct {
+	MessageId int64 `json:"messageId"`
+	// Data is the callback data of the button the page is opened with
+	Data string `json:"data"`
+}
+
+// NavStack is a list of the pages recently opened in the chat messages,
+// the last opened is on top. It's stored in the database as JSON.
+type NavStack []NavEntry
+
+// Top returns the page currently opened in the message, or false if it's unknown
+func (s NavStack) Top(messageId int64) (NavEntry, bool) {
+	i := s.lastIndex(messageId, len(s))
+	if i == -1 {
+		return NavEntry{}, false
+	}
+	return s[i], true
+}
+
+// Push returns the stack with the entry on top.
+// The oldest entries over MaxNavDepth are removed.
+func (s NavStack) Push(entry NavEntry) NavStack {
+	s = append(s[:len(s):len(s)], entry)
+	if len(s) > MaxNavDepth {
+		s = s[len(s)-MaxNavDepth:]
+	}
+	return s
+}
+
+// Back returns the stack without the page currently opened in the message,
+// and the page opened in the message before it. Returns false if there is
+// no previous page, e.g. the message was sent by the command.
+func (s NavStack) Back(messageId int64) (NavStack, NavEntry, bool) {
+	current := s.lastIndex(messageId, len(s))
+	if current == -1 {
+		return s, NavEntry{}, false
+	}
+	s = append(s[:current:current], s[current+1:]...)
+
+	prev := s.lastIndex(messageId, current)
+	if prev == -1 {
+		return s, NavEntry{}, false
+	}
+	return s, s[prev], true
+}
+
+// lastIndex returns the index of the last page of the message before end, or -1 if there is no such page
+func (s NavStack) lastIndex(messageId int64, end int) int {
+	for i := end - 1; i >= 0; i-- {
+		if s[i].MessageId == messageId {
+			return i
+		}
+	}
+	return -1
+}
+
+// Value implements driver.Valuer
+func (s NavStack) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (s *NavStack) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*s = NavStack{}
+		return nil
+	case []byte:
+		return json.Unmarshal(src, s)
+	case string:
+		return json.Unmarshal([]byte(src), s)
+	default:
+		return fmt.Errorf("unsupported type for NavStack: %T", src)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestNavStackPushCap(t *testing.T) {
+	var stack NavStack
+	for i := 0; i < MaxNavDepth+2; i++ {
+		stack = stack.Push(NavEntry{MessageId: 1, Data: strconv.Itoa(i)})
+	}
+
+	if len(stack) != MaxNavDepth {
+		t.Fatalf("got %d entries, want %d", len(stack), MaxNavDepth)
+	}
+	// The oldest entries are removed
+	if stack[0].Data != "2" || stack[len(stack)-1].Data != strconv.Itoa(MaxNavDepth+1) {
+		t.Errorf("got %+v, want the last %d entries", stack, MaxNavDepth)
+	}
+}
+
+func TestNavStackPushDoesNotShareArray(t *testing.T) {
+	stack := make(NavStack, 1, 4)
+	stack[0] = NavEntry{MessageId: 1, Data: "a"}
+
+	first := stack.Push(NavEntry{MessageId: 1, Data: "b"})
+	second := stack.Push(NavEntry{MessageId: 1, Data: "c"})
+	if first[1].Data != "b" || second[1].Data != "c" {
+		t.Errorf("pushes to the same stack overwrite each other: %+v, %+v", first, second)
+	}
+}
+
+func TestNavStackBack(t *testing.T) {
+	stack := NavStack{
+		{MessageId: 1, Data: "open.menu"},
+		{MessageId: 2, Data: "open.settings"},
+		{MessageId: 1, Data: "open.schedule.day"},
+		{MessageId: 1, Data: "open.schedule.extra"},
+	}
+
+	if top, ok := stack.Top(1); !ok || top.Data != "open.schedule.extra" {
+		t.Errorf("Top(1) = %+v, %t, want the extra page", top, ok)
+	}
+
+	stack, prev, ok := stack.Back(1)
+	if !ok || prev.Data != "open.schedule.day" {
+		t.Fatalf("Back(1) = %+v, %t, want the day schedule", prev, ok)
+	}
+	stack, prev, ok = stack.Back(1)
+	if !ok || prev.Data != "open.menu" {
+		t.Fatalf("second Back(1) = %+v, %t, want the menu", prev, ok)
+	}
+
+	// Back past the root, the menu is opened then
+	stack, _, ok = stack.Back(1)
+	if ok {
+		t.Error("Back(1) from the first page returned a page")
+	}
+	want := NavStack{{MessageId: 2, Data: "open.settings"}}
+	if !reflect.DeepEqual(stack, want) {
+		t.Errorf("got %+v, want only the other message page left", stack)
+	}
+
+	// Message without the pages, e.g. sent by the command
+	if _, _, ok := stack.Back(3); ok {
+		t.Error("Back(3) of the unknown message returned a page")
+	}
+}
+
+func TestNavStackScan(t *testing.T) {
+	tests := []struct {
+		name string
+		src  any
+		want NavStack
+	}{
+		// Chats saved before the stack was added
+		{"null", nil, NavStack{}},
+		{"empty", "[]", NavStack{}},
+		{"bytes", []byte(`[{"messageId":1,"data":"open.menu"}]`), NavStack{{MessageId: 1, Data: "open.menu"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stack NavStack
+			if err := stack.Scan(tt.src); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(stack, tt.want) {
+				t.Errorf("Scan(%v) = %+v, want %+v", tt.src, stack, tt.want)
+			}
+		})
+	}
+
+	value, err := NavStack(nil).Value()
+	if err != nil || value != "[]" {
+		t.Errorf("nil stack Value() = %v, %v, want []", value, err)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	_ "embed"
 	"errors"
 	"github.com/jmoiron/sqlx"
+	"time"
 )
 
 // Load SQL queries from files
@@ -40,6 +41,29 @@ var (
 	getChats15mQuery string
 	//go:embed sql/get_chats_1m.sql
 	getChats1mQuery string
+	//go:embed sql/get_chats_reminder.sql
+	getChatsReminderQuery string
+	//go:embed sql/get_chats_morning_schedule.sql
+	getChatsMorningScheduleQuery string
+	//go:embed sql/get_chats_evening_schedule.sql
+	getChatsEveningScheduleQuery string
+	//go:embed sql/get_chats_changes.sql
+	getChatsChangesQuery string
+	//go:embed sql/get_chats_lesson_reminders.sql
+	getChatsLessonRemindersQuery string
+	//go:embed sql/get_chats_accessible.sql
+	getChatsAccessibleQuery string
+	//go:embed sql/get_chats_all.sql
+	getChatsAllQuery string
+
+	//go:embed sql/claim_morning_schedule.sql
+	claimMorningScheduleQuery string
+	//go:embed sql/claim_evening_schedule.sql
+	claimEveningScheduleQuery string
+	//go:embed sql/complete_morning_schedule.sql
+	completeMorningScheduleQuery string
+	//go:embed sql/complete_evening_schedule.sql
+	completeEveningScheduleQuery string
 )
 
 // PostgresChatRepository implements ChatRepository interface for PostgreSQL.
@@ -100,3 +124,116 @@ func (r *PostgresChatRepository) GetChatsWithEnabled1mNotification() ([]*Chat, e
 
 	return chats, nil
 }
+
+func (r *PostgresChatRepository) GetChatsWithEnabledReminder() ([]*Chat, error) {
+	chats := make([]*Chat, 0)
+	err := r.db.Select(&chats, getChatsReminderQuery)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return chats, nil
+}
+
+func (r *PostgresChatRepository) GetChatsWithEnabledMorningSchedule() ([]*Chat, error) {
+	chats := make([]*Chat, 0)
+	err := r.db.Select(&chats, getChatsMorningScheduleQuery)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return chats, nil
+}
+
+func (r *PostgresChatRepository) GetChatsWithEnabledEveningSchedule() ([]*Chat, error) {
+	chats := make([]*Chat, 0)
+	err := r.db.Select(&chats, getChatsEveningScheduleQuery)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return chats, nil
+}
+
+func (r *PostgresChatRepository) GetChatsWithEnabledChangesNotification() ([]*Chat, error) {
+	chats := make([]*Chat, 0)
+	err := r.db.Select(&chats, getChatsChangesQuery)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return chats, nil
+}
+
+func (r *PostgresChatRepository) GetChatsWithLessonReminders() ([]*Chat, error) {
+	chats := make([]*Chat, 0)
+	err := r.db.Select(&chats, getChatsLessonRemindersQuery)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return chats, nil
+}
+
+func (r *PostgresChatRepository) GetAccessibleChats() ([]*Chat, error) {
+	chats := make([]*Chat, 0)
+	err := r.db.Select(&chats, getChatsAccessibleQuery)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return chats, nil
+}
+
+func (r *PostgresChatRepository) GetAllChats() ([]*Chat, error) {
+	chats := make([]*Chat, 0)
+	err := r.db.Select(&chats, getChatsAllQuery)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return chats, nil
+}
+
+func (r *PostgresChatRepository) ClaimMorningSchedule(id int64, date string, timeout time.Duration) (bool, error) {
+	return r.claim(claimMorningScheduleQuery, id, date, timeout)
+}
+
+func (r *PostgresChatRepository) CompleteMorningSchedule(id int64, date string) error {
+	_, err := r.db.Exec(completeMorningScheduleQuery, id, date)
+	return err
+}
+
+func (r *PostgresChatRepository) ClaimEveningSchedule(id int64, date string, timeout time.Duration) (bool, error) {
+	return r.claim(claimEveningScheduleQuery, id, date, timeout)
+}
+
+func (r *PostgresChatRepository) CompleteEveningSchedule(id int64, date string) error {
+	_, err := r.db.Exec(completeEveningScheduleQuery, id, date)
+	return err
+}
+
+// claim executes the claim query and checks if the row was updated.
+//
+// Update is atomic, so only one bot instance can claim the same date.
+func (r *PostgresChatRepository) claim(query string, id int64, date string, timeout time.Duration) (bool, error) {
+	now := time.Now()
+	res, err := r.db.Exec(query, id, date, now.Unix(), now.Add(-timeout).Unix())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
@@ -35,6 +35,8 @@ var (
 	getUserQuery string
 	//go:embed sql/update_user.sql
 	updateUserQuery string
+	//go:embed sql/get_users_all.sql
+	getUsersAllQuery string
 )
 
 // PostgresUserRepository implements UserRepository interface for PostgreSQL.
@@ -71,3 +73,14 @@ func (r *PostgresUserRepository) Update(user *User) error {
 
 	return nil
 }
+
+func (r *PostgresUserRepository) GetAllUsers() ([]*User, error) {
+	users := make([]*User, 0)
+	err := r.db.Select(&users, getUsersAllQuery)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/cubicbyte/dteubot/pkg/api"
+	"os"
+)
+
+// ScheduleSnapshot is the last received schedule of the group
+type ScheduleSnapshot struct {
+	// DateEnd is the last day of the schedule, in time.DateOnly format
+	DateEnd  string       `json:"dateEnd"`
+	Schedule api.Schedule `json:"schedule"`
+	// EmptySince is the Monday of the first next week in a row without lessons,
+	// to detect the new semester schedule. Empty if the last next week had lessons.
+	EmptySince string `json:"emptySince,omitempty"`
+}
+
+// ScheduleSnapshots stores the last received schedules of the groups,
+// to find out what has changed in the fresh ones.
+//
+// Saved to the JSON file, so the changes made while the bot
+// was restarting are noticed too. Not safe for concurrent use.
+//
+// Should be created via NewScheduleSnapshots.
+type ScheduleSnapshots struct {
+	file      string
+	snapshots map[int]ScheduleSnapshot
+}
+
+// NewScheduleSnapshots creates a new instance of ScheduleSnapshots
+// and loads the snapshots saved to the file. If file is empty,
+// the snapshots are kept in memory only.
+func NewScheduleSnapshots(file string) (*ScheduleSnapshots, error) {
+	s := &ScheduleSnapshots{
+		file:      file,
+		snapshots: make(map[int]ScheduleSnapshot),
+	}
+
+	if file == "" {
+		return s, nil
+	}
+
+	content, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, &s.snapshots); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Get returns the snapshot of the group schedule.
+// ok is false if there is no snapshot yet.
+func (s *ScheduleSnapshots) Get(groupId int) (snapshot ScheduleSnapshot, ok bool) {
+	snapshot, ok = s.snapshots[groupId]
+	return snapshot, ok
+}
+
+// Set replaces the snapshot of the group schedule.
+// Call Save to write the changes to the file.
+func (s *ScheduleSnapshots) Set(groupId int, snapshot ScheduleSnapshot) {
+	s.snapshots[groupId] = snapshot
+}
+
+// Retain removes the snapshots of the groups for which keep returns false
+func (s *ScheduleSnapshots) Retain(keep func(groupId int) bool) {
+	for groupId := range s.snapshots {
+		if !keep(groupId) {
+			delete(s.snapshots, groupId)
+		}
+	}
+}
+
+// Save writes the snapshots to the file.
+// The file is replaced atomically, so it is not corrupted if the bot is killed.
+func (s *ScheduleSnapshots) Save() error {
+	if s.file == "" {
+		return nil
+	}
+
+	content, err := json.Marshal(s.snapshots)
+	if err != nil {
+		return err
+	}
+
+	tmpFile := s.file + ".tmp"
+	if err := os.WriteFile(tmpFile, content, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, s.file)
+}
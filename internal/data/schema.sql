@@ -0,0 +1,73 @@
+-- The full SQLite database schema, for reference.
+-- The bot creates and updates the schema at startup using the migrations
+-- from internal/data/sql/sqlite/migrations, so this file doesn't need to be run.
+-- The migrations are checked to create this schema in the tests.
+
+CREATE TABLE chats (
+    id INTEGER NOT NULL,
+    group_id INTEGER NOT NULL DEFAULT -1,
+    lang_code TEXT NOT NULL,
+    timezone TEXT NOT NULL DEFAULT '',
+    saved_groups TEXT NOT NULL DEFAULT '[]',
+    cl_notif_15m BOOLEAN NOT NULL DEFAULT FALSE,
+    cl_notif_1m BOOLEAN NOT NULL DEFAULT FALSE,
+    cl_notif_next_part BOOLEAN NOT NULL DEFAULT FALSE,
+    cl_reminder BOOLEAN NOT NULL DEFAULT FALSE,
+    reminder_offset INTEGER NOT NULL DEFAULT 15,
+    snoozed_class TEXT NOT NULL DEFAULT '',
+    morning_schedule BOOLEAN NOT NULL DEFAULT FALSE,
+    morning_schedule_time TEXT NOT NULL DEFAULT '07:00',
+    morning_schedule_sent TEXT NOT NULL DEFAULT '',
+    morning_schedule_claimed INTEGER NOT NULL DEFAULT 0,
+    evening_schedule BOOLEAN NOT NULL DEFAULT FALSE,
+    evening_schedule_time TEXT NOT NULL DEFAULT '20:00',
+    evening_schedule_sent TEXT NOT NULL DEFAULT '',
+    evening_schedule_claimed INTEGER NOT NULL DEFAULT 0,
+    daily_schedule_empty BOOLEAN NOT NULL DEFAULT FALSE,
+    teacher_search_query TEXT NOT NULL DEFAULT '',
+    group_search_query TEXT NOT NULL DEFAULT '',
+    notify_changes BOOLEAN NOT NULL DEFAULT FALSE,
+    hide_empty_lessons BOOLEAN NOT NULL DEFAULT FALSE,
+    hidden_lessons TEXT NOT NULL DEFAULT '[]',
+    strike_hidden_lessons BOOLEAN NOT NULL DEFAULT FALSE,
+    lesson_reminders TEXT NOT NULL DEFAULT '[]',
+    nav_stack TEXT NOT NULL DEFAULT '[]',
+    cleanup_pages BOOLEAN NOT NULL DEFAULT FALSE,
+    last_page_id INTEGER NOT NULL DEFAULT 0,
+    settings_locked BOOLEAN NOT NULL DEFAULT FALSE,
+    seen_settings BOOLEAN NOT NULL DEFAULT FALSE,
+    accessible BOOLEAN NOT NULL DEFAULT TRUE,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+-- Notifications indexes
+CREATE INDEX cl_notif_15m_idx ON chats (cl_notif_15m);
+CREATE INDEX cl_notif_1m_idx ON chats (cl_notif_1m);
+CREATE INDEX cl_reminder_idx ON chats (cl_reminder);
+CREATE INDEX morning_schedule_idx ON chats (morning_schedule);
+CREATE INDEX evening_schedule_idx ON chats (evening_schedule);
+CREATE INDEX notify_changes_idx ON chats (notify_changes);
+
+
+CREATE TABLE users (
+    id INTEGER NOT NULL,
+    first_name TEXT NOT NULL DEFAULT '',
+    last_name TEXT NOT NULL DEFAULT '',
+    username TEXT NOT NULL DEFAULT '',
+    is_admin BOOLEAN NOT NULL DEFAULT FALSE,
+    referral TEXT NOT NULL DEFAULT '',
+    group_id INTEGER NOT NULL DEFAULT -1,
+    use_own_group BOOLEAN NOT NULL DEFAULT FALSE,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+
+CREATE TABLE usage_stats (
+    date TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    key TEXT NOT NULL DEFAULT '',
+    count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (date, kind, key)
+);
@@ -0,0 +1,12 @@
+INSERT INTO usage_stats (
+    date,
+    kind,
+    key,
+    count
+) VALUES (
+    :date,
+    :kind,
+    :key,
+    :count
+) ON CONFLICT (date, kind, key) DO UPDATE SET
+    count = usage_stats.count + excluded.count;
@@ -0,0 +1,6 @@
+UPDATE
+    chats
+SET
+    morning_schedule_sent = $2
+WHERE
+    id = $1;
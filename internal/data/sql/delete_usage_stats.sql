@@ -0,0 +1 @@
+DELETE FROM usage_stats WHERE date < ?;
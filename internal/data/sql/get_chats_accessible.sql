@@ -0,0 +1,6 @@
+SELECT
+    *
+FROM
+    chats
+WHERE
+    accessible;
@@ -0,0 +1,4 @@
+SELECT
+    *
+FROM
+    chats;
\ No newline at end of file
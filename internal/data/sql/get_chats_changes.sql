@@ -0,0 +1,8 @@
+SELECT
+    *
+FROM
+    chats
+WHERE
+    notify_changes AND
+    accessible AND
+    group_id != -1;
@@ -0,0 +1,8 @@
+SELECT
+    *
+FROM
+    chats
+WHERE
+    evening_schedule AND
+    accessible AND
+    group_id != -1;
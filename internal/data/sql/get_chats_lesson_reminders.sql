@@ -0,0 +1,7 @@
+SELECT
+    *
+FROM
+    chats
+WHERE
+    lesson_reminders != '[]' AND
+    accessible;
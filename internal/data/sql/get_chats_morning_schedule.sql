@@ -0,0 +1,8 @@
+SELECT
+    *
+FROM
+    chats
+WHERE
+    morning_schedule AND
+    accessible AND
+    group_id != -1;
@@ -0,0 +1,8 @@
+SELECT
+    *
+FROM
+    chats
+WHERE
+    cl_reminder AND
+    accessible AND
+    group_id != -1;
@@ -0,0 +1,3 @@
+SELECT date, kind, key, count FROM usage_stats
+WHERE date >= ?
+ORDER BY date, kind, key;
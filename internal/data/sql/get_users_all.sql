@@ -0,0 +1,4 @@
+SELECT
+    *
+FROM
+    users;
\ No newline at end of file
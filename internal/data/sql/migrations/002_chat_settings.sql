@@ -0,0 +1,26 @@
+ALTER TABLE chats
+    ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS saved_groups JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS cl_reminder BOOL NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS reminder_offset INT NOT NULL DEFAULT 15,
+    ADD COLUMN IF NOT EXISTS snoozed_class VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS morning_schedule BOOL NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS morning_schedule_time VARCHAR(5) NOT NULL DEFAULT '07:00',
+    ADD COLUMN IF NOT EXISTS morning_schedule_sent VARCHAR(10) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS evening_schedule BOOL NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS evening_schedule_time VARCHAR(5) NOT NULL DEFAULT '20:00',
+    ADD COLUMN IF NOT EXISTS evening_schedule_sent VARCHAR(10) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS daily_schedule_empty BOOL NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS teacher_search_query VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS notify_changes BOOL NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS settings_locked BOOL NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS cl_reminder_idx ON chats (cl_reminder);
+CREATE INDEX IF NOT EXISTS morning_schedule_idx ON chats (morning_schedule);
+CREATE INDEX IF NOT EXISTS evening_schedule_idx ON chats (evening_schedule);
+CREATE INDEX IF NOT EXISTS notify_changes_idx ON chats (notify_changes);
+
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS group_id INT NOT NULL DEFAULT -1,
+    ADD COLUMN IF NOT EXISTS use_own_group BOOL NOT NULL DEFAULT FALSE;
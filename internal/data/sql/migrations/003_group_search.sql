@@ -0,0 +1,2 @@
+ALTER TABLE chats
+    ADD COLUMN IF NOT EXISTS group_search_query VARCHAR(64) NOT NULL DEFAULT '';
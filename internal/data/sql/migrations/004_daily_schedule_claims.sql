@@ -0,0 +1,3 @@
+ALTER TABLE chats
+    ADD COLUMN IF NOT EXISTS morning_schedule_claimed BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS evening_schedule_claimed BIGINT NOT NULL DEFAULT 0;
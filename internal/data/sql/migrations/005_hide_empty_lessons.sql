@@ -0,0 +1,2 @@
+ALTER TABLE chats
+    ADD COLUMN IF NOT EXISTS hide_empty_lessons BOOL NOT NULL DEFAULT FALSE;
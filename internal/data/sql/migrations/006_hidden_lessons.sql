@@ -0,0 +1,3 @@
+ALTER TABLE chats
+    ADD COLUMN IF NOT EXISTS hidden_lessons JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS strike_hidden_lessons BOOL NOT NULL DEFAULT FALSE;
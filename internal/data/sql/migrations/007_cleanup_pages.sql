@@ -0,0 +1,6 @@
+ALTER TABLE chats
+    ADD COLUMN IF NOT EXISTS cleanup_pages BOOL NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS last_page_id BIGINT NOT NULL DEFAULT 0;
+
+-- Private chat ids are positive
+UPDATE chats SET cleanup_pages = TRUE WHERE id > 0;
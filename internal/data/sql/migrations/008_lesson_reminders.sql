@@ -0,0 +1 @@
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS lesson_reminders JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1 @@
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS nav_stack JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,3 @@
+-- Breaks weren't shown before the setting was added
+ALTER TABLE chats ALTER COLUMN hide_empty_lessons SET DEFAULT TRUE;
+UPDATE chats SET hide_empty_lessons = TRUE;
@@ -0,0 +1,8 @@
+-- Daily bot usage counters, the date is in YYYY-MM-DD format
+CREATE TABLE IF NOT EXISTS usage_stats (
+    date VARCHAR(10) NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    key VARCHAR(64) NOT NULL DEFAULT '',
+    count INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (date, kind, key)
+);
@@ -0,0 +1,8 @@
+UPDATE
+    chats
+SET
+    evening_schedule_claimed = ?3
+WHERE
+    id = ?1 AND
+    evening_schedule_sent != ?2 AND
+    evening_schedule_claimed <= ?4;
@@ -0,0 +1,8 @@
+UPDATE
+    chats
+SET
+    morning_schedule_claimed = ?3
+WHERE
+    id = ?1 AND
+    morning_schedule_sent != ?2 AND
+    morning_schedule_claimed <= ?4;
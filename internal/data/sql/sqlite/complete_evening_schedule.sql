@@ -0,0 +1,6 @@
+UPDATE
+    chats
+SET
+    evening_schedule_sent = ?2
+WHERE
+    id = ?1;
@@ -0,0 +1 @@
+SELECT * FROM chats WHERE id = ? LIMIT 1;
\ No newline at end of file
@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS chats (
+    id INTEGER NOT NULL,
+    group_id INTEGER NOT NULL DEFAULT -1,
+    lang_code TEXT NOT NULL,
+    timezone TEXT NOT NULL DEFAULT '',
+    saved_groups TEXT NOT NULL DEFAULT '[]',
+    cl_notif_15m BOOLEAN NOT NULL DEFAULT FALSE,
+    cl_notif_1m BOOLEAN NOT NULL DEFAULT FALSE,
+    cl_notif_next_part BOOLEAN NOT NULL DEFAULT FALSE,
+    cl_reminder BOOLEAN NOT NULL DEFAULT FALSE,
+    reminder_offset INTEGER NOT NULL DEFAULT 15,
+    snoozed_class TEXT NOT NULL DEFAULT '',
+    morning_schedule BOOLEAN NOT NULL DEFAULT FALSE,
+    morning_schedule_time TEXT NOT NULL DEFAULT '07:00',
+    morning_schedule_sent TEXT NOT NULL DEFAULT '',
+    evening_schedule BOOLEAN NOT NULL DEFAULT FALSE,
+    evening_schedule_time TEXT NOT NULL DEFAULT '20:00',
+    evening_schedule_sent TEXT NOT NULL DEFAULT '',
+    daily_schedule_empty BOOLEAN NOT NULL DEFAULT FALSE,
+    teacher_search_query TEXT NOT NULL DEFAULT '',
+    group_search_query TEXT NOT NULL DEFAULT '',
+    notify_changes BOOLEAN NOT NULL DEFAULT FALSE,
+    settings_locked BOOLEAN NOT NULL DEFAULT FALSE,
+    seen_settings BOOLEAN NOT NULL DEFAULT FALSE,
+    accessible BOOLEAN NOT NULL DEFAULT TRUE,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+-- Notifications indexes
+CREATE INDEX IF NOT EXISTS cl_notif_15m_idx ON chats (cl_notif_15m);
+CREATE INDEX IF NOT EXISTS cl_notif_1m_idx ON chats (cl_notif_1m);
+CREATE INDEX IF NOT EXISTS cl_reminder_idx ON chats (cl_reminder);
+CREATE INDEX IF NOT EXISTS morning_schedule_idx ON chats (morning_schedule);
+CREATE INDEX IF NOT EXISTS evening_schedule_idx ON chats (evening_schedule);
+CREATE INDEX IF NOT EXISTS notify_changes_idx ON chats (notify_changes);
+
+
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER NOT NULL,
+    first_name TEXT NOT NULL DEFAULT '',
+    last_name TEXT NOT NULL DEFAULT '',
+    username TEXT NOT NULL DEFAULT '',
+    is_admin BOOLEAN NOT NULL DEFAULT FALSE,
+    referral TEXT NOT NULL DEFAULT '',
+    group_id INTEGER NOT NULL DEFAULT -1,
+    use_own_group BOOLEAN NOT NULL DEFAULT FALSE,
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
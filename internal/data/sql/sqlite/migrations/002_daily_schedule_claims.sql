@@ -0,0 +1,2 @@
+ALTER TABLE chats ADD COLUMN morning_schedule_claimed INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE chats ADD COLUMN evening_schedule_claimed INTEGER NOT NULL DEFAULT 0;
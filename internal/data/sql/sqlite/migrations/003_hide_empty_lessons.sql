@@ -0,0 +1 @@
+ALTER TABLE chats ADD COLUMN hide_empty_lessons BOOLEAN NOT NULL DEFAULT FALSE;
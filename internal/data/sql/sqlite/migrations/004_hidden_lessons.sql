@@ -0,0 +1,2 @@
+ALTER TABLE chats ADD COLUMN hidden_lessons TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE chats ADD COLUMN strike_hidden_lessons BOOLEAN NOT NULL DEFAULT FALSE;
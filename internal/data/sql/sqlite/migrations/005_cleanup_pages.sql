@@ -0,0 +1,5 @@
+ALTER TABLE chats ADD COLUMN cleanup_pages BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE chats ADD COLUMN last_page_id INTEGER NOT NULL DEFAULT 0;
+
+-- Private chat ids are positive
+UPDATE chats SET cleanup_pages = TRUE WHERE id > 0;
@@ -0,0 +1 @@
+ALTER TABLE chats ADD COLUMN lesson_reminders TEXT NOT NULL DEFAULT '[]';
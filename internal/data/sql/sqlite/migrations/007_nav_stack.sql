@@ -0,0 +1 @@
+ALTER TABLE chats ADD COLUMN nav_stack TEXT NOT NULL DEFAULT '[]';
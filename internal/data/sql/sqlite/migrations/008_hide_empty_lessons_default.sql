@@ -0,0 +1,3 @@
+-- Breaks weren't shown before the setting was added.
+-- SQLite can't change the column default, chats are always saved with all the columns.
+UPDATE chats SET hide_empty_lessons = TRUE;
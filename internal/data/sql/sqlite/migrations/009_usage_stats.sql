@@ -0,0 +1,8 @@
+-- Daily bot usage counters, the date is in YYYY-MM-DD format
+CREATE TABLE IF NOT EXISTS usage_stats (
+    date TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    key TEXT NOT NULL DEFAULT '',
+    count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (date, kind, key)
+);
@@ -2,25 +2,96 @@ INSERT INTO chats (
     id,
     group_id,
     lang_code,
+    timezone,
+    saved_groups,
     cl_notif_15m,
     cl_notif_1m,
     cl_notif_next_part,
+    cl_reminder,
+    reminder_offset,
+    snoozed_class,
+    morning_schedule,
+    morning_schedule_time,
+    morning_schedule_sent,
+    evening_schedule,
+    evening_schedule_time,
+    evening_schedule_sent,
+    daily_schedule_empty,
+    teacher_search_query,
+    group_search_query,
+    notify_changes,
+    hide_empty_lessons,
+    hidden_lessons,
+    strike_hidden_lessons,
+    lesson_reminders,
+    nav_stack,
+    cleanup_pages,
+    last_page_id,
+    settings_locked,
     seen_settings,
-    accessible
+    accessible,
+    created
 ) VALUES (
     :id,
     :group_id,
     :lang_code,
+    :timezone,
+    :saved_groups,
     :cl_notif_15m,
     :cl_notif_1m,
     :cl_notif_next_part,
+    :cl_reminder,
+    :reminder_offset,
+    :snoozed_class,
+    :morning_schedule,
+    :morning_schedule_time,
+    :morning_schedule_sent,
+    :evening_schedule,
+    :evening_schedule_time,
+    :evening_schedule_sent,
+    :daily_schedule_empty,
+    :teacher_search_query,
+    :group_search_query,
+    :notify_changes,
+    :hide_empty_lessons,
+    :hidden_lessons,
+    :strike_hidden_lessons,
+    :lesson_reminders,
+    :nav_stack,
+    :cleanup_pages,
+    :last_page_id,
+    :settings_locked,
     :seen_settings,
-    :accessible
+    :accessible,
+    :created
 ) ON CONFLICT (id) DO UPDATE SET
     group_id = :group_id,
     lang_code = :lang_code,
+    timezone = :timezone,
+    saved_groups = :saved_groups,
     cl_notif_15m = :cl_notif_15m,
     cl_notif_1m = :cl_notif_1m,
     cl_notif_next_part = :cl_notif_next_part,
+    cl_reminder = :cl_reminder,
+    reminder_offset = :reminder_offset,
+    snoozed_class = :snoozed_class,
+    morning_schedule = :morning_schedule,
+    morning_schedule_time = :morning_schedule_time,
+    morning_schedule_sent = :morning_schedule_sent,
+    evening_schedule = :evening_schedule,
+    evening_schedule_time = :evening_schedule_time,
+    evening_schedule_sent = :evening_schedule_sent,
+    daily_schedule_empty = :daily_schedule_empty,
+    teacher_search_query = :teacher_search_query,
+    group_search_query = :group_search_query,
+    notify_changes = :notify_changes,
+    hide_empty_lessons = :hide_empty_lessons,
+    hidden_lessons = :hidden_lessons,
+    strike_hidden_lessons = :strike_hidden_lessons,
+    lesson_reminders = :lesson_reminders,
+    nav_stack = :nav_stack,
+    cleanup_pages = :cleanup_pages,
+    last_page_id = :last_page_id,
+    settings_locked = :settings_locked,
     seen_settings = :seen_settings,
     accessible = :accessible;
@@ -4,17 +4,25 @@ INSERT INTO users (
     last_name,
     username,
     is_admin,
-    referral
+    referral,
+    group_id,
+    use_own_group,
+    created
 ) VALUES (
     :id,
     :first_name,
     :last_name,
     :username,
     :is_admin,
-    :referral
+    :referral,
+    :group_id,
+    :use_own_group,
+    :created
 ) ON CONFLICT (id) DO UPDATE SET
     first_name = :first_name,
     last_name = :last_name,
     username = :username,
     is_admin = :is_admin,
-    referral = :referral;
+    referral = :referral,
+    group_id = :group_id,
+    use_own_group = :use_own_group;
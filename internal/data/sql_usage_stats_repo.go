@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	_ "embed"
+	"github.com/jmoiron/sqlx"
+)
+
+// Load SQL queries from files
+var (
+	//go:embed sql/add_usage_stats.sql
+	addUsageStatsQuery string
+	//go:embed sql/get_usage_stats.sql
+	getUsageStatsQuery string
+	//go:embed sql/delete_usage_stats.sql
+	deleteUsageStatsQuery string
+)
+
+// SQLUsageStatsRepository implements UsageStatsRepository interface
+// for PostgreSQL and SQLite, the queries are the same for both.
+//
+// Should be created via NewSQLUsageStatsRepository.
+type SQLUsageStatsRepository struct {
+	db *sqlx.DB
+}
+
+// NewSQLUsageStatsRepository creates a new instance of SQLUsageStatsRepository.
+func NewSQLUsageStatsRepository(db *sqlx.DB) UsageStatsRepository {
+	return &SQLUsageStatsRepository{db: db}
+}
+
+func (r *SQLUsageStatsRepository) AddCounters(counters []UsageCounter) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, counter := range counters {
+		if _, err := tx.NamedExec(addUsageStatsQuery, counter); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLUsageStatsRepository) GetCounters(from string) ([]UsageCounter, error) {
+	counters := make([]UsageCounter, 0)
+	if err := r.db.Select(&counters, r.db.Rebind(getUsageStatsQuery), from); err != nil {
+		return nil, err
+	}
+
+	return counters, nil
+}
+
+func (r *SQLUsageStatsRepository) DeleteCountersBefore(date string) error {
+	_, err := r.db.Exec(r.db.Rebind(deleteUsageStatsQuery), date)
+	return err
+}
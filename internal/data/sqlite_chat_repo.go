@@ -0,0 +1,205 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"database/sql"
+	_ "embed"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+// Load SQLite queries that differ from the PostgreSQL ones
+var (
+	//go:embed sql/sqlite/get_chat.sql
+	getChatSQLiteQuery string
+	//go:embed sql/sqlite/claim_morning_schedule.sql
+	claimMorningScheduleSQLiteQuery string
+	//go:embed sql/sqlite/claim_evening_schedule.sql
+	claimEveningScheduleSQLiteQuery string
+	//go:embed sql/sqlite/complete_morning_schedule.sql
+	completeMorningScheduleSQLiteQuery string
+	//go:embed sql/sqlite/complete_evening_schedule.sql
+	completeEveningScheduleSQLiteQuery string
+)
+
+// SQLiteChatRepository implements ChatRepository interface for SQLite.
+// All the queries are prepared when the repository is created.
+//
+// Should be created via NewSQLiteChatRepository.
+type SQLiteChatRepository struct {
+	getChatStmt              *sqlx.Stmt
+	updateChatStmt           *sqlx.NamedStmt
+	getChats15mStmt          *sqlx.Stmt
+	getChats1mStmt           *sqlx.Stmt
+	getChatsReminderStmt     *sqlx.Stmt
+	getChatsMorningStmt      *sqlx.Stmt
+	getChatsEveningStmt      *sqlx.Stmt
+	getChatsChangesStmt      *sqlx.Stmt
+	getChatsRemindersStmt    *sqlx.Stmt
+	getChatsAccessibleStmt   *sqlx.Stmt
+	getChatsAllStmt          *sqlx.Stmt
+	claimMorningScheduleStmt *sqlx.Stmt
+	claimEveningScheduleStmt *sqlx.Stmt
+	completeMorningStmt      *sqlx.Stmt
+	completeEveningStmt      *sqlx.Stmt
+}
+
+// NewSQLiteChatRepository creates a new instance of SQLiteChatRepository.
+//
+// The database must be opened with OpenSQLite.
+func NewSQLiteChatRepository(db *sqlx.DB) (ChatRepository, error) {
+	r := &SQLiteChatRepository{}
+
+	var err error
+	if r.updateChatStmt, err = db.PrepareNamed(updateChatQuery); err != nil {
+		return nil, err
+	}
+
+	stmts := []struct {
+		stmt  **sqlx.Stmt
+		query string
+	}{
+		{&r.getChatStmt, getChatSQLiteQuery},
+		{&r.getChats15mStmt, getChats15mQuery},
+		{&r.getChats1mStmt, getChats1mQuery},
+		{&r.getChatsReminderStmt, getChatsReminderQuery},
+		{&r.getChatsMorningStmt, getChatsMorningScheduleQuery},
+		{&r.getChatsEveningStmt, getChatsEveningScheduleQuery},
+		{&r.getChatsChangesStmt, getChatsChangesQuery},
+		{&r.getChatsRemindersStmt, getChatsLessonRemindersQuery},
+		{&r.getChatsAccessibleStmt, getChatsAccessibleQuery},
+		{&r.getChatsAllStmt, getChatsAllQuery},
+		{&r.claimMorningScheduleStmt, claimMorningScheduleSQLiteQuery},
+		{&r.claimEveningScheduleStmt, claimEveningScheduleSQLiteQuery},
+		{&r.completeMorningStmt, completeMorningScheduleSQLiteQuery},
+		{&r.completeEveningStmt, completeEveningScheduleSQLiteQuery},
+	}
+	for _, s := range stmts {
+		if *s.stmt, err = db.Preparex(s.query); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func (r *SQLiteChatRepository) GetById(id int64) (*Chat, error) {
+	chat := new(Chat)
+	err := r.getChatStmt.Get(chat, id)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return chat, nil
+}
+
+func (r *SQLiteChatRepository) Update(chat *Chat) error {
+	_, err := r.updateChatStmt.Exec(chat)
+	return err
+}
+
+func (r *SQLiteChatRepository) GetChatsWithEnabled15mNotification() ([]*Chat, error) {
+	return selectChats(r.getChats15mStmt)
+}
+
+func (r *SQLiteChatRepository) GetChatsWithEnabled1mNotification() ([]*Chat, error) {
+	return selectChats(r.getChats1mStmt)
+}
+
+func (r *SQLiteChatRepository) GetChatsWithEnabledReminder() ([]*Chat, error) {
+	return selectChats(r.getChatsReminderStmt)
+}
+
+func (r *SQLiteChatRepository) GetChatsWithEnabledMorningSchedule() ([]*Chat, error) {
+	return selectChats(r.getChatsMorningStmt)
+}
+
+func (r *SQLiteChatRepository) GetChatsWithEnabledEveningSchedule() ([]*Chat, error) {
+	return selectChats(r.getChatsEveningStmt)
+}
+
+func (r *SQLiteChatRepository) GetChatsWithEnabledChangesNotification() ([]*Chat, error) {
+	return selectChats(r.getChatsChangesStmt)
+}
+
+func (r *SQLiteChatRepository) GetChatsWithLessonReminders() ([]*Chat, error) {
+	return selectChats(r.getChatsRemindersStmt)
+}
+
+func (r *SQLiteChatRepository) GetAccessibleChats() ([]*Chat, error) {
+	return selectChats(r.getChatsAccessibleStmt)
+}
+
+func (r *SQLiteChatRepository) GetAllChats() ([]*Chat, error) {
+	return selectChats(r.getChatsAllStmt)
+}
+
+func (r *SQLiteChatRepository) ClaimMorningSchedule(id int64, date string, timeout time.Duration) (bool, error) {
+	return claimSQLite(r.claimMorningScheduleStmt, id, date, timeout)
+}
+
+func (r *SQLiteChatRepository) CompleteMorningSchedule(id int64, date string) error {
+	_, err := r.completeMorningStmt.Exec(id, date)
+	return err
+}
+
+func (r *SQLiteChatRepository) ClaimEveningSchedule(id int64, date string, timeout time.Duration) (bool, error) {
+	return claimSQLite(r.claimEveningScheduleStmt, id, date, timeout)
+}
+
+func (r *SQLiteChatRepository) CompleteEveningSchedule(id int64, date string) error {
+	_, err := r.completeEveningStmt.Exec(id, date)
+	return err
+}
+
+// selectChats executes the prepared query that returns chats
+func selectChats(stmt *sqlx.Stmt) ([]*Chat, error) {
+	chats := make([]*Chat, 0)
+	if err := stmt.Select(&chats); err != nil {
+		return nil, err
+	}
+
+	return chats, nil
+}
+
+// claimSQLite executes the claim statement and checks if the row was updated
+func claimSQLite(stmt *sqlx.Stmt, id int64, date string, timeout time.Duration) (bool, error) {
+	now := time.Now()
+	res, err := stmt.Exec(id, date, now.Unix(), now.Add(-timeout).Unix())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
@@ -0,0 +1,333 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	_ "embed"
+	"github.com/jmoiron/sqlx"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+//go:embed schema.sql
+var sqliteSchema string
+
+// newTestSQLite opens the in-memory SQLite database with the migrations applied
+func newTestSQLite(t testing.TB) *sqlx.DB {
+	db, err := OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := MigrateSQLite(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func newTestSQLiteChatRepo(t testing.TB) ChatRepository {
+	repo, err := NewSQLiteChatRepository(newTestSQLite(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+// sqliteColumn is a column returned by the table_info pragma
+type sqliteColumn struct {
+	Cid     int     `db:"cid"`
+	Name    string  `db:"name"`
+	Type    string  `db:"type"`
+	NotNull bool    `db:"notnull"`
+	Default *string `db:"dflt_value"`
+	Pk      int     `db:"pk"`
+}
+
+// tableColumns returns the table columns by their names
+func tableColumns(t *testing.T, db *sqlx.DB, table string) map[string]sqliteColumn {
+	var columns []sqliteColumn
+	if err := db.Select(&columns, "SELECT * FROM pragma_table_info(?)", table); err != nil {
+		t.Fatal(err)
+	}
+
+	byName := make(map[string]sqliteColumn, len(columns))
+	for _, column := range columns {
+		// The order of the columns added by the migrations differs
+		column.Cid = 0
+		byName[column.Name] = column
+	}
+	return byName
+}
+
+func TestSQLiteMigrationsCreateSchema(t *testing.T) {
+	migrated := newTestSQLite(t)
+
+	schema, err := OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer schema.Close()
+	if _, err := schema.Exec(sqliteSchema); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, table := range []string{"chats", "users", "usage_stats"} {
+		want := tableColumns(t, schema, table)
+		got := tableColumns(t, migrated, table)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("migrated %s table columns differ from schema.sql:\ngot  %+v\nwant %+v", table, got, want)
+		}
+	}
+
+	var wantIndexes, gotIndexes []string
+	query := "SELECT name FROM sqlite_master WHERE type = 'index' AND sql IS NOT NULL ORDER BY name"
+	if err := schema.Select(&wantIndexes, query); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrated.Select(&gotIndexes, query); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(gotIndexes, wantIndexes) {
+		t.Errorf("migrated indexes are %v, want %v", gotIndexes, wantIndexes)
+	}
+}
+
+func TestSQLiteChatRepositoryGetUpdate(t *testing.T) {
+	repo := newTestSQLiteChatRepo(t)
+
+	chat, err := repo.GetById(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat != nil {
+		t.Fatalf("got chat %d before it was created", chat.Id)
+	}
+
+	chat = NewChat(1)
+	chat.GroupId = 1234
+	chat.Timezone = "Europe/Warsaw"
+	chat.SavedGroups = GroupRefs{{Id: 4321}}
+	chat.MorningSchedule = true
+	chat.LessonReminders = LessonReminders{{GroupId: 1234, Date: "2024-10-28", Number: 2, Offset: 15}}
+	chat.Created = chat.Created.Truncate(time.Second)
+	if err := repo.Update(chat); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := repo.GetById(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil {
+		t.Fatal("chat not found after it was created")
+	}
+	if !got.Created.Equal(chat.Created) {
+		t.Errorf("chat created at %s, want %s", got.Created, chat.Created)
+	}
+	got.Created = chat.Created
+	if !reflect.DeepEqual(got, chat) {
+		t.Errorf("got chat\n%+v\nwant\n%+v", got, chat)
+	}
+
+	chat.GroupId = 5678
+	chat.SavedGroups = GroupRefs{}
+	if err := repo.Update(chat); err != nil {
+		t.Fatal(err)
+	}
+	got, err = repo.GetById(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GroupId != 5678 || len(got.SavedGroups) != 0 {
+		t.Errorf("chat is not updated: group %d, saved groups %v", got.GroupId, got.SavedGroups)
+	}
+}
+
+func TestSQLiteChatRepositoryClaimSchedule(t *testing.T) {
+	repo := newTestSQLiteChatRepo(t)
+	if err := repo.Update(NewChat(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	claim := func(date string, timeout time.Duration, want bool) {
+		t.Helper()
+		claimed, err := repo.ClaimMorningSchedule(1, date, timeout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claimed != want {
+			t.Errorf("ClaimMorningSchedule(%s, %s) = %t, want %t", date, timeout, claimed, want)
+		}
+	}
+
+	claim("2024-10-28", time.Hour, true)
+	// Claimed by another instance
+	claim("2024-10-28", time.Hour, false)
+	// Claim expired, e.g. the instance crashed before sending
+	claim("2024-10-28", -time.Second, true)
+
+	if err := repo.CompleteMorningSchedule(1, "2024-10-28"); err != nil {
+		t.Fatal(err)
+	}
+	// Already sent
+	claim("2024-10-28", -time.Second, false)
+	claim("2024-10-29", -time.Second, true)
+
+	// The evening schedule is claimed separately
+	claimed, err := repo.ClaimEveningSchedule(1, "2024-10-28", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !claimed {
+		t.Error("evening schedule is not claimed after the morning one")
+	}
+	if err := repo.CompleteEveningSchedule(1, "2024-10-28"); err != nil {
+		t.Fatal(err)
+	}
+	chat, err := repo.GetById(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat.MorningScheduleSent != "2024-10-28" || chat.EveningScheduleSent != "2024-10-28" {
+		t.Errorf("schedules are sent on %q and %q, want 2024-10-28", chat.MorningScheduleSent, chat.EveningScheduleSent)
+	}
+
+	// Unknown chats can't be claimed
+	claimed, err = repo.ClaimMorningSchedule(2, "2024-10-28", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed {
+		t.Error("unknown chat schedule is claimed")
+	}
+}
+
+func TestSQLiteChatRepositoryGetChatsWith(t *testing.T) {
+	repo := newTestSQLiteChatRepo(t)
+
+	enableAll := func(chat *Chat) *Chat {
+		chat.ClassesNotification15m = true
+		chat.ClassesNotification1m = true
+		chat.ClassesReminder = true
+		chat.MorningSchedule = true
+		chat.EveningSchedule = true
+		chat.NotifyChanges = true
+		chat.LessonReminders = LessonReminders{{GroupId: 1234, Date: "2024-10-28", Number: 1}}
+		return chat
+	}
+
+	enabled := enableAll(NewChat(1))
+	enabled.GroupId = 1234
+	withoutGroup := enableAll(NewChat(2))
+	inaccessible := enableAll(NewChat(3))
+	inaccessible.GroupId = 1234
+	inaccessible.Accessible = false
+	disabled := NewChat(4)
+	disabled.GroupId = 1234
+
+	for _, chat := range []*Chat{enabled, withoutGroup, inaccessible, disabled} {
+		if err := repo.Update(chat); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		get  func() ([]*Chat, error)
+		want []int64
+	}{
+		{"15m notification", repo.GetChatsWithEnabled15mNotification, []int64{1}},
+		{"1m notification", repo.GetChatsWithEnabled1mNotification, []int64{1}},
+		{"reminder", repo.GetChatsWithEnabledReminder, []int64{1}},
+		{"morning schedule", repo.GetChatsWithEnabledMorningSchedule, []int64{1}},
+		{"evening schedule", repo.GetChatsWithEnabledEveningSchedule, []int64{1}},
+		{"changes notification", repo.GetChatsWithEnabledChangesNotification, []int64{1}},
+		{"lesson reminders", repo.GetChatsWithLessonReminders, []int64{1, 2}},
+		{"accessible", repo.GetAccessibleChats, []int64{1, 2, 4}},
+		{"all", repo.GetAllChats, []int64{1, 2, 3, 4}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chats, err := test.get()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ids := make([]int64, 0, len(chats))
+			for _, chat := range chats {
+				ids = append(ids, chat.Id)
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, test.want) {
+				t.Errorf("got chats %v, want %v", ids, test.want)
+			}
+		})
+	}
+}
+
+// benchmarkChats is the number of chats in the database in the benchmarks
+const benchmarkChats = 10_000
+
+func newBenchmarkSQLiteChatRepo(b *testing.B) ChatRepository {
+	repo := newTestSQLiteChatRepo(b)
+	for i := int64(1); i <= benchmarkChats; i++ {
+		chat := NewChat(i)
+		chat.GroupId = int(i)
+		if err := repo.Update(chat); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return repo
+}
+
+func BenchmarkSQLiteChatRepositoryGetById(b *testing.B) {
+	repo := newBenchmarkSQLiteChatRepo(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.GetById(int64(i%benchmarkChats + 1)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSQLiteChatRepositoryUpdate(b *testing.B) {
+	repo := newBenchmarkSQLiteChatRepo(b)
+	chats := make([]*Chat, benchmarkChats)
+	for i := range chats {
+		chats[i] = NewChat(int64(i + 1))
+		chats[i].GroupSearchQuery = "group " + strconv.Itoa(i)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := repo.Update(chats[i%benchmarkChats]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"database/sql"
+	_ "embed"
+	"errors"
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed sql/sqlite/get_user.sql
+var getUserSQLiteQuery string
+
+// SQLiteUserRepository implements UserRepository interface for SQLite.
+// All the queries are prepared when the repository is created.
+//
+// Should be created via NewSQLiteUserRepository.
+type SQLiteUserRepository struct {
+	getUserStmt     *sqlx.Stmt
+	updateUserStmt  *sqlx.NamedStmt
+	getUsersAllStmt *sqlx.Stmt
+}
+
+// NewSQLiteUserRepository creates a new instance of SQLiteUserRepository.
+//
+// The database must be opened with OpenSQLite.
+func NewSQLiteUserRepository(db *sqlx.DB) (UserRepository, error) {
+	r := &SQLiteUserRepository{}
+
+	var err error
+	if r.getUserStmt, err = db.Preparex(getUserSQLiteQuery); err != nil {
+		return nil, err
+	}
+	if r.updateUserStmt, err = db.PrepareNamed(updateUserQuery); err != nil {
+		return nil, err
+	}
+	if r.getUsersAllStmt, err = db.Preparex(getUsersAllQuery); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *SQLiteUserRepository) GetById(id int64) (*User, error) {
+	user := &User{}
+	err := r.getUserStmt.Get(user, id)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *SQLiteUserRepository) Update(user *User) error {
+	_, err := r.updateUserStmt.Exec(user)
+	return err
+}
+
+func (r *SQLiteUserRepository) GetAllUsers() ([]*User, error) {
+	users := make([]*User, 0)
+	if err := r.getUsersAllStmt.Select(&users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+// Storage is the backend that stores the chats, users and usage stats data,
+// like files or a database. The packages that use the data receive
+// it on setup, so the backend can be swapped, e.g. in tests.
+type Storage interface {
+	// Chats returns the chat repository of the storage.
+	Chats() ChatRepository
+	// Users returns the user repository of the storage.
+	Users() UserRepository
+	// UsageStats returns the usage stats repository of the storage.
+	UsageStats() UsageStatsRepository
+}
+
+// repoStorage is the Storage made of the repositories
+type repoStorage struct {
+	chats      ChatRepository
+	users      UserRepository
+	usageStats UsageStatsRepository
+}
+
+func (s *repoStorage) Chats() ChatRepository {
+	return s.chats
+}
+
+func (s *repoStorage) Users() UserRepository {
+	return s.users
+}
+
+func (s *repoStorage) UsageStats() UsageStatsRepository {
+	return s.usageStats
+}
+
+// NewStorage creates the Storage of the given repositories.
+func NewStorage(chats ChatRepository, users UserRepository, usageStats UsageStatsRepository) Storage {
+	return &repoStorage{chats, users, usageStats}
+}
+
+// NewFileStorage creates the Storage that keeps every chat
+// and user in a separate json file of the given directories,
+// and the usage stats in the statsFile.
+func NewFileStorage(chatsDir string, usersDir string, statsFile string) (Storage, error) {
+	chats, err := NewFileChatRepository(chatsDir)
+	if err != nil {
+		return nil, err
+	}
+	users, err := NewFileUserRepository(usersDir)
+	if err != nil {
+		return nil, err
+	}
+	usageStats, err := NewFileUsageStatsRepository(statsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStorage(chats, users, usageStats), nil
+}
+
+// NewPostgresStorage creates the Storage in the PostgreSQL database.
+// The schema must be migrated with Migrate.
+func NewPostgresStorage(db *sqlx.DB) Storage {
+	return NewStorage(NewPostgresChatRepository(db), NewPostgresUserRepository(db), NewSQLUsageStatsRepository(db))
+}
+
+// NewSQLiteStorage creates the Storage in the SQLite database.
+// The schema must be migrated with MigrateSQLite.
+func NewSQLiteStorage(db *sqlx.DB) (Storage, error) {
+	chats, err := NewSQLiteChatRepository(db)
+	if err != nil {
+		return nil, err
+	}
+	users, err := NewSQLiteUserRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStorage(chats, users, NewSQLUsageStatsRepository(db)), nil
+}
+
+// NewMemoryStorage creates the Storage that keeps the data in memory,
+// meant for the tests.
+func NewMemoryStorage() Storage {
+	return NewStorage(NewMemoryChatRepository(), NewMemoryUserRepository(), NewMemoryUsageStatsRepository())
+}
+
+// CopyStorage copies all the chats, users and usage stats from one storage to another.
+// Records that are already in the destination storage are overwritten,
+// the usage counters are added to the destination ones.
+// Returns the number of copied chats and users.
+func CopyStorage(from Storage, to Storage) (int, int, error) {
+	// Copy users
+	users, err := from.Users().GetAllUsers()
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, user := range users {
+		// Files created by the old versions have no creation time
+		if user.Created.IsZero() {
+			user.Created = time.Now()
+		}
+		if err := to.Users().Update(user); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	// Copy chats
+	chats, err := from.Chats().GetAllChats()
+	if err != nil {
+		return 0, len(users), err
+	}
+	for _, chat := range chats {
+		if chat.Created.IsZero() {
+			chat.Created = time.Now()
+		}
+		if err := to.Chats().Update(chat); err != nil {
+			return 0, len(users), err
+		}
+	}
+
+	// Copy usage stats
+	counters, err := from.UsageStats().GetCounters("")
+	if err != nil {
+		return len(chats), len(users), err
+	}
+	if err := to.UsageStats().AddCounters(counters); err != nil {
+		return len(chats), len(users), err
+	}
+
+	return len(chats), len(users), nil
+}
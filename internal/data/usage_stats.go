@@ -0,0 +1,267 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultUsageStatsRetention is the default number of days the usage stats are kept
+const DefaultUsageStatsRetention = 90
+
+// Kinds of the usage counters
+const (
+	// UsageActiveChats is the number of the chats that used the bot that day
+	UsageActiveChats = "active_chats"
+	// UsageCommand is the number of the command uses, the key is the command name without the slash
+	UsageCommand = "command"
+	// UsageButton is the number of the button presses, the key is the button action
+	UsageButton = "button"
+	// UsageGroupViews is the number of the schedule views, the key is the group id
+	UsageGroupViews = "group_views"
+	// UsageApiErrors is the number of the failed university API requests
+	UsageApiErrors = "api_errors"
+)
+
+// UsageCounter is a daily bot usage counter
+type UsageCounter struct {
+	// Date is the day, in time.DateOnly format
+	Date string `db:"date" json:"date"`
+	// Kind is one of the Usage* constants
+	Kind string `db:"kind" json:"kind"`
+	// Key is the command, action or group id counted, empty for the totals
+	Key   string `db:"key" json:"key"`
+	Count int    `db:"count" json:"count"`
+}
+
+// UsageStatsRepository stores the daily usage counters.
+type UsageStatsRepository interface {
+	// AddCounters adds the counts to the stored counters of the same day, kind and key
+	AddCounters(counters []UsageCounter) error
+	// GetCounters returns the counters of the days starting from the date,
+	// sorted by date, kind and key
+	GetCounters(from string) ([]UsageCounter, error)
+	// DeleteCountersBefore removes the counters of the days before the date
+	DeleteCountersBefore(date string) error
+}
+
+// UsageSummary is the bot usage of a number of days
+type UsageSummary struct {
+	// ActiveChats is the average daily number of chats that used the bot
+	ActiveChats int
+	Commands    int
+	Buttons     int
+	ApiErrors   int
+	// Groups are the numbers of the schedule views by group id
+	Groups map[int]int
+}
+
+// usageKey identifies the counter in the pending counts
+type usageKey struct {
+	Date string
+	Kind string
+	Key  string
+}
+
+// UsageStats counts the daily bot usage, shown to the bot admins.
+//
+// The counts are kept in memory and added to the repository on Flush,
+// so the updates don't wait for the database. Chats are counted once a day:
+// only the chats that used the bot today are remembered.
+// Safe for concurrent use.
+//
+// Should be created via NewUsageStats.
+type UsageStats struct {
+	repo UsageStatsRepository
+	// retention is the number of days the stats are kept
+	retention int
+
+	mu      sync.Mutex
+	pending map[usageKey]int
+	// activeDate is the day of activeChats
+	activeDate  string
+	activeChats map[int64]bool
+}
+
+// NewUsageStats creates a new instance of UsageStats that stores the counters in repo.
+func NewUsageStats(repo UsageStatsRepository, retention int) *UsageStats {
+	if retention <= 0 {
+		retention = DefaultUsageStatsRetention
+	}
+
+	return &UsageStats{
+		repo:        repo,
+		retention:   retention,
+		pending:     make(map[usageKey]int),
+		activeChats: make(map[int64]bool),
+	}
+}
+
+// CountCommand counts the command sent to the chat
+func (s *UsageStats) CountCommand(chatId int64, command string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.countChat(chatId)
+	s.count(UsageCommand, command)
+}
+
+// CountButton counts the button pressed in the chat
+func (s *UsageStats) CountButton(chatId int64, action string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.countChat(chatId)
+	s.count(UsageButton, action)
+}
+
+// CountGroupView counts the view of the group schedule
+func (s *UsageStats) CountGroupView(groupId int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.count(UsageGroupViews, strconv.Itoa(groupId))
+}
+
+// CountApiError counts the failed university API request
+func (s *UsageStats) CountApiError() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.count(UsageApiErrors, "")
+}
+
+// Flush adds the pending counts to the repository and removes
+// the days older than the retention. The counts are kept
+// to be added on the next flush if the repository fails.
+func (s *UsageStats) Flush() error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[usageKey]int)
+	s.mu.Unlock()
+
+	if len(pending) != 0 {
+		counters := make([]UsageCounter, 0, len(pending))
+		for key, count := range pending {
+			counters = append(counters, UsageCounter{Date: key.Date, Kind: key.Kind, Key: key.Key, Count: count})
+		}
+
+		if err := s.repo.AddCounters(counters); err != nil {
+			s.mu.Lock()
+			for key, count := range pending {
+				s.pending[key] += count
+			}
+			s.mu.Unlock()
+			return err
+		}
+	}
+
+	oldest := time.Now().AddDate(0, 0, -s.retention+1).Format(time.DateOnly)
+	return s.repo.DeleteCountersBefore(oldest)
+}
+
+// Counters flushes the pending counts and returns the counters
+// of the last days, starting from today, sorted by date, kind and key.
+// Returns all the kept days if days is not positive.
+func (s *UsageStats) Counters(days int) ([]UsageCounter, error) {
+	if err := s.Flush(); err != nil {
+		return nil, err
+	}
+
+	from := ""
+	if days > 0 {
+		from = time.Now().AddDate(0, 0, -days+1).Format(time.DateOnly)
+	}
+	return s.repo.GetCounters(from)
+}
+
+// Summary sums up the stats of the last days, starting from today
+func (s *UsageStats) Summary(days int) (UsageSummary, error) {
+	counters, err := s.Counters(days)
+	if err != nil {
+		return UsageSummary{}, err
+	}
+
+	summary := UsageSummary{Groups: make(map[int]int)}
+	activeDays := make(map[string]bool)
+	for _, counter := range counters {
+		switch counter.Kind {
+		case UsageActiveChats:
+			summary.ActiveChats += counter.Count
+			activeDays[counter.Date] = true
+		case UsageCommand:
+			summary.Commands += counter.Count
+		case UsageButton:
+			summary.Buttons += counter.Count
+		case UsageApiErrors:
+			summary.ApiErrors += counter.Count
+		case UsageGroupViews:
+			if groupId, err := strconv.Atoi(counter.Key); err == nil {
+				summary.Groups[groupId] += counter.Count
+			}
+		}
+	}
+
+	if len(activeDays) != 0 {
+		summary.ActiveChats /= len(activeDays)
+	}
+	return summary, nil
+}
+
+// count adds one to the pending counter of today.
+// Must be called with the mutex locked.
+func (s *UsageStats) count(kind string, key string) {
+	s.pending[usageKey{Date: time.Now().Format(time.DateOnly), Kind: kind, Key: key}]++
+}
+
+// countChat counts the chat as active, if it's the first time today.
+// Must be called with the mutex locked.
+func (s *UsageStats) countChat(chatId int64) {
+	today := time.Now().Format(time.DateOnly)
+	if s.activeDate != today {
+		s.activeDate = today
+		clear(s.activeChats)
+	}
+
+	if !s.activeChats[chatId] {
+		s.activeChats[chatId] = true
+		s.count(UsageActiveChats, "")
+	}
+}
+
+// sortUsageCounters sorts the counters by date, kind and key
+func sortUsageCounters(counters []UsageCounter) {
+	sort.Slice(counters, func(i, j int) bool {
+		a, b := counters[i], counters[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Key < b.Key
+	})
+}
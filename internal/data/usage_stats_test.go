@@ -0,0 +1,174 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package data
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUsageStatsRepositories(t *testing.T) {
+	fileRepo, err := NewFileUsageStatsRepository(filepath.Join(t.TempDir(), "usage_counters.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repos := map[string]UsageStatsRepository{
+		"sqlite": NewSQLUsageStatsRepository(newTestSQLite(t)),
+		"memory": NewMemoryUsageStatsRepository(),
+		"file":   fileRepo,
+	}
+
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			err := repo.AddCounters([]UsageCounter{
+				{Date: "2024-03-01", Kind: UsageCommand, Key: "today", Count: 2},
+				{Date: "2024-03-02", Kind: UsageCommand, Key: "today", Count: 1},
+				{Date: "2024-03-02", Kind: UsageApiErrors, Count: 1},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Counters of the same day, kind and key are summed up
+			err = repo.AddCounters([]UsageCounter{
+				{Date: "2024-03-02", Kind: UsageCommand, Key: "today", Count: 3},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := repo.DeleteCountersBefore("2024-03-02"); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := repo.GetCounters("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []UsageCounter{
+				{Date: "2024-03-02", Kind: UsageApiErrors, Count: 1},
+				{Date: "2024-03-02", Kind: UsageCommand, Key: "today", Count: 4},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("GetCounters() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestFileUsageStatsRepositoryLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "usage_counters.json")
+	repo, err := NewFileUsageStatsRepository(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counters := []UsageCounter{{Date: "2024-03-02", Kind: UsageButton, Key: "open.menu", Count: 5}}
+	if err := repo.AddCounters(counters); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := NewFileUsageStatsRepository(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := loaded.GetCounters("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, counters) {
+		t.Errorf("loaded counters = %+v, want %+v", got, counters)
+	}
+}
+
+func TestUsageStatsSummary(t *testing.T) {
+	repo := NewSQLUsageStatsRepository(newTestSQLite(t))
+	yesterday := time.Now().AddDate(0, 0, -1).Format(time.DateOnly)
+	err := repo.AddCounters([]UsageCounter{
+		{Date: yesterday, Kind: UsageActiveChats, Count: 4},
+		{Date: yesterday, Kind: UsageGroupViews, Key: "1", Count: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := NewUsageStats(repo, 0)
+	// Chats are counted once a day
+	stats.CountCommand(1, "today")
+	stats.CountButton(1, "open.menu")
+	stats.CountCommand(2, "start")
+	stats.CountGroupView(1)
+	stats.CountApiError()
+
+	today, err := stats.Summary(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := UsageSummary{ActiveChats: 2, Commands: 2, Buttons: 1, ApiErrors: 1, Groups: map[int]int{1: 1}}
+	if !reflect.DeepEqual(today, want) {
+		t.Errorf("Summary(1) = %+v, want %+v", today, want)
+	}
+
+	// Active chats of a few days are the daily average
+	week, err := stats.Summary(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = UsageSummary{ActiveChats: 3, Commands: 2, Buttons: 1, ApiErrors: 1, Groups: map[int]int{1: 3}}
+	if !reflect.DeepEqual(week, want) {
+		t.Errorf("Summary(7) = %+v, want %+v", week, want)
+	}
+}
+
+// failingUsageStatsRepository fails to add the counters
+type failingUsageStatsRepository struct {
+	UsageStatsRepository
+}
+
+func (r failingUsageStatsRepository) AddCounters([]UsageCounter) error {
+	return errors.New("database is down")
+}
+
+func TestUsageStatsFlushKeepsCountsOnError(t *testing.T) {
+	repo := NewMemoryUsageStatsRepository()
+	stats := NewUsageStats(failingUsageStatsRepository{repo}, 0)
+	stats.CountApiError()
+
+	if err := stats.Flush(); err == nil {
+		t.Fatal("Flush() error = nil, want the repository error")
+	}
+
+	stats.repo = repo
+	if err := stats.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	counters, err := repo.GetCounters("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counters) != 1 || counters[0].Kind != UsageApiErrors || counters[0].Count != 1 {
+		t.Errorf("counters after the failed flush = %+v, want one API error", counters)
+	}
+}
@@ -29,13 +29,15 @@ import (
 
 // User is a struct that contains all the user data
 type User struct {
-	Id        int64     `db:"id" json:"id"`
-	FirstName string    `db:"first_name" json:"firstName"`
-	LastName  string    `db:"last_name" json:"lastName"`
-	Username  string    `db:"username" json:"username"`
-	IsAdmin   bool      `db:"is_admin" json:"isAdmin"`
-	Referral  string    `db:"referral" json:"referral"`
-	Created   time.Time `db:"created" json:"created"`
+	Id          int64     `db:"id" json:"id"`
+	FirstName   string    `db:"first_name" json:"firstName"`
+	LastName    string    `db:"last_name" json:"lastName"`
+	Username    string    `db:"username" json:"username"`
+	IsAdmin     bool      `db:"is_admin" json:"isAdmin"`
+	Referral    string    `db:"referral" json:"referral"`
+	GroupId     int       `db:"group_id" json:"groupId"`
+	UseOwnGroup bool      `db:"use_own_group" json:"useOwnGroup"`
+	Created     time.Time `db:"created" json:"created"`
 }
 
 // UserRepository is an interface for working with user data.
@@ -44,17 +46,21 @@ type UserRepository interface {
 	GetById(id int64) (*User, error)
 	// Update updates the user data.
 	Update(user *User) error
+	// GetAllUsers returns all users.
+	GetAllUsers() ([]*User, error)
 }
 
 // NewUser creates a new instance of User.
 func NewUser(id int64) *User {
 	return &User{
-		Id:        id,
-		FirstName: "",
-		LastName:  "",
-		Username:  "",
-		IsAdmin:   false,
-		Referral:  "",
-		Created:   time.Now(),
+		Id:          id,
+		FirstName:   "",
+		LastName:    "",
+		Username:    "",
+		IsAdmin:     false,
+		Referral:    "",
+		GroupId:     -1,
+		UseOwnGroup: false,
+		Created:     time.Now(),
 	}
 }
@@ -0,0 +1,473 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/lifecycle"
+	"github.com/op/go-logging"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var log = logging.MustGetLogger("Broadcast")
+
+// MessagesPerSecond is the default maximum number of messages sent per second.
+// Telegram allows bots to send about 30 messages per second to different chats.
+const MessagesPerSecond = 25
+
+// MaxReportedErrors is the maximum number of distinct send errors kept in the report
+const MaxReportedErrors = 10
+
+// MaxRetries is the maximum number of retries of a message
+// that was rejected with "Too Many Requests" error
+const MaxRetries = 3
+
+// ErrAlreadyRunning is returned when the broadcast is started while another one is running
+var ErrAlreadyRunning = errors.New("broadcast is already running")
+
+// MessageDelay is the delay between the sent messages
+var MessageDelay = time.Second / MessagesPerSecond
+
+// ProgressFile is the file the running broadcast is saved to, so the
+// broadcast interrupted by the bot restart can be resumed. The number of
+// the processed chats is saved after every chat to ProgressFile + ".state".
+// Progress is not saved if empty.
+var ProgressFile string
+
+// state is the part of the job changed after every chat
+type state struct {
+	Processed int    `json:"processed"`
+	Report    Report `json:"report"`
+}
+
+// Report is the result of the broadcast
+type Report struct {
+	// Sent is the number of chats the message was sent to
+	Sent int `json:"sent"`
+	// Blocked is the number of chats in which the bot is blocked or which are not found
+	Blocked int `json:"blocked"`
+	// Failed is the number of chats the message was not sent to because of other errors
+	Failed int `json:"failed"`
+	// Cancelled is true if the broadcast was stopped before all chats were processed
+	Cancelled bool `json:"cancelled"`
+	// Errors are the descriptions of the errors counted in Failed
+	// with the number of chats they occurred in
+	Errors map[string]int `json:"errors,omitempty"`
+}
+
+// Job is the message broadcast with its progress
+type Job struct {
+	// FromChatId and MessageId identify the message to copy
+	FromChatId int64 `json:"fromChatId"`
+	MessageId  int64 `json:"messageId"`
+	// Text is sent instead of copying the message, if not empty
+	Text string `json:"text,omitempty"`
+	// ReportChatId is the chat to send the report to
+	ReportChatId int64 `json:"reportChatId"`
+	// ChatIds are the chats to send the message to
+	ChatIds []int64 `json:"chatIds"`
+	// Processed is the number of chats from ChatIds already processed
+	Processed int `json:"processed"`
+	// Report is the result of the processed chats
+	Report Report `json:"report"`
+}
+
+var (
+	// mu guards cancel and stopping
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	// stopping is true if the broadcast is stopped
+	// by the shutdown and should be resumed later
+	stopping bool
+)
+
+// NewJob creates a new broadcast of the message to the chats.
+func NewJob(chats []*data.Chat, fromChatId int64, messageId int64, reportChatId int64) *Job {
+	chatIds := make([]int64, len(chats))
+	for i, chat := range chats {
+		chatIds[i] = chat.Id
+	}
+
+	return &Job{
+		FromChatId:   fromChatId,
+		MessageId:    messageId,
+		ReportChatId: reportChatId,
+		ChatIds:      chatIds,
+	}
+}
+
+// NewTextJob creates a new broadcast of the text to the chats.
+func NewTextJob(chats []*data.Chat, text string, reportChatId int64) *Job {
+	job := NewJob(chats, 0, 0, reportChatId)
+	job.Text = text
+	return job
+}
+
+// FilterByGroup returns the chats with the given group selected
+func FilterByGroup(chats []*data.Chat, groupId int) []*data.Chat {
+	filtered := make([]*data.Chat, 0)
+	for _, chat := range chats {
+		if chat.GroupId == groupId {
+			filtered = append(filtered, chat)
+		}
+	}
+	return filtered
+}
+
+// Start copies the message to the chats in a background goroutine.
+// Only one broadcast can run at a time.
+//
+// Chats in which the bot is blocked or which are not found
+// are marked as not accessible, so the bot stops sending messages to them.
+//
+// onDone is called when the broadcast is completed or cancelled,
+// but not when it is stopped by the shutdown.
+func Start(bot *gotgbot.Bot, chatRepo data.ChatRepository, job *Job, onDone func(*Job)) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if cancel != nil {
+		return ErrAlreadyRunning
+	}
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+
+	if job.Text != "" {
+		log.Infof("Starting broadcast of text message to %d chats at chat %d",
+			len(job.ChatIds), job.Processed)
+	} else {
+		log.Infof("Starting broadcast of message %d from chat %d to %d chats at chat %d",
+			job.MessageId, job.FromChatId, len(job.ChatIds), job.Processed)
+	}
+
+	err := lifecycle.Go(func() {
+		run(ctx, bot, chatRepo, job)
+
+		mu.Lock()
+		cancel = nil
+		interrupted := stopping
+		mu.Unlock()
+		cancelCtx()
+
+		if interrupted {
+			log.Infof("Broadcast interrupted after %d of %d chats, it will be resumed on the next start",
+				job.Processed, len(job.ChatIds))
+			if err := saveProgress(job); err != nil {
+				log.Errorf("Error saving broadcast progress: %s", err)
+			}
+			return
+		}
+
+		if err := removeProgress(); err != nil {
+			log.Errorf("Error removing broadcast progress: %s", err)
+		}
+
+		log.Infof("Broadcast finished: sent %d, blocked %d, failed %d, cancelled %t",
+			job.Report.Sent, job.Report.Blocked, job.Report.Failed, job.Report.Cancelled)
+		onDone(job)
+	})
+	if err != nil {
+		cancelCtx()
+		return err
+	}
+
+	cancel = cancelCtx
+	return nil
+}
+
+// Resume starts the broadcast saved to ProgressFile, if any.
+//
+// Returns false if there is no broadcast to resume.
+func Resume(bot *gotgbot.Bot, chatRepo data.ChatRepository, onDone func(*Job)) (bool, error) {
+	job, err := loadProgress()
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	if err := Start(bot, chatRepo, job, onDone); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Cancel stops the running broadcast. It will not be resumed.
+//
+// Returns false if there is no running broadcast.
+func Cancel() bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if cancel == nil {
+		return false
+	}
+
+	cancel()
+	return true
+}
+
+// Stop stops the running broadcast on shutdown.
+// Its progress is saved, so it is resumed on the next start.
+func Stop() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	stopping = true
+	cancel()
+}
+
+// run sends the message to the chats, stays under the rate limit
+// and stops when ctx is done
+func run(ctx context.Context, bot *gotgbot.Bot, chatRepo data.ChatRepository, job *Job) {
+	ticker := time.NewTicker(MessageDelay)
+	defer ticker.Stop()
+
+	if err := saveProgress(job); err != nil {
+		log.Errorf("Error saving broadcast progress: %s", err)
+	}
+
+	for job.Processed < len(job.ChatIds) {
+		select {
+		case <-ctx.Done():
+			job.Report.Cancelled = true
+			return
+		case <-ticker.C:
+		}
+
+		chatId := job.ChatIds[job.Processed]
+
+		err := sendMessage(ctx, bot, chatId, job)
+		switch {
+		case err == nil:
+			job.Report.Sent++
+
+		case isUnreachable(err):
+			job.Report.Blocked++
+			log.Infof("Chat %d is unreachable: %s", chatId, err)
+			if err := markInaccessible(chatRepo, chatId); err != nil {
+				log.Errorf("Error marking chat %d as not accessible: %s", chatId, err)
+			}
+
+		case errors.Is(err, context.Canceled):
+			// The message was not sent, so the chat is not processed
+			job.Report.Cancelled = true
+			return
+
+		default:
+			job.Report.Failed++
+			job.Report.addError(err)
+			log.Warningf("Error sending broadcast message to chat %d: %s", chatId, err)
+		}
+
+		// The message is not sent to the chat again if the bot crashes
+		job.Processed++
+		if err := saveState(job); err != nil {
+			log.Errorf("Error saving broadcast progress: %s", err)
+		}
+	}
+}
+
+// addError counts the error in the report.
+// Only the first MaxReportedErrors distinct errors are kept.
+func (r *Report) addError(err error) {
+	description := err.Error()
+
+	var tgError *gotgbot.TelegramError
+	if errors.As(err, &tgError) {
+		// The method and params differ in every chat
+		description = tgError.Description
+	}
+
+	if r.Errors == nil {
+		r.Errors = make(map[string]int)
+	}
+	if _, ok := r.Errors[description]; !ok && len(r.Errors) == MaxReportedErrors {
+		return
+	}
+	r.Errors[description]++
+}
+
+// sendMessage sends the text or copies the message of the job to the chat.
+// Waits and retries if Telegram asks to slow down.
+func sendMessage(ctx context.Context, bot *gotgbot.Bot, chatId int64, job *Job) error {
+	for i := 0; ; i++ {
+		var err error
+		if job.Text != "" {
+			_, err = bot.SendMessage(chatId, job.Text, nil)
+		} else {
+			_, err = bot.CopyMessage(chatId, job.FromChatId, job.MessageId, nil)
+		}
+
+		var tgError *gotgbot.TelegramError
+		if i == MaxRetries || !errors.As(err, &tgError) || tgError.Code != 429 || tgError.ResponseParams == nil {
+			return err
+		}
+
+		retryAfter := time.Duration(tgError.ResponseParams.RetryAfter) * time.Second
+		log.Warningf("Too many requests, retry after %s", retryAfter)
+
+		timer := time.NewTimer(retryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isUnreachable checks if the error means that the bot can't send
+// messages to the chat: the bot is blocked, kicked, or the chat is not found
+func isUnreachable(err error) bool {
+	var tgError *gotgbot.TelegramError
+	if !errors.As(err, &tgError) {
+		return false
+	}
+
+	return tgError.Code == 403 ||
+		(tgError.Code == 400 && strings.Contains(tgError.Description, "chat not found"))
+}
+
+// markInaccessible marks the chat as not accessible.
+// The chat is read again, because it could be changed during the broadcast.
+func markInaccessible(chatRepo data.ChatRepository, chatId int64) error {
+	chat, err := chatRepo.GetById(chatId)
+	if err != nil || chat == nil {
+		return err
+	}
+
+	chat.Accessible = false
+	return chatRepo.Update(chat)
+}
+
+// saveProgress writes the job to ProgressFile and its state to the state file
+func saveProgress(job *Job) error {
+	if ProgressFile == "" {
+		return nil
+	}
+
+	// Interrupted broadcast is not cancelled, it continues after the restart
+	saved := *job
+	saved.Report.Cancelled = false
+
+	if err := writeFile(ProgressFile, saved); err != nil {
+		return err
+	}
+	return saveState(job)
+}
+
+// saveState writes the number of the processed chats and the report to
+// the state file. It's much smaller than the job, so it's saved after every chat.
+func saveState(job *Job) error {
+	if ProgressFile == "" {
+		return nil
+	}
+
+	return writeFile(stateFile(), state{Processed: job.Processed, Report: job.Report})
+}
+
+// writeFile writes the JSON of v to the file. The file is
+// replaced atomically, so it is not corrupted if the bot is killed.
+func writeFile(file string, v any) error {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	tmpFile := file + ".tmp"
+	if err := os.WriteFile(tmpFile, content, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, file)
+}
+
+// stateFile returns the file the state of the job is saved to
+func stateFile() string {
+	return ProgressFile + ".state"
+}
+
+// loadProgress reads the job from ProgressFile.
+// Returns nil if there is no saved job.
+func loadProgress() (*Job, error) {
+	if ProgressFile == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(ProgressFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var job Job
+	if err := json.Unmarshal(content, &job); err != nil {
+		return nil, err
+	}
+
+	// State is saved after the job, so it's newer, if any
+	content, err = os.ReadFile(stateFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return &job, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var saved state
+	if err := json.Unmarshal(content, &saved); err != nil {
+		return nil, err
+	}
+	job.Processed = saved.Processed
+	job.Report = saved.Report
+	job.Report.Cancelled = false
+
+	return &job, nil
+}
+
+// removeProgress removes ProgressFile, so the completed broadcast is not resumed
+func removeProgress() error {
+	if ProgressFile == "" {
+		return nil
+	}
+
+	for _, file := range []string{ProgressFile, stateFile()} {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
@@ -36,13 +36,13 @@ func HandleAdminPanelButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
-	if !user.IsAdmin {
+	if !utils.IsBotAdmin(user) {
 		// TODO: send error message here & everywhere else
 		return nil
 	}
 
 	// Open admin panel
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
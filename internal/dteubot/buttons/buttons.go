@@ -24,26 +24,36 @@ package buttons
 
 import (
 	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/groupscache"
 	"github.com/cubicbyte/dteubot/internal/i18n"
 	api2 "github.com/cubicbyte/dteubot/pkg/api"
 )
 
 var (
-	chatRepo  data.ChatRepository
-	userRepo  data.UserRepository
-	api       api2.Api
-	languages map[string]i18n.Language
+	chatRepo    data.ChatRepository
+	userRepo    data.UserRepository
+	api         api2.Api
+	languages   map[string]i18n.Language
+	inputStates *data.InputStates
+	groupsCache *groupscache.Cache
+	usageStats  *data.UsageStats
 )
 
 // InitButtons initializes the buttons package. Must be called before using the package
 func InitButtons(
-	chatRepo2 data.ChatRepository,
-	userRepo2 data.UserRepository,
+	storage data.Storage,
 	api2 api2.Api,
 	languages2 map[string]i18n.Language,
+	inputStates2 *data.InputStates,
+	groupsCache2 *groupscache.Cache,
+	usageStats2 *data.UsageStats,
 ) {
-	chatRepo = chatRepo2
-	userRepo = userRepo2
+	chatRepo = storage.Chats()
+	userRepo = storage.Users()
 	api = api2
 	languages = languages2
+	inputStates = inputStates2
+	groupsCache = groupsCache2
+	usageStats = usageStats2
+	navHandlers = newNavHandlers()
 }
@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"bytes"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/ical"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"os"
+	"strconv"
+)
+
+func HandleCalendarExportButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	if settings.GroupId == -1 {
+		page, err := pages.CreateInvalidGroupPage(lang)
+		return openPage(bot, ctx, page, err)
+	}
+
+	days, err := strconv.Atoi(os.Getenv("CALENDAR_EXPORT_DAYS"))
+	if err != nil {
+		return err
+	}
+
+	calendar, err := ical.CreateGroupCalendar(api, settings.GroupId, days, utils.NowFor(chat))
+	if err != nil {
+		return err
+	}
+
+	// Send "sending document" action
+	_, err = bot.SendChatAction(ctx.EffectiveChat.Id, "upload_document", nil)
+	if err != nil {
+		return err
+	}
+
+	// Send calendar file
+	page, err := pages.CreateCalendarExportPage(lang, days)
+	if err != nil {
+		return err
+	}
+
+	_, err = bot.SendDocument(ctx.EffectiveChat.Id, gotgbot.NamedFile{
+		File:     bytes.NewReader(calendar),
+		FileName: "schedule.ics",
+	}, &gotgbot.SendDocumentOpts{
+		Caption:   page.Text,
+		ParseMode: page.ParseMode,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = bot.AnswerCallbackQuery(ctx.CallbackQuery.Id, nil)
+	return err
+}
@@ -30,7 +30,7 @@ import (
 )
 
 func HandleCallsButton(bot *gotgbot.Bot, ctx *ext.Context) error {
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
@@ -40,6 +40,6 @@ func HandleCallsButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
-	page, err := pages.CreateCallSchedulePage(lang, "open.more#from=calls")
+	page, err := pages.CreateCallSchedulePage(utils.UpdateContext(ctx), lang, utils.NewButtonData("open.more").Set("from", "calls").String(), utils.ChatLocation(chat), utils.NowFor(chat))
 	return openPage(bot, ctx, page, err)
 }
@@ -35,14 +35,14 @@ func HandleClearCacheButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
-	if !user.IsAdmin {
+	if !utils.IsBotAdmin(user) {
 		return nil
 	}
 
 	// TODO: Clear cache
 
 	// Send "Done" alert
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
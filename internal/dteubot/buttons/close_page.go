@@ -34,7 +34,7 @@ import (
 )
 
 func HandleClosePageButton(bot *gotgbot.Bot, ctx *ext.Context) error {
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
@@ -69,7 +69,7 @@ func ClosePage(chatId int64, messageId int64, bot *gotgbot.Bot, lang i18n.Langua
 
 				opts := page.CreateEditMessageOpts(chatId, messageId)
 				_, _, err = bot.EditMessageText(page.Text, &opts)
-				if err != nil {
+				if err != nil && !utils.IsMessageNotModified(err) {
 					return err
 				}
 
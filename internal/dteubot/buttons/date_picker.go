@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"fmt"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"time"
+)
+
+func HandleDatePickerButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// Get month and the schedule date from button params
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	month, err := button.Param("month")
+	if err != nil {
+		return err
+	}
+	if _, err := time.Parse(pages.DatePickerMonthFormat, month); err != nil {
+		return fmt.Errorf("%w: invalid month %q", utils.ErrInvalidButtonData, month)
+	}
+
+	date, err := button.Param("date")
+	if err != nil {
+		return err
+	}
+
+	// Schedule of the other chat group is opened with its id
+	groupId := -1
+	if _, ok := button.Params["groupId"]; ok {
+		groupId, err = getGroupIdParam(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	page, err := pages.CreateDatePickerPage(lang, chat, month, date, groupId)
+	return openPage(bot, ctx, page, err)
+}
+
+// HandleNoopButton answers the button that does nothing, like the date picker weekdays
+func HandleNoopButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	_, err := bot.AnswerCallbackQuery(ctx.CallbackQuery.Id, nil)
+	return err
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+)
+
+func HandleShareScheduleButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	link, err := getDeepLinkParams(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Reply with the link, the schedule message stays as is
+	page, err := pages.CreateShareSchedulePage(lang, link, bot.Username)
+	if err != nil {
+		return err
+	}
+
+	if err := sendPage(bot, ctx, page); err != nil {
+		return err
+	}
+
+	_, err = bot.AnswerCallbackQuery(ctx.CallbackQuery.Id, nil)
+	return err
+}
+
+func HandleShareGroupButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	groupId, err := getGroupIdParam(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Reply with the link, the group selection message stays as is
+	page, err := pages.CreateShareGroupPage(lang, groupId, bot.Username)
+	if err != nil {
+		return err
+	}
+
+	if err := sendPage(bot, ctx, page); err != nil {
+		return err
+	}
+
+	_, err = bot.AnswerCallbackQuery(ctx.CallbackQuery.Id, nil)
+	return err
+}
+
+func HandleDeepLinkButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	link, err := getDeepLinkParams(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Switch to the deep link group
+	chat.GroupId = link.GroupId
+
+	err = chatRepo.Update(chat)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.UpdateUserGroup(ctx, userRepo, link.GroupId); err != nil {
+		return err
+	}
+
+	page, err := pages.CreateSchedulePage(utils.UpdateContext(ctx), lang, link.GroupId, nil, link.Date, utils.ChatLocation(chat), chat.Id, pages.ChatScheduleView(chat))
+	return openPage(bot, ctx, page, err)
+}
+
+// getDeepLinkParams returns the schedule group and date from the button params
+func getDeepLinkParams(ctx *ext.Context) (utils.DeepLink, error) {
+	groupId, err := getGroupIdParam(ctx)
+	if err != nil {
+		return utils.DeepLink{}, err
+	}
+
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return utils.DeepLink{}, err
+	}
+
+	date, err := button.Param("date")
+	if err != nil {
+		return utils.DeepLink{}, err
+	}
+
+	return utils.DeepLink{GroupId: groupId, Date: date}, nil
+}
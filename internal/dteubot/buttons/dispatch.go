@@ -0,0 +1,175 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+)
+
+// navHandlers are the handlers of the pages the back button can return to,
+// by the button action. Only the pages that just show something are here,
+// so opening the page again doesn't change the chat settings.
+//
+// Set in InitButtons, as the handlers use the map themselves.
+var navHandlers map[string]func(*gotgbot.Bot, *ext.Context) error
+
+func newNavHandlers() map[string]func(*gotgbot.Bot, *ext.Context) error {
+	return map[string]func(*gotgbot.Bot, *ext.Context) error{
+		"open.calls":                HandleCallsButton,
+		"open.info":                 HandleInfoButton,
+		"open.left":                 HandleLeftButton,
+		"open.menu":                 HandleMenuButton,
+		"open.more":                 HandleMoreButton,
+		"open.next":                 HandleNextLessonButton,
+		"open.select_group":         HandleOpenSelectGroupButton,
+		"open.select_lang":          HandleOpenSelectLanguageButton,
+		"open.select_teacher":       HandleOpenSelectTeacherButton,
+		"open.schedule.day":         HandleScheduleDayButton,
+		"open.schedule.school_day":  HandleSchoolDayButton,
+		"open.date_picker":          HandleDatePickerButton,
+		"show.lesson":               HandleLessonInfoButton,
+		"open.schedule.extra":       HandleScheduleExtraButton,
+		"open.schedule.today":       HandleScheduleTodayButton,
+		"open.schedule.teacher":     HandleTeacherScheduleButton,
+		"open.schedule.week":        HandleScheduleWeekButton,
+		"open.schedule.overview":    HandleScheduleOverviewButton,
+		"select.schedule.course":    HandleSelectCourseButton,
+		"select.schedule.faculty":   HandleSelectFacultyButton,
+		"select.schedule.structure": HandleSelectStructureButton,
+		"select.teacher_chair":      HandleSelectTeacherChairButton,
+		"select.teacher_faculty":    HandleSelectTeacherFacultyButton,
+		"select.teacher_structure":  HandleSelectTeacherStructureButton,
+		"open.hidden_lessons":       HandleHiddenLessonsButton,
+		"open.semester_overview":    HandleSemesterOverviewButton,
+		"open.lesson_reminder":      HandleLessonReminderButton,
+		"open.settings":             HandleSettingsButton,
+		"open.daily_schedule":       HandleDailyScheduleButton,
+		"open.timezone":             HandleTimezoneButton,
+		"open.saved_groups":         HandleGroupSwitchButton,
+		"open.exams":                HandleExamScheduleButton,
+		"open.free_rooms":           HandleFreeRoomsButton,
+		"open.students_list":        HandleStudentsListButton,
+	}
+}
+
+// HandleBackButton returns to the page opened in the message before the current one.
+//
+// If there is no such page, e.g. the message is sent by the command,
+// the day schedule is opened if the button has the date, or the menu otherwise.
+func HandleBackButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	stack, entry, ok := chat.NavStack.Back(ctx.EffectiveMessage.MessageId)
+	if len(stack) != len(chat.NavStack) {
+		chat.NavStack = stack
+		if err := chatRepo.Update(chat); err != nil {
+			return err
+		}
+	}
+
+	if !ok {
+		date, ok := button.Params["date"]
+		if !ok {
+			return HandleMenuButton(bot, ctx)
+		}
+		entry = data.NavEntry{Data: utils.NewButtonData("open.schedule.day").Set("date", date).String()}
+	}
+
+	// Page could be remembered before its button was removed
+	handler, ok := navHandlers[utils.ButtonAction(entry.Data)]
+	if !ok {
+		return HandleMenuButton(bot, ctx)
+	}
+
+	// Button statistics should count the back button, not the page it opens
+	buttonData := ctx.CallbackQuery.Data
+	ctx.CallbackQuery.Data = entry.Data
+	defer func() {
+		ctx.CallbackQuery.Data = buttonData
+	}()
+
+	return handler(bot, ctx)
+}
+
+// chatKey is the update context data key of the chat loaded by getChat
+const chatKey = "chat"
+
+// getChat returns the chat the update is sent to. The chat is loaded
+// once per update, so rememberPage and the handlers called by other
+// handlers, like the back button, don't read it again.
+//
+// Returns nil if the chat is not saved yet.
+func getChat(ctx *ext.Context) (*data.Chat, error) {
+	if chat, ok := ctx.Data[chatKey].(*data.Chat); ok {
+		return chat, nil
+	}
+
+	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	if err != nil || chat == nil {
+		return chat, err
+	}
+
+	ctx.Data[chatKey] = chat
+	return chat, nil
+}
+
+// rememberPage pushes the page opened with the button to the chat
+// navigation stack, so that the back button can return to it
+func rememberPage(ctx *ext.Context) error {
+	if ctx.EffectiveMessage == nil {
+		return nil
+	}
+	if _, ok := navHandlers[utils.ButtonAction(ctx.CallbackQuery.Data)]; !ok {
+		return nil
+	}
+
+	entry := data.NavEntry{
+		MessageId: ctx.EffectiveMessage.MessageId,
+		Data:      ctx.CallbackQuery.Data,
+	}
+
+	chat, err := getChat(ctx)
+	if err != nil || chat == nil {
+		return err
+	}
+
+	// The page is opened again, e.g. with the back button
+	if top, ok := chat.NavStack.Top(entry.MessageId); ok && top == entry {
+		return nil
+	}
+
+	chat.NavStack = chat.NavStack.Push(entry)
+	return chatRepo.Update(chat)
+}
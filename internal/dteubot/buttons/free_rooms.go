@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"strconv"
+)
+
+func HandleFreeRoomsButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// Get params from button data
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	date, err := button.Param("date")
+	if err != nil {
+		return err
+	}
+
+	// Select building first
+	buildingStr, ok := button.Params["building"]
+	if !ok {
+		page, err := pages.CreateFreeRoomsBuildingsPage(lang, date)
+		return openPage(bot, ctx, page, err)
+	}
+
+	building, err := strconv.Atoi(buildingStr)
+	if err != nil {
+		return err
+	}
+
+	// Then select lesson
+	lessonStr, ok := button.Params["lesson"]
+	if !ok {
+		page, err := pages.CreateFreeRoomsLessonsPage(utils.UpdateContext(ctx), lang, building, date)
+		return openPage(bot, ctx, page, err)
+	}
+
+	lesson, err := strconv.Atoi(lessonStr)
+	if err != nil {
+		return err
+	}
+
+	buildings := pages.GetFreeRoomsBuildings()
+	if building < 0 || building >= len(buildings) {
+		// Buildings list has changed since the page was sent
+		page, err := pages.CreateFreeRoomsBuildingsPage(lang, date)
+		return openPage(bot, ctx, page, err)
+	}
+
+	page, err := pages.CreateRoomsPage(utils.UpdateContext(ctx), lang, buildings[building], lesson, date)
+	if err != nil {
+		return err
+	}
+
+	// List of the occupied rooms can be long
+	return openPages(bot, ctx, pages.SplitLongPage(page, pages.MaxPageLength), nil)
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+)
+
+// HandleGroupChatSetupButton sets up the shared schedule of the group chat:
+// the chat settings are locked, so only chat admins can change them,
+// and the admin is asked to select the group of the whole chat.
+func HandleGroupChatSetupButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// Only chat admins can set up the group chat
+	if ctx.EffectiveChat.Type != "private" {
+		admin, err := utils.IsChatAdmin(bot, chat.Id, ctx.EffectiveUser.Id)
+		if err != nil {
+			return err
+		}
+		if !admin {
+			_, err = bot.AnswerCallbackQuery(ctx.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+				Text:      lang.Alert.NotChatAdmin,
+				ShowAlert: true,
+			})
+			return err
+		}
+
+		if !chat.SettingsLocked {
+			chat.SettingsLocked = true
+
+			err = chatRepo.Update(chat)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Select the group of the chat
+	structures, err := api.WithContext(utils.UpdateContext(ctx)).GetStructures()
+	if err != nil {
+		return err
+	}
+
+	var page pages.Page
+	if len(structures) == 1 {
+		page, err = pages.CreateFacultiesListPage(utils.UpdateContext(ctx), lang, structures[0].Id, chat.GroupId, false)
+	} else {
+		page, err = pages.CreateStructuresListPage(utils.UpdateContext(ctx), lang, chat.GroupId, false)
+	}
+
+	return openPage(bot, ctx, page, err)
+}
@@ -0,0 +1,220 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/sirkon/go-format/v2"
+)
+
+func HandleHiddenLessonsButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	pageNum, err := getPageNum(button)
+	if err != nil {
+		return err
+	}
+
+	page, err := pages.CreateHiddenLessonsPage(lang, chat, pageNum)
+	return openPage(bot, ctx, page, err)
+}
+
+// HandleHideLessonButton hides all the lesson occurrences from the schedule
+func HandleHideLessonButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	lessonId, err := button.Param("lessonId")
+	if err != nil {
+		return err
+	}
+
+	date, err := button.Param("date")
+	if err != nil {
+		return err
+	}
+
+	groupId, err := getViewedGroupId(button, settings)
+	if err != nil {
+		return err
+	}
+
+	if chat.HiddenLessons.Index(lessonId) == -1 {
+		if len(chat.HiddenLessons) >= data.MaxHiddenLessons {
+			return answerAlert(bot, ctx, format.Formatp(lang.Alert.HiddenLessonsFull, data.MaxHiddenLessons))
+		}
+
+		// Remember the lesson name to show it in the hidden lessons list
+		lesson, ok, err := pages.GetHiddenLesson(utils.UpdateContext(ctx), groupId, date, lessonId)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			chat.HiddenLessons = append(chat.HiddenLessons, lesson)
+			if err := chatRepo.Update(chat); err != nil {
+				return err
+			}
+		}
+	}
+
+	page, err := pages.CreateScheduleExtraInfoPage(utils.UpdateContext(ctx), lang, groupId, settings.GroupId, date, chat.HiddenLessons)
+	return openPage(bot, ctx, page, err)
+}
+
+// HandleUnhideLessonButton shows the hidden lesson on the schedule again.
+// The button is on the lesson details page if it has the date,
+// or on the hidden lessons page otherwise.
+func HandleUnhideLessonButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	lessonId, err := button.Param("lessonId")
+	if err != nil {
+		return err
+	}
+
+	if chat.HiddenLessons.Index(lessonId) != -1 {
+		chat.HiddenLessons = chat.HiddenLessons.Remove(lessonId)
+		if err := chatRepo.Update(chat); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := button.Params["date"]; !ok {
+		pageNum, err := getPageNum(button)
+		if err != nil {
+			return err
+		}
+
+		page, err := pages.CreateHiddenLessonsPage(lang, chat, pageNum)
+		return openPage(bot, ctx, page, err)
+	}
+
+	date, err := button.Param("date")
+	if err != nil {
+		return err
+	}
+
+	groupId, err := getViewedGroupId(button, settings)
+	if err != nil {
+		return err
+	}
+
+	page, err := pages.CreateScheduleExtraInfoPage(utils.UpdateContext(ctx), lang, groupId, settings.GroupId, date, chat.HiddenLessons)
+	return openPage(bot, ctx, page, err)
+}
+
+func HandleSetStrikeHiddenLessonsButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// Get state from button data
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	state, err := button.Param("state")
+	if err != nil {
+		return err
+	}
+
+	chat.StrikeHiddenLessons = state == "1"
+
+	err = chatRepo.Update(chat)
+	if err != nil {
+		return err
+	}
+
+	pageNum, err := getPageNum(button)
+	if err != nil {
+		return err
+	}
+
+	// Update page
+	page, err := pages.CreateHiddenLessonsPage(lang, chat, pageNum)
+	return openPage(bot, ctx, page, err)
+}
@@ -30,16 +30,21 @@ import (
 )
 
 func HandleLeftButton(bot *gotgbot.Bot, ctx *ext.Context) error {
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
 
-	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
 	if err != nil {
 		return err
 	}
 
-	page, err := pages.CreateLeftPage(lang, chat.GroupId, "open.more#from=left")
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	page, err := pages.CreateLeftPage(lang, chat, settings.GroupId, utils.NewButtonData("open.more").Set("from", "left").String())
 	return openPage(bot, ctx, page, err)
 }
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"fmt"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"strconv"
+)
+
+func HandleLessonInfoButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// Get date and lesson number from button params
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	date, err := button.Param("date")
+	if err != nil {
+		return err
+	}
+
+	lessonStr, err := button.Param("lesson")
+	if err != nil {
+		return err
+	}
+
+	lesson, err := strconv.Atoi(lessonStr)
+	if err != nil {
+		return fmt.Errorf("%w: invalid lesson %q", utils.ErrInvalidButtonData, lessonStr)
+	}
+
+	groupId, err := getViewedGroupId(button, settings)
+	if err != nil {
+		return err
+	}
+
+	summary, err := pages.GetLessonSummary(utils.UpdateContext(ctx), lang, groupId, date, lesson, utils.ChatLocation(chat))
+	if err != nil {
+		return err
+	}
+
+	// Schedule has changed since the page was sent
+	if summary == "" {
+		return answerAlert(bot, ctx, lang.Alert.LessonNotFound)
+	}
+
+	return answerToast(bot, ctx, summary)
+}
+
+// HandleNoEarlierDataButton answers the disabled navigation arrow,
+// that would lead to the dates with no schedule
+func HandleNoEarlierDataButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	return answerToast(bot, ctx, lang.Alert.NoEarlierData)
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"fmt"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/middleware"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/sirkon/go-format/v2"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// HandleLessonReminderButton opens the page to choose when to remind about the lesson
+func HandleLessonReminderButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	groupId, date, number, err := getLessonParams(ctx, settings)
+	if err != nil {
+		return err
+	}
+
+	page, err := pages.CreateLessonReminderPage(utils.UpdateContext(ctx), lang, chat, groupId, settings.GroupId, date, number, utils.ChatLocation(chat))
+	return openPage(bot, ctx, page, err)
+}
+
+// HandleSetLessonReminderButton sets the one-shot lesson reminder,
+// or cancels it if the offset is 0
+func HandleSetLessonReminderButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	groupId, date, number, err := getLessonParams(ctx, settings)
+	if err != nil {
+		return err
+	}
+
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	offsetStr, err := button.Param("offset")
+	if err != nil {
+		return err
+	}
+
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil {
+		return err
+	}
+	if offset != 0 && !slices.Contains(pages.LessonReminderOffsets, offset) {
+		return fmt.Errorf("%w: invalid offset %d", utils.ErrInvalidButtonData, offset)
+	}
+
+	reminders := chat.LessonReminders.Remove(groupId, date, number)
+
+	if offset == 0 {
+		middleware.SetToast(ctx, lang.Alert.LessonReminderCancelled)
+	} else {
+		if len(reminders) >= data.MaxLessonReminders {
+			return answerAlert(bot, ctx, format.Formatp(lang.Alert.LessonRemindersFull, data.MaxLessonReminders))
+		}
+
+		// Schedule has changed since the page was sent
+		lesson, err := pages.GetLesson(utils.UpdateContext(ctx), groupId, date, number)
+		if err != nil {
+			return err
+		}
+		if lesson == nil {
+			return answerAlert(bot, ctx, lang.Alert.LessonNotFound)
+		}
+
+		reminder, err := pages.NewLessonReminder(groupId, date, lesson, offset)
+		if err != nil {
+			return err
+		}
+		if reminder.Time <= time.Now().Unix() {
+			return answerAlert(bot, ctx, format.Formatp(lang.Alert.LessonReminderTooLate, offset))
+		}
+
+		reminders = append(reminders, reminder)
+		middleware.SetToast(ctx, format.Formatp(lang.Alert.LessonReminderSet, offset))
+	}
+
+	chat.LessonReminders = reminders
+	if err := chatRepo.Update(chat); err != nil {
+		return err
+	}
+
+	page, err := pages.CreateLessonReminderPage(utils.UpdateContext(ctx), lang, chat, groupId, settings.GroupId, date, number, utils.ChatLocation(chat))
+	return openPage(bot, ctx, page, err)
+}
+
+// getLessonParams returns the viewed group, the date and the lesson number from the button data
+func getLessonParams(ctx *ext.Context, settings utils.Settings) (int, string, int, error) {
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return 0, "", 0, err
+	}
+
+	groupId, err := getViewedGroupId(button, settings)
+	if err != nil {
+		return 0, "", 0, err
+	}
+
+	date, err := button.Param("date")
+	if err != nil {
+		return 0, "", 0, err
+	}
+
+	lessonStr, err := button.Param("lesson")
+	if err != nil {
+		return 0, "", 0, err
+	}
+
+	lesson, err := strconv.Atoi(lessonStr)
+	if err != nil {
+		return 0, "", 0, fmt.Errorf("%w: invalid lesson %q", utils.ErrInvalidButtonData, lessonStr)
+	}
+
+	return groupId, date, lesson, nil
+}
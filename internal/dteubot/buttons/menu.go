@@ -30,7 +30,7 @@ import (
 )
 
 func HandleMenuButton(bot *gotgbot.Bot, ctx *ext.Context) error {
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
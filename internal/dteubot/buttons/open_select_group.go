@@ -30,7 +30,7 @@ import (
 )
 
 func HandleOpenSelectGroupButton(bot *gotgbot.Bot, ctx *ext.Context) error {
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
@@ -40,16 +40,23 @@ func HandleOpenSelectGroupButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
-	structures, err := api.GetStructures()
+	// Group is selected to save it, if opened from the saved groups
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+	_, save := button.Params["save"]
+
+	structures, err := api.WithContext(utils.UpdateContext(ctx)).GetStructures()
 	if err != nil {
 		return err
 	}
 
 	var page pages.Page
 	if len(structures) == 1 {
-		page, err = pages.CreateFacultiesListPage(lang, structures[0].Id)
+		page, err = pages.CreateFacultiesListPage(utils.UpdateContext(ctx), lang, structures[0].Id, chat.GroupId, save)
 	} else {
-		page, err = pages.CreateStructuresListPage(lang)
+		page, err = pages.CreateStructuresListPage(utils.UpdateContext(ctx), lang, chat.GroupId, save)
 	}
 
 	return openPage(bot, ctx, page, err)
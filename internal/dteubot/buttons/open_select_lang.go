@@ -30,7 +30,7 @@ import (
 )
 
 func HandleOpenSelectLanguageButton(bot *gotgbot.Bot, ctx *ext.Context) error {
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
@@ -40,6 +40,6 @@ func HandleOpenSelectLanguageButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
-	page, err := pages.CreateLanguageSelectionPage(lang, "open.settings#from=select_lang")
+	page, err := pages.CreateLanguageSelectionPage(lang, "settings")
 	return openPage(bot, ctx, page, err)
 }
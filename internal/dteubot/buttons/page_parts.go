@@ -0,0 +1,98 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"sync"
+	"time"
+)
+
+// PagePartsTTL is how long the parts of the split page are remembered.
+// Telegram doesn't let the bots delete the messages older than 48 hours.
+const PagePartsTTL = 48 * time.Hour
+
+// pagePartsKey is the message with the last part of the split page
+type pagePartsKey struct {
+	ChatId    int64
+	MessageId int64
+}
+
+type pageParts struct {
+	MessageIds []int64
+	Sent       time.Time
+}
+
+var (
+	// pagePartsMu guards splitPages
+	pagePartsMu sync.Mutex
+	// splitPages contains the messages with the first parts of the split pages
+	// by the message with the last part, the one with the buttons
+	splitPages = make(map[pagePartsKey]pageParts)
+)
+
+// rememberPageParts remembers the messages with the first parts of the page
+// split with pages.SplitLongPage, so they are deleted when another page
+// is opened from the last part, see deletePageParts.
+func rememberPageParts(chatId int64, lastId int64, partIds []int64) {
+	pagePartsMu.Lock()
+	defer pagePartsMu.Unlock()
+
+	now := time.Now()
+	for key, parts := range splitPages {
+		if now.Sub(parts.Sent) > PagePartsTTL {
+			delete(splitPages, key)
+		}
+	}
+
+	splitPages[pagePartsKey{chatId, lastId}] = pageParts{
+		MessageIds: partIds,
+		Sent:       now,
+	}
+}
+
+// deletePageParts deletes the first parts of the split page the button
+// is pressed on, because the message is about to show another page.
+// The parts are forgotten when the bot restarts, so they are left then.
+func deletePageParts(bot *gotgbot.Bot, ctx *ext.Context) {
+	if ctx.EffectiveMessage == nil {
+		return
+	}
+
+	key := pagePartsKey{ctx.EffectiveChat.Id, ctx.EffectiveMessage.MessageId}
+
+	pagePartsMu.Lock()
+	parts, ok := splitPages[key]
+	delete(splitPages, key)
+	pagePartsMu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	for _, messageId := range parts.MessageIds {
+		utils.RemovePage(bot, key.ChatId, messageId)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"time"
+)
+
+func HandleRefreshScheduleButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// Get date from button params
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	date, err := button.Param("date")
+	if err != nil {
+		return err
+	}
+
+	date2, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return err
+	}
+
+	groupId, err := getViewedGroupId(button, settings)
+	if err != nil {
+		return err
+	}
+
+	// Expire cached schedule of all the days used by the schedule page
+	if expirer, ok := api.(api2.ScheduleExpirer); ok {
+		dateStart, dateEnd := api2.GetDateRange(date2, pages.ScheduleDateRange)
+		err := expirer.ExpireGroupSchedule(groupId, dateStart.Format(time.DateOnly), dateEnd.Format(time.DateOnly))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update page
+	page, err := pages.CreateSchedulePage(utils.UpdateContext(ctx), lang, groupId, settings.ScheduleGroups(chat), date, utils.ChatLocation(chat), chat.Id, pages.ChatScheduleView(chat))
+	if err != nil {
+		return err
+	}
+
+	err = editOrSendPage(bot, ctx, page)
+	if err == nil {
+		return nil
+	}
+	if !utils.IsMessageNotModified(err) {
+		return err
+	}
+
+	// Schedule has not changed since the last update
+	_, err = bot.AnswerCallbackQuery(ctx.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+		Text: lang.Alert.ScheduleUpToDate,
+	})
+	return err
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"strconv"
+)
+
+func HandleGroupSwitchButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	page, err := pages.CreateSavedGroupsPage(lang, chat)
+	return openPage(bot, ctx, page, err)
+}
+
+func HandleSetActiveGroupButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	groupId, err := getGroupIdParam(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Update chat group id
+	chat.SelectSavedGroup(groupId)
+
+	err = chatRepo.Update(chat)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.UpdateUserGroup(ctx, userRepo, chat.GroupId); err != nil {
+		return err
+	}
+
+	// Update page
+	page, err := pages.CreateSavedGroupsPage(lang, chat)
+	return openPage(bot, ctx, page, err)
+}
+
+func HandleRemoveSavedGroupsButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	page, err := pages.CreateRemoveSavedGroupsPage(lang, chat, nil)
+	return openPage(bot, ctx, page, err)
+}
+
+func HandleRemoveSavedGroupButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	groupId, err := getGroupIdParam(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Remove group from the saved ones, another one is selected instead of the chat group
+	groupChanged := chat.RemoveSavedGroup(groupId)
+
+	err = chatRepo.Update(chat)
+	if err != nil {
+		return err
+	}
+
+	if groupChanged {
+		if err := utils.UpdateUserGroup(ctx, userRepo, chat.GroupId); err != nil {
+			return err
+		}
+	}
+
+	// Update page
+	page, err := pages.CreateRemoveSavedGroupsPage(lang, chat, nil)
+	return openPage(bot, ctx, page, err)
+}
+
+// getGroupIdParam returns the group id from the button params
+func getGroupIdParam(ctx *ext.Context) (int, error) {
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return 0, err
+	}
+
+	groupId, err := button.Param("groupId")
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(groupId)
+}
@@ -23,7 +23,6 @@
 package buttons
 
 import (
-	"errors"
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/cubicbyte/dteubot/internal/data"
@@ -35,26 +34,39 @@ import (
 
 func HandleScheduleDayButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 	// Get chat
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
 
-	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
 	if err != nil {
 		return err
 	}
 
 	// Get date from button params
-	button := utils.ParseButtonData(ctx.CallbackQuery.Data)
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
 
-	date, ok := button.Params["date"]
-	if !ok {
-		return errors.New("date param not found")
+	date, err := button.Param("date")
+	if err != nil {
+		return err
+	}
+
+	groupId, err := getViewedGroupId(button, settings)
+	if err != nil {
+		return err
 	}
 
 	// Open schedule page
-	page, err := pages.CreateSchedulePage(lang, chat.GroupId, date)
+	page, err := pages.CreateSchedulePage(utils.UpdateContext(ctx), lang, groupId, settings.ScheduleGroups(chat), date, utils.ChatLocation(chat), chat.Id, pages.ChatScheduleView(chat))
 	err = openPage(bot, ctx, page, err)
 	if err != nil {
 		return err
@@ -73,7 +85,6 @@ func SuggestNotifications(chat *data.Chat, lang i18n.Language, bot *gotgbot.Bot)
 		return nil
 	}
 
-	// Update chat
 	chat.SeenSettings = true
 
 	if err := chatRepo.Update(chat); err != nil {
@@ -23,7 +23,6 @@
 package buttons
 
 import (
-	"errors"
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
@@ -32,25 +31,38 @@ import (
 
 func HandleScheduleExtraButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 	// Get chat
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
 
-	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
 	if err != nil {
 		return err
 	}
 
 	// Get date from button
-	button := utils.ParseButtonData(ctx.CallbackQuery.Data)
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
 
-	date, ok := button.Params["date"]
-	if !ok {
-		return errors.New("date param not found")
+	date, err := button.Param("date")
+	if err != nil {
+		return err
+	}
+
+	groupId, err := getViewedGroupId(button, settings)
+	if err != nil {
+		return err
 	}
 
 	// Send page
-	page, err := pages.CreateScheduleExtraInfoPage(lang, chat.GroupId, date)
+	page, err := pages.CreateScheduleExtraInfoPage(utils.UpdateContext(ctx), lang, groupId, settings.GroupId, date, chat.HiddenLessons)
 	return openPage(bot, ctx, page, err)
 }
@@ -27,23 +27,37 @@ import (
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
 	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
-	"time"
 )
 
 func HandleScheduleTodayButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 	// Get chat
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
 
-	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	groupId, err := getViewedGroupId(button, settings)
 	if err != nil {
 		return err
 	}
 
 	// Open page
-	today := time.Now().Format("2006-01-02")
-	page, err := pages.CreateSchedulePage(lang, chat.GroupId, today)
+	today := utils.NowFor(chat).Format("2006-01-02")
+	page, err := pages.CreateSchedulePage(utils.UpdateContext(ctx), lang, groupId, settings.ScheduleGroups(chat), today, utils.ChatLocation(chat), chat.Id, pages.ChatScheduleView(chat))
 	return openPage(bot, ctx, page, err)
 }
@@ -23,7 +23,6 @@
 package buttons
 
 import (
-	"errors"
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
@@ -33,7 +32,7 @@ import (
 
 func HandleSelectCourseButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 	// Get chat
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
@@ -44,19 +43,22 @@ func HandleSelectCourseButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 	}
 
 	// Get course, faculty id and structure id from button params
-	button := utils.ParseButtonData(ctx.CallbackQuery.Data)
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
 
-	course, ok := button.Params["course"]
-	if !ok {
-		return errors.New("course param not found")
+	course, err := button.Param("course")
+	if err != nil {
+		return err
 	}
-	facultyId, ok := button.Params["facultyId"]
-	if !ok {
-		return errors.New("facultyId param not found")
+	facultyId, err := button.Param("facultyId")
+	if err != nil {
+		return err
 	}
-	structureId, ok := button.Params["structureId"]
-	if !ok {
-		return errors.New("structureId param not found")
+	structureId, err := button.Param("structureId")
+	if err != nil {
+		return err
 	}
 
 	course2, err := strconv.Atoi(course)
@@ -72,7 +74,14 @@ func HandleSelectCourseButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
+	_, save := button.Params["save"]
+
+	pageNum, err := getPageNum(button)
+	if err != nil {
+		return err
+	}
+
 	// Open groups list page
-	page, err := pages.CreateGroupsListPage(lang, facId, course2, structId)
+	page, err := pages.CreateGroupsListPage(utils.UpdateContext(ctx), lang, facId, course2, structId, save, pageNum)
 	return openPage(bot, ctx, page, err)
 }
@@ -23,7 +23,6 @@
 package buttons
 
 import (
-	"errors"
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
@@ -33,7 +32,7 @@ import (
 
 func HandleSelectFacultyButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 	// Get chat
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
@@ -44,15 +43,18 @@ func HandleSelectFacultyButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 	}
 
 	// Get faculty id and structure id from button params
-	button := utils.ParseButtonData(ctx.CallbackQuery.Data)
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
 
-	facultyId, ok := button.Params["facultyId"]
-	if !ok {
-		return errors.New("facultyId param not found")
+	facultyId, err := button.Param("facultyId")
+	if err != nil {
+		return err
 	}
-	structureId, ok := button.Params["structureId"]
-	if !ok {
-		return errors.New("structureId param not found")
+	structureId, err := button.Param("structureId")
+	if err != nil {
+		return err
 	}
 
 	facId, err := strconv.Atoi(facultyId)
@@ -64,7 +66,9 @@ func HandleSelectFacultyButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
+	_, save := button.Params["save"]
+
 	// Open courses list page
-	page, err := pages.CreateCoursesListPage(lang, facId, structId)
+	page, err := pages.CreateCoursesListPage(utils.UpdateContext(ctx), lang, facId, structId, save)
 	return openPage(bot, ctx, page, err)
 }
@@ -23,17 +23,18 @@
 package buttons
 
 import (
-	"errors"
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/data"
 	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
 	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
 	"strconv"
 )
 
 func HandleSelectGroupButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 	// Get chat and user
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
@@ -49,11 +50,14 @@ func HandleSelectGroupButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 	}
 
 	// Get group id from button params
-	button := utils.ParseButtonData(ctx.CallbackQuery.Data)
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
 
-	groupId, ok := button.Params["groupId"]
-	if !ok {
-		return errors.New("groupId param not found")
+	groupId, err := button.Param("groupId")
+	if err != nil {
+		return err
 	}
 
 	groupId2, err := strconv.Atoi(groupId)
@@ -61,6 +65,14 @@ func HandleSelectGroupButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
+	if err := pages.CacheGroup(groupId2); err != nil {
+		return err
+	}
+
+	if _, save := button.Params["save"]; save {
+		return saveGroup(bot, ctx, chat, lang, groupId2)
+	}
+
 	// Update chat group id
 	chat.GroupId = groupId2
 
@@ -69,7 +81,37 @@ func HandleSelectGroupButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
+	if err := utils.UpdateUserGroup(ctx, userRepo, groupId2); err != nil {
+		return err
+	}
+
 	// Open menu page
 	page, err := pages.CreateMenuPage(lang, user)
 	return openPage(bot, ctx, page, err)
 }
+
+// saveGroup adds the group selected from the saved groups page to the saved groups
+func saveGroup(bot *gotgbot.Bot, ctx *ext.Context, chat *data.Chat, lang i18n.Language, groupId int) error {
+	// Group is cached on selection
+	group := data.GroupRef{Id: groupId}
+	if cached, _ := groupsCache.GetGroupById(groupId); cached != nil {
+		group.Name = cached.Name
+	}
+
+	if chat.SavedGroups.Index(groupId) == -1 {
+		// Ask to remove one of the groups if there is no space left
+		if len(chat.SavedGroups) >= data.MaxSavedGroups {
+			page, err := pages.CreateRemoveSavedGroupsPage(lang, chat, &group)
+			return openPage(bot, ctx, page, err)
+		}
+
+		chat.SavedGroups = append(chat.SavedGroups, group)
+
+		if err := chatRepo.Update(chat); err != nil {
+			return err
+		}
+	}
+
+	page, err := pages.CreateSavedGroupsPage(lang, chat)
+	return openPage(bot, ctx, page, err)
+}
@@ -23,7 +23,6 @@
 package buttons
 
 import (
-	"errors"
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
@@ -32,26 +31,46 @@ import (
 
 func HandleSelectLanguageButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 	// Get chat
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
 
 	// Get language code from button params
-	button := utils.ParseButtonData(ctx.CallbackQuery.Data)
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	langCode, err := button.Param("lang")
+	if err != nil {
+		return err
+	}
 
-	langCode, ok := button.Params["lang"]
+	// Page to go back to. Buttons created before
+	// this param was introduced lead to settings
+	back, ok := button.Params["back"]
 	if !ok {
-		return errors.New("lang param not found")
+		back = "settings"
 	}
 
-	// Go back to settings if user selected the same language
+	// Go back if user selected the same language
 	if langCode == chat.LanguageCode {
 		lang, err := utils.GetLang(chat.LanguageCode, languages)
 		if err != nil {
 			return err
 		}
 
+		if back == "menu" {
+			user, err := userRepo.GetById(ctx.EffectiveUser.Id)
+			if err != nil {
+				return err
+			}
+
+			page, err := pages.CreateMenuPage(lang, user)
+			return openPage(bot, ctx, page, err)
+		}
+
 		page, err := pages.CreateSettingsPage(lang, chat)
 		return openPage(bot, ctx, page, err)
 	}
@@ -70,7 +89,7 @@ func HandleSelectLanguageButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
-	// Update page
-	page, err := pages.CreateLanguageSelectionPage(lang, "open.settings#from=select_lang")
+	// Re-render page in the selected language
+	page, err := pages.CreateLanguageSelectionPage(lang, back)
 	return openPage(bot, ctx, page, err)
 }
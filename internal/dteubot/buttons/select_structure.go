@@ -23,7 +23,6 @@
 package buttons
 
 import (
-	"errors"
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
@@ -33,7 +32,7 @@ import (
 
 func HandleSelectStructureButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 	// Get chat
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
@@ -45,11 +44,14 @@ func HandleSelectStructureButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 	}
 
 	// Get structure id from button params
-	button := utils.ParseButtonData(ctx.CallbackQuery.Data)
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
 
-	structureId, ok := button.Params["structureId"]
-	if !ok {
-		return errors.New("structureId param not found")
+	structureId, err := button.Param("structureId")
+	if err != nil {
+		return err
 	}
 
 	structId, err := strconv.Atoi(structureId)
@@ -57,7 +59,9 @@ func HandleSelectStructureButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
+	_, save := button.Params["save"]
+
 	// Open faculties list page
-	page, err := pages.CreateFacultiesListPage(lang, structId)
+	page, err := pages.CreateFacultiesListPage(utils.UpdateContext(ctx), lang, structId, chat.GroupId, save)
 	return openPage(bot, ctx, page, err)
 }
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"strconv"
+)
+
+func HandleSelectTeacherChairButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// Get chair id, faculty id and structure id from button params
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	chairId, err := button.Param("chairId")
+	if err != nil {
+		return err
+	}
+	facultyId, err := button.Param("facultyId")
+	if err != nil {
+		return err
+	}
+	structureId, err := button.Param("structureId")
+	if err != nil {
+		return err
+	}
+
+	chId, err := strconv.Atoi(chairId)
+	if err != nil {
+		return err
+	}
+	facId, err := strconv.Atoi(facultyId)
+	if err != nil {
+		return err
+	}
+	structId, err := strconv.Atoi(structureId)
+	if err != nil {
+		return err
+	}
+
+	pageNum, err := getPageNum(button)
+	if err != nil {
+		return err
+	}
+
+	// Open teachers list page
+	page, err := pages.CreateTeachersListPage(utils.UpdateContext(ctx), lang, chat, structId, facId, chId, pageNum)
+	return openPage(bot, ctx, page, err)
+}
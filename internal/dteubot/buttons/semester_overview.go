@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"bytes"
+	"context"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"time"
+)
+
+// SemesterOverviewWait is how long to wait for the semester overview
+// image before showing that it's being created
+const SemesterOverviewWait = 2 * time.Second
+
+// SemesterOverviewTimeout is the max time the semester overview image is created in
+const SemesterOverviewTimeout = 20 * time.Second
+
+// semesterOverview is the result of the semester overview image creation
+type semesterOverview struct {
+	image []byte
+	err   error
+}
+
+// HandleSemesterOverviewButton sends the semester overview image of the group.
+// If the schedule loads slowly, the message is changed to the loading page
+// until the image is sent, and then back to the "more" page.
+func HandleSemesterOverviewButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	if settings.GroupId == -1 {
+		page, err := pages.CreateInvalidGroupPage(lang)
+		return openPage(bot, ctx, page, err)
+	}
+
+	loc := utils.ChatLocation(chat)
+	overviewCtx, cancel := context.WithTimeout(utils.UpdateContext(ctx), SemesterOverviewTimeout)
+	defer cancel()
+
+	result := make(chan semesterOverview, 1)
+	go func() {
+		image, err := pages.CreateSemesterOverviewImage(overviewCtx, settings.GroupId, pages.ChatScheduleView(chat), loc)
+		result <- semesterOverview{image, err}
+	}()
+
+	var overview semesterOverview
+	loading := false
+	select {
+	case overview = <-result:
+	case <-time.After(SemesterOverviewWait):
+		loading = true
+		page, err := pages.CreateSemesterOverviewLoadingPage(lang)
+		if err := openPage(bot, ctx, page, err); err != nil {
+			return err
+		}
+		overview = <-result
+	}
+
+	if overview.err != nil {
+		return overview.err
+	}
+
+	// Send "sending photo" action
+	_, err = bot.SendChatAction(ctx.EffectiveChat.Id, "upload_photo", nil)
+	if err != nil {
+		return err
+	}
+
+	page, err := pages.CreateSemesterOverviewPage(lang, loc)
+	if err != nil {
+		return err
+	}
+
+	_, err = bot.SendPhoto(ctx.EffectiveChat.Id, gotgbot.NamedFile{
+		File:     bytes.NewReader(overview.image),
+		FileName: "semester.png",
+	}, &gotgbot.SendPhotoOpts{
+		Caption:   page.Text,
+		ParseMode: page.ParseMode,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !loading {
+		return nil
+	}
+
+	// Restore the page the button was pressed on
+	page, err = pages.CreateMorePage(lang)
+	return openPage(bot, ctx, page, err)
+}
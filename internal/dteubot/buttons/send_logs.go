@@ -25,6 +25,7 @@ package buttons
 import (
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
 	"github.com/cubicbyte/dteubot/internal/logging"
 )
 
@@ -35,7 +36,7 @@ func HandleSendLogsButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
-	if !user.IsAdmin {
+	if !utils.IsBotAdmin(user) {
 		return nil
 	}
 
@@ -33,7 +33,7 @@ import (
 
 func HandleSetClassesNotificationsButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 	// Get chat and user
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
@@ -49,15 +49,18 @@ func HandleSetClassesNotificationsButton(bot *gotgbot.Bot, ctx *ext.Context) err
 	}
 
 	// Get button data
-	button := utils.ParseButtonData(ctx.CallbackQuery.Data)
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
 
-	state, ok := button.Params["state"]
-	if !ok {
-		return errors.New("notif param not found")
+	state, err := button.Param("state")
+	if err != nil {
+		return err
 	}
-	time2, ok := button.Params["time"]
-	if !ok {
-		return errors.New("time param not found")
+	time2, err := button.Param("time")
+	if err != nil {
+		return err
 	}
 	_, isSuggestion := button.Params["suggestion"]
 
@@ -100,7 +103,7 @@ func HandleSetClassesNotificationsButton(bot *gotgbot.Bot, ctx *ext.Context) err
 
 func HandleSetClassesNotificationsNextPartButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 	// Get chat
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
@@ -111,11 +114,14 @@ func HandleSetClassesNotificationsNextPartButton(bot *gotgbot.Bot, ctx *ext.Cont
 	}
 
 	// Get state from button data
-	button := utils.ParseButtonData(ctx.CallbackQuery.Data)
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
 
-	state, ok := button.Params["state"]
-	if !ok {
-		return errors.New("state param not found")
+	state, err := button.Param("state")
+	if err != nil {
+		return err
 	}
 
 	// Update chat classes notifications settings
@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"errors"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"time"
+)
+
+func HandleDailyScheduleButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	page, err := pages.CreateDailyScheduleSettingsPage(lang, chat)
+	return openPage(bot, ctx, page, err)
+}
+
+func HandleSetDailyScheduleButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// Get kind and state from button data
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	kind, err := button.Param("kind")
+	if err != nil {
+		return err
+	}
+	state, err := button.Param("state")
+	if err != nil {
+		return err
+	}
+
+	// Update chat daily schedule settings
+	switch kind {
+	case "morning":
+		chat.MorningSchedule = state == "1"
+	case "evening":
+		chat.EveningSchedule = state == "1"
+	default:
+		return errors.New("invalid daily schedule kind: " + kind)
+	}
+
+	err = chatRepo.Update(chat)
+	if err != nil {
+		return err
+	}
+
+	// Update page
+	page, err := pages.CreateDailyScheduleSettingsPage(lang, chat)
+	return openPage(bot, ctx, page, err)
+}
+
+func HandleSetDailyScheduleTimeButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// Get kind and time from button data
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	kind, err := button.Param("kind")
+	if err != nil {
+		return err
+	}
+	time2, err := button.Param("time")
+	if err != nil {
+		return err
+	}
+
+	if _, err := time.Parse("15:04", time2); err != nil {
+		return err
+	}
+
+	// Update chat daily schedule time
+	switch kind {
+	case "morning":
+		chat.MorningScheduleTime = time2
+	case "evening":
+		chat.EveningScheduleTime = time2
+	default:
+		return errors.New("invalid daily schedule kind: " + kind)
+	}
+
+	err = chatRepo.Update(chat)
+	if err != nil {
+		return err
+	}
+
+	// Update page
+	page, err := pages.CreateDailyScheduleSettingsPage(lang, chat)
+	return openPage(bot, ctx, page, err)
+}
+
+func HandleSetDailyScheduleEmptyButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// Get state from button data
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	state, err := button.Param("state")
+	if err != nil {
+		return err
+	}
+
+	// Update chat daily schedule settings
+	chat.DailyScheduleEmpty = state == "1"
+
+	err = chatRepo.Update(chat)
+	if err != nil {
+		return err
+	}
+
+	// Update page
+	page, err := pages.CreateDailyScheduleSettingsPage(lang, chat)
+	return openPage(bot, ctx, page, err)
+}
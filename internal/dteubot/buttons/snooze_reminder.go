@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"context"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"github.com/sirkon/go-format/v2"
+	"strconv"
+	"time"
+)
+
+// HandleSnoozeReminderButton sends the class reminder again in pages.ReminderSnoozeTime
+// minutes. The repeated reminder is the one-shot reminder of the lesson.
+func HandleSnoozeReminderButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := getChat(ctx)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// Get lesson from button params
+	button, err := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+	if err != nil {
+		return err
+	}
+
+	date, err := button.Param("date")
+	if err != nil {
+		return err
+	}
+
+	lesson, err := getSnoozedLesson(utils.UpdateContext(ctx), button, chat.GroupId, date)
+	if err != nil {
+		return err
+	}
+	if lesson == nil {
+		return answerAlert(bot, ctx, lang.Alert.LessonNotFound)
+	}
+
+	start, err := pages.GetLessonStart(date, lesson)
+	if err != nil {
+		return err
+	}
+
+	offset := int(time.Until(start).Minutes()) - pages.ReminderSnoozeTime
+	if offset <= 0 {
+		return answerAlert(bot, ctx, format.Formatp(lang.Alert.LessonReminderTooLate, pages.ReminderSnoozeTime))
+	}
+
+	reminders := chat.LessonReminders.Remove(chat.GroupId, date, lesson.Number)
+	if len(reminders) >= data.MaxLessonReminders {
+		return answerAlert(bot, ctx, format.Formatp(lang.Alert.LessonRemindersFull, data.MaxLessonReminders))
+	}
+
+	reminder, err := pages.NewLessonReminder(chat.GroupId, date, lesson, offset)
+	if err != nil {
+		return err
+	}
+
+	chat.LessonReminders = append(reminders, reminder)
+	if err := chatRepo.Update(chat); err != nil {
+		return err
+	}
+
+	_, err = bot.AnswerCallbackQuery(ctx.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+		Text: format.Formatp(lang.Alert.ReminderSnoozed, pages.ReminderSnoozeTime),
+	})
+	return err
+}
+
+// getSnoozedLesson returns the lesson of the snooze button, or nil if it's not on the schedule anymore.
+//
+// The buttons sent before the lesson param was added have
+// the class param instead, the TimeTablePeriod.R1 field.
+func getSnoozedLesson(ctx context.Context, button *utils.ButtonData, groupId int, date string) (*api2.TimeTableLesson, error) {
+	if number, ok := button.Params["lesson"]; ok {
+		number2, err := strconv.Atoi(number)
+		if err != nil {
+			return nil, err
+		}
+		return pages.GetLesson(ctx, groupId, date, number2)
+	}
+
+	class, err := button.Param("class")
+	if err != nil {
+		return nil, err
+	}
+	classCode, err := strconv.Atoi(class)
+	if err != nil {
+		return nil, err
+	}
+
+	day, err := api.WithContext(ctx).GetGroupScheduleDay(groupId, date)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lesson := range day.Lessons {
+		for _, period := range lesson.Periods {
+			if period.R1 == classCode {
+				return &lesson, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package buttons
+
+import (
+	"bytes"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"time"
+)
+
+// HandleStatsCsvButton sends the daily bot usage stats to the bot admins as a CSV file
+func HandleStatsCsvButton(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Check if user is admin
+	user, err := userRepo.GetById(ctx.EffectiveUser.Id)
+	if err != nil {
+		return err
+	}
+
+	if !utils.IsBotAdmin(user) {
+		return nil
+	}
+
+	// Send "sending document" action
+	_, err = bot.SendChatAction(ctx.EffectiveChat.Id, "upload_document", nil)
+	if err != nil {
+		return err
+	}
+
+	file, err := pages.CreateUsageStatsCSV(usageStats)
+	if err != nil {
+		return err
+	}
+
+	_, err = bot.SendDocument(ctx.EffectiveChat.Id, gotgbot.NamedFile{
+		File:     bytes.NewReader(file),
+		FileName: "dteubot-stats-" + time.Now().Format(time.DateOnly) + ".csv",
+	}, nil)
+	return err
+}
@@ -31,16 +31,21 @@ import (
 
 func HandleStudentsListButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 	// Get chat
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
 
-	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
 	if err != nil {
 		return err
 	}
 
-	page, err := pages.CreateStudentsListPage(lang, chat.GroupId)
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	page, err := pages.CreateStudentsListPage(utils.UpdateContext(ctx), lang, settings.GroupId)
 	return openPage(bot, ctx, page, err)
 }
@@ -31,7 +31,7 @@ import (
 
 func HandleUnsupportedButton(bot *gotgbot.Bot, ctx *ext.Context) error {
 	// Get chat and user
-	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	chat, err := getChat(ctx)
 	if err != nil {
 		return err
 	}
@@ -23,22 +23,213 @@
 package buttons
 
 import (
+	"fmt"
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"strconv"
 )
 
+// MaxAnswerLength is the Telegram callback query answer text length limit
+const MaxAnswerLength = 200
+
 // openPage edits the message with the given page.
+//
+// If the page is the same as the message, the callback query is
+// answered to stop the loading animation. If the message is deleted,
+// the page is sent as a new message.
 func openPage(bot *gotgbot.Bot, ctx *ext.Context, page pages.Page, err error) error {
 	if err != nil {
 		return err
 	}
 
+	deletePageParts(bot, ctx)
+
+	err = editOrSendPage(bot, ctx, page)
+	switch {
+	case err == nil:
+		return rememberPage(ctx)
+	case utils.IsMessageNotModified(err):
+		// Button is pressed twice, or the content has not changed
+		_, err = bot.AnswerCallbackQuery(ctx.CallbackQuery.Id, nil)
+		return err
+	case utils.IsMessageCantBeEdited(err):
+		// Message is too old, tell why the page is sent again
+		if err := sendPage(bot, ctx, page); err != nil {
+			return err
+		}
+
+		chat, err := getChat(ctx)
+		if err != nil {
+			return err
+		}
+
+		lang, err := utils.GetLang(chat.LanguageCode, languages)
+		if err != nil {
+			return err
+		}
+
+		return answerToast(bot, ctx, lang.Alert.MessageCantBeEdited)
+	default:
+		return err
+	}
+}
+
+// openPages is like openPage, but for the page split with pages.SplitLongPage.
+// The message is edited with the first page, the rest are sent as new messages.
+//
+// Only the last message has the buttons, so the first ones are
+// deleted when another page is opened from it.
+func openPages(bot *gotgbot.Bot, ctx *ext.Context, parts []pages.Page, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if err := openPage(bot, ctx, parts[0], nil); err != nil {
+		return err
+	}
+	if len(parts) == 1 {
+		return nil
+	}
+
+	partIds := []int64{ctx.EffectiveMessage.MessageId}
+	for i, part := range parts[1:] {
+		msg, err := sendPageMessage(bot, ctx, part)
+		if err != nil {
+			// The page is incomplete and has no buttons, don't leave its parts
+			for _, messageId := range partIds[1:] {
+				utils.RemovePage(bot, ctx.EffectiveChat.Id, messageId)
+			}
+			return err
+		}
+
+		if i != len(parts)-2 {
+			partIds = append(partIds, msg.MessageId)
+			continue
+		}
+		rememberPageParts(ctx.EffectiveChat.Id, msg.MessageId, partIds)
+	}
+
+	return nil
+}
+
+// editPage edits the message with the given page and returns the Telegram error as is
+func editPage(bot *gotgbot.Bot, ctx *ext.Context, page pages.Page) error {
 	opts := page.CreateEditMessageOpts(ctx.EffectiveChat.Id, ctx.EffectiveMessage.MessageId)
-	_, _, err = bot.EditMessageText(page.Text, &opts)
+	_, _, err := bot.EditMessageText(page.Text, &opts)
 	if err != nil {
 		return err
 	}
 
+	utils.TrackPage(bot, chatRepo, ctx.EffectiveChat.Id, ctx.EffectiveMessage.MessageId, page.HasButtons())
 	return nil
 }
+
+// editOrSendPage edits the message with the given page, or sends
+// the page as a new message if the user deleted the message
+func editOrSendPage(bot *gotgbot.Bot, ctx *ext.Context, page pages.Page) error {
+	err := editPage(bot, ctx, page)
+	if utils.IsMessageToEditNotFound(err) {
+		return sendPage(bot, ctx, page)
+	}
+	return err
+}
+
+// sendPage sends the given page as a new message
+func sendPage(bot *gotgbot.Bot, ctx *ext.Context, page pages.Page) error {
+	_, err := sendPageMessage(bot, ctx, page)
+	return err
+}
+
+// sendPageMessage is like sendPage, but returns the sent message
+func sendPageMessage(bot *gotgbot.Bot, ctx *ext.Context, page pages.Page) (*gotgbot.Message, error) {
+	opts := page.CreateSendMessageOpts()
+	msg, err := bot.SendMessage(ctx.EffectiveChat.Id, page.Text, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	utils.TrackPage(bot, chatRepo, ctx.EffectiveChat.Id, msg.MessageId, page.HasButtons())
+	return msg, nil
+}
+
+// answerToast answers the callback query with a short
+// notification shown on top of the chat, instead of editing the message
+func answerToast(bot *gotgbot.Bot, ctx *ext.Context, text string) error {
+	_, err := bot.AnswerCallbackQuery(ctx.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+		Text: truncateAnswer(text),
+	})
+	return err
+}
+
+// answerAlert answers the callback query with a popup
+// the user has to close, instead of editing the message
+func answerAlert(bot *gotgbot.Bot, ctx *ext.Context, text string) error {
+	_, err := bot.AnswerCallbackQuery(ctx.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+		Text:      truncateAnswer(text),
+		ShowAlert: true,
+	})
+	return err
+}
+
+// truncateAnswer cuts the text to the callback query answer length limit
+func truncateAnswer(text string) string {
+	runes := []rune(text)
+	if len(runes) <= MaxAnswerLength {
+		return text
+	}
+	return string(runes[:MaxAnswerLength-1]) + "…"
+}
+
+// getViewedGroupId returns the group of the schedule page the button is pressed on:
+// the groupId button param, if the page shows another of the chat groups,
+// or the settings group
+func getViewedGroupId(button *utils.ButtonData, settings utils.Settings) (int, error) {
+	groupId, ok := button.Params["groupId"]
+	if !ok {
+		return settings.GroupId, nil
+	}
+
+	groupId2, err := strconv.Atoi(groupId)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid groupId %q", utils.ErrInvalidButtonData, groupId)
+	}
+
+	return groupId2, nil
+}
+
+// isSearchOutdated returns true if the search results button was created
+// for a different query than the current chat query. The buttons sent
+// before the query hash was added are not checked.
+func isSearchOutdated(button *utils.ButtonData, query string) bool {
+	hash, ok := button.Params["queryHash"]
+	return ok && hash != strconv.Itoa(utils.QueryHash(query))
+}
+
+// answerSearchOutdated tells the user the search results are outdated, so the
+// old results message doesn't page through the newer search
+func answerSearchOutdated(bot *gotgbot.Bot, ctx *ext.Context, lang i18n.Language) error {
+	_, err := bot.AnswerCallbackQuery(ctx.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+		Text:      lang.Alert.SearchOutdated,
+		ShowAlert: true,
+	})
+	return err
+}
+
+// getPageNum returns the list page number from the page button param,
+// or 0 if the first page is opened
+func getPageNum(button *utils.ButtonData) (int, error) {
+	pageNum, ok := button.Params["page"]
+	if !ok {
+		return 0, nil
+	}
+
+	pageNum2, err := strconv.Atoi(pageNum)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid page %q", utils.ErrInvalidButtonData, pageNum)
+	}
+
+	return pageNum2, nil
+}
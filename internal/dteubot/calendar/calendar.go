@@ -0,0 +1,170 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package calendar
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultHolidays is the list of the Ukrainian public holidays
+//
+//go:embed holidays.json
+var defaultHolidays []byte
+
+// Holiday is a day with no classes
+type Holiday struct {
+	// Date is either "2006-01-02" for a single day,
+	// or "01-02" for the day repeated every year
+	Date string `json:"date"`
+	// Name is the holiday name shown if there is no name in the chat language
+	Name string `json:"name"`
+	// Names are the holiday names by language code
+	Names map[string]string `json:"names,omitempty"`
+}
+
+// LocalizedName returns the holiday name in the language
+func (h Holiday) LocalizedName(langCode string) string {
+	if name, ok := h.Names[langCode]; ok {
+		return name
+	}
+	return h.Name
+}
+
+// HolidayCalendar is a list of the days with no classes.
+//
+// The holidays are loaded from File, or from the embedded list of the
+// Ukrainian public holidays if File is not set. The holidays added by
+// the administrators are kept in AddedFile.
+type HolidayCalendar struct {
+	File      string
+	AddedFile string
+	mu        sync.RWMutex
+	holidays  map[string]Holiday
+	added     []Holiday
+}
+
+// Load loads the holidays from the files
+func (c *HolidayCalendar) Load() error {
+	list := defaultHolidays
+	if c.File != "" {
+		var err error
+		list, err = os.ReadFile(c.File)
+		if err != nil {
+			return err
+		}
+	}
+
+	var holidays []Holiday
+	if err := json.Unmarshal(list, &holidays); err != nil {
+		return fmt.Errorf("error parsing holidays list: %w", err)
+	}
+
+	var added []Holiday
+	if c.AddedFile != "" {
+		addedList, err := os.ReadFile(c.AddedFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err == nil {
+			if err := json.Unmarshal(addedList, &added); err != nil {
+				return fmt.Errorf("error parsing added holidays: %w", err)
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.holidays = make(map[string]Holiday, len(holidays)+len(added))
+	for _, holiday := range append(holidays, added...) {
+		if !IsValidDate(holiday.Date) {
+			return fmt.Errorf("invalid holiday date: %q", holiday.Date)
+		}
+		c.holidays[holiday.Date] = holiday
+	}
+	c.added = added
+
+	return nil
+}
+
+// IsHoliday returns the holiday on the date, like "2024-08-24"
+func (c *HolidayCalendar) IsHoliday(date string) (Holiday, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// Single days go first, they can override the yearly ones
+	if holiday, ok := c.holidays[date]; ok {
+		return holiday, true
+	}
+	if len(date) == len(time.DateOnly) {
+		if holiday, ok := c.holidays[date[5:]]; ok {
+			return holiday, true
+		}
+	}
+
+	return Holiday{}, false
+}
+
+// Add adds the holiday and saves it to AddedFile
+func (c *HolidayCalendar) Add(holiday Holiday) error {
+	if !IsValidDate(holiday.Date) {
+		return fmt.Errorf("invalid holiday date: %q", holiday.Date)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	added := append(c.added, holiday)
+	if c.AddedFile != "" {
+		data, err := json.MarshalIndent(added, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(c.AddedFile, data, 0644); err != nil {
+			return err
+		}
+	}
+
+	c.added = added
+	if c.holidays == nil {
+		c.holidays = make(map[string]Holiday)
+	}
+	c.holidays[holiday.Date] = holiday
+
+	return nil
+}
+
+// IsValidDate checks if the date is "2006-01-02" or "01-02"
+func IsValidDate(date string) bool {
+	if _, err := time.Parse(time.DateOnly, date); err == nil {
+		return true
+	}
+	_, err := time.Parse("01-02", date)
+	return err == nil
+}
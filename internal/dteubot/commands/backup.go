@@ -0,0 +1,302 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxBackupSize is the maximum size of the settings backup file in bytes
+const MaxBackupSize = 16 * 1024
+
+// BackupFileName is the name of the settings backup file
+const BackupFileName = "dteubot-backup.json"
+
+// errInvalidBackup is returned when the backup file is not valid
+var errInvalidBackup = errors.New("invalid backup")
+
+func HandleBackupCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	backup, err := json.MarshalIndent(chat, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	page, err := pages.CreateBackupPage(lang)
+	if err != nil {
+		return err
+	}
+
+	_, err = bot.SendDocument(ctx.EffectiveChat.Id, gotgbot.NamedFile{
+		File:     bytes.NewReader(backup),
+		FileName: BackupFileName,
+	}, &gotgbot.SendDocumentOpts{
+		ReplyToMessageId:         replyToMessageId(ctx),
+		AllowSendingWithoutReply: true,
+		Caption:                  page.Text,
+		ParseMode:                page.ParseMode,
+	})
+	return err
+}
+
+// HandleRestoreCommand restores the chat settings from the backup file,
+// created by HandleBackupCommand. The file must be attached to
+// the command message or to the message the command replies to.
+func HandleRestoreCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	document := ctx.EffectiveMessage.Document
+	if document == nil && ctx.EffectiveMessage.ReplyToMessage != nil {
+		document = ctx.EffectiveMessage.ReplyToMessage.Document
+	}
+	if document == nil {
+		page, err := pages.CreateRestoreUsagePage(lang)
+		return sendPage(bot, ctx, page, err)
+	}
+
+	if document.FileSize > MaxBackupSize {
+		page, err := pages.CreateRestoreInvalidPage(lang)
+		return sendPage(bot, ctx, page, err)
+	}
+
+	backup, err := downloadFile(bot, document.FileId)
+	if err != nil {
+		return err
+	}
+
+	restored, err := parseChatBackup(backup)
+	if errors.Is(err, errInvalidBackup) {
+		page, err := pages.CreateRestoreInvalidPage(lang)
+		return sendPage(bot, ctx, page, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Check if the group still exists
+	if restored.GroupId != -1 {
+		exists, err := groupExists(utils.UpdateContext(ctx), chat, restored.GroupId)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			page, err := pages.CreateRestoreInvalidGroupPage(lang)
+			return sendPage(bot, ctx, page, err)
+		}
+	}
+
+	restoreChatSettings(chat, restored)
+	if err := chatRepo.Update(chat); err != nil {
+		return err
+	}
+
+	if err := utils.UpdateUserGroup(ctx, userRepo, chat.GroupId); err != nil {
+		return err
+	}
+
+	// Settings language could be changed
+	lang, err = utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	page, err := pages.CreateRestoreDonePage(lang)
+	return sendPage(bot, ctx, page, err)
+}
+
+// downloadFile downloads the file sent to the bot
+func downloadFile(bot *gotgbot.Bot, fileId string) ([]byte, error) {
+	file, err := bot.GetFile(fileId, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+
+	res, err := client.Get(file.URL(bot, nil))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error downloading file: status code %d", res.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, MaxBackupSize+1))
+}
+
+// parseChatBackup parses and validates the chat settings backup.
+// Returns errInvalidBackup if the backup is not valid.
+func parseChatBackup(backup []byte) (*data.Chat, error) {
+	if len(backup) > MaxBackupSize {
+		return nil, fmt.Errorf("%w: file is too large", errInvalidBackup)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(backup))
+	decoder.DisallowUnknownFields()
+
+	var chat data.Chat
+	if err := decoder.Decode(&chat); err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidBackup, err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("%w: unexpected data after the settings", errInvalidBackup)
+	}
+
+	if chat.GroupId != -1 && (chat.GroupId <= 0 || chat.GroupId > math.MaxInt32) {
+		return nil, fmt.Errorf("%w: invalid group id %d", errInvalidBackup, chat.GroupId)
+	}
+	if len(chat.SavedGroups) > data.MaxSavedGroups {
+		return nil, fmt.Errorf("%w: too many saved groups", errInvalidBackup)
+	}
+	for _, group := range chat.SavedGroups {
+		if group.Id <= 0 || group.Id > math.MaxInt32 {
+			return nil, fmt.Errorf("%w: invalid saved group id %d", errInvalidBackup, group.Id)
+		}
+	}
+	if len(chat.HiddenLessons) > data.MaxHiddenLessons {
+		return nil, fmt.Errorf("%w: too many hidden lessons", errInvalidBackup)
+	}
+	if _, ok := languages[chat.LanguageCode]; !ok && chat.LanguageCode != "" {
+		return nil, fmt.Errorf("%w: unknown language %q", errInvalidBackup, chat.LanguageCode)
+	}
+	if chat.Timezone != "" {
+		if _, err := time.LoadLocation(chat.Timezone); err != nil {
+			return nil, fmt.Errorf("%w: unknown timezone %q", errInvalidBackup, chat.Timezone)
+		}
+	}
+	// Chats saved before these settings were added have zero values,
+	// that mean the defaults everywhere else
+	if chat.ReminderOffset == 0 {
+		chat.ReminderOffset = data.DefaultReminderOffset
+	}
+	if chat.MorningScheduleTime == "" {
+		chat.MorningScheduleTime = data.DefaultMorningScheduleTime
+	}
+	if chat.EveningScheduleTime == "" {
+		chat.EveningScheduleTime = data.DefaultEveningScheduleTime
+	}
+
+	if !slices.Contains(pages.ReminderOffsets, chat.ReminderOffset) {
+		return nil, fmt.Errorf("%w: invalid reminder offset %d", errInvalidBackup, chat.ReminderOffset)
+	}
+	if !slices.Contains(pages.MorningScheduleTimes, chat.MorningScheduleTime) {
+		return nil, fmt.Errorf("%w: invalid morning schedule time %q", errInvalidBackup, chat.MorningScheduleTime)
+	}
+	if !slices.Contains(pages.EveningScheduleTimes, chat.EveningScheduleTime) {
+		return nil, fmt.Errorf("%w: invalid evening schedule time %q", errInvalidBackup, chat.EveningScheduleTime)
+	}
+	if utf8.RuneCountInString(chat.TeacherSearchQuery) > MaxTeacherQueryLength {
+		return nil, fmt.Errorf("%w: teacher search query is too long", errInvalidBackup)
+	}
+	if utf8.RuneCountInString(chat.GroupSearchQuery) > MaxGroupQueryLength {
+		return nil, fmt.Errorf("%w: group search query is too long", errInvalidBackup)
+	}
+
+	return &chat, nil
+}
+
+// groupExists checks if the group exists using the API,
+// requesting its schedule for today of the chat
+func groupExists(ctx context.Context, chat *data.Chat, groupId int) (bool, error) {
+	today := utils.NowFor(chat).Format(time.DateOnly)
+
+	_, err := api.WithContext(ctx).GetGroupSchedule(groupId, today, today)
+	if err == nil {
+		return true, nil
+	}
+
+	var httpApiError *api2.HTTPApiError
+	if errors.As(err, &httpApiError) && httpApiError.Code == http.StatusUnprocessableEntity {
+		return false, nil
+	}
+
+	return false, err
+}
+
+// restoreChatSettings copies the settings from the backup to the chat.
+//
+// Chat id, creation time and the notifications state,
+// like sent daily schedules, are not restored.
+func restoreChatSettings(chat *data.Chat, backup *data.Chat) {
+	chat.GroupId = backup.GroupId
+	chat.SavedGroups = backup.SavedGroups
+	chat.LanguageCode = backup.LanguageCode
+	chat.Timezone = backup.Timezone
+	chat.ClassesNotification15m = backup.ClassesNotification15m
+	chat.ClassesNotification1m = backup.ClassesNotification1m
+	chat.ClassesNotificationNextPart = backup.ClassesNotificationNextPart
+	chat.ClassesReminder = backup.ClassesReminder
+	chat.ReminderOffset = backup.ReminderOffset
+	chat.MorningSchedule = backup.MorningSchedule
+	chat.MorningScheduleTime = backup.MorningScheduleTime
+	chat.EveningSchedule = backup.EveningSchedule
+	chat.EveningScheduleTime = backup.EveningScheduleTime
+	chat.DailyScheduleEmpty = backup.DailyScheduleEmpty
+	chat.TeacherSearchQuery = backup.TeacherSearchQuery
+	chat.GroupSearchQuery = backup.GroupSearchQuery
+	chat.NotifyChanges = backup.NotifyChanges
+	chat.HideEmptyLessons = backup.HideEmptyLessons
+	chat.HiddenLessons = backup.HiddenLessons
+	chat.StrikeHiddenLessons = backup.StrikeHiddenLessons
+	chat.CleanupPages = backup.CleanupPages
+	chat.SettingsLocked = backup.SettingsLocked
+}
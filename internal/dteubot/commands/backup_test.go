@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package commands
+
+import (
+	"errors"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestParseChatBackup(t *testing.T) {
+	tests := []struct {
+		name    string
+		backup  string
+		wantErr bool
+	}{
+		{"valid", `{"groupId":1234,"reminderOffset":10,"morningScheduleTime":"06:30","eveningScheduleTime":"22:00"}`, false},
+		{"no group", `{"groupId":-1}`, false},
+		// Chats saved before the settings were added
+		{"legacy zero values", `{"groupId":1234,"reminderOffset":0,"morningScheduleTime":"","eveningScheduleTime":""}`, false},
+		{"not json", `groupId=1234`, true},
+		{"unknown key", `{"groupId":1234,"isAdmin":true}`, true},
+		{"trailing data", `{"groupId":1234}{"groupId":5678}`, true},
+		{"zero group id", `{"groupId":0}`, true},
+		{"negative group id", `{"groupId":-2}`, true},
+		{"group id out of range", `{"groupId":` + strconv.Itoa(math.MaxInt32+1) + `}`, true},
+		{"saved group id out of range", `{"groupId":1234,"savedGroups":[{"id":` + strconv.Itoa(math.MaxInt32+1) + `}]}`, true},
+		{"invalid reminder offset", `{"groupId":1234,"reminderOffset":7}`, true},
+		{"invalid morning schedule time", `{"groupId":1234,"morningScheduleTime":"03:00"}`, true},
+		{"unknown timezone", `{"groupId":1234,"timezone":"Mars/Olympus"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseChatBackup([]byte(tt.backup))
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidBackup) {
+					t.Errorf("got %v, want errInvalidBackup", err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseChatBackupDefaults(t *testing.T) {
+	chat, err := parseChatBackup([]byte(`{"groupId":1234}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chat.ReminderOffset != data.DefaultReminderOffset {
+		t.Errorf("ReminderOffset = %d, want %d", chat.ReminderOffset, data.DefaultReminderOffset)
+	}
+	if chat.MorningScheduleTime != data.DefaultMorningScheduleTime {
+		t.Errorf("MorningScheduleTime = %q, want %q", chat.MorningScheduleTime, data.DefaultMorningScheduleTime)
+	}
+	if chat.EveningScheduleTime != data.DefaultEveningScheduleTime {
+		t.Errorf("EveningScheduleTime = %q, want %q", chat.EveningScheduleTime, data.DefaultEveningScheduleTime)
+	}
+}
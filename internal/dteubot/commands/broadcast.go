@@ -0,0 +1,190 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package commands
+
+import (
+	"errors"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/broadcast"
+	"github.com/cubicbyte/dteubot/internal/dteubot/errorhandler"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// HandleBroadcastCommand sends the command text or the message the command replies to
+// to all accessible chats. Available only for the bot administrators.
+//
+// "/broadcast --group <groupId> ..." sends the message only to the chats with the group selected.
+// "/broadcast cancel" stops the running broadcast.
+func HandleBroadcastCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Check if user is admin
+	user, err := userRepo.GetById(ctx.EffectiveUser.Id)
+	if err != nil {
+		return err
+	}
+
+	if !utils.IsBotAdmin(user) {
+		return nil
+	}
+
+	// Get chat
+	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	args, ok := parseBroadcastArgs(ctx.EffectiveMessage.Text)
+
+	// Cancel the running broadcast
+	if ok && args.groupId == 0 && args.text == "cancel" {
+		if !broadcast.Cancel() {
+			page, err := pages.CreateBroadcastNotRunningPage(lang)
+			return sendPage(bot, ctx, page, err)
+		}
+
+		page, err := pages.CreateBroadcastCancellingPage(lang)
+		return sendPage(bot, ctx, page, err)
+	}
+
+	message := ctx.EffectiveMessage.ReplyToMessage
+	if !ok || (args.text == "" && message == nil) {
+		page, err := pages.CreateBroadcastUsagePage(lang)
+		return sendPage(bot, ctx, page, err)
+	}
+
+	chats, err := chatRepo.GetAccessibleChats()
+	if err != nil {
+		return err
+	}
+
+	if args.groupId != 0 {
+		chats = broadcast.FilterByGroup(chats, args.groupId)
+	}
+
+	var job *broadcast.Job
+	if args.text != "" {
+		job = broadcast.NewTextJob(chats, args.text, chat.Id)
+	} else {
+		job = broadcast.NewJob(chats, message.Chat.Id, message.MessageId, chat.Id)
+	}
+
+	err = broadcast.Start(bot, chatRepo, job, sendBroadcastReport(bot))
+	if errors.Is(err, broadcast.ErrAlreadyRunning) {
+		page, err := pages.CreateBroadcastRunningPage(lang)
+		return sendPage(bot, ctx, page, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	page, err := pages.CreateBroadcastStartedPage(lang, len(chats))
+	return sendPage(bot, ctx, page, err)
+}
+
+// broadcastArgs are the arguments of the /broadcast command
+type broadcastArgs struct {
+	// groupId is the group whose chats receive the message, 0 for all chats
+	groupId int
+	text    string
+}
+
+// parseBroadcastArgs parses the "/broadcast [--group <groupId>] [text]" command.
+// Returns false if the group id is missing or invalid.
+func parseBroadcastArgs(command string) (broadcastArgs, bool) {
+	var args broadcastArgs
+
+	i := strings.IndexFunc(command, unicode.IsSpace)
+	if i == -1 {
+		return args, true
+	}
+	rest := strings.TrimSpace(command[i:])
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || fields[0] != "--group" {
+		args.text = rest
+		return args, true
+	}
+	if len(fields) < 2 {
+		return args, false
+	}
+
+	groupId, err := strconv.Atoi(fields[1])
+	if err != nil || groupId <= 0 {
+		return args, false
+	}
+	args.groupId = groupId
+
+	// Keep the text formatting, such as the line breaks
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, fields[0]))
+	args.text = strings.TrimSpace(strings.TrimPrefix(rest, fields[1]))
+
+	return args, true
+}
+
+// ResumeBroadcast continues the broadcast interrupted by the bot restart, if any.
+//
+// Returns false if there is no broadcast to resume.
+func ResumeBroadcast(bot *gotgbot.Bot) (bool, error) {
+	return broadcast.Resume(bot, chatRepo, sendBroadcastReport(bot))
+}
+
+// sendBroadcastReport returns a function that sends
+// the report to the admin when the broadcast is done
+func sendBroadcastReport(bot *gotgbot.Bot) func(*broadcast.Job) {
+	return func(job *broadcast.Job) {
+		chat, err := chatRepo.GetById(job.ReportChatId)
+		if err != nil {
+			errorhandler.SendErrorToTelegram(err, bot)
+			return
+		}
+		if chat == nil {
+			return
+		}
+
+		lang, err := utils.GetLang(chat.LanguageCode, languages)
+		if err != nil {
+			errorhandler.SendErrorToTelegram(err, bot)
+			return
+		}
+
+		page, err := pages.CreateBroadcastReportPage(lang, job.Report)
+		if err != nil {
+			errorhandler.SendErrorToTelegram(err, bot)
+			return
+		}
+
+		opts := page.CreateSendMessageOpts()
+		if _, err := bot.SendMessage(chat.Id, page.Text, &opts); err != nil {
+			errorhandler.SendErrorToTelegram(err, bot)
+		}
+	}
+}
@@ -41,6 +41,6 @@ func HandleCallsCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
-	page, err := pages.CreateCallSchedulePage(lang, "open.menu#from=calls")
+	page, err := pages.CreateCallSchedulePage(utils.UpdateContext(ctx), lang, utils.NewButtonData("open.menu").Set("from", "calls").String(), utils.ChatLocation(chat), utils.NowFor(chat))
 	return sendPage(bot, ctx, page, err)
 }
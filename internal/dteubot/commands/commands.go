@@ -24,6 +24,7 @@ package commands
 
 import (
 	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/calendar"
 	"github.com/cubicbyte/dteubot/internal/dteubot/groupscache"
 	"github.com/cubicbyte/dteubot/internal/i18n"
 	api2 "github.com/cubicbyte/dteubot/pkg/api"
@@ -35,19 +36,27 @@ var (
 	api         api2.Api
 	languages   map[string]i18n.Language
 	groupsCache *groupscache.Cache
+	holidays    *calendar.HolidayCalendar
+	inputStates *data.InputStates
+	usageStats  *data.UsageStats
 )
 
 // InitCommands initializes commands package. Must be called before using this package
 func InitCommands(
-	chatRepo2 data.ChatRepository,
-	userRepo2 data.UserRepository,
+	storage data.Storage,
 	api2 api2.Api,
 	languages2 map[string]i18n.Language,
 	groupsCache2 *groupscache.Cache,
+	holidays2 *calendar.HolidayCalendar,
+	inputStates2 *data.InputStates,
+	usageStats2 *data.UsageStats,
 ) {
-	chatRepo = chatRepo2
-	userRepo = userRepo2
+	chatRepo = storage.Chats()
+	userRepo = storage.Users()
 	api = api2
 	languages = languages2
 	groupsCache = groupsCache2
+	holidays = holidays2
+	inputStates = inputStates2
+	usageStats = usageStats2
 }
@@ -0,0 +1,95 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package commands
+
+import (
+	"bytes"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"strconv"
+	"strings"
+)
+
+func HandleExportCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	if err != nil {
+		return err
+	}
+
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	if settings.GroupId == -1 {
+		page, err := pages.CreateInvalidGroupPage(lang)
+		return sendPage(bot, ctx, page, err)
+	}
+
+	// Get week offset from command arguments: /export 1 - next week
+	weekOffset := 0
+	if strings.Contains(ctx.EffectiveMessage.Text, " ") {
+		arg := strings.SplitN(ctx.EffectiveMessage.Text, " ", 2)[1]
+		weekOffset, err = strconv.Atoi(strings.TrimSpace(arg))
+		if err != nil {
+			page, err := pages.CreateWeekExportUsagePage(lang)
+			return sendPage(bot, ctx, page, err)
+		}
+	}
+
+	calendar, err := pages.CreateICSExport(utils.UpdateContext(ctx), chat, settings.GroupId, weekOffset)
+	if err != nil {
+		return err
+	}
+
+	// Send "sending document" action
+	_, err = bot.SendChatAction(ctx.EffectiveChat.Id, "upload_document", nil)
+	if err != nil {
+		return err
+	}
+
+	// Send calendar file
+	page, err := pages.CreateWeekExportPage(lang, chat, weekOffset)
+	if err != nil {
+		return err
+	}
+
+	_, err = bot.SendDocument(ctx.EffectiveChat.Id, gotgbot.NamedFile{
+		File:     bytes.NewReader(calendar),
+		FileName: "schedule-week.ics",
+	}, &gotgbot.SendDocumentOpts{
+		ReplyToMessageId:         replyToMessageId(ctx),
+		AllowSendingWithoutReply: true,
+		Caption:                  page.Text,
+		ParseMode:                page.ParseMode,
+	})
+	return err
+}
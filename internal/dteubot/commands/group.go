@@ -57,7 +57,10 @@ func HandleGroupCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
 			}
 
 			if group == nil {
-				// Do not set group if it does not exist
+				// Show the groups with similar names instead
+				if query := strings.TrimSpace(arg); query != "" {
+					return searchGroup(bot, ctx, chat, lang, query)
+				}
 				goto CREATE_PAGE
 			}
 
@@ -73,23 +76,27 @@ func HandleGroupCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
 			return err
 		}
 
+		if err := utils.UpdateUserGroup(ctx, userRepo, groupId); err != nil {
+			return err
+		}
+
 		// Create today's schedule page
-		today := time.Now().Format(time.DateOnly)
-		page, err := pages.CreateSchedulePage(lang, groupId, today)
+		today := utils.NowFor(chat).Format(time.DateOnly)
+		page, err := pages.CreateSchedulePage(utils.UpdateContext(ctx), lang, groupId, nil, today, utils.ChatLocation(chat), chat.Id, pages.ChatScheduleView(chat))
 		return sendPage(bot, ctx, page, err)
 	}
 
 CREATE_PAGE:
-	structures, err := api.GetStructures()
+	structures, err := api.WithContext(utils.UpdateContext(ctx)).GetStructures()
 	if err != nil {
 		return err
 	}
 
 	var page pages.Page
 	if len(structures) == 1 {
-		page, err = pages.CreateFacultiesListPage(lang, structures[0].Id)
+		page, err = pages.CreateFacultiesListPage(utils.UpdateContext(ctx), lang, structures[0].Id, chat.GroupId, false)
 	} else {
-		page, err = pages.CreateStructuresListPage(lang)
+		page, err = pages.CreateStructuresListPage(utils.UpdateContext(ctx), lang, chat.GroupId, false)
 	}
 
 	return sendPage(bot, ctx, page, err)
@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package commands
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"strings"
+)
+
+// MaxGroupQueryLength is a max length of the group search query
+const MaxGroupQueryLength = 64
+
+// HandleGroupSearchMessage searches the group by the name sent
+// as a regular message, so new users don't have to know the group id.
+//
+// In private chats every message is the group name, in other chats
+// only the one sent after the search button was pressed.
+func HandleGroupSearchMessage(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// The group name is received, stop waiting for it
+	inputStates.Clear(chat.Id)
+
+	query := strings.TrimSpace(ctx.EffectiveMessage.Text)
+	if query == "" {
+		return nil
+	}
+
+	return searchGroup(bot, ctx, chat, lang, query)
+}
+
+// searchGroup saves the query and sends the page with the found groups
+func searchGroup(bot *gotgbot.Bot, ctx *ext.Context, chat *data.Chat, lang i18n.Language, query string) error {
+	if len([]rune(query)) > MaxGroupQueryLength {
+		query = string([]rune(query)[:MaxGroupQueryLength])
+	}
+
+	// Save query to use it on results pages,
+	// because it may not fit in the button data
+	chat.GroupSearchQuery = query
+
+	if err := chatRepo.Update(chat); err != nil {
+		return err
+	}
+
+	page, err := pages.CreateGroupSearchPage(lang, query, 0)
+	return sendPage(bot, ctx, page, err)
+}
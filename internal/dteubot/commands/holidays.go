@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package commands
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/calendar"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"strings"
+)
+
+// HandleAddHolidayCommand adds a day with no classes, like
+// "/addholiday 2024-10-21 "University Day"". Available only for the bot administrators.
+//
+// The "01-02" date repeats the holiday every year.
+func HandleAddHolidayCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Check if user is admin
+	user, err := userRepo.GetById(ctx.EffectiveUser.Id)
+	if err != nil {
+		return err
+	}
+
+	if !utils.IsBotAdmin(user) {
+		return nil
+	}
+
+	// Get chat
+	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// Get date and reason from command arguments
+	args := strings.Fields(ctx.EffectiveMessage.Text)
+	if len(args) < 3 {
+		page, err := pages.CreateAddHolidayUsagePage(lang)
+		return sendPage(bot, ctx, page, err)
+	}
+
+	date := args[1]
+	if !calendar.IsValidDate(date) {
+		page, err := pages.CreateAddHolidayUsagePage(lang)
+		return sendPage(bot, ctx, page, err)
+	}
+
+	reason := strings.Trim(strings.Join(args[2:], " "), "\"«»“”")
+	if reason == "" {
+		page, err := pages.CreateAddHolidayUsagePage(lang)
+		return sendPage(bot, ctx, page, err)
+	}
+
+	holiday := calendar.Holiday{Date: date, Name: reason}
+	if err := holidays.Add(holiday); err != nil {
+		return err
+	}
+
+	page, err := pages.CreateHolidayAddedPage(lang, holiday)
+	return sendPage(bot, ctx, page, err)
+}
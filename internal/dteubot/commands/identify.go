@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package commands
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HandleIdentifyCommand finds the group of the student by the student id
+// and selects it, so the user doesn't have to look for the group.
+//
+// Student id is sensitive: it is hidden in the logs and statistics,
+// see utils.SensitiveCommands, and is not included into the errors.
+func HandleIdentifyCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// Get student id from command arguments
+	fields := strings.Fields(ctx.EffectiveMessage.Text)
+	if len(fields) < 2 {
+		page, err := pages.CreateIdentifyUsagePage(lang)
+		return sendPage(bot, ctx, page, err)
+	}
+
+	// The parsing error contains the student id, so it is not returned
+	studentId, err := strconv.Atoi(fields[1])
+	if err != nil || studentId <= 0 {
+		page, err := pages.CreateIdentifyUsagePage(lang)
+		return sendPage(bot, ctx, page, err)
+	}
+
+	groups, err := pages.FindStudentGroups(studentId)
+	if err != nil {
+		return err
+	}
+
+	if len(groups) == 0 {
+		page, err := pages.CreateIdentifyNotFoundPage(lang)
+		return sendPage(bot, ctx, page, err)
+	}
+
+	if len(groups) > 1 {
+		// Let the user choose the group
+		page, err := pages.CreateIdentifyGroupsPage(lang, groups)
+		return sendPage(bot, ctx, page, err)
+	}
+
+	group := groups[0]
+	if err := pages.CacheGroup(group.Id); err != nil {
+		return err
+	}
+
+	// Set chat group
+	chat.GroupId = group.Id
+
+	err = chatRepo.Update(chat)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.UpdateUserGroup(ctx, userRepo, group.Id); err != nil {
+		return err
+	}
+
+	// Create today's schedule page
+	today := utils.NowFor(chat).Format(time.DateOnly)
+	page, err := pages.CreateSchedulePage(utils.UpdateContext(ctx), lang, group.Id, nil, today, utils.ChatLocation(chat), chat.Id, pages.ChatScheduleView(chat))
+	return sendPage(bot, ctx, page, err)
+}
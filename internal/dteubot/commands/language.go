@@ -27,6 +27,7 @@ import (
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
 	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"strings"
 )
 
 func HandleLanguageCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
@@ -36,11 +37,22 @@ func HandleLanguageCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
+	// Set language from command arguments, like "/lang en"
+	if strings.Contains(ctx.EffectiveMessage.Text, " ") {
+		langCode := strings.TrimSpace(strings.SplitN(ctx.EffectiveMessage.Text, " ", 2)[1])
+		if _, ok := languages[langCode]; ok {
+			chat.LanguageCode = langCode
+			if err := chatRepo.Update(chat); err != nil {
+				return err
+			}
+		}
+	}
+
 	lang, err := utils.GetLang(chat.LanguageCode, languages)
 	if err != nil {
 		return err
 	}
 
-	page, err := pages.CreateLanguageSelectionPage(lang, "open.menu#from=language")
+	page, err := pages.CreateLanguageSelectionPage(lang, "menu")
 	return sendPage(bot, ctx, page, err)
 }
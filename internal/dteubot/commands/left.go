@@ -36,11 +36,16 @@ func HandleLeftCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
-	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
 	if err != nil {
 		return err
 	}
 
-	page, err := pages.CreateLeftPage(lang, chat.GroupId, "open.menu#from=left")
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	page, err := pages.CreateLeftPage(lang, chat, settings.GroupId, utils.NewButtonData("open.menu").Set("from", "left").String())
 	return sendPage(bot, ctx, page, err)
 }
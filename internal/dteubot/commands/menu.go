@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"os"
+)
+
+// MenuCommands returns the commands shown in the Telegram "/" menu.
+// Administrator commands are not listed.
+func MenuCommands(lang i18n.Language) []gotgbot.BotCommand {
+	return []gotgbot.BotCommand{
+		{Command: "today", Description: lang.Command.Today},
+		{Command: "tomorrow", Description: lang.Command.Tomorrow},
+		{Command: "next", Description: lang.Command.Next},
+		{Command: "left", Description: lang.Command.Left},
+		{Command: "calls", Description: lang.Command.Calls},
+		{Command: "teacher", Description: lang.Command.Teacher},
+		{Command: "rooms", Description: lang.Command.Rooms},
+		{Command: "students", Description: lang.Command.Students},
+		{Command: "calendar", Description: lang.Command.Calendar},
+		{Command: "group", Description: lang.Command.Group},
+		{Command: "findgroup", Description: lang.Command.FindGroup},
+		{Command: "identify", Description: lang.Command.Identify},
+		{Command: "settings", Description: lang.Command.Settings},
+		{Command: "lang", Description: lang.Command.Lang},
+	}
+}
+
+// RegisterCommands sets the commands menu of the bot for every language,
+// and for the users whose language is not supported, in DEFAULT_LANG.
+//
+// Menu set manually in BotFather is replaced.
+func RegisterCommands(bot *gotgbot.Bot) error {
+	var errs []error
+
+	if lang, ok := languages[os.Getenv("DEFAULT_LANG")]; ok {
+		if _, err := bot.SetMyCommands(MenuCommands(lang), nil); err != nil {
+			errs = append(errs, fmt.Errorf("default commands: %w", err))
+		}
+	}
+
+	for code, lang := range languages {
+		_, err := bot.SetMyCommands(MenuCommands(lang), &gotgbot.SetMyCommandsOpts{
+			LanguageCode: code,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s commands: %w", code, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
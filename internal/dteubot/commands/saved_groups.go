@@ -0,0 +1,167 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package commands
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"strconv"
+	"strings"
+)
+
+func HandleAddGroupCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// Get group from command arguments, or use the current one
+	var group *data.GroupRef
+	if strings.Contains(ctx.EffectiveMessage.Text, " ") {
+		arg := strings.SplitN(ctx.EffectiveMessage.Text, " ", 2)[1]
+		group, err = findGroup(arg)
+	} else if chat.GroupId != -1 {
+		group, err = getGroupRef(chat.GroupId)
+	}
+	if err != nil {
+		return err
+	}
+
+	if group == nil {
+		_, err = bot.SendMessage(ctx.EffectiveChat.Id, lang.Page.AddGroupUsage, &gotgbot.SendMessageOpts{
+			ReplyToMessageId:         replyToMessageId(ctx),
+			AllowSendingWithoutReply: true,
+			ParseMode:                "MarkdownV2",
+		})
+		return err
+	}
+
+	if chat.SavedGroups.Index(group.Id) == -1 {
+		// Ask to remove one of the groups if there is no space left
+		if len(chat.SavedGroups) >= data.MaxSavedGroups {
+			page, err := pages.CreateRemoveSavedGroupsPage(lang, chat, group)
+			return sendPage(bot, ctx, page, err)
+		}
+
+		chat.SavedGroups = append(chat.SavedGroups, *group)
+
+		err = chatRepo.Update(chat)
+		if err != nil {
+			return err
+		}
+	}
+
+	page, err := pages.CreateSavedGroupsPage(lang, chat)
+	return sendPage(bot, ctx, page, err)
+}
+
+func HandleRemoveGroupCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// Remove group from command arguments
+	if strings.Contains(ctx.EffectiveMessage.Text, " ") {
+		arg := strings.SplitN(ctx.EffectiveMessage.Text, " ", 2)[1]
+		group, err := findGroup(arg)
+		if err != nil {
+			return err
+		}
+
+		if group != nil && chat.SavedGroups.Index(group.Id) != -1 {
+			// Another group is selected instead of the chat group
+			groupChanged := chat.RemoveSavedGroup(group.Id)
+
+			err = chatRepo.Update(chat)
+			if err != nil {
+				return err
+			}
+
+			if groupChanged {
+				if err := utils.UpdateUserGroup(ctx, userRepo, chat.GroupId); err != nil {
+					return err
+				}
+			}
+
+			page, err := pages.CreateSavedGroupsPage(lang, chat)
+			return sendPage(bot, ctx, page, err)
+		}
+	}
+
+	// Let user select the group to remove
+	page, err := pages.CreateRemoveSavedGroupsPage(lang, chat, nil)
+	return sendPage(bot, ctx, page, err)
+}
+
+// findGroup finds the group by its id or name.
+// Returns nil if the group name is unknown.
+func findGroup(query string) (*data.GroupRef, error) {
+	query = strings.TrimSpace(query)
+
+	groupId, err := strconv.Atoi(query)
+	if err == nil {
+		if groupId <= 0 {
+			return nil, nil
+		}
+		return getGroupRef(groupId)
+	}
+
+	group, err := groupsCache.GetGroupByName(query)
+	if err != nil || group == nil {
+		return nil, err
+	}
+
+	return &data.GroupRef{Id: group.Id, Name: group.Name}, nil
+}
+
+// getGroupRef creates a reference to the group, with the group name if it's known
+func getGroupRef(groupId int) (*data.GroupRef, error) {
+	// Outdated group is returned along with the update error
+	group, err := groupsCache.GetGroupById(groupId)
+	if err != nil && group == nil {
+		return nil, err
+	}
+
+	ref := &data.GroupRef{Id: groupId}
+	if group != nil {
+		ref.Name = group.Name
+	}
+
+	return ref, nil
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package commands
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+)
+
+// RequireSettingsAccess wraps the command handler that changes the chat settings.
+// See utils.RequireSettingsAccess.
+func RequireSettingsAccess(handler func(*gotgbot.Bot, *ext.Context) error) func(*gotgbot.Bot, *ext.Context) error {
+	return utils.RequireSettingsAccess(chatRepo, languages, sendAlert, handler)
+}
+
+// sendAlert answers the command with the text message
+func sendAlert(bot *gotgbot.Bot, ctx *ext.Context, text string) error {
+	_, err := bot.SendMessage(ctx.EffectiveChat.Id, text, &gotgbot.SendMessageOpts{
+		ReplyToMessageId:         replyToMessageId(ctx),
+		AllowSendingWithoutReply: true,
+	})
+	return err
+}
+
+// RequireChatAdmin wraps the command handler, so that
+// in group chats it's only available to chat admins.
+func RequireChatAdmin(handler func(*gotgbot.Bot, *ext.Context) error) func(*gotgbot.Bot, *ext.Context) error {
+	return func(bot *gotgbot.Bot, ctx *ext.Context) error {
+		if ctx.EffectiveChat.Type == "private" {
+			return handler(bot, ctx)
+		}
+
+		if ctx.EffectiveUser != nil {
+			admin, err := utils.IsChatAdmin(bot, ctx.EffectiveChat.Id, ctx.EffectiveUser.Id)
+			if err != nil {
+				return err
+			}
+			if admin {
+				return handler(bot, ctx)
+			}
+		}
+
+		chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+		if err != nil {
+			return err
+		}
+
+		lang, err := utils.GetLang(chat.LanguageCode, languages)
+		if err != nil {
+			return err
+		}
+
+		_, err = bot.SendMessage(ctx.EffectiveChat.Id, lang.Alert.NotChatAdmin, &gotgbot.SendMessageOpts{
+			ReplyToMessageId:         replyToMessageId(ctx),
+			AllowSendingWithoutReply: true,
+		})
+		return err
+	}
+}
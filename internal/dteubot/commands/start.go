@@ -25,9 +25,12 @@ package commands
 import (
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/data"
 	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
 	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
 	"strings"
+	"time"
 )
 
 func HandleStartCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
@@ -47,48 +50,100 @@ func HandleStartCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
+	var payload string
+	if strings.Contains(ctx.EffectiveMessage.Text, " ") {
+		payload = strings.SplitN(ctx.EffectiveMessage.Text, " ", 2)[1]
+	}
+
+	// Open the deep link. Malformed and expired links fall back to the regular /start
+	if link, err := utils.UnmarshalDeepLink(payload); err == nil {
+		return openDeepLink(bot, ctx, chat, lang, link)
+	}
+
 	// Register referral if available
-	if user.Referral == "" && strings.Contains(ctx.EffectiveMessage.Text, " ") {
-		user.Referral = strings.SplitN(ctx.EffectiveMessage.Text, " ", 2)[1]
+	if user.Referral == "" && payload != "" {
+		user.Referral = payload
 		if err := userRepo.Update(user); err != nil {
 			return err
 		}
 	}
 
-	// Send greeting
-	greeting, err := pages.CreateGreetingPage(lang)
+	// Chats with the group selected get today's schedule
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
 	if err != nil {
 		return err
 	}
 
-	opts := greeting.CreateSendMessageOpts()
-	_, err = bot.SendMessage(ctx.EffectiveChat.Id, greeting.Text, &opts)
-	if err != nil {
-		return err
+	if settings.GroupId != -1 {
+		lang, err := utils.GetLang(settings.LanguageCode, languages)
+		if err != nil {
+			return err
+		}
+
+		today := utils.NowFor(chat).Format(time.DateOnly)
+		page, err := pages.CreateSchedulePage(utils.UpdateContext(ctx), lang, settings.GroupId, settings.ScheduleGroups(chat), today, utils.ChatLocation(chat), chat.Id, pages.ChatScheduleView(chat))
+		return sendPage(bot, ctx, page, err)
 	}
 
-	// Send group selection page
-	structures, err := api.GetStructures()
-	if err != nil {
-		return err
+	// Group chats share the schedule, selected by chat admins
+	if ctx.EffectiveChat.Type != "private" {
+		page, err := pages.CreateGroupChatSetupPage(lang)
+		return sendPage(bot, ctx, page, err)
 	}
 
-	var groupSelection pages.Page
-	if len(structures) == 1 {
-		groupSelection, err = pages.CreateFacultiesListPage(lang, structures[0].Id)
-	} else {
-		groupSelection, err = pages.CreateStructuresListPage(lang)
+	// New users are explained how to use the bot
+	page, err := pages.CreateOnboardingPage(lang)
+	return sendPage(bot, ctx, page, err)
+}
+
+// openDeepLink sends the page the /start deep link leads to
+func openDeepLink(bot *gotgbot.Bot, ctx *ext.Context, chat *data.Chat, lang i18n.Language, link utils.DeepLink) error {
+	today := utils.NowFor(chat).Format(time.DateOnly)
+
+	// Teacher schedule
+	if link.TeacherId != 0 {
+		page, err := pages.CreateTeacherSchedulePage(utils.UpdateContext(ctx), lang, link.TeacherId, today, utils.ChatLocation(chat))
+		return sendPage(bot, ctx, page, err)
 	}
 
-	if err != nil {
-		return err
+	// Group, e.g. from the QR code: select it right away
+	if link.Date == "" {
+		exists, err := groupExists(utils.UpdateContext(ctx), chat, link.GroupId)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			page, err := pages.CreateInvalidGroupPage(lang)
+			return sendPage(bot, ctx, page, err)
+		}
+
+		// Without the settings access only the schedule is shown
+		access, err := utils.HasSettingsAccess(bot, ctx, chat)
+		if err != nil {
+			return err
+		}
+		if access && chat.GroupId != link.GroupId {
+			chat.GroupId = link.GroupId
+			if err := chatRepo.Update(chat); err != nil {
+				return err
+			}
+
+			if err := utils.UpdateUserGroup(ctx, userRepo, link.GroupId); err != nil {
+				return err
+			}
+		}
+
+		page, err := pages.CreateSchedulePage(utils.UpdateContext(ctx), lang, link.GroupId, nil, today, utils.ChatLocation(chat), chat.Id, pages.ChatScheduleView(chat))
+		return sendPage(bot, ctx, page, err)
 	}
 
-	opts = groupSelection.CreateSendMessageOpts()
-	_, err = bot.SendMessage(ctx.EffectiveChat.Id, groupSelection.Text, &opts)
-	if err != nil {
-		return err
+	// Shared schedule for the day
+	if link.GroupId == chat.GroupId {
+		page, err := pages.CreateSchedulePage(utils.UpdateContext(ctx), lang, link.GroupId, nil, link.Date, utils.ChatLocation(chat), chat.Id, pages.ChatScheduleView(chat))
+		return sendPage(bot, ctx, page, err)
 	}
 
-	return nil
+	// Ask before switching to another group
+	page, err := pages.CreateDeepLinkConfirmPage(lang, link)
+	return sendPage(bot, ctx, page, err)
 }
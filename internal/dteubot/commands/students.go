@@ -36,11 +36,16 @@ func HandleStudentsCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
-	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
 	if err != nil {
 		return err
 	}
 
-	page, err := pages.CreateStudentsListPage(lang, chat.GroupId)
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	page, err := pages.CreateStudentsListPage(utils.UpdateContext(ctx), lang, settings.GroupId)
 	return sendPage(bot, ctx, page, err)
 }
@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package commands
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"strings"
+)
+
+// MaxTeacherQueryLength is a max length of the teacher search query
+const MaxTeacherQueryLength = 64
+
+// HandleTeacherCommand saves the teacher search query from the command arguments,
+// or waits for it in the next message of the chat
+func HandleTeacherCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	// Get teacher surname from command arguments
+	query := ""
+	if strings.Contains(ctx.EffectiveMessage.Text, " ") {
+		query = strings.TrimSpace(strings.SplitN(ctx.EffectiveMessage.Text, " ", 2)[1])
+	}
+	if query == "" {
+		inputStates.Set(chat.Id, data.InputTeacherSearch)
+
+		page, err := pages.CreateTeacherSearchUsagePage(lang)
+		return sendPage(bot, ctx, page, err)
+	}
+
+	return setTeacherQuery(bot, ctx, chat, lang, query)
+}
+
+// HandleTeacherSearchMessage changes the teacher search query
+// to the message sent after the search was started
+func HandleTeacherSearchMessage(bot *gotgbot.Bot, ctx *ext.Context) error {
+	// Get chat
+	chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	query := strings.TrimSpace(ctx.EffectiveMessage.Text)
+	if query == "" {
+		return nil
+	}
+
+	return setTeacherQuery(bot, ctx, chat, lang, query)
+}
+
+// setTeacherQuery saves the query and sends the page with the search button.
+// The bot keeps waiting for the messages that change the query
+func setTeacherQuery(bot *gotgbot.Bot, ctx *ext.Context, chat *data.Chat, lang i18n.Language, query string) error {
+	if len([]rune(query)) > MaxTeacherQueryLength {
+		query = string([]rune(query)[:MaxTeacherQueryLength])
+	}
+
+	// Save query to use it on results pages,
+	// because it may not fit in the button data
+	chat.TeacherSearchQuery = query
+
+	if err := chatRepo.Update(chat); err != nil {
+		return err
+	}
+
+	inputStates.Set(chat.Id, data.InputTeacherSearch)
+
+	page, err := pages.CreateTeacherSearchQueryPage(lang, query)
+	return sendPage(bot, ctx, page, err)
+}
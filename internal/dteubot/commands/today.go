@@ -27,7 +27,6 @@ import (
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
 	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
-	"time"
 )
 
 func HandleTodayCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
@@ -37,13 +36,18 @@ func HandleTodayCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
-	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
+	if err != nil {
+		return err
+	}
+
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
 	if err != nil {
 		return err
 	}
 
 	// Send today's schedule page
-	today := time.Now().Format("2006-01-02")
-	page, err := pages.CreateSchedulePage(lang, chat.GroupId, today)
+	today := utils.NowFor(chat).Format("2006-01-02")
+	page, err := pages.CreateSchedulePage(utils.UpdateContext(ctx), lang, settings.GroupId, settings.ScheduleGroups(chat), today, utils.ChatLocation(chat), chat.Id, pages.ChatScheduleView(chat))
 	return sendPage(bot, ctx, page, err)
 }
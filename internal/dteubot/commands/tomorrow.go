@@ -27,7 +27,6 @@ import (
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
 	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
-	"time"
 )
 
 func HandleTomorrowCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
@@ -37,12 +36,17 @@ func HandleTomorrowCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return err
 	}
 
-	lang, err := utils.GetLang(chat.LanguageCode, languages)
+	settings, err := utils.LoadSettings(ctx, chat, userRepo)
 	if err != nil {
 		return err
 	}
 
-	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
-	page, err := pages.CreateSchedulePage(lang, chat.GroupId, tomorrow)
+	lang, err := utils.GetLang(settings.LanguageCode, languages)
+	if err != nil {
+		return err
+	}
+
+	tomorrow := utils.NowFor(chat).AddDate(0, 0, 1).Format("2006-01-02")
+	page, err := pages.CreateSchedulePage(utils.UpdateContext(ctx), lang, settings.GroupId, settings.ScheduleGroups(chat), tomorrow, utils.ChatLocation(chat), chat.Id, pages.ChatScheduleView(chat))
 	return sendPage(bot, ctx, page, err)
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
 )
 
 // sendPage sends a page to the chat
@@ -35,10 +36,24 @@ func sendPage(bot *gotgbot.Bot, ctx *ext.Context, page pages.Page, err error) er
 	}
 
 	opts := page.CreateSendMessageOpts()
-	_, err = bot.SendMessage(ctx.EffectiveChat.Id, page.Text, &opts)
+	opts.ReplyToMessageId = replyToMessageId(ctx)
+	opts.AllowSendingWithoutReply = true
+	msg, err := bot.SendMessage(ctx.EffectiveChat.Id, page.Text, &opts)
 	if err != nil {
 		return err
 	}
 
+	utils.TrackPage(bot, chatRepo, ctx.EffectiveChat.Id, msg.MessageId, page.HasButtons())
 	return nil
 }
+
+// replyToMessageId returns the id of the message the bot answers to.
+// In group chats the answers are sent as replies to the command,
+// so they are not lost among the other messages.
+// Returns 0 in private chats, where the messages are not replied.
+func replyToMessageId(ctx *ext.Context) int64 {
+	if ctx.EffectiveChat.Type == "private" || ctx.EffectiveMessage == nil {
+		return 0
+	}
+	return ctx.EffectiveMessage.MessageId
+}
@@ -23,28 +23,41 @@
 package dteubot
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
-	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/callbackquery"
+	"github.com/cubicbyte/dteubot/internal/cache"
 	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/broadcast"
 	"github.com/cubicbyte/dteubot/internal/dteubot/buttons"
+	"github.com/cubicbyte/dteubot/internal/dteubot/calendar"
 	"github.com/cubicbyte/dteubot/internal/dteubot/commands"
 	"github.com/cubicbyte/dteubot/internal/dteubot/errorhandler"
 	"github.com/cubicbyte/dteubot/internal/dteubot/groupscache"
+	"github.com/cubicbyte/dteubot/internal/dteubot/inline"
+	"github.com/cubicbyte/dteubot/internal/dteubot/lifecycle"
+	"github.com/cubicbyte/dteubot/internal/dteubot/metrics"
+	"github.com/cubicbyte/dteubot/internal/dteubot/middleware"
 	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/ratelimit"
+	"github.com/cubicbyte/dteubot/internal/dteubot/rooms"
 	"github.com/cubicbyte/dteubot/internal/dteubot/statistics"
 	"github.com/cubicbyte/dteubot/internal/dteubot/teachers"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/dteubot/workerpool"
 	"github.com/cubicbyte/dteubot/internal/i18n"
 	"github.com/cubicbyte/dteubot/internal/notifier"
+	"github.com/cubicbyte/dteubot/internal/webapp"
 	api2 "github.com/cubicbyte/dteubot/pkg/api"
 	"github.com/cubicbyte/dteubot/pkg/api/cachedapi"
 	"github.com/go-co-op/gocron"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 	"github.com/op/go-logging"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -60,13 +73,21 @@ const ApiCachePath = CachePath + "/api.sqlite"
 const GroupsCachePath = CachePath + "/groups.csv"
 const TeachersListPath = "teachers.csv"
 
+// UsageStatsPath is the file with the daily bot usage counters of the file storage
+const UsageStatsPath = "usage_counters.json"
+
+// UsageStatsSaveInterval is how often the usage stats are saved to the storage, in minutes
+const UsageStatsSaveInterval = 5
+
 var log = logging.MustGetLogger("Bot")
 
 var (
 	bot          *gotgbot.Bot
 	updater      *ext.Updater
+	pool         *workerpool.Pool
 	db           *sqlx.DB
 	api          api2.Api
+	storage      data.Storage
 	chatRepo     data.ChatRepository
 	userRepo     data.UserRepository
 	statLogger   statistics.Logger
@@ -74,6 +95,9 @@ var (
 	languages    map[string]i18n.Language
 	groupsCache  *groupscache.Cache
 	teachersList *teachers.TeachersList
+	holidays     *calendar.HolidayCalendar
+	inputStates  *data.InputStates
+	usageStats   *data.UsageStats
 )
 
 // Setup sets up all the Bot components.
@@ -92,6 +116,52 @@ func Setup() {
 		log.Fatalf("Error creating cache directory: %s\n", err)
 	}
 
+	// Setup the database
+	switch os.Getenv("DATABASE_TYPE") {
+	case "postgres":
+		db, err = connectPostgres()
+		if err != nil {
+			log.Fatalf("Error connecting to database: %s\n", err)
+		}
+
+		storage = data.NewPostgresStorage(db)
+		statLogger = statistics.NewPostgresLogger(db)
+
+	case "sqlite":
+		db, err = connectSQLite()
+		if err != nil {
+			log.Fatalf("Error opening database: %s\n", err)
+		}
+
+		storage, err = data.NewSQLiteStorage(db)
+		if err != nil {
+			log.Fatalf("Error setting up storage: %s\n", err)
+		}
+		statLogger, err = statistics.NewFileLogger("statistics")
+		if err != nil {
+			log.Fatalf("Error setting up statistics logger: %s\n", err)
+		}
+
+	case "file":
+		storage, err = data.NewFileStorage(ChatsDirPath, UsersDirPath, UsageStatsPath)
+		if err != nil {
+			log.Fatalf("Error setting up storage: %s\n", err)
+		}
+		statLogger, err = statistics.NewFileLogger("statistics")
+		if err != nil {
+			log.Fatalf("Error setting up statistics logger: %s\n", err)
+		}
+
+	default:
+		log.Fatalf("Unknown database type: %s\n", os.Getenv("DATABASE_TYPE"))
+	}
+	chatRepo = storage.Chats()
+	userRepo = storage.Users()
+
+	// Set up the usage stats. API errors are counted too, so it's done before the API setup
+	retention, _ := strconv.Atoi(os.Getenv("STATS_RETENTION_DAYS"))
+	usageStats = data.NewUsageStats(storage.UsageStats(), retention)
+
 	// Setup the API
 	// Get expiration time
 	expires, err := strconv.Atoi(os.Getenv("API_CACHE_EXPIRES"))
@@ -102,18 +172,36 @@ func Setup() {
 	if err != nil {
 		log.Fatalf("Error parsing API_REQUEST_TIMEOUT: %s\n", err)
 	}
-	api, err = cachedapi.New(
+	retryAttempts, err := strconv.Atoi(os.Getenv("API_RETRY_ATTEMPTS"))
+	if err != nil {
+		log.Fatalf("Error parsing API_RETRY_ATTEMPTS: %s\n", err)
+	}
+	var calls api2.CallSchedule
+	if os.Getenv("CALL_SCHEDULE") != "" {
+		calls, _ = api2.ParseCallSchedule(os.Getenv("CALL_SCHEDULE"))
+	}
+	var breaker *api2.Breaker
+	if errorRate, _ := strconv.ParseFloat(os.Getenv("API_BREAKER_ERROR_RATE"), 64); errorRate > 0 {
+		breaker = api2.NewBreaker(errorRate)
+	}
+	cachedApi, err := cachedapi.New(
 		os.Getenv("API_URL"),
 		&cachedapi.ApiConfig{
-			LevelDBPath:  ApiLevelDBPath,
-			SQLiteDbPath: ApiCachePath,
-			Expires:      time.Duration(expires) * time.Second,
-			Timeout:      time.Duration(timeout) * time.Millisecond,
+			LevelDBPath:   ApiLevelDBPath,
+			SQLiteDbPath:  ApiCachePath,
+			Expires:       time.Duration(expires) * time.Second,
+			Timeout:       time.Duration(timeout) * time.Millisecond,
+			RetryAttempts: &retryAttempts,
+			Breaker:       breaker,
+			CallSchedule:  calls,
+			Observer:      metrics.ApiObserver{UsageStats: usageStats},
+			Context:       lifecycle.Context(),
 		},
 	)
 	if err != nil {
 		log.Fatalf("Error setting up API: %s\n", err)
 	}
+	api = cachedApi
 
 	// Load localization files
 	languages, err = i18n.LoadLangs()
@@ -122,51 +210,6 @@ func Setup() {
 	}
 	log.Infof("Loaded %d languages\n", len(languages))
 
-	// Setup the database
-	switch os.Getenv("DATABASE_TYPE") {
-	case "postgres":
-		var ssl string
-		if os.Getenv("POSTGRES_SSL") == "true" {
-			ssl = "require"
-		} else {
-			ssl = "disable"
-		}
-
-		connStr := fmt.Sprintf("user=%s password=%s host=%s port=%s dbname=%s sslmode=%s",
-			os.Getenv("POSTGRES_USER"),
-			os.Getenv("POSTGRES_PASSWORD"),
-			os.Getenv("POSTGRES_HOST"),
-			os.Getenv("POSTGRES_PORT"),
-			os.Getenv("POSTGRES_DB"),
-			ssl,
-		)
-		db, err = sqlx.Connect("postgres", connStr)
-		if err != nil {
-			log.Fatalf("Error connecting to database: %s\n", err)
-		}
-
-		chatRepo = data.NewPostgresChatRepository(db)
-		userRepo = data.NewPostgresUserRepository(db)
-		statLogger = statistics.NewPostgresLogger(db)
-
-	case "file":
-		chatRepo, err = data.NewFileChatRepository(ChatsDirPath)
-		if err != nil {
-			log.Fatalf("Error setting up chat repository: %s\n", err)
-		}
-		userRepo, err = data.NewFileUserRepository(UsersDirPath)
-		if err != nil {
-			log.Fatalf("Error setting up user repository: %s\n", err)
-		}
-		statLogger, err = statistics.NewFileLogger("statistics")
-		if err != nil {
-			log.Fatalf("Error setting up statistics logger: %s\n", err)
-		}
-
-	default:
-		log.Fatalf("Unknown database type: %s\n", os.Getenv("DATABASE_TYPE"))
-	}
-
 	// Load the groups cache
 	groupsCache = groupscache.New(GroupsCachePath, api)
 	if err = groupsCache.Load(); err != nil {
@@ -183,6 +226,17 @@ func Setup() {
 		log.Fatalf("Error loading teachers list: %s\n", err)
 	}
 
+	// Load the holidays
+	holidays = &calendar.HolidayCalendar{
+		File:      os.Getenv("HOLIDAYS_FILE"),
+		AddedFile: os.Getenv("ADDED_HOLIDAYS_FILE"),
+	}
+	if err = holidays.Load(); err != nil {
+		log.Fatalf("Error loading holidays: %s\n", err)
+	}
+
+	inputStates = data.NewInputStates(data.InputStateTTL)
+
 	// Set up error handler
 	errorhandler.Setup(languages, chatRepo)
 
@@ -191,6 +245,7 @@ func Setup() {
 
 	opts := gotgbot.BotOpts{
 		DisableTokenCheck: true, // Prevent crash when network is unreachable
+		BotClient:         middleware.TrackAnswers(&gotgbot.BaseBotClient{}),
 	}
 
 	bot, err = gotgbot.NewBot(os.Getenv("BOT_TOKEN"), &opts)
@@ -207,36 +262,218 @@ func Setup() {
 	}
 
 	dispatcher := ext.NewDispatcher(&ext.DispatcherOpts{
-		Error:       errorhandler.HandleError,
-		Panic:       errorhandler.PanicsHandler,
-		MaxRoutines: ext.DefaultMaxRoutines,
+		// Callback queries of the failed handlers are answered after the error is handled
+		Error: func(b *gotgbot.Bot, ctx *ext.Context, err error) ext.DispatcherAction {
+			defer middleware.AnswerPending(b, ctx)
+			return errorhandler.HandleError(b, ctx, err)
+		},
+		Panic: func(b *gotgbot.Bot, ctx *ext.Context, r interface{}) {
+			defer middleware.AnswerPending(b, ctx)
+			errorhandler.PanicsHandler(b, ctx, r)
+		},
 	})
-	updater = ext.NewUpdater(dispatcher, nil)
+
+	// Updates are processed concurrently, one by one for each chat
+	workers, err := strconv.Atoi(os.Getenv("UPDATE_WORKERS"))
+	if err != nil {
+		log.Fatalf("Error parsing UPDATE_WORKERS: %s\n", err)
+	}
+	shutdownTimeout, err := strconv.Atoi(os.Getenv("SHUTDOWN_TIMEOUT"))
+	if err != nil {
+		log.Fatalf("Error parsing SHUTDOWN_TIMEOUT: %s\n", err)
+	}
+	pool = workerpool.NewPool(dispatcher, workers, time.Duration(shutdownTimeout)*time.Second)
+	updater = ext.NewUpdater(pool, nil)
 
 	// Add bot update handlers
 	setupDispatcherHandlers(dispatcher)
 
 	// Set up notifier
 	log.Info("Setting up notifier")
-	scheduler, err = notifier.Setup(api, bot, languages, chatRepo)
+	changesInterval, err := strconv.Atoi(os.Getenv("SCHEDULE_CHANGES_INTERVAL"))
+	if err != nil {
+		log.Fatalf("Error parsing SCHEDULE_CHANGES_INTERVAL: %s\n", err)
+	}
+	snapshots, err := data.NewScheduleSnapshots(os.Getenv("SCHEDULE_SNAPSHOTS_FILE"))
+	if err != nil {
+		log.Fatalf("Error loading schedule snapshots: %s\n", err)
+	}
+	scheduler, err = notifier.Setup(api, bot, languages, chatRepo, snapshots, changesInterval)
 	if err != nil {
 		log.Fatalf("Error setting up notifier: %s\n", err)
 	}
+	_, err = scheduler.Every(UsageStatsSaveInterval).Minutes().Do(FlushUsageStats)
+	if err != nil {
+		log.Fatalf("Error scheduling usage stats saving: %s\n", err)
+	}
 
 	// Set up pages, commands and buttons
-	pages.InitPages(chatRepo, userRepo, api, groupsCache, teachersList, languages)
-	buttons.InitButtons(chatRepo, userRepo, api, languages)
-	commands.InitCommands(chatRepo, userRepo, api, languages, groupsCache)
+	pages.InitPages(storage, api, groupsCache, teachersList, holidays, languages)
+	buttons.InitButtons(storage, api, languages, inputStates, groupsCache, usageStats)
+	commands.InitCommands(storage, api, languages, groupsCache, holidays, inputStates, usageStats)
+	inline.InitInline(storage, languages)
+
+	// Keep the classrooms usage of the current week collected,
+	// collecting it takes a request for every group and teacher
+	_, err = scheduler.Every(int(rooms.CacheTTL.Minutes())).Minutes().Do(pages.PreloadFreeRooms)
+	if err != nil {
+		log.Fatalf("Error scheduling free rooms preloading: %s\n", err)
+	}
+
+	// Keep the students index built, so /identify doesn't
+	// request the students of every group
+	_, err = scheduler.Every(int(groupscache.StudentIndexTTL.Hours())).Hours().Do(pages.UpdateStudentIndex)
+	if err != nil {
+		log.Fatalf("Error scheduling students index updating: %s\n", err)
+	}
+
+	// Set the commands menu. The bot works without it, so don't stop on error
+	if err := commands.RegisterCommands(bot); err != nil {
+		log.Warningf("Error registering bot commands: %s\n", err)
+	}
+
+	// Continue the broadcast interrupted by the restart
+	delay, _ := strconv.ParseInt(os.Getenv("BROADCAST_DELAY"), 10, 64)
+	broadcast.MessageDelay = time.Duration(delay) * time.Millisecond
+	broadcast.ProgressFile = os.Getenv("BROADCAST_PROGRESS_FILE")
+	if resumed, err := commands.ResumeBroadcast(bot); err != nil {
+		log.Errorf("Error resuming broadcast: %s\n", err)
+	} else if resumed {
+		log.Info("Resumed the interrupted broadcast")
+	}
+
+	// Start the metrics server
+	if port := os.Getenv("METRICS_PORT"); port != "" {
+		metrics.Setup(chatRepo)
+		lifecycle.OnStop(metrics.StartServer(":"+port, metrics.BotHealthCheck(bot, os.Getenv("API_URL"))))
+	}
+
+	// Start the Mini App API server
+	if port := os.Getenv("WEBAPP_PORT"); port != "" {
+		webAppUrl, _ := url.Parse(os.Getenv("WEBAPP_URL"))
+		lifecycle.OnStop(webapp.StartServer(":"+port, webapp.Config{
+			Api:      api,
+			BotToken: os.Getenv("BOT_TOKEN"),
+			Origin:   webAppUrl.Scheme + "://" + webAppUrl.Host,
+		}))
+	}
+
+	// Set up graceful shutdown
+	lifecycle.OnStop(func() error {
+		// Stop receiving updates, running scheduled jobs and broadcasts
+		updater.StopAllBots()
+		broadcast.Stop()
+
+		// Scheduler is stopped when the running notifier jobs complete.
+		// They save their state, like the schedule snapshots, so they are
+		// waited along with the handlers, within the shutdown timeout
+		return lifecycle.Go(scheduler.Stop)
+	})
+	lifecycle.OnClose(cachedApi.Close)
+	lifecycle.OnClose(usageStats.Flush)
+	if db != nil {
+		lifecycle.OnClose(db.Close)
+	}
+}
+
+// connectPostgres connects to the PostgreSQL database
+// and applies the schema migrations
+func connectPostgres() (*sqlx.DB, error) {
+	var ssl string
+	if os.Getenv("POSTGRES_SSL") == "true" {
+		ssl = "require"
+	} else {
+		ssl = "disable"
+	}
+
+	connStr := fmt.Sprintf("user=%s password=%s host=%s port=%s dbname=%s sslmode=%s",
+		os.Getenv("POSTGRES_USER"),
+		os.Getenv("POSTGRES_PASSWORD"),
+		os.Getenv("POSTGRES_HOST"),
+		os.Getenv("POSTGRES_PORT"),
+		os.Getenv("POSTGRES_DB"),
+		ssl,
+	)
+	db, err := sqlx.Connect("postgres", connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := data.Migrate(db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if applied > 0 {
+		log.Infof("Applied %d database migrations\n", applied)
+	}
+
+	return db, nil
 }
 
-// Run starts the Bot.
-func Run() {
+// connectSQLite opens the SQLite database file
+// and applies the schema migrations
+func connectSQLite() (*sqlx.DB, error) {
+	db, err := data.OpenSQLite(os.Getenv("SQLITE_FILE"))
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := data.MigrateSQLite(db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if applied > 0 {
+		log.Infof("Applied %d database migrations\n", applied)
+	}
+
+	return db, nil
+}
+
+// Run starts the Bot and blocks until ctx is done.
+// Use lifecycle.Shutdown to stop it after that.
+func Run(ctx context.Context) {
 	log.Info("Starting Bot")
 
 	// Start notifier
 	scheduler.StartAsync()
 
+	if err := lifecycle.Go(func() { middleware.SweepThrottle(lifecycle.Context()) }); err != nil {
+		log.Warningf("Error starting throttle sweeping: %s", err)
+	}
+
+	// Fill the schedule cache before receiving the updates, so the first users
+	// after the restart don't wait for the API. Stops early on the shutdown signal
+	if workers, _ := strconv.Atoi(os.Getenv("WARMUP_WORKERS")); workers > 0 {
+		ttl, _ := strconv.Atoi(os.Getenv("WARMUP_CACHE_TTL"))
+		warmUpCache(ctx, workers, time.Duration(ttl)*time.Second)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
 	// Start bot
+	if os.Getenv("UPDATES_MODE") == "webhook" {
+		stop, serveErr, err := startWebhook()
+		if err == nil {
+			select {
+			case <-ctx.Done():
+				lifecycle.OnStop(stop)
+				return
+			case err = <-serveErr:
+			}
+
+			// Received updates are processed before the polling starts
+			if stopErr := stop(); stopErr != nil {
+				log.Warningf("Error stopping webhook: %s", stopErr)
+			}
+		}
+
+		// Polling deletes the webhook, if it was set before
+		log.Warningf("Error receiving updates with webhook, falling back to polling: %s", err)
+	}
+
 	err := updater.StartPolling(bot, &ext.PollingOpts{
 		DropPendingUpdates: true,
 		GetUpdatesOpts: &gotgbot.GetUpdatesOpts{
@@ -250,8 +487,26 @@ func Run() {
 		log.Fatalf("Error starting polling: %s\n", err)
 	}
 
-	// Wait for SIGINT
-	updater.Idle()
+	// Process the received updates after the polling is stopped
+	lifecycle.OnStop(func() error {
+		pool.Stop()
+		return nil
+	})
+
+	<-ctx.Done()
+}
+
+// warmUpCache fetches the current week schedule of the chat groups to the API cache
+func warmUpCache(ctx context.Context, workers int, ttl time.Duration) {
+	groups, err := cache.ChatGroups(chatRepo)
+	if err != nil {
+		log.Errorf("Error getting groups to warm up: %s", err)
+		return
+	}
+
+	if err := cache.NewWarmer(api, workers, ttl).WarmUp(ctx, groups); err != nil {
+		log.Warningf("Error warming up the schedule cache: %s", err)
+	}
 }
 
 type OrderedMap[KT interface{}, VT interface{}] []struct {
@@ -259,40 +514,151 @@ type OrderedMap[KT interface{}, VT interface{}] []struct {
 	Value VT
 }
 
+// buttonActionFilter matches the buttons with exactly the given action,
+// so the actions starting with the same prefix don't shadow each other
+func buttonActionFilter(action string) func(cq *gotgbot.CallbackQuery) bool {
+	return func(cq *gotgbot.CallbackQuery) bool {
+		return utils.ButtonAction(cq.Data) == action
+	}
+}
+
 func setupDispatcherHandlers(dp *ext.Dispatcher) {
 	anyCommandFilter := func(m *gotgbot.Message) bool {
-		return strings.HasPrefix(m.Text, "/")
+		// Commands can also be sent in the file caption, like /restore
+		return strings.HasPrefix(m.Text, "/") || strings.HasPrefix(m.Caption, "/")
+	}
+
+	// Plain text in private chats is a group name to search.
+	// In other chats only after the search button was pressed
+	groupSearchFilter := func(m *gotgbot.Message) bool {
+		if m.Text == "" || strings.HasPrefix(m.Text, "/") {
+			return false
+		}
+		state := inputStates.Get(m.Chat.Id)
+		return state == data.InputGroupSearch || (m.Chat.Type == "private" && state == data.InputNone)
+	}
+
+	// Plain text after the teacher search was started changes the search query
+	teacherSearchFilter := func(m *gotgbot.Message) bool {
+		if m.Text == "" || strings.HasPrefix(m.Text, "/") {
+			return false
+		}
+		return inputStates.Get(m.Chat.Id) == data.InputTeacherSearch
+	}
+
+	// Plain text after the timezone input button was pressed is a timezone name
+	timezoneInputFilter := func(m *gotgbot.Message) bool {
+		if m.Text == "" || strings.HasPrefix(m.Text, "/") {
+			return false
+		}
+		return inputStates.Get(m.Chat.Id) == data.InputTimezone
+	}
+
+	// The bot is added to the group chat, or is back in it
+	botAddedFilter := func(u *gotgbot.ChatMemberUpdated) bool {
+		if u.Chat.Type != "group" && u.Chat.Type != "supergroup" {
+			return false
+		}
+		switch u.OldChatMember.GetStatus() {
+		case "left", "kicked":
+		default:
+			return false
+		}
+		switch u.NewChatMember.GetStatus() {
+		case "member", "administrator":
+			return true
+		default:
+			return false
+		}
 	}
 
 	anyCallbackFilter := func(cq *gotgbot.CallbackQuery) bool {
 		return true
 	}
 
+	anyInlineQueryFilter := func(iq *gotgbot.InlineQuery) bool {
+		return true
+	}
+
 	var buttonsMapping = OrderedMap[string, func(*gotgbot.Bot, *ext.Context) error]{
 		{"open.admin_panel", buttons.HandleAdminPanelButton},
 		{"open.calls", buttons.HandleCallsButton},
 		{"admin.clear_cache", buttons.HandleClearCacheButton},
 		{"admin.clear_logs", buttons.HandleClearLogsButton},
 		{"close_page", buttons.HandleClosePageButton},
+		{"export.calendar", buttons.HandleCalendarExportButton},
 		{"open.info", buttons.HandleInfoButton},
 		{"open.left", buttons.HandleLeftButton},
 		{"open.menu", buttons.HandleMenuButton},
+		{"nav.back", buttons.HandleBackButton},
 		{"open.more", buttons.HandleMoreButton},
+		{"open.webapp", buttons.HandleWebAppButton},
+		{"open.next", buttons.HandleNextLessonButton},
 		{"open.select_group", buttons.HandleOpenSelectGroupButton},
+		{"setup.group_chat", buttons.HandleGroupChatSetupButton},
+		{"open.group_search", buttons.RequireSettingsAccess(buttons.HandleOpenGroupSearchButton)},
 		{"open.select_lang", buttons.HandleOpenSelectLanguageButton},
+		{"open.select_teacher", buttons.HandleOpenSelectTeacherButton},
 		{"open.schedule.day", buttons.HandleScheduleDayButton},
+		{"open.schedule.school_day", buttons.HandleSchoolDayButton},
+		{"open.date_picker", buttons.HandleDatePickerButton},
+		{"noop", buttons.HandleNoopButton},
+		{"show.lesson", buttons.HandleLessonInfoButton},
+		{"alert.no_earlier_data", buttons.HandleNoEarlierDataButton},
+		{"share.schedule", buttons.HandleShareScheduleButton},
+		{"share.group", buttons.HandleShareGroupButton},
 		{"open.schedule.extra", buttons.HandleScheduleExtraButton},
+		{"copy.lesson", buttons.HandleCopyLessonButton},
 		{"open.schedule.today", buttons.HandleScheduleTodayButton},
+		{"open.schedule.teacher", buttons.HandleTeacherScheduleButton},
+		{"open.schedule.week", buttons.HandleScheduleWeekButton},
+		{"open.schedule.overview", buttons.HandleScheduleOverviewButton},
 		{"select.schedule.course", buttons.HandleSelectCourseButton},
 		{"select.schedule.faculty", buttons.HandleSelectFacultyButton},
-		{"select.schedule.group", buttons.HandleSelectGroupButton},
-		{"select.lang", buttons.HandleSelectLanguageButton},
+		{"select.schedule.group", buttons.RequireSettingsAccess(buttons.HandleSelectGroupButton)},
+		{"select.teacher_chair", buttons.HandleSelectTeacherChairButton},
+		{"select.teacher_faculty", buttons.HandleSelectTeacherFacultyButton},
+		{"select.teacher_structure", buttons.HandleSelectTeacherStructureButton},
+		{"select.lang", buttons.RequireSettingsAccess(buttons.HandleSelectLanguageButton)},
 		{"select.schedule.structure", buttons.HandleSelectStructureButton},
-		{"admin.send_logs", buttons.HandleSendLogsButton},
-		{"set.cl_notif_next_part", buttons.HandleSetClassesNotificationsNextPartButton},
-		{"set.cl_notif", buttons.HandleSetClassesNotificationsButton},
+		{"admin.get_logs", buttons.HandleSendLogsButton},
+		{"admin.stats_csv", buttons.HandleStatsCsvButton},
+		{"set.cl_notif_next_part", buttons.RequireSettingsAccess(buttons.HandleSetClassesNotificationsNextPartButton)},
+		{"set.cl_reminder", buttons.RequireSettingsAccess(buttons.HandleSetClassesReminderButton)},
+		{"set.reminder_offset", buttons.RequireSettingsAccess(buttons.HandleSetReminderOffsetButton)},
+		{"set.daily_schedule", buttons.RequireSettingsAccess(buttons.HandleSetDailyScheduleButton)},
+		{"set.daily_time", buttons.RequireSettingsAccess(buttons.HandleSetDailyScheduleTimeButton)},
+		{"set.daily_empty", buttons.RequireSettingsAccess(buttons.HandleSetDailyScheduleEmptyButton)},
+		{"set.notify_changes", buttons.RequireSettingsAccess(buttons.HandleSetNotifyChangesButton)},
+		{"set.hide_empty_lessons", buttons.RequireSettingsAccess(buttons.HandleSetHideEmptyLessonsButton)},
+		{"set.cleanup_pages", buttons.RequireSettingsAccess(buttons.HandleSetCleanupPagesButton)},
+		{"set.strike_hidden_lessons", buttons.RequireSettingsAccess(buttons.HandleSetStrikeHiddenLessonsButton)},
+		{"open.hidden_lessons", buttons.HandleHiddenLessonsButton},
+		{"open.semester_overview", buttons.HandleSemesterOverviewButton},
+		{"open.lesson_reminder", buttons.HandleLessonReminderButton},
+		{"set.lesson_reminder", buttons.RequireSettingsAccess(buttons.HandleSetLessonReminderButton)},
+		{"hide.lesson", buttons.RequireSettingsAccess(buttons.HandleHideLessonButton)},
+		{"unhide.lesson", buttons.RequireSettingsAccess(buttons.HandleUnhideLessonButton)},
+		{"set.settings_lock", buttons.HandleSetSettingsLockButton},
+		{"set.own_group", buttons.HandleSetOwnGroupButton},
+		{"set.cl_notif", buttons.RequireSettingsAccess(buttons.HandleSetClassesNotificationsButton)},
 		{"open.settings", buttons.HandleSettingsButton},
+		{"open.daily_schedule", buttons.HandleDailyScheduleButton},
+		{"open.timezone", buttons.HandleTimezoneButton},
+		{"set.timezone", buttons.RequireSettingsAccess(buttons.HandleSetTimezoneButton)},
+		{"open.timezone_input", buttons.RequireSettingsAccess(buttons.HandleOpenTimezoneInputButton)},
+		{"open.saved_groups", buttons.HandleGroupSwitchButton},
+		{"open.remove_groups", buttons.HandleRemoveSavedGroupsButton},
+		{"set.active_group", buttons.RequireSettingsAccess(buttons.HandleSetActiveGroupButton)},
+		{"set.deep_link", buttons.RequireSettingsAccess(buttons.HandleDeepLinkButton)},
+		{"del.saved_group", buttons.RequireSettingsAccess(buttons.HandleRemoveSavedGroupButton)},
+		{"open.exams", buttons.HandleExamScheduleButton},
+		{"open.free_rooms", buttons.HandleFreeRoomsButton},
+		{"snooze.reminder", buttons.HandleSnoozeReminderButton},
 		{"open.students_list", buttons.HandleStudentsListButton},
+		{"refresh.schedule", buttons.HandleRefreshScheduleButton},
+		{"search.teacher", buttons.HandleTeacherSearchButton},
+		{"search.group", buttons.HandleGroupSearchButton},
 
 		// Note: buttons & commands is being handled by its query prefix.
 		// It means that it's dangerous to have multiple queries with the same prefix,
@@ -300,21 +666,36 @@ func setupDispatcherHandlers(dp *ext.Dispatcher) {
 	}
 
 	var commandsMapping = OrderedMap[string, func(*gotgbot.Bot, *ext.Context) error]{
+		{"addgroup", commands.RequireSettingsAccess(commands.HandleAddGroupCommand)},
+		{"findgroup", commands.HandleFindGroupCommand},
+		{"backup", commands.RequireChatAdmin(commands.HandleBackupCommand)},
+		{"broadcast", commands.HandleBroadcastCommand},
+		{"addholiday", commands.HandleAddHolidayCommand},
+		{"calendar", commands.HandleCalendarCommand},
 		{"calls", commands.HandleCallsCommand},
 		{"c", commands.HandleCallsCommand},
-		{"group", commands.HandleGroupCommand},
-		{"g", commands.HandleGroupCommand},
-		{"lang", commands.HandleLanguageCommand},
-		{"language", commands.HandleLanguageCommand},
+		{"export", commands.HandleExportCommand},
+		{"removegroup", commands.RequireSettingsAccess(commands.HandleRemoveGroupCommand)},
+		{"group", commands.RequireSettingsAccess(commands.HandleGroupCommand)},
+		{"g", commands.RequireSettingsAccess(commands.HandleGroupCommand)},
+		{"identify", commands.RequireSettingsAccess(commands.HandleIdentifyCommand)},
+		{"lang", commands.RequireSettingsAccess(commands.HandleLanguageCommand)},
+		{"language", commands.RequireSettingsAccess(commands.HandleLanguageCommand)},
 		{"left", commands.HandleLeftCommand},
 		{"l", commands.HandleLeftCommand},
+		{"next", commands.HandleNextLessonCommand},
+		{"n", commands.HandleNextLessonCommand},
 		{"settings", commands.HandleSettingsCommand},
 		{"start", commands.HandleStartCommand},
+		{"restore", commands.RequireChatAdmin(commands.HandleRestoreCommand)},
+		{"rooms", commands.HandleRoomsCommand},
 		{"today", commands.HandleTodayCommand},
 		{"t", commands.HandleTodayCommand},
 		{"tomorrow", commands.HandleTomorrowCommand},
 		{"tt", commands.HandleTomorrowCommand},
+		{"stats", commands.HandleStatsCommand},
 		{"students", commands.HandleStudentsCommand},
+		{"teacher", commands.HandleTeacherCommand},
 	}
 
 	// Here is handlers distribution by priority:
@@ -324,33 +705,84 @@ func setupDispatcherHandlers(dp *ext.Dispatcher) {
 	//   0: Buttons and commands
 	//  40: Save bot interaction to statistics
 
-	// Log update
-	dp.AddHandlerToGroup(handlers.NewMessage(anyCommandFilter, func(b *gotgbot.Bot, ctx *ext.Context) error {
-		log.Infof("Handling command %s from %s\n", ctx.EffectiveMessage.Text, ctx.EffectiveUser.FirstName)
-		return nil
-	}), -20)
-	dp.AddHandlerToGroup(handlers.NewCallback(anyCallbackFilter, func(b *gotgbot.Bot, ctx *ext.Context) error {
-		log.Infof("Handling button %s from %s\n", ctx.Update.CallbackQuery.Data, ctx.EffectiveUser.FirstName)
-		return nil
-	}), -20)
+	// Log update with the request logger, kept for the next handlers of the update
+	dp.AddHandlerToGroup(handlers.NewMessage(anyCommandFilter, middleware.Chain(LogUpdate, middleware.RequestLogger)), -20)
+	dp.AddHandlerToGroup(handlers.NewMessage(groupSearchFilter, middleware.Chain(LogUpdate, middleware.RequestLogger)), -20)
+	dp.AddHandlerToGroup(handlers.NewMessage(teacherSearchFilter, middleware.Chain(LogUpdate, middleware.RequestLogger)), -20)
+	dp.AddHandlerToGroup(handlers.NewMessage(timezoneInputFilter, middleware.Chain(LogUpdate, middleware.RequestLogger)), -20)
+	dp.AddHandlerToGroup(handlers.NewCallback(anyCallbackFilter, middleware.Chain(LogUpdate, middleware.RequestLogger)), -20)
+	dp.AddHandlerToGroup(handlers.NewInlineQuery(anyInlineQueryFilter, middleware.Chain(LogUpdate, middleware.RequestLogger)), -20)
 
 	// Init database records
 	dp.AddHandlerToGroup(handlers.NewMessage(anyCommandFilter, InitDatabaseRecords), -10)
+	dp.AddHandlerToGroup(handlers.NewMessage(groupSearchFilter, InitDatabaseRecords), -10)
+	dp.AddHandlerToGroup(handlers.NewMessage(teacherSearchFilter, InitDatabaseRecords), -10)
+	dp.AddHandlerToGroup(handlers.NewMessage(timezoneInputFilter, InitDatabaseRecords), -10)
 	dp.AddHandlerToGroup(handlers.NewCallback(anyCallbackFilter, InitDatabaseRecords), -10)
+	dp.AddHandlerToGroup(handlers.NewMyChatMember(botAddedFilter, InitDatabaseRecords), -10)
 	// Save interaction to statistics
 	dp.AddHandlerToGroup(handlers.NewMessage(anyCommandFilter, CommandStatisticHandler), 40)
 	dp.AddHandlerToGroup(handlers.NewCallback(anyCallbackFilter, ButtonStatisticHandler), 40)
 
+	// Limit the number of updates per user to protect the university API,
+	// and per chat to not exceed the Telegram limits
+	rateLimit := middleware.RateLimit(middleware.RateLimits{
+		Users:  envLimiter("RATE_LIMIT_BURST", "RATE_LIMIT_RATE"),
+		Chats:  envLimiter("CHAT_RATE_LIMIT_BURST", "CHAT_RATE_LIMIT_RATE"),
+		Admins: envLimiter("RATE_LIMIT_ADMIN_BURST", "RATE_LIMIT_ADMIN_RATE"),
+	}, chatRepo, languages)
+
+	// Stop the university API requests of the updates handled too long
+	updateTimeout, err := strconv.Atoi(os.Getenv("UPDATE_TIMEOUT"))
+	if err != nil {
+		log.Fatalf("Error parsing UPDATE_TIMEOUT: %s\n", err)
+	}
+	timeout := middleware.Timeout(time.Duration(updateTimeout) * time.Second)
+
 	// Buttons
+	actions := make([]string, 0, len(buttonsMapping))
+	for _, entry := range buttonsMapping {
+		actions = append(actions, entry.Key)
+	}
+	utils.RegisterButtonActions(actions...)
+
 	for _, entry := range buttonsMapping {
-		dp.AddHandlerToGroup(handlers.NewCallback(callbackquery.Prefix(entry.Key), entry.Value), 0)
+		dp.AddHandlerToGroup(handlers.NewCallback(buttonActionFilter(entry.Key), middleware.Chain(logHandler(entry.Key, entry.Value), middleware.RequestLogger, middleware.AnswerCallbacks, metrics.CountUpdates("button"), metrics.CountCallbacks, lifecycle.Track, middleware.DeduplicateCallbacks, middleware.ThrottleCallbacks, rateLimit, timeout)), 0)
 	}
 
 	// Commands
 	for _, entry := range commandsMapping {
-		dp.AddHandlerToGroup(handlers.NewCommand(entry.Key, entry.Value), 0)
+		knownCommands = append(knownCommands, entry.Key)
+		dp.AddHandlerToGroup(handlers.NewCommand(entry.Key, middleware.Chain(logHandler("/"+entry.Key, entry.Value), middleware.RequestLogger, metrics.CountUpdates("command"), lifecycle.Track, rateLimit, timeout)), 0)
 	}
 
+	// Group search by name
+	dp.AddHandlerToGroup(handlers.NewMessage(groupSearchFilter, middleware.Chain(logHandler("group_search", commands.HandleGroupSearchMessage), middleware.RequestLogger, metrics.CountUpdates("message"), lifecycle.Track, rateLimit, timeout)), 0)
+
+	// Teacher search query
+	dp.AddHandlerToGroup(handlers.NewMessage(teacherSearchFilter, middleware.Chain(logHandler("teacher_search", commands.HandleTeacherSearchMessage), middleware.RequestLogger, metrics.CountUpdates("message"), lifecycle.Track, rateLimit, timeout)), 0)
+
+	// Timezone input
+	dp.AddHandlerToGroup(handlers.NewMessage(timezoneInputFilter, middleware.Chain(logHandler("timezone_input", commands.HandleTimezoneMessage), middleware.RequestLogger, metrics.CountUpdates("message"), lifecycle.Track, rateLimit, timeout)), 0)
+
+	// Group chat setup
+	dp.AddHandlerToGroup(handlers.NewMyChatMember(botAddedFilter, middleware.Chain(logHandler("bot_added", commands.HandleBotAddedToGroup), middleware.RequestLogger, metrics.CountUpdates("my_chat_member"), lifecycle.Track)), 0)
+
+	// Inline queries
+	dp.AddHandlerToGroup(handlers.NewInlineQuery(anyInlineQueryFilter, middleware.Chain(logHandler("inline", inline.HandleInlineQuery), middleware.RequestLogger, metrics.CountUpdates("inline"), lifecycle.Track, timeout)), 0)
+
 	// Unsupported button
-	dp.AddHandlerToGroup(handlers.NewCallback(anyCallbackFilter, buttons.HandleUnsupportedButton), 0)
+	dp.AddHandlerToGroup(handlers.NewCallback(anyCallbackFilter, middleware.Chain(logHandler("unsupported", buttons.HandleUnsupportedButton), middleware.RequestLogger, middleware.AnswerCallbacks)), 0)
+}
+
+// envLimiter creates the rate limiter with the burst and the rate
+// from the env variables, or returns nil if the burst is 0
+func envLimiter(burstEnv string, rateEnv string) *ratelimit.Limiter {
+	burst, _ := strconv.Atoi(os.Getenv(burstEnv))
+	if burst <= 0 {
+		return nil
+	}
+
+	rate, _ := strconv.ParseFloat(os.Getenv(rateEnv), 64)
+	return ratelimit.NewLimiter(burst, rate)
 }
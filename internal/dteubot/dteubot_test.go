@@ -0,0 +1,57 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package dteubot
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"testing"
+)
+
+func TestButtonActionFilter(t *testing.T) {
+	utils.RegisterButtonActions("open.schedule.day", "open.schedule.day_extra", "open.schedule")
+
+	tests := []struct {
+		name   string
+		action string
+		data   string
+		want   bool
+	}{
+		{"same action", "open.schedule.day", "open.schedule.day", true},
+		{"same action with params", "open.schedule.day", utils.NewButtonData("open.schedule.day").Set("date", "2024-10-27").String(), true},
+		{"longer action with the same prefix", "open.schedule.day", "open.schedule.day_extra", false},
+		{"shorter action", "open.schedule.day", "open.schedule", false},
+		{"prefix of the action", "open.schedule", "open.schedule.day", false},
+		{"unknown action", "open.schedule.day", "open.schedule.week", false},
+		{"invalid data", "open.schedule.day", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := buttonActionFilter(tt.action)
+			if got := filter(&gotgbot.CallbackQuery{Data: tt.data}); got != tt.want {
+				t.Errorf("buttonActionFilter(%q)(%q) = %v, want %v", tt.action, tt.data, got, tt.want)
+			}
+		})
+	}
+}
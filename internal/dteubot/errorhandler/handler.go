@@ -23,6 +23,7 @@
 package errorhandler
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/PaulSonOfLars/gotgbot/v2"
@@ -34,6 +35,7 @@ import (
 	"github.com/cubicbyte/dteubot/pkg/api"
 	"github.com/op/go-logging"
 	"github.com/sirkon/go-format/v2"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
@@ -64,40 +66,77 @@ func HandleError(b *gotgbot.Bot, ctx *ext.Context, err error) ext.DispatcherActi
 		return ext.DispatcherActionNoop
 	}
 
+	fields := errorFields(ctx, err)
+	logger := errorLogger(ctx, err)
+	logger.Warn("Error handling update", "error", err)
+
 	var urlError *url.Error
 	var httpApiError *api.HTTPApiError
 	var tgError *gotgbot.TelegramError
 
 	// Get update chat data
 	if ctx.EffectiveChat == nil {
-		log.Errorf("Error %T with no chat: %s", err, err)
+		logger.Error("Error with no chat", "type", fmt.Sprintf("%T", err), "error", err)
 		SendErrorToTelegram(err, b)
 		return ext.DispatcherActionEndGroups
 	}
 
 	chatData, err2 := chatRepo.GetById(ctx.EffectiveChat.Id)
 	if err2 != nil {
-		log.Errorf("Error getting chat data: %s", err2)
+		logger.Error("Error getting chat data", "error", err2)
 		SendErrorToTelegram(err2, b)
 		return ext.DispatcherActionNoop
 	}
 
+	if chatData == nil {
+		// Chat record was not created, ask to restart the bot
+		logger.Error("Chat not found")
+		SendUpdateErrorToTelegram(err, fields, b)
+
+		lang, err2 := utils.GetLang("", langs)
+		if err2 != nil {
+			logger.Error("Error getting language", "error", err2)
+			return ext.DispatcherActionEndGroups
+		}
+
+		page, err2 := pages.CreateChatNotFoundPage(lang)
+		if err2 != nil {
+			logger.Error("Error creating chat not found page", "error", err2)
+			return ext.DispatcherActionEndGroups
+		}
+
+		SendPageToChat(ctx, b, &page)
+		return ext.DispatcherActionEndGroups
+	}
+
 	// Get chat language
 	lang, err2 := utils.GetLang(chatData.LanguageCode, langs)
 	if err2 != nil {
-		log.Errorf("Error getting language: %s", err2)
+		logger.Error("Error getting language", "error", err2)
 		SendErrorToTelegram(err2, b)
 		return ext.DispatcherActionNoop
 	}
 
 	switch {
-	case errors.As(err, &urlError):
-		// Can't make request to the university API
+	case errors.Is(err, api.ErrAPIUnavailable):
+		// Circuit breaker is open, the API is down for some time
 
-		// Send "API unavailable" page
-		page, err := pages.CreateApiUnavailablePage(lang)
+		page, err := pages.CreateAPIUnavailablePage(lang)
 		if err != nil {
-			log.Errorf("Error creating api unavailable page: %s", err)
+			logger.Error("Error creating api unavailable page", "error", err)
+			SendErrorToTelegram(err, b)
+			break
+		}
+
+		SendPageToChat(ctx, b, &page)
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &urlError):
+		// Can't make request to the university API,
+		// or it did not respond in the update timeout
+
+		// Send "API not responding" page
+		page, err := pages.CreateAPINotRespondingPage(lang)
+		if err != nil {
+			logger.Error("Error creating api not responding page", "error", err)
 			SendErrorToTelegram(err, b)
 			break
 		}
@@ -106,23 +145,23 @@ func HandleError(b *gotgbot.Bot, ctx *ext.Context, err error) ext.DispatcherActi
 	case errors.As(err, &httpApiError):
 		// Non-200 api status code
 
-		log.Warningf("Api %d error: %s", httpApiError.Code, httpApiError.Body)
+		logger.Warn("Api error", "code", httpApiError.Code, "body", httpApiError.Body)
 
 		var page = pages.Page{}
 		var pageErr error
 
 		switch httpApiError.Code {
 		case http.StatusUnauthorized:
-			page, pageErr = pages.CreateForbiddenPage(lang, "open.menu#from=unauthorized")
+			page, pageErr = pages.CreateForbiddenPage(lang, utils.NewButtonData("open.menu").Set("from", "unauthorized").String())
 
 		case http.StatusInternalServerError:
-			page, pageErr = pages.CreateApiUnavailablePage(lang)
+			page, pageErr = pages.CreateAPINotRespondingPage(lang)
 
 		case http.StatusForbidden:
-			page, pageErr = pages.CreateForbiddenPage(lang, "open.menu#from=forbidden")
+			page, pageErr = pages.CreateForbiddenPage(lang, utils.NewButtonData("open.menu").Set("from", "forbidden").String())
 
 		case http.StatusNotFound:
-			page, pageErr = pages.CreateNotFoundPage(lang, "open.menu#from=not_found")
+			page, pageErr = pages.CreateNotFoundPage(lang, utils.NewButtonData("open.menu").Set("from", "not_found").String())
 
 		case http.StatusUnprocessableEntity:
 			// Request body is invalid: incorrect group id, etc
@@ -133,29 +172,29 @@ func HandleError(b *gotgbot.Bot, ctx *ext.Context, err error) ext.DispatcherActi
 				default:
 					// Unknown field
 					page, pageErr = pages.CreateErrorPage(lang)
-					log.Errorf("Unknown validation error field: %s", field.Field)
+					logger.Error("Unknown validation error field", "field", field.Field)
 					SendErrorToTelegram(err, b)
 				}
 			}
 			if page.Text == "" {
 				// No fields in ValidationError
 				page, pageErr = pages.CreateErrorPage(lang)
-				log.Errorf("No fields in ValidationError: %s", err)
+				logger.Error("No fields in ValidationError", "error", err)
 				SendErrorToTelegram(err, b)
 			}
 
 		default:
 			// Unknown error
-			page, pageErr = pages.CreateApiUnavailablePage(lang)
+			page, pageErr = pages.CreateAPINotRespondingPage(lang)
 
 			if httpApiError.Code/100 != 5 {
-				log.Errorf("Unknown API http status code %d: %s", httpApiError.Code, httpApiError.Body)
+				logger.Error("Unknown API http status code", "code", httpApiError.Code, "body", httpApiError.Body)
 				SendErrorToTelegram(err, b)
 			}
 		}
 
 		if pageErr != nil {
-			log.Errorf("Error creating HTTPApiError page: %s", pageErr)
+			logger.Error("Error creating HTTPApiError page", "error", pageErr)
 			SendErrorToTelegram(pageErr, b)
 			break
 		}
@@ -171,16 +210,16 @@ func HandleError(b *gotgbot.Bot, ctx *ext.Context, err error) ext.DispatcherActi
 			chatData.Accessible = false
 			err = chatRepo.Update(chatData)
 			if err != nil {
-				log.Errorf("Error updating chat data: %s", err)
+				logger.Error("Error updating chat data", "error", err)
 				SendErrorToTelegram(err, b)
 				break
 			}
 		case 429:
 			// Too Many Requests, sometimes occurs when bot API is lagging
-			log.Warningf("Too many requests, retry after %d: %s", tgError.ResponseParams.RetryAfter, err)
+			logger.Warn("Too many requests", "retry_after", tgError.ResponseParams.RetryAfter, "error", err)
 		case 420:
 			// Flood control exceeded
-			log.Warningf("Flood control exceeded, retry after %d: %s", tgError.ResponseParams.RetryAfter, err)
+			logger.Warn("Flood control exceeded", "retry_after", tgError.ResponseParams.RetryAfter, "error", err)
 
 			// Send alert to chat
 			if ctx.CallbackQuery == nil {
@@ -194,60 +233,120 @@ func HandleError(b *gotgbot.Bot, ctx *ext.Context, err error) ext.DispatcherActi
 			})
 
 			if err != nil {
-				log.Errorf("Error sending flood control alert: %s", err)
+				logger.Error("Error sending flood control alert", "error", err)
 				break
 			}
 		case 400:
 			// Bad request
 			// TODO: test this
-			if strings.HasPrefix(tgError.Description, "Bad Request: message is not modified") {
+			if utils.IsMessageNotModified(err) {
 				// We are trying to edit message with no changes,
 				// probably because of lag. Just send warning to log.
-				log.Warningf("Message is not modified: %s", err)
+				logger.Warn("Message is not modified", "error", err)
+				answerCallback(ctx, b)
 				break
 			}
 
 			if strings.HasPrefix(tgError.Description, "Bad Request: message to edit not found") {
 				// Message to edit not found
-				log.Warningf("Message to edit not found: %s", err)
+				logger.Warn("Message to edit not found", "error", err)
 				break
 			}
 
 			if strings.HasPrefix(tgError.Description, "Bad Request: message can't be deleted for everyone") {
 				// We are trying to delete message that is older than 48 hours
-				log.Warningf("Message can't be deleted for everyone: %s", err)
+				logger.Warn("Message can't be deleted for everyone", "error", err)
 				break
 			}
 
 			if strings.HasPrefix(tgError.Description, "Bad Request: message to delete not found") {
 				// Probably user tried to delete message that is already deleted
-				log.Warningf("Message to delete not found: %s", err)
+				logger.Warn("Message to delete not found", "error", err)
 				break
 			}
 
 			// Unknown Telegram API error
-			log.Errorf("Unknown bad request error: %s", err)
-			SendErrorToTelegram(err, b)
+			logger.Error("Unknown bad request error", "error", err)
+			SendUpdateErrorToTelegram(err, fields, b)
 			SendErrorPageToChat(ctx, b, lang)
 
 		default:
 			// Unknown Telegram API error
-			log.Errorf("Unknown Telegram API %d error: %s", tgError.Code, err)
-			SendErrorToTelegram(err, b)
+			logger.Error("Unknown Telegram API error", "code", tgError.Code, "error", err)
+			SendUpdateErrorToTelegram(err, fields, b)
 			SendErrorPageToChat(ctx, b, lang)
 		}
 
+	case errors.Is(err, utils.ErrInvalidButtonData):
+		// Button is created by the older version of the bot
+		logger.Warn("Invalid button data", "error", err)
+
+		if ctx.CallbackQuery == nil {
+			break
+		}
+
+		_, err = b.AnswerCallbackQuery(ctx.CallbackQuery.Id, &gotgbot.AnswerCallbackQueryOpts{
+			Text:      lang.Alert.ButtonOutdated,
+			ShowAlert: true,
+		})
+		if err != nil {
+			logger.Error("Error sending button outdated alert", "error", err)
+		}
+
 	default:
 		// Unknown error
-		log.Errorf("Unknown error: %s", err.Error())
-		SendErrorToTelegram(err, b)
+		logger.Error("Unknown error", "error", err)
+		SendUpdateErrorToTelegram(err, fields, b)
 		SendErrorPageToChat(ctx, b, lang)
 	}
 
 	return ext.DispatcherActionEndGroups
 }
 
+// errorLogger returns the update logger with the fields of the failed update:
+// the handler name, its duration and the button data
+func errorLogger(ctx *ext.Context, err error) *slog.Logger {
+	logger := utils.Logger(ctx)
+
+	var handlerErr *HandlerError
+	if errors.As(err, &handlerErr) {
+		logger = logger.With("handler", handlerErr.Handler, "duration", handlerErr.Duration)
+	}
+	if ctx.CallbackQuery != nil {
+		logger = logger.With("data", ctx.CallbackQuery.Data)
+	}
+
+	return logger
+}
+
+// errorFields returns the fields of the failed update for the error reports:
+// the update fields, the handler name, its duration and the button data
+func errorFields(ctx *ext.Context, err error) string {
+	fields := utils.LogFields(ctx)
+
+	var handlerErr *HandlerError
+	if errors.As(err, &handlerErr) {
+		fields += fmt.Sprintf(" handler=%s duration=%s", handlerErr.Handler, handlerErr.Duration)
+	}
+	if ctx.CallbackQuery != nil {
+		fields += fmt.Sprintf(" data=%q", ctx.CallbackQuery.Data)
+	}
+
+	return fields
+}
+
 func SendErrorToTelegram(err error, bot *gotgbot.Bot) {
+	sendToLogChat(fmt.Sprintf("Error %T: %s", err, err), bot)
+}
+
+// SendUpdateErrorToTelegram sends the unexpected error to the log chat
+// together with the update fields and the chain of the wrapped errors
+func SendUpdateErrorToTelegram(err error, fields string, bot *gotgbot.Bot) {
+	sendToLogChat(fmt.Sprintf("Error handling update %s\n\n%s", fields, errorChain(err)), bot)
+}
+
+// sendToLogChat sends the text to the LOG_CHAT_ID chat, if it's set
+func sendToLogChat(text string, bot *gotgbot.Bot) {
 	// Don't send errors too often
 	if time.Since(lastErrorTime) < ErrorSendDelay {
 		return
@@ -259,18 +358,16 @@ func SendErrorToTelegram(err error, bot *gotgbot.Bot) {
 		return
 	}
 
-	chatId, err2 := strconv.ParseInt(chatIdStr, 10, 64)
-	if err2 != nil {
-		log.Errorf("Error parsing LOG_CHAT_ID: %s", err2)
+	chatId, err := strconv.ParseInt(chatIdStr, 10, 64)
+	if err != nil {
+		log.Errorf("Error parsing LOG_CHAT_ID: %s", err)
 		return
 	}
 
 	// Send error to Telegram
-	errStr := fmt.Sprintf("Error %T: %s", err, err)
-
-	_, err2 = bot.SendMessage(chatId, errStr, nil)
-	if err2 != nil {
-		log.Errorf("Error sending error to Telegram: %s", err2)
+	_, err = bot.SendMessage(chatId, text, nil)
+	if err != nil {
+		log.Errorf("Error sending error to Telegram: %s", err)
 	}
 
 	lastErrorTime = time.Now()
@@ -293,20 +390,37 @@ func SendErrorPageToChat(ctx2 *ext.Context, bot *gotgbot.Bot, lang i18n.Language
 }
 
 // SendPageToChat sends the given page to the chat of the given update.
-// It either edits the message or sends a new one.
+// It either edits the message or sends a new one, if the update
+// is not a button press or the message is deleted.
 func SendPageToChat(ctx2 *ext.Context, bot *gotgbot.Bot, page *pages.Page) {
 	var err error
 
 	if ctx2.CallbackQuery != nil {
 		opts := page.CreateEditMessageOpts(ctx2.EffectiveChat.Id, ctx2.EffectiveMessage.MessageId)
 		_, _, err = bot.EditMessageText(page.Text, &opts)
-	} else {
+	}
+
+	// Message is deleted by the user, send the page as a new one
+	if ctx2.CallbackQuery == nil || utils.IsMessageToEditNotFound(err) {
 		opts := page.CreateSendMessageOpts()
 		_, err = bot.SendMessage(ctx2.EffectiveChat.Id, page.Text, &opts)
 	}
 
-	if err != nil {
+	if err != nil && !utils.IsMessageNotModified(err) {
 		log.Errorf("Error sending page: %s", err)
 		SendErrorToTelegram(err, bot)
 	}
+
+	answerCallback(ctx2, bot)
+}
+
+// answerCallback stops the button loading animation
+func answerCallback(ctx2 *ext.Context, bot *gotgbot.Bot) {
+	if ctx2.CallbackQuery == nil {
+		return
+	}
+
+	if _, err := bot.AnswerCallbackQuery(ctx2.CallbackQuery.Id, nil); err != nil {
+		log.Warningf("Error answering callback query: %s", err)
+	}
 }
@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package errorhandler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HandlerError is the error returned by the update handler,
+// annotated with the handler name and how long it ran.
+// It's logged by HandleError together with the update fields.
+type HandlerError struct {
+	Handler  string
+	Duration time.Duration
+	Err      error
+}
+
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Handler, e.Err)
+}
+
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// errorChain returns the types and messages of the wrapped errors,
+// one per line, starting from the outermost one.
+// Go errors carry no stack trace, so this is the closest thing to it.
+func errorChain(err error) string {
+	var sb strings.Builder
+	for i := 0; err != nil; i++ {
+		fmt.Fprintf(&sb, "%d. %T: %s\n", i, err, err)
+		err = unwrap(err)
+	}
+	return sb.String()
+}
+
+// unwrap returns the first wrapped error, if any
+func unwrap(err error) error {
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return e.Unwrap()
+	case interface{ Unwrap() []error }:
+		if errs := e.Unwrap(); len(errs) > 0 {
+			return errs[0]
+		}
+	}
+	return nil
+}
@@ -31,14 +31,24 @@ import (
 )
 
 // PanicsHandler handles panics in the code and sends them to the user.
+// The dispatcher recovers the panic, so the next updates are still processed.
 //
 // Implements the ext.DispatcherPanicHandler interface.
 func PanicsHandler(b *gotgbot.Bot, ctx *ext.Context, r interface{}) {
-	log.Errorf("Panic: %s\n%s", r, string(debug.Stack()))
+	stack := string(debug.Stack())
+	utils.Logger(ctx).Error("Panic", "panic", r, "stack", stack)
+
+	// Handler of the panic must not panic too, it would crash the bot
+	defer func() {
+		if r2 := recover(); r2 != nil {
+			utils.Logger(ctx).Error("Panic while handling panic", "panic", r2, "stack", string(debug.Stack()))
+		}
+	}()
 
 	if ctx.EffectiveChat == nil {
 		// Send error to the developer
-		SendErrorToTelegram(fmt.Errorf("panic: %s\n%s", r, string(debug.Stack())), b)
+		SendErrorToTelegram(fmt.Errorf("panic: %s\n%s", r, stack), b)
+		answerCallback(ctx, b)
 		return
 	}
 
@@ -47,16 +57,24 @@ func PanicsHandler(b *gotgbot.Bot, ctx *ext.Context, r interface{}) {
 	if err != nil {
 		log.Errorf("Error getting chat: %s\n", err)
 		SendErrorToTelegram(err, b)
+		answerCallback(ctx, b)
 		return
 	}
 
-	lang, err := utils.GetLang(chat.LanguageCode, langs)
+	// Chat record may be not created yet, use the default language then
+	langCode := ""
+	if chat != nil {
+		langCode = chat.LanguageCode
+	}
+
+	lang, err := utils.GetLang(langCode, langs)
 	if err != nil {
 		log.Errorf("Error getting language: %s\n", err)
 		SendErrorToTelegram(err, b)
+		answerCallback(ctx, b)
 		return
 	}
 
 	SendErrorPageToChat(ctx, b, lang)
-	SendErrorToTelegram(fmt.Errorf("panic: %s\n%s", r, string(debug.Stack())), b)
+	SendErrorToTelegram(fmt.Errorf("panic: %s\n%s", r, stack), b)
 }
@@ -0,0 +1,152 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package errorhandler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/middleware"
+	"github.com/cubicbyte/dteubot/internal/dteubot/workerpool"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClient is the bot client that records the requests instead of sending them
+type fakeClient struct {
+	gotgbot.BaseBotClient
+	mu       sync.Mutex
+	requests []string
+}
+
+func (c *fakeClient) RequestWithContext(_ context.Context, _ string, method string, params map[string]string, _ map[string]gotgbot.NamedReader, _ *gotgbot.RequestOpts) (json.RawMessage, error) {
+	c.mu.Lock()
+	c.requests = append(c.requests, method+" "+params["callback_query_id"])
+	c.mu.Unlock()
+
+	if method == "answerCallbackQuery" {
+		return json.RawMessage("true"), nil
+	}
+	return json.RawMessage(`{"message_id":1,"date":0,"chat":{"id":1,"type":"private"}}`), nil
+}
+
+func (c *fakeClient) called(request string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, r := range c.requests {
+		if r == request {
+			return true
+		}
+	}
+	return false
+}
+
+func callbackUpdate(id int64, queryData string) json.RawMessage {
+	update := gotgbot.Update{
+		UpdateId: id,
+		CallbackQuery: &gotgbot.CallbackQuery{
+			Id:   queryData,
+			From: gotgbot.User{Id: 1},
+			Message: &gotgbot.Message{
+				MessageId: 1,
+				Chat:      gotgbot.Chat{Id: 1, Type: "private"},
+			},
+			Data: queryData,
+		},
+	}
+
+	raw, err := json.Marshal(update)
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+func TestPanicsHandler(t *testing.T) {
+	t.Setenv("DEFAULT_LANG", "en")
+
+	langs2, err := i18n.LoadLangs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	Setup(langs2, data.NewMemoryChatRepository())
+
+	client := &fakeClient{}
+	bot := &gotgbot.Bot{Token: "test", BotClient: middleware.TrackAnswers(client)}
+
+	// Same as in dteubot.Run
+	dispatcher := ext.NewDispatcher(&ext.DispatcherOpts{
+		Panic: func(b *gotgbot.Bot, ctx *ext.Context, r interface{}) {
+			defer middleware.AnswerPending(b, ctx)
+			PanicsHandler(b, ctx, r)
+		},
+	})
+
+	var handled sync.Map
+	dispatcher.AddHandler(handlers.NewCallback(nil, middleware.AnswerCallbacks(func(bot *gotgbot.Bot, ctx *ext.Context) error {
+		if ctx.CallbackQuery.Data == "panic" {
+			var params map[string]string
+			params["nil"] = "map"
+		}
+
+		handled.Store(ctx.CallbackQuery.Data, true)
+		return nil
+	})))
+
+	// Updates of the same chat are processed one by one by the same worker
+	pool := workerpool.NewPool(dispatcher, 1, time.Second)
+	updates := make(chan json.RawMessage)
+	done := make(chan struct{})
+	go func() {
+		pool.Start(bot, updates)
+		close(done)
+	}()
+
+	updates <- callbackUpdate(1, "panic")
+	updates <- callbackUpdate(2, "next")
+	close(updates)
+	<-done
+	pool.Stop()
+
+	if _, ok := handled.Load("next"); !ok {
+		t.Error("update after the panic is not processed")
+	}
+	if _, ok := handled.Load("panic"); ok {
+		t.Error("panicking handler is not stopped")
+	}
+	if !client.called("editMessageText ") {
+		t.Error("error page is not shown")
+	}
+	if !client.called("answerCallbackQuery panic") {
+		t.Error("callback query of the panicking handler is not answered")
+	}
+	if !client.called("answerCallbackQuery next") {
+		t.Error("callback query of the next update is not answered")
+	}
+}
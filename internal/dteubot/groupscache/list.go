@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package groupscache
+
+import (
+	"github.com/cubicbyte/dteubot/pkg/api"
+	"sync"
+	"time"
+)
+
+// ListTTL is how long the full groups list is used before it's fetched again
+const ListTTL = 24 * time.Hour
+
+// List is the list of the groups of all the structures, faculties
+// and courses, fetched from the API at once to search through it
+type List struct {
+	api     api.Api
+	ttl     time.Duration
+	groups  []Group
+	updated time.Time
+	mu      sync.Mutex
+
+	// fetching is closed when the running fetch is done, nil if there is none
+	fetching chan struct{}
+	fetchErr error
+}
+
+// NewList creates the groups list that is fetched again after ttl
+func NewList(api2 api.Api, ttl time.Duration) *List {
+	return &List{
+		api: api2,
+		ttl: ttl,
+	}
+}
+
+// Get returns all the groups.
+//
+// The list is fetched without holding the lock, concurrent calls wait
+// for the same fetch instead of starting their own.
+//
+// If the list is outdated and the API is unavailable, the outdated
+// list is returned along with the time it was fetched at.
+// Otherwise, returns zero time.
+func (l *List) Get() ([]Group, time.Time, error) {
+	l.mu.Lock()
+	if l.groups != nil && time.Since(l.updated) < l.ttl {
+		defer l.mu.Unlock()
+		return l.groups, time.Time{}, nil
+	}
+
+	fetching := l.fetching
+	if fetching == nil {
+		fetching = make(chan struct{})
+		l.fetching = fetching
+		l.mu.Unlock()
+
+		groups, err := l.fetch()
+
+		l.mu.Lock()
+		if err == nil {
+			l.groups = groups
+			l.updated = time.Now()
+		}
+		l.fetchErr = err
+		l.fetching = nil
+		close(fetching)
+	} else {
+		l.mu.Unlock()
+		<-fetching
+		l.mu.Lock()
+	}
+	defer l.mu.Unlock()
+
+	if l.fetchErr != nil {
+		if l.groups == nil {
+			return nil, time.Time{}, l.fetchErr
+		}
+
+		log.Warningf("Error updating the groups list, using the one from %s: %s", l.updated.Format(time.DateTime), l.fetchErr)
+		return l.groups, l.updated, nil
+	}
+
+	return l.groups, time.Time{}, nil
+}
+
+// fetch requests the groups of every structure, faculty and course
+func (l *List) fetch() ([]Group, error) {
+	structures, err := l.api.GetStructures()
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]Group, 0)
+	now := time.Now().Unix()
+
+	for _, structure := range structures {
+		faculties, err := l.api.GetFaculties(structure.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, faculty := range faculties {
+			courses, err := l.api.GetCourses(faculty.Id)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, course := range courses {
+				courseGroups, err := l.api.GetGroups(faculty.Id, course.Course)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, group := range courseGroups {
+					groups = append(groups, Group{
+						Id:        group.Id,
+						Name:      group.Name,
+						Course:    group.Course,
+						FacultyId: faculty.Id,
+						Updated:   now,
+					})
+				}
+			}
+		}
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package groupscache
+
+import (
+	"errors"
+	"github.com/cubicbyte/dteubot/pkg/api"
+	"sync"
+	"time"
+)
+
+// StudentIndexTTL is how often the students index should be built again
+const StudentIndexTTL = 24 * time.Hour
+
+// StudentIndex finds the groups of the students by the student ids.
+//
+// The API has no endpoint to find the student, so the index is built from
+// the students lists of all the groups of the List. Student id is
+// sensitive, so it is never included into the returned errors or logs.
+type StudentIndex struct {
+	api    api.Api
+	list   *List
+	groups map[int][]Group
+	mu     sync.Mutex
+	// building is locked while the index is built, so it's built once at a time
+	building sync.Mutex
+}
+
+// NewStudentIndex creates the students index of the groups of the list.
+// The index is empty until it's built by Update or the first Find.
+func NewStudentIndex(api2 api.Api, list *List) *StudentIndex {
+	return &StudentIndex{
+		api:  api2,
+		list: list,
+	}
+}
+
+// Find returns the groups of the student with the given id.
+// A student can study in multiple groups, for example after the transfer
+// or on the double major. Returns an empty slice if the student is not found.
+//
+// If the index is not built yet, it's built first, which takes a while.
+func (i *StudentIndex) Find(studentId int) ([]Group, error) {
+	groups, err := i.get()
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]Group, len(groups[studentId]))
+	copy(found, groups[studentId])
+	return found, nil
+}
+
+// Update builds the index again, so the students moved to other groups are found.
+// Groups whose students can't be fetched are skipped. Returns an error
+// if the index can't be built, the old one is kept then.
+func (i *StudentIndex) Update() error {
+	i.building.Lock()
+	defer i.building.Unlock()
+
+	return i.build()
+}
+
+// get returns the index, building it if it's not built yet
+func (i *StudentIndex) get() (map[int][]Group, error) {
+	i.mu.Lock()
+	groups := i.groups
+	i.mu.Unlock()
+	if groups != nil {
+		return groups, nil
+	}
+
+	i.building.Lock()
+	defer i.building.Unlock()
+
+	// The index could be built while waiting
+	i.mu.Lock()
+	groups = i.groups
+	i.mu.Unlock()
+	if groups != nil {
+		return groups, nil
+	}
+
+	if err := i.build(); err != nil {
+		return nil, err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.groups, nil
+}
+
+// build fetches the students of every group. Must be called with building locked.
+func (i *StudentIndex) build() error {
+	started := time.Now()
+
+	groups, _, err := i.list.Get()
+	if err != nil {
+		return err
+	}
+
+	index := make(map[int][]Group)
+	received := 0
+	for _, group := range groups {
+		students, err := i.api.GetGroupStudents(group.Id)
+		if err != nil {
+			log.Warningf("Error getting students of group %d, skipping it: %s", group.Id, err)
+			continue
+		}
+		received++
+
+		for _, student := range students {
+			index[student.Id] = append(index[student.Id], group)
+		}
+	}
+
+	if received == 0 && len(groups) > 0 {
+		return errors.New("error getting students of all the groups")
+	}
+
+	i.mu.Lock()
+	i.groups = index
+	i.mu.Unlock()
+
+	log.Infof("Built students index of %d groups in %s", received, time.Since(started).Round(time.Second))
+	return nil
+}
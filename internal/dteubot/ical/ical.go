@@ -0,0 +1,256 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ical
+
+import (
+	"fmt"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"strings"
+	"time"
+)
+
+// maxLineLength is the maximum length of the content line in octets (RFC 5545, 3.1)
+const maxLineLength = 75
+
+// localTimeFormat is the format of the local date-time (RFC 5545, 3.3.5)
+const localTimeFormat = "20060102T150405"
+
+// CreateGroupCalendar creates a calendar with the group schedule
+// for the given number of days starting from today, the day of now.
+func CreateGroupCalendar(api api2.Api, groupId int, days int, now time.Time) ([]byte, error) {
+	dateStart := now
+	dateEnd := dateStart.AddDate(0, 0, days-1)
+
+	schedule, err := api.GetGroupSchedule(
+		groupId,
+		dateStart.Format(time.DateOnly),
+		dateEnd.Format(time.DateOnly),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	calls, err := api.GetCallSchedule()
+	if err != nil {
+		return nil, err
+	}
+
+	return CreateCalendar(schedule, calls, groupId, utils.UniversityLocation())
+}
+
+// CreateCalendar serializes the group schedule to the iCalendar (RFC 5545) format.
+//
+// Each lesson period becomes a separate VEVENT, empty days are skipped.
+// Call schedule is used for periods that have no start/end time.
+// Schedule times are interpreted in the loc timezone and written with its TZID,
+// so calendar apps show the lessons at the same time on the DST change days.
+//
+// Event UIDs depend only on the group, date, lesson number and class code,
+// so importing the same period twice updates the event instead of duplicating it.
+func CreateCalendar(schedule api2.Schedule, calls api2.CallSchedule, groupId int, loc *time.Location) ([]byte, error) {
+	w := &writer{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:-//cubicbyte//dteubot//UK")
+	w.line("CALSCALE:GREGORIAN")
+	w.line("METHOD:PUBLISH")
+	w.line("X-WR-TIMEZONE:" + loc.String())
+	rangeStart, rangeEnd := scheduleRange(schedule, loc)
+	w.timezone(loc, rangeStart, rangeEnd)
+
+	stamp := formatTime(time.Now())
+	uids := make(map[string]bool)
+
+	for _, day := range schedule {
+		for _, lesson := range day.Lessons {
+			for _, period := range lesson.Periods {
+				if utils.IsPeriodHidden(period) {
+					continue
+				}
+
+				timeStart, timeEnd := period.TimeStart, period.TimeEnd
+				if timeStart == "" || timeEnd == "" {
+					call := calls.GetCall(lesson.Number)
+					if call == nil {
+						continue
+					}
+					timeStart, timeEnd = call.TimeStart, call.TimeEnd
+				}
+
+				start, err := time.ParseInLocation("2006-01-02 15:04", day.Date+" "+timeStart, loc)
+				if err != nil {
+					return nil, err
+				}
+				end, err := time.ParseInLocation("2006-01-02 15:04", day.Date+" "+timeEnd, loc)
+				if err != nil {
+					return nil, err
+				}
+
+				summary := period.DisciplineFullName
+				if summary == "" {
+					summary = period.DisciplineShortName
+				}
+				if period.TypeStr != "" {
+					summary += " (" + period.TypeStr + ")"
+				}
+
+				// Every period gets its own UID, so the same lesson
+				// repeating on different days is never merged into one event
+				uid := fmt.Sprintf("%s-%d-%d-%d", day.Date, groupId, lesson.Number, period.R1)
+				for i := 2; uids[uid]; i++ {
+					uid = fmt.Sprintf("%s-%d-%d-%d-%d", day.Date, groupId, lesson.Number, period.R1, i)
+				}
+				uids[uid] = true
+
+				w.line("BEGIN:VEVENT")
+				w.line("UID:" + uid + "@dteubot")
+				w.line("DTSTAMP:" + stamp)
+				w.line("DTSTART;TZID=" + loc.String() + ":" + start.Format(localTimeFormat))
+				w.line("DTEND;TZID=" + loc.String() + ":" + end.Format(localTimeFormat))
+				w.line("SUMMARY:" + escapeText(summary))
+				if period.TeachersNameFull != "" {
+					w.line("DESCRIPTION:" + escapeText(period.TeachersNameFull))
+				}
+				if period.Classroom != "" {
+					w.line("LOCATION:" + escapeText(period.Classroom))
+				}
+				w.line("END:VEVENT")
+			}
+		}
+	}
+
+	w.line("END:VCALENDAR")
+
+	return []byte(w.String()), nil
+}
+
+// formatTime formats the time as UTC date-time (RFC 5545, 3.3.5)
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// scheduleRange returns the time range of the schedule days,
+// or the current moment if the schedule is empty
+func scheduleRange(schedule api2.Schedule, loc *time.Location) (time.Time, time.Time) {
+	start, end := time.Now(), time.Now()
+	for i, day := range schedule {
+		date, err := time.ParseInLocation(time.DateOnly, day.Date, loc)
+		if err != nil {
+			continue
+		}
+		if i == 0 || date.Before(start) {
+			start = date
+		}
+		if i == 0 || date.AddDate(0, 0, 1).After(end) {
+			end = date.AddDate(0, 0, 1)
+		}
+	}
+	return start, end
+}
+
+// formatOffset formats the UTC offset in seconds, like "+0300" (RFC 5545, 3.3.14)
+func formatOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset/60%60)
+}
+
+// escapeText escapes the TEXT value (RFC 5545, 3.3.11)
+func escapeText(text string) string {
+	return strings.NewReplacer(
+		"\\", "\\\\",
+		";", "\\;",
+		",", "\\,",
+		"\r\n", "\\n",
+		"\n", "\\n",
+	).Replace(text)
+}
+
+// writer writes the content lines, folding them if they are too long
+type writer struct {
+	strings.Builder
+}
+
+// timezone writes the VTIMEZONE component (RFC 5545, 3.6.5) of loc, with
+// the offset in effect at start and every offset change until end
+func (w *writer) timezone(loc *time.Location, start time.Time, end time.Time) {
+	w.line("BEGIN:VTIMEZONE")
+	w.line("TZID:" + loc.String())
+
+	_, offset := start.In(loc).Zone()
+	w.observance(start.In(loc), offset, offset, time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	for t := start.Truncate(time.Hour); t.Before(end); t = t.Add(time.Hour) {
+		if _, next := t.Add(time.Hour).In(loc).Zone(); next == offset {
+			continue
+		}
+
+		// Offset changes within the hour, find the minute
+		change := t.Add(time.Minute)
+		for _, o := change.In(loc).Zone(); o == offset; _, o = change.In(loc).Zone() {
+			change = change.Add(time.Minute)
+		}
+
+		_, next := change.In(loc).Zone()
+		// Onset is in the local time before the change
+		w.observance(change.In(loc), offset, next, change.UTC().Add(time.Duration(offset)*time.Second))
+		offset = next
+	}
+
+	w.line("END:VTIMEZONE")
+}
+
+// observance writes the STANDARD or DAYLIGHT component, depending on whether t is in the daylight time
+func (w *writer) observance(t time.Time, from int, to int, onset time.Time) {
+	kind := "STANDARD"
+	if t.IsDST() {
+		kind = "DAYLIGHT"
+	}
+	name, _ := t.Zone()
+
+	w.line("BEGIN:" + kind)
+	w.line("DTSTART:" + onset.Format(localTimeFormat))
+	w.line("TZOFFSETFROM:" + formatOffset(from))
+	w.line("TZOFFSETTO:" + formatOffset(to))
+	w.line("TZNAME:" + escapeText(name))
+	w.line("END:" + kind)
+}
+
+func (w *writer) line(line string) {
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > maxLineLength {
+			// Continuation lines start with a space, that counts in the line length
+			w.WriteString("\r\n ")
+			length = 1
+		}
+		w.WriteRune(r)
+		length += size
+	}
+	w.WriteString("\r\n")
+}
@@ -31,9 +31,12 @@ import (
 func InitDatabaseRecords(_ *gotgbot.Bot, ctx *ext.Context) error {
 	log.Debug("Initializing database records")
 
+	var chat *data.Chat
+	var err error
+
 	// Create chat if not exists
 	if ctx.EffectiveChat != nil {
-		chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+		chat, err = chatRepo.GetById(ctx.EffectiveChat.Id)
 		if err != nil {
 			return err
 		}
@@ -63,6 +66,18 @@ func InitDatabaseRecords(_ *gotgbot.Bot, ctx *ext.Context) error {
 				return err
 			}
 		}
+
+		// Copy settings of the private chat to its owner's user record.
+		// Needed for the users that selected the group before the
+		// user settings were introduced, so they keep their configuration.
+		if chat != nil && ctx.EffectiveChat.Type == "private" && user.GroupId == -1 && chat.GroupId != -1 {
+			log.Debug("Migrating private chat settings to user record")
+
+			user.GroupId = chat.GroupId
+			if err := userRepo.Update(user); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
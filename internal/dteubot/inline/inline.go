@@ -0,0 +1,220 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package inline
+
+import (
+	"context"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/sirkon/go-format/v2"
+	"strings"
+	"time"
+)
+
+// CacheTime is the time in seconds the inline results may be cached by Telegram
+const CacheTime = 30
+
+var (
+	chatRepo  data.ChatRepository
+	userRepo  data.UserRepository
+	languages map[string]i18n.Language
+)
+
+// dateKeywords maps the inline query keywords to the day offset from today.
+// Ukrainian and russian variants are accepted as well
+var dateKeywords = []struct {
+	Offset   int
+	Keywords []string
+	Title    func(lang i18n.Language) string
+}{
+	{0, []string{"today", "сьогодні", "сегодня"}, func(lang i18n.Language) string { return lang.Button.ScheduleToday }},
+	{1, []string{"tomorrow", "завтра"}, func(lang i18n.Language) string { return lang.Button.ScheduleTomorrow }},
+}
+
+// InitInline initializes inline package. Must be called before using this package
+func InitInline(
+	storage data.Storage,
+	languages2 map[string]i18n.Language,
+) {
+	chatRepo = storage.Chats()
+	userRepo = storage.Users()
+	languages = languages2
+}
+
+// HandleInlineQuery answers inline queries like "@bot today", "@bot tomorrow"
+// or "@bot 2024-03-15" with the schedule of the user's personal group.
+func HandleInlineQuery(bot *gotgbot.Bot, ctx *ext.Context) error {
+	query := ctx.InlineQuery
+
+	// Inline queries have no chat, so the group selected by user is used
+	user, err := userRepo.GetById(query.From.Id)
+	if err != nil {
+		return err
+	}
+
+	// Private chat id is equal to the user id
+	chat, err := chatRepo.GetById(query.From.Id)
+	if err != nil {
+		return err
+	}
+
+	groupId := -1
+	if user != nil && user.GroupId != -1 {
+		groupId = user.GroupId
+	} else if chat != nil {
+		// Group was selected before the personal group was introduced
+		groupId = chat.GroupId
+	}
+
+	langCode := query.From.LanguageCode
+	if chat != nil {
+		langCode = chat.LanguageCode
+	}
+	lang, err := utils.GetLang(langCode, languages)
+	if err != nil {
+		// User language is not supported
+		lang, err = utils.GetLang("", languages)
+		if err != nil {
+			return err
+		}
+	}
+
+	if groupId == -1 {
+		return answerNoGroup(bot, query, lang)
+	}
+
+	// Lesson times are shown in the timezone of the user's private chat
+	loc := utils.ChatLocation(chat)
+
+	// Get requested days
+	text := strings.ToLower(strings.TrimSpace(query.Query))
+	today := utils.NowFor(chat)
+	results := make([]gotgbot.InlineQueryResult, 0, 2)
+
+	for _, day := range dateKeywords {
+		if !matchKeyword(text, day.Keywords) {
+			continue
+		}
+
+		result, err := createScheduleResult(utils.UpdateContext(ctx), lang, groupId, today.AddDate(0, 0, day.Offset), day.Title(lang), loc)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+	if len(results) == 0 {
+		date, err := time.ParseInLocation(time.DateOnly, text, time.Local)
+		if err != nil {
+			results = append(results, createUsageResult(lang, today))
+		} else {
+			result, err := createScheduleResult(utils.UpdateContext(ctx), lang, groupId, date, date.Format(time.DateOnly), loc)
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+	}
+
+	_, err = query.Answer(bot, results, &gotgbot.AnswerInlineQueryOpts{
+		CacheTime:  CacheTime,
+		IsPersonal: true,
+	})
+	return err
+}
+
+// matchKeyword checks if the query is a prefix of any of the keywords
+func matchKeyword(query string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.HasPrefix(keyword, query) {
+			return true
+		}
+	}
+	return false
+}
+
+// createScheduleResult creates an inline result with the schedule for the given date
+func createScheduleResult(ctx context.Context, lang i18n.Language, groupId int, date time.Time, title string, loc *time.Location) (gotgbot.InlineQueryResult, error) {
+	dateStr := date.Format(time.DateOnly)
+
+	page, err := pages.CreateSchedulePage(ctx, lang, groupId, nil, dateStr, loc, 0, pages.ScheduleView{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Page buttons are not attached, because they
+	// don't work in chats where the bot is not a member
+	result := gotgbot.InlineQueryResultArticle{
+		Id:          dateStr,
+		Title:       title,
+		Description: dateStr,
+		InputMessageContent: gotgbot.InputTextMessageContent{
+			MessageText:           page.Text,
+			ParseMode:             page.ParseMode,
+			DisableWebPagePreview: page.DisableWebPagePreview,
+		},
+	}
+
+	return result, nil
+}
+
+// createUsageResult creates an inline result explaining the expected query format
+func createUsageResult(lang i18n.Language, today time.Time) gotgbot.InlineQueryResult {
+	return gotgbot.InlineQueryResultArticle{
+		Id:    "usage",
+		Title: lang.Text.InlineUsage,
+		InputMessageContent: gotgbot.InputTextMessageContent{
+			MessageText: format.Formatm(lang.Page.InlineUsage, format.Values{
+				"example": today.Format(time.DateOnly),
+			}),
+			ParseMode: "MarkdownV2",
+		},
+	}
+}
+
+// answerNoGroup answers the inline query with the prompt to select a group in the bot
+func answerNoGroup(bot *gotgbot.Bot, query *gotgbot.InlineQuery, lang i18n.Language) error {
+	results := []gotgbot.InlineQueryResult{
+		gotgbot.InlineQueryResultArticle{
+			Id:    "no_group",
+			Title: lang.Text.InlineNoGroup,
+			InputMessageContent: gotgbot.InputTextMessageContent{
+				MessageText: lang.Page.InlineNoGroup,
+				ParseMode:   "MarkdownV2",
+			},
+		},
+	}
+
+	_, err := query.Answer(bot, results, &gotgbot.AnswerInlineQueryOpts{
+		CacheTime:  CacheTime,
+		IsPersonal: true,
+		Button: &gotgbot.InlineQueryResultsButton{
+			Text:           lang.Button.InlineSetup,
+			StartParameter: "inline",
+		},
+	})
+	return err
+}
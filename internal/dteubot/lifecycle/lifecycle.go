@@ -0,0 +1,160 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/op/go-logging"
+	"runtime/debug"
+	"sync"
+)
+
+var log = logging.MustGetLogger("Lifecycle")
+
+// ErrShuttingDown is returned when a job is started after the shutdown began
+var ErrShuttingDown = errors.New("shutting down")
+
+var (
+	// handlers tracks the in-flight update handlers and background jobs
+	handlers sync.WaitGroup
+	// mu guards stopping, so that no handlers are
+	// added to the wait group while Shutdown waits for it
+	mu       sync.RWMutex
+	stopping bool
+	stopFns  []func() error
+	closeFns []func() error
+
+	// ctx is done when the shutdown begins
+	ctx, cancel = context.WithCancel(context.Background())
+)
+
+// OnStop registers a function that is called at the beginning of the shutdown.
+// It is used to stop receiving new updates and running scheduled jobs.
+func OnStop(fn func() error) {
+	stopFns = append(stopFns, fn)
+}
+
+// OnClose registers a function that is called after all in-flight
+// handlers are completed. It is used to close database connections
+// and other resources used by the handlers.
+func OnClose(fn func() error) {
+	closeFns = append(closeFns, fn)
+}
+
+// Context returns the context that is done when the shutdown begins.
+// It is used to stop waiting, like between the request retries,
+// so the in-flight handlers complete sooner.
+func Context() context.Context {
+	return ctx
+}
+
+// Track wraps the update handler, so that Shutdown waits for it to complete.
+// Updates received after the shutdown began are not handled.
+func Track(handler func(*gotgbot.Bot, *ext.Context) error) func(*gotgbot.Bot, *ext.Context) error {
+	return func(bot *gotgbot.Bot, ctx *ext.Context) error {
+		mu.RLock()
+		if stopping {
+			mu.RUnlock()
+			log.Warningf("Skipping update %d: shutting down", ctx.UpdateId)
+			return nil
+		}
+		handlers.Add(1)
+		mu.RUnlock()
+
+		defer handlers.Done()
+		return handler(bot, ctx)
+	}
+}
+
+// Go runs fn in a new goroutine, so that Shutdown waits for it to complete.
+// It is used for the long-running jobs started by the handlers.
+// Panics in fn are logged instead of crashing the bot.
+//
+// Returns ErrShuttingDown and does not run fn if the shutdown already began.
+func Go(fn func()) error {
+	mu.RLock()
+	defer mu.RUnlock()
+	if stopping {
+		return ErrShuttingDown
+	}
+
+	handlers.Add(1)
+	go func() {
+		defer handlers.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				log.Errorf("Panic in background job: %s\n%s", r, string(debug.Stack()))
+			}
+		}()
+		fn()
+	}()
+
+	return nil
+}
+
+// Shutdown gracefully stops the bot: stops receiving updates, waits for
+// the in-flight handlers to complete and closes the resources.
+//
+// If ctx is done before the handlers complete, the resources
+// are closed anyway and ctx error is returned.
+func Shutdown(ctx context.Context) error {
+	log.Info("Shutting down")
+
+	var errs []error
+	for _, fn := range stopFns {
+		if err := fn(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	mu.Lock()
+	stopping = true
+	mu.Unlock()
+	cancel()
+
+	// Wait for in-flight handlers
+	done := make(chan struct{})
+	go func() {
+		handlers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Info("All handlers completed")
+	case <-ctx.Done():
+		log.Warning("Shutdown timeout exceeded, some handlers are not completed")
+		errs = append(errs, ctx.Err())
+	}
+
+	for _, fn := range closeFns {
+		if err := fn(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package lifecycle_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+	"github.com/cubicbyte/dteubot/internal/dteubot/lifecycle"
+	"github.com/cubicbyte/dteubot/internal/dteubot/workerpool"
+	"testing"
+	"time"
+)
+
+// TestShutdownCompletesInFlightHandler checks the shutdown the way main does it:
+// the run context is cancelled by the signal while a handler is running,
+// and Shutdown waits for the handler before closing the resources.
+//
+// The lifecycle state is global, so the shutdown can be tested only once.
+func TestShutdownCompletesInFlightHandler(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	completed := make(chan struct{})
+	handler := func(_ *gotgbot.Bot, _ *ext.Context) error {
+		close(started)
+		<-release
+		close(completed)
+		return nil
+	}
+
+	dispatcher := ext.NewDispatcher(nil)
+	dispatcher.AddHandler(handlers.NewCallback(nil, lifecycle.Track(handler)))
+	pool := workerpool.NewPool(dispatcher, 2, 10*time.Millisecond)
+	updates := make(chan json.RawMessage, 1)
+	go pool.Start(nil, updates)
+
+	lifecycle.OnStop(func() error {
+		close(updates)
+		pool.Stop()
+		return nil
+	})
+	closed := make(chan bool, 1)
+	lifecycle.OnClose(func() error {
+		select {
+		case <-completed:
+			closed <- true
+		default:
+			closed <- false
+		}
+		return nil
+	})
+
+	updates <- json.RawMessage(`{"update_id":1,"callback_query":{"id":"1","from":{"id":1,"first_name":"Test"},"chat_instance":"1","data":"open.menu"}}`)
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("handler is not started")
+	}
+
+	// The signal cancels the run context, then the shutdown begins
+	runCtx, stop := context.WithCancel(context.Background())
+	shutdownErr := make(chan error, 1)
+	go func() {
+		<-runCtx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownErr <- lifecycle.Shutdown(shutdownCtx)
+	}()
+	stop()
+
+	select {
+	case <-lifecycle.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("lifecycle context is not done after the shutdown began")
+	}
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("Shutdown() returned %v before the handler completed", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-shutdownErr:
+		if err != nil {
+			t.Fatalf("Shutdown() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown() didn't return after the handler completed")
+	}
+	if !<-closed {
+		t.Error("resources are closed before the in-flight handler completed")
+	}
+
+	// Updates received after the shutdown began are skipped
+	called := false
+	skipped := lifecycle.Track(func(_ *gotgbot.Bot, _ *ext.Context) error {
+		called = true
+		return nil
+	})
+	if err := skipped(nil, &ext.Context{Update: &gotgbot.Update{UpdateId: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if called {
+		t.Error("handler is called after the shutdown began")
+	}
+	if err := lifecycle.Go(func() {}); !errors.Is(err, lifecycle.ErrShuttingDown) {
+		t.Errorf("Go() error = %v, want ErrShuttingDown", err)
+	}
+}
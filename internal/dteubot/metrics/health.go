@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package metrics
+
+import (
+	"fmt"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"net/http"
+	"time"
+)
+
+// HealthCheckTimeout is the timeout of every request made by the health check
+const HealthCheckTimeout = 5 * time.Second
+
+// HealthCheck returns the error if the bot can't work right now
+type HealthCheck func() error
+
+// BotHealthCheck returns the health check that makes sure
+// the Telegram Bot API and the university API are reachable.
+//
+// The university API is requested directly, bypassing the cache.
+func BotHealthCheck(bot *gotgbot.Bot, apiUrl string) HealthCheck {
+	api := &api2.DefaultApi{
+		Url:     apiUrl,
+		Timeout: HealthCheckTimeout,
+	}
+
+	return func() error {
+		_, err := bot.GetMe(&gotgbot.GetMeOpts{
+			RequestOpts: &gotgbot.RequestOpts{Timeout: HealthCheckTimeout},
+		})
+		if err != nil {
+			return fmt.Errorf("telegram: %w", err)
+		}
+
+		// Call schedule is the smallest response of the API
+		if _, err := api.GetCallSchedule(); err != nil {
+			return fmt.Errorf("api: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// healthHandler responds with 200 if the health check passes, and 503 otherwise
+func healthHandler(check HealthCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := check(); err != nil {
+			log.Warningf("Health check failed: %s", err)
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = fmt.Fprintln(w, "ok")
+	}
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/middleware"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/op/go-logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ActiveChatsRefreshInterval is how often the active chats count is recalculated.
+// Counting the chats stored in files is slow, so it's not done on every scrape
+const ActiveChatsRefreshInterval = 5 * time.Minute
+
+var log = logging.MustGetLogger("Metrics")
+
+var (
+	updatesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dteubot_updates_total",
+		Help: "Number of processed updates by type.",
+	}, []string{"type"})
+
+	callbacksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dteubot_callbacks_total",
+		Help: "Number of processed callback queries by button action.",
+	}, []string{"action"})
+
+	handlerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dteubot_handler_duration_seconds",
+		Help:    "Latency of the update handlers by update type.",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"type"})
+
+	handlerErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dteubot_handler_errors_total",
+		Help: "Number of updates whose handler returned an error, by update type.",
+	}, []string{"type"})
+
+	apiRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dteubot_api_request_duration_seconds",
+		Help:    "Latency of the university API requests by endpoint.",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"endpoint", "result"})
+
+	apiCacheLookupsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dteubot_api_cache_lookups_total",
+		Help: "Number of the university API cache lookups by result (hit or miss).",
+	}, []string{"result"})
+)
+
+// Setup registers the active chats count metric
+func Setup(chatRepo data.ChatRepository) {
+	var (
+		mu        sync.Mutex
+		count     float64
+		updatedAt time.Time
+	)
+
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "dteubot_active_chats",
+		Help: "Number of chats the bot can send messages to.",
+	}, func() float64 {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if time.Since(updatedAt) < ActiveChatsRefreshInterval {
+			return count
+		}
+
+		chats, err := chatRepo.GetAccessibleChats()
+		if err != nil {
+			log.Warningf("Error counting active chats: %s", err)
+			return count
+		}
+
+		count = float64(len(chats))
+		updatedAt = time.Now()
+		return count
+	})
+}
+
+// CountUpdates counts the updates handled by the handler, e.g. "button" or "command",
+// the errors returned by it and how long it runs
+func CountUpdates(updateType string) middleware.Middleware {
+	counter := updatesTotal.WithLabelValues(updateType)
+	errCounter := handlerErrorsTotal.WithLabelValues(updateType)
+	duration := handlerDuration.WithLabelValues(updateType)
+
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(bot *gotgbot.Bot, ctx *ext.Context) error {
+			counter.Inc()
+			start := time.Now()
+			err := handler(bot, ctx)
+			duration.Observe(time.Since(start).Seconds())
+			if err != nil {
+				errCounter.Inc()
+			}
+			return err
+		}
+	}
+}
+
+// CountCallbacks counts the callback queries by the button action
+func CountCallbacks(handler middleware.Handler) middleware.Handler {
+	return func(bot *gotgbot.Bot, ctx *ext.Context) error {
+		if ctx.CallbackQuery != nil {
+			callbacksTotal.WithLabelValues(utils.ButtonAction(ctx.CallbackQuery.Data)).Inc()
+		}
+		return handler(bot, ctx)
+	}
+}
+
+// ApiObserver collects the university API requests metrics.
+// Implements api.Observer.
+type ApiObserver struct {
+	// UsageStats counts the failed requests for the admins, if not nil
+	UsageStats *data.UsageStats
+}
+
+func (o ApiObserver) ObserveRequest(endpoint string, duration time.Duration, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+		if o.UsageStats != nil {
+			o.UsageStats.CountApiError()
+		}
+	}
+	apiRequestDuration.WithLabelValues(endpoint, result).Observe(duration.Seconds())
+}
+
+func (ApiObserver) ObserveCache(hit bool) {
+	if hit {
+		apiCacheLookupsTotal.WithLabelValues("hit").Inc()
+	} else {
+		apiCacheLookupsTotal.WithLabelValues("miss").Inc()
+	}
+}
+
+// StartServer starts the HTTP server with the metrics at /metrics
+// and the health check at /healthz, if health is not nil.
+// Returns the function that stops the server.
+func StartServer(addr string, health HealthCheck) func() error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	if health != nil {
+		mux.Handle("/healthz", healthHandler(health))
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		log.Infof("Serving metrics on %s", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("Error serving metrics: %s", err)
+		}
+	}()
+
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(ctx)
+	}
+}
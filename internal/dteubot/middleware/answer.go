@@ -0,0 +1,141 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"sync"
+)
+
+// toastKey is the update context data key of the toast text
+const toastKey = "toast"
+
+var (
+	// answersMu guards answers
+	answersMu sync.Mutex
+	// answers contains the callback queries that are being handled
+	// and whether they are already answered
+	answers = make(map[string]bool)
+)
+
+// answerTracker is the bot client that remembers
+// which of the handled callback queries are answered
+type answerTracker struct {
+	gotgbot.BotClient
+}
+
+// TrackAnswers wraps the bot client to skip answering the callback queries
+// in AnswerCallbacks if the handler already answered them
+func TrackAnswers(client gotgbot.BotClient) gotgbot.BotClient {
+	return &answerTracker{client}
+}
+
+func (t *answerTracker) RequestWithContext(ctx context.Context, token string, method string, params map[string]string, data map[string]gotgbot.NamedReader, opts *gotgbot.RequestOpts) (json.RawMessage, error) {
+	res, err := t.BotClient.RequestWithContext(ctx, token, method, params, data, opts)
+	if method != "answerCallbackQuery" {
+		return res, err
+	}
+
+	// Query can't be answered again even if the request failed
+	answersMu.Lock()
+	if _, ok := answers[params["callback_query_id"]]; ok {
+		answers[params["callback_query_id"]] = true
+	}
+	answersMu.Unlock()
+
+	return res, err
+}
+
+// SetToast sets the text shown on top of the chat
+// when the callback query is answered by AnswerCallbacks
+func SetToast(ctx *ext.Context, text string) {
+	ctx.Data[toastKey] = text
+}
+
+// AnswerCallbacks answers the callback query after the handler, unless the
+// handler already answered it, to stop the loading animation on the button.
+// The query is answered with the toast set by SetToast, if any.
+//
+// If the handler fails, the query is answered by AnswerPending
+// after the error handler, so it can answer with the error alert instead.
+// Requires the bot client to be wrapped with TrackAnswers.
+func AnswerCallbacks(handler Handler) Handler {
+	return func(bot *gotgbot.Bot, ctx *ext.Context) error {
+		if ctx.CallbackQuery == nil {
+			return handler(bot, ctx)
+		}
+
+		answersMu.Lock()
+		answers[ctx.CallbackQuery.Id] = false
+		answersMu.Unlock()
+
+		err := handler(bot, ctx)
+		if err != nil && !errors.Is(err, ext.EndGroups) && !errors.Is(err, ext.ContinueGroups) {
+			return err
+		}
+
+		if err2 := answerPending(bot, ctx); err2 != nil {
+			log.Warningf("Error answering callback query: %s", err2)
+		}
+
+		return err
+	}
+}
+
+// AnswerPending answers the callback query if the handler wrapped with
+// AnswerCallbacks failed and the query is still not answered.
+// Must be called after the update error or panic is handled.
+func AnswerPending(bot *gotgbot.Bot, ctx *ext.Context) {
+	if ctx.CallbackQuery == nil {
+		return
+	}
+
+	if err := answerPending(bot, ctx); err != nil {
+		log.Warningf("Error answering callback query: %s", err)
+	}
+}
+
+// answerPending answers the handled callback query if it's not
+// answered yet and forgets it
+func answerPending(bot *gotgbot.Bot, ctx *ext.Context) error {
+	answersMu.Lock()
+	answered, ok := answers[ctx.CallbackQuery.Id]
+	delete(answers, ctx.CallbackQuery.Id)
+	answersMu.Unlock()
+
+	if !ok || answered {
+		return nil
+	}
+
+	var opts *gotgbot.AnswerCallbackQueryOpts
+	if text, ok := ctx.Data[toastKey].(string); ok {
+		opts = &gotgbot.AnswerCallbackQueryOpts{Text: text}
+	}
+
+	_, err := bot.AnswerCallbackQuery(ctx.CallbackQuery.Id, opts)
+	return err
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package middleware
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DeduplicationTTL is how long the button press is remembered
+// to skip the same presses that follow it
+const DeduplicationTTL = 3 * time.Second
+
+var (
+	// mu guards pressed
+	mu sync.Mutex
+	// pressed contains the expiration time of the recent button presses
+	pressed = make(map[string]time.Time)
+)
+
+// DeduplicateCallbacks skips the callback queries that repeat the recent one,
+// e.g. when the user taps the button multiple times quickly.
+// The skipped query is answered immediately without editing the message.
+//
+// Every tap has its own query id, so the queries are considered the same
+// if they are sent by the same user from the same message with the same data.
+func DeduplicateCallbacks(handler Handler) Handler {
+	return func(bot *gotgbot.Bot, ctx *ext.Context) error {
+		if ctx.CallbackQuery == nil {
+			return handler(bot, ctx)
+		}
+
+		if !isFirstPress(callbackKey(ctx.CallbackQuery)) {
+			log.Debugf("Skipping duplicate callback query in update %d", ctx.UpdateId)
+			if _, err := bot.AnswerCallbackQuery(ctx.CallbackQuery.Id, nil); err != nil {
+				return err
+			}
+
+			// Do not save the duplicate to statistics
+			return ext.EndGroups
+		}
+
+		return handler(bot, ctx)
+	}
+}
+
+// callbackKey returns the key used to find the same callback queries
+func callbackKey(query *gotgbot.CallbackQuery) string {
+	key := strconv.FormatInt(query.From.Id, 10) + ":"
+
+	switch {
+	case query.InlineMessageId != "":
+		key += query.InlineMessageId
+	case query.Message != nil:
+		key += strconv.FormatInt(query.Message.Chat.Id, 10) + "/" + strconv.FormatInt(query.Message.MessageId, 10)
+	}
+
+	return key + ":" + query.Data
+}
+
+// isFirstPress checks if the button press with the key is not a duplicate
+// and remembers it for DeduplicationTTL
+func isFirstPress(key string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now()
+
+	// Remove expired presses
+	for k, expires := range pressed {
+		if now.After(expires) {
+			delete(pressed, k)
+		}
+	}
+
+	if _, ok := pressed[key]; ok {
+		return false
+	}
+
+	pressed[key] = now.Add(DeduplicationTTL)
+	return true
+}
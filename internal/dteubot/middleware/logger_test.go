@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestRequestLogger(t *testing.T) {
+	var out bytes.Buffer
+	prevLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&out, nil)))
+	defer slog.SetDefault(prevLogger)
+
+	ctx := ext.NewContext(&gotgbot.Update{
+		UpdateId: 123,
+		CallbackQuery: &gotgbot.CallbackQuery{
+			Id:      "1",
+			From:    gotgbot.User{Id: 42},
+			Message: &gotgbot.Message{Chat: gotgbot.Chat{Id: -100}},
+			Data:    "open.menu",
+		},
+	}, nil)
+
+	var requestIds []string
+	handler := Chain(func(b *gotgbot.Bot, ctx *ext.Context) error {
+		requestIds = append(requestIds, utils.RequestId(ctx))
+		utils.Logger(ctx).Info("Handled")
+		return nil
+	}, RequestLogger, Timeout(time.Minute))
+
+	// The second handler of the same update keeps its request id
+	for i := 0; i < 2; i++ {
+		if err := handler(nil, ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(requestIds[0]) != 16 || requestIds[1] != requestIds[0] {
+		t.Fatalf("got request ids %q, want the same 16 hex digits id", requestIds)
+	}
+
+	for _, line := range bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n")) {
+		var record struct {
+			Msg       string `json:"msg"`
+			UpdateId  int64  `json:"update_id"`
+			RequestId string `json:"request_id"`
+			ChatId    int64  `json:"chat_id"`
+			UserId    int64  `json:"user_id"`
+			Action    string `json:"action"`
+		}
+		if err := json.Unmarshal(line, &record); err != nil {
+			t.Fatalf("log record %s is not JSON: %s", line, err)
+		}
+
+		if record.Msg != "Handled" || record.UpdateId != 123 || record.RequestId != requestIds[0] ||
+			record.ChatId != -100 || record.UserId != 42 || record.Action != "open.menu" {
+			t.Errorf("log record %s doesn't have the update fields", line)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package middleware
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/ratelimit"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+)
+
+// RateLimits are the limiters used by RateLimit. Nil limiter disables its limit.
+type RateLimits struct {
+	// Users limits the updates of each user to protect the university API
+	Users *ratelimit.Limiter
+	// Chats limits the updates of each chat, so Telegram doesn't limit the bot for everyone
+	Chats *ratelimit.Limiter
+	// Admins limits the updates in the chats of the bot administrators listed
+	// in ADMIN_IDS instead of Users and Chats, expected to have higher limits
+	Admins *ratelimit.Limiter
+}
+
+// RateLimit skips the updates of the users and chats that exceeded the limits.
+//
+// Callback queries of the users over the limit are answered with an alert,
+// the ones of the chats over the limit are answered with a toast.
+// Other updates are skipped silently.
+func RateLimit(limits RateLimits, chatRepo data.ChatRepository, languages map[string]i18n.Language) Middleware {
+	return func(handler Handler) Handler {
+		if limits.Users == nil && limits.Chats == nil && limits.Admins == nil {
+			return handler
+		}
+
+		return func(bot *gotgbot.Bot, ctx *ext.Context) error {
+			if ctx.EffectiveUser == nil {
+				return handler(bot, ctx)
+			}
+
+			alert, ok := allowUpdate(ctx, limits)
+			if ok {
+				return handler(bot, ctx)
+			}
+
+			log.Infof("Rate limit exceeded by user %d in update %d, %d updates throttled in total",
+				ctx.EffectiveUser.Id, ctx.UpdateId, limits.throttled())
+
+			if ctx.CallbackQuery != nil {
+				if err := answerTooManyRequests(bot, ctx, alert, chatRepo, languages); err != nil {
+					return err
+				}
+			}
+
+			// Do not save the skipped update to statistics
+			return ext.EndGroups
+		}
+	}
+}
+
+// allowUpdate takes the tokens of the update from the limiters.
+// Returns false if the update exceeds any limit, and whether
+// it's the limit of the user, which is answered with an alert.
+func allowUpdate(ctx *ext.Context, limits RateLimits) (alert bool, ok bool) {
+	// Updates without a chat, like inline queries, are limited by the user
+	chatId := ctx.EffectiveUser.Id
+	if ctx.EffectiveChat != nil {
+		chatId = ctx.EffectiveChat.Id
+	}
+
+	if limits.Admins != nil && utils.IsAdminId(ctx.EffectiveUser.Id) {
+		return false, limits.Admins.Allow(chatId)
+	}
+
+	if limits.Users != nil && !limits.Users.Allow(ctx.EffectiveUser.Id) {
+		return true, false
+	}
+	if limits.Chats != nil && !limits.Chats.Allow(chatId) {
+		return false, false
+	}
+
+	return false, true
+}
+
+// throttled returns the number of the updates rejected by all the limiters
+func (l RateLimits) throttled() uint64 {
+	var throttled uint64
+	for _, limiter := range []*ratelimit.Limiter{l.Users, l.Chats, l.Admins} {
+		if limiter != nil {
+			throttled += limiter.Throttled()
+		}
+	}
+	return throttled
+}
+
+// answerTooManyRequests answers the callback query with an alert
+// or a toast in the chat language
+func answerTooManyRequests(bot *gotgbot.Bot, ctx *ext.Context, alert bool, chatRepo data.ChatRepository, languages map[string]i18n.Language) error {
+	langCode := ""
+	if ctx.EffectiveChat != nil {
+		chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+		if err != nil {
+			return err
+		}
+		if chat != nil {
+			langCode = chat.LanguageCode
+		}
+	}
+
+	lang, err := utils.GetLang(langCode, languages)
+	if err != nil {
+		return err
+	}
+
+	opts := &gotgbot.AnswerCallbackQueryOpts{
+		Text:      lang.Alert.TooFast,
+		ShowAlert: alert,
+	}
+	if alert {
+		opts.Text = lang.Alert.TooManyRequests
+	}
+
+	_, err = bot.AnswerCallbackQuery(ctx.CallbackQuery.Id, opts)
+	return err
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package middleware
+
+import (
+	"context"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ThrottleInterval is the minimum time between the handled
+// button presses of the user on the same message
+const ThrottleInterval = 500 * time.Millisecond
+
+// ThrottleSweepInterval is how often the expired presses are removed, see SweepThrottle
+const ThrottleSweepInterval = time.Minute
+
+var (
+	// throttleMu guards handled
+	throttleMu sync.Mutex
+	// handled contains the time of the last handled button press of the user on the message
+	handled = make(map[string]time.Time)
+)
+
+// ThrottleCallbacks drops the callback queries that arrive faster than
+// one per ThrottleInterval from the same user on the same message,
+// e.g. when the user quickly taps through the schedule days.
+// The dropped query is answered immediately without editing the message.
+//
+// Presses of the different users and on the different messages are
+// independent, and the refresh buttons are never dropped.
+func ThrottleCallbacks(handler Handler) Handler {
+	return func(bot *gotgbot.Bot, ctx *ext.Context) error {
+		if ctx.CallbackQuery == nil || isRefresh(ctx.CallbackQuery) {
+			return handler(bot, ctx)
+		}
+
+		if !takeTurn(throttleKey(ctx.CallbackQuery)) {
+			log.Debugf("Dropping too frequent callback query in update %d", ctx.UpdateId)
+			if _, err := bot.AnswerCallbackQuery(ctx.CallbackQuery.Id, nil); err != nil {
+				return err
+			}
+
+			// Do not save the dropped query to statistics
+			return ext.EndGroups
+		}
+
+		return handler(bot, ctx)
+	}
+}
+
+// isRefresh checks if the query is sent by the refresh button,
+// like "refresh.schedule" or the one with the "refresh" param
+func isRefresh(query *gotgbot.CallbackQuery) bool {
+	button, err := utils.UnmarshalButtonData(query.Data)
+	if err != nil {
+		return false
+	}
+
+	if strings.HasPrefix(button.Action, "refresh.") {
+		return true
+	}
+
+	_, err = button.Param("refresh")
+	return err == nil
+}
+
+// throttleKey returns the key of the user and the message the button is attached to
+func throttleKey(query *gotgbot.CallbackQuery) string {
+	key := strconv.FormatInt(query.From.Id, 10) + ":"
+
+	switch {
+	case query.InlineMessageId != "":
+		key += query.InlineMessageId
+	case query.Message != nil:
+		key += strconv.FormatInt(query.Message.Chat.Id, 10) + "/" + strconv.FormatInt(query.Message.MessageId, 10)
+	}
+
+	return key
+}
+
+// takeTurn checks if ThrottleInterval has passed since
+// the last handled press with the key, and remembers this one
+func takeTurn(key string) bool {
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+
+	now := time.Now()
+	if last, ok := handled[key]; ok && now.Sub(last) < ThrottleInterval {
+		return false
+	}
+
+	handled[key] = now
+	return true
+}
+
+// SweepThrottle removes the expired presses every ThrottleSweepInterval
+// until ctx is done, so the presses of the users that left are not kept forever
+func SweepThrottle(ctx context.Context) {
+	ticker := time.NewTicker(ThrottleSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			sweepThrottle(now)
+		}
+	}
+}
+
+// sweepThrottle removes the presses that expired by now
+func sweepThrottle(now time.Time) {
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+
+	for key, last := range handled {
+		if now.Sub(last) >= ThrottleInterval {
+			delete(handled, key)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package middleware
+
+import (
+	"context"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"time"
+)
+
+// Timeout handles the update within the context that is done after timeout,
+// see utils.UpdateContext. The API requests made with the context are cancelled
+// then, so the handler fails instead of waiting for the retries of the slow
+// university API. The context is not done on shutdown, so the in-flight
+// updates are completed.
+//
+// The context keeps the values of the update context, like the request logger.
+// The update context is restored after the handler, so the next handlers of
+// the update and the error handler don't get the done context.
+func Timeout(timeout time.Duration) Middleware {
+	return func(handler Handler) Handler {
+		return func(bot *gotgbot.Bot, ctx *ext.Context) error {
+			parent := utils.UpdateContext(ctx)
+			updateCtx, cancel := context.WithTimeout(context.WithoutCancel(parent), timeout)
+			defer cancel()
+
+			utils.SetUpdateContext(ctx, updateCtx)
+			defer utils.SetUpdateContext(ctx, parent)
+			return handler(bot, ctx)
+		}
+	}
+}
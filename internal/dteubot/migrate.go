@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package dteubot
+
+import (
+	"errors"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"os"
+)
+
+// MigrateToPostgres copies the chats and users stored in files
+// to the PostgreSQL database, set up in the env variables.
+//
+// Records that are already in the database are overwritten.
+// Statistics are not copied.
+func MigrateToPostgres() error {
+	if os.Getenv("DATABASE_TYPE") != "postgres" {
+		return errors.New("set DATABASE_TYPE to postgres and configure the database to migrate to")
+	}
+
+	db, err := connectPostgres()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return migrateFromFiles(data.NewPostgresStorage(db))
+}
+
+// MigrateToSQLite copies the chats and users stored in files
+// to the SQLite database file, set up in the env variables.
+//
+// Records that are already in the database are overwritten.
+// Statistics are not copied.
+func MigrateToSQLite() error {
+	if os.Getenv("DATABASE_TYPE") != "sqlite" {
+		return errors.New("set DATABASE_TYPE to sqlite and configure the database to migrate to")
+	}
+
+	db, err := connectSQLite()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	storage, err := data.NewSQLiteStorage(db)
+	if err != nil {
+		return err
+	}
+
+	return migrateFromFiles(storage)
+}
+
+// migrateFromFiles copies the chats and users stored in files to the given storage
+func migrateFromFiles(storage data.Storage) error {
+	files, err := data.NewFileStorage(ChatsDirPath, UsersDirPath, UsageStatsPath)
+	if err != nil {
+		return err
+	}
+
+	chats, users, err := data.CopyStorage(files, storage)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Migrated %d users and %d chats\n", users, chats)
+	return nil
+}
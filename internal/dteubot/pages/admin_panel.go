@@ -24,12 +24,29 @@ package pages
 
 import (
 	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
 	"github.com/cubicbyte/dteubot/internal/i18n"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"github.com/sirkon/go-format/v2"
 )
 
 func CreateAdminPanelPage(lang i18n.Language) (Page, error) {
+	text := lang.Page.AdminPanel
+
+	// Show the API requests stats, if the API counts them
+	if provider, ok := api.(api2.StatsProvider); ok {
+		stats := provider.Stats()
+		text += "\n\n" + format.Formatm(lang.Text.AdminApiStats, format.Values{
+			"attempts": stats.Attempts,
+			"retries":  stats.Retries,
+			"failures": stats.Failures,
+			"rejected": stats.Rejected,
+			"breaker":  utils.EscapeMarkdownV2(stats.Breaker.String()),
+		})
+	}
+
 	page := Page{
-		Text: lang.Page.AdminPanel,
+		Text: text,
 		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
 			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
 				{{
@@ -27,7 +27,9 @@ import (
 	"github.com/cubicbyte/dteubot/internal/i18n"
 )
 
-func CreateApiUnavailablePage(lang i18n.Language) (Page, error) {
+// CreateAPINotRespondingPage creates a page shown when the API request
+// fails with a timeout, a connection error or a 5xx status code
+func CreateAPINotRespondingPage(lang i18n.Language) (Page, error) {
 	page := Page{
 		Text: lang.Page.ApiUnavailable,
 		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
@@ -43,3 +45,21 @@ func CreateApiUnavailablePage(lang i18n.Language) (Page, error) {
 
 	return page, nil
 }
+
+// CreateAPIUnavailablePage creates a page shown while the API circuit breaker is open
+func CreateAPIUnavailablePage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text: lang.Page.ApiDown,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+				{{
+					Text:         lang.Button.Back,
+					CallbackData: "open.menu",
+				}},
+			},
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+)
+
+// CreateBackupPage creates a caption of the settings backup file
+func CreateBackupPage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text:      lang.Page.Backup,
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+func CreateRestoreUsagePage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text:      lang.Page.RestoreUsage,
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+func CreateRestoreInvalidPage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text:      lang.Page.RestoreInvalid,
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+func CreateRestoreInvalidGroupPage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text: lang.Page.RestoreInvalidGroup,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+				{{
+					Text:         lang.Button.SelectGroup,
+					CallbackData: "open.select_group",
+				}},
+			},
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+func CreateRestoreDonePage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text: lang.Page.RestoreDone,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+				{{
+					Text:         lang.Button.Settings,
+					CallbackData: "open.settings",
+				}, {
+					Text:         lang.Button.Menu,
+					CallbackData: "open.menu",
+				}},
+			},
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
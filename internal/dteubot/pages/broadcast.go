@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/cubicbyte/dteubot/internal/dteubot/broadcast"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/sirkon/go-format/v2"
+	"sort"
+)
+
+func CreateBroadcastUsagePage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text:      lang.Page.BroadcastUsage,
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+func CreateBroadcastStartedPage(lang i18n.Language, chats int) (Page, error) {
+	page := Page{
+		Text: format.Formatm(lang.Page.BroadcastStarted, format.Values{
+			"chats":     chats,
+			"chatsWord": lang.Plural(chats, lang.Text.PluralChats),
+		}),
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+func CreateBroadcastRunningPage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text:      lang.Page.BroadcastRunning,
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+func CreateBroadcastNotRunningPage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text:      lang.Page.BroadcastNotRunning,
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+func CreateBroadcastCancellingPage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text:      lang.Page.BroadcastCancelling,
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// CreateBroadcastReportPage creates a page with the broadcast delivery report
+func CreateBroadcastReportPage(lang i18n.Language, report broadcast.Report) (Page, error) {
+	text := lang.Page.BroadcastDone
+	if report.Cancelled {
+		text = lang.Page.BroadcastCancelled
+	}
+
+	text = format.Formatm(text, format.Values{
+		"sent":    report.Sent,
+		"blocked": report.Blocked,
+		"failed":  report.Failed,
+	})
+
+	if len(report.Errors) > 0 {
+		// Most frequent errors first
+		descriptions := make([]string, 0, len(report.Errors))
+		for description := range report.Errors {
+			descriptions = append(descriptions, description)
+		}
+		sort.Slice(descriptions, func(i, j int) bool {
+			return report.Errors[descriptions[i]] > report.Errors[descriptions[j]]
+		})
+
+		text += "\n\n" + lang.Text.BroadcastErrors
+		for _, description := range descriptions {
+			text += "\n" + format.Formatm(lang.Text.BroadcastError, format.Values{
+				"error": utils.EscapeMarkdownV2(description),
+				"count": report.Errors[description],
+			})
+		}
+	}
+
+	page := Page{
+		Text:      text,
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/sirkon/go-format/v2"
+)
+
+// CreateCalendarExportPage creates a caption for the exported calendar file
+func CreateCalendarExportPage(lang i18n.Language, days int) (Page, error) {
+	page := Page{
+		Text:      format.Formatp(lang.Page.CalendarExport, days),
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// CreateWeekExportPage creates a caption for the exported week calendar file
+//
+// weekOffset is the number of weeks from the current one of the chat
+func CreateWeekExportPage(lang i18n.Language, chat *data.Chat, weekOffset int) (Page, error) {
+	weekStart := GetWeekStart(utils.NowFor(chat)).AddDate(0, 0, weekOffset*7)
+
+	page := Page{
+		Text: format.Formatm(lang.Page.WeekExport, format.Values{
+			"dateStart": getLocalizedShortDate(lang, weekStart),
+			"dateEnd":   getLocalizedShortDate(lang, weekStart.AddDate(0, 0, 6)),
+		}),
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// CreateWeekExportUsagePage creates a page with the /export command usage
+func CreateWeekExportUsagePage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text:      lang.Page.ExportUsage,
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
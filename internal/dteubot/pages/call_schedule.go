@@ -23,20 +23,43 @@
 package pages
 
 import (
+	"context"
 	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
 	"github.com/cubicbyte/dteubot/internal/i18n"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
 	"github.com/sirkon/go-format/v2"
+	"time"
 )
 
-func CreateCallSchedulePage(lang i18n.Language, backButton string) (Page, error) {
-	calls, err := api.GetCallSchedule()
+// CreateCallSchedulePage creates a call schedule page.
+// The current class is highlighted and the time left until
+// the break or the next class is shown.
+func CreateCallSchedulePage(ctx context.Context, lang i18n.Language, backButton string, loc *time.Location, now time.Time) (Page, error) {
+	calls, err := api.WithContext(ctx).GetCallSchedule()
 	if err != nil {
 		return Page{}, err
 	}
 
+	// Calls status is calculated in the university timezone
+	now = now.In(utils.UniversityLocation())
+	status, current, err := getCallsStatusText(lang, calls, now, loc)
+	if err != nil {
+		return Page{}, err
+	}
+
+	today := now.Format(time.DateOnly)
 	callsText := ""
 	for _, call := range calls {
-		callsText += format.Formatp("`$)` *$* `-` *$*\n", call.Number, call.TimeStart, call.TimeEnd)
+		timeStart := utils.ConvertLessonTime(today, call.TimeStart, loc)
+		timeEnd := utils.ConvertLessonTime(today, call.TimeEnd, loc)
+		if call.Number == current {
+			callsText += "👉 "
+		}
+		callsText += format.Formatp("`$)` *$* `-` *$*\n", call.Number, timeStart, timeEnd)
+	}
+	if status != "" {
+		callsText += "\n" + status
 	}
 
 	page := Page{
@@ -54,3 +77,48 @@ func CreateCallSchedulePage(lang i18n.Language, backButton string) (Page, error)
 
 	return page, nil
 }
+
+// getCallsStatusText returns the text with the time left until the break
+// or the next class, and the number of the current class, or 0 if there is no class now.
+// Saturday and Sunday are considered days off.
+func getCallsStatusText(lang i18n.Language, calls api2.CallSchedule, now time.Time, loc *time.Location) (string, int, error) {
+	if len(calls) == 0 {
+		return "", 0, nil
+	}
+
+	status, err := utils.GetCallScheduleStatus(calls, now)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if status != nil {
+		left := utils.FormatDuration(status.Time.Sub(now), 2, lang)
+		switch status.Status {
+		case utils.CallStatusBeforeStart:
+			return format.Formatp(lang.Page.CallScheduleBefore, left), 0, nil
+		case utils.CallStatusBefore:
+			return format.Formatp(lang.Page.CallScheduleBreak, left), 0, nil
+		case utils.CallStatusDuring:
+			return format.Formatm(lang.Page.CallScheduleDuring, format.Values{
+				"number": status.Number,
+				"left":   left,
+			}), status.Number, nil
+		}
+	}
+
+	// No more classes today, find the next working day
+	next := now.AddDate(0, 0, 1)
+	for utils.IsWeekend(next) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	text := lang.Page.CallScheduleOver
+	if utils.IsWeekend(now) {
+		text = lang.Page.CallScheduleWeekend
+	}
+
+	return format.Formatm(text, format.Values{
+		"day":  getWeekDayName(lang, next.Weekday()),
+		"time": utils.ConvertLessonTime(next.Format(time.DateOnly), calls[0].TimeStart, loc),
+	}), 0, nil
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"github.com/sirkon/go-format/v2"
+	"time"
+)
+
+// ReminderSnoozeTime is the number of minutes the snoozed class reminder is sent again in
+const ReminderSnoozeTime = 5
+
+// CreateClassReminderPage creates a reminder page for the class that starts in offset minutes
+func CreateClassReminderPage(lang i18n.Language, lesson *api2.TimeTableLesson, date string, offset int, loc *time.Location) (Page, error) {
+	pageText := format.Formatm(lang.Page.ClassReminder, format.Values{
+		"remaining": offset,
+		"lessons":   formatReminderLessons(lesson, date, loc),
+	})
+
+	buttons := [][]gotgbot.InlineKeyboardButton{{
+		{
+			Text:         lang.Button.OpenSchedule,
+			CallbackData: utils.NewButtonData("open.schedule.day").Set("from", "notification").Set("date", date).String(),
+		},
+	}}
+
+	if len(lesson.Periods) != 0 {
+		buttons[0] = append(buttons[0], gotgbot.InlineKeyboardButton{
+			Text:         lang.Button.SnoozeReminder,
+			CallbackData: utils.NewButtonData("snooze.reminder").Set("date", date).SetInt("lesson", lesson.Number).String(),
+		})
+	}
+
+	page := Page{
+		Text: pageText,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: buttons,
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// CreateLessonReminderNotificationPage creates a page of the one-shot
+// lesson reminder, for the lesson that starts in remaining minutes
+func CreateLessonReminderNotificationPage(lang i18n.Language, lesson *api2.TimeTableLesson, date string, remaining int, loc *time.Location) (Page, error) {
+	pageText := format.Formatm(lang.Page.ClassReminder, format.Values{
+		"remaining": remaining,
+		"lessons":   formatReminderLessons(lesson, date, loc),
+	})
+
+	page := Page{
+		Text: pageText,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{{
+				Text:         lang.Button.OpenSchedule,
+				CallbackData: utils.NewButtonData("open.schedule.day").Set("from", "notification").Set("date", date).String(),
+			}}},
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// formatReminderLessons formats the lesson periods with their time and classroom
+func formatReminderLessons(lesson *api2.TimeTableLesson, date string, loc *time.Location) string {
+	lessonsText := ""
+	for _, period := range lesson.Periods {
+		lessonsText += format.Formatm("`$lesson\\)` $lessonIcon *$name*`[$type]`\n`   `🕒 `$timeStart` \\- `$timeEnd`\n`   `$classroom\n", format.Values{
+			"lesson":     lesson.Number,
+			"lessonIcon": utils.GetLessonIcon(period.Type),
+			"name":       utils.EscapeMarkdownV2(period.DisciplineShortName),
+			"type":       utils.EscapeMarkdownV2(period.TypeStr),
+			"timeStart":  utils.EscapeMarkdownV2(utils.ConvertLessonTime(date, period.TimeStart, loc)),
+			"timeEnd":    utils.EscapeMarkdownV2(utils.ConvertLessonTime(date, period.TimeEnd, loc)),
+			"classroom":  utils.EscapeMarkdownV2(period.Classroom),
+		})
+	}
+	return lessonsText
+}
@@ -23,28 +23,35 @@
 package pages
 
 import (
+	"context"
 	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
 	"github.com/cubicbyte/dteubot/internal/i18n"
 	"strconv"
 )
 
-func CreateCoursesListPage(lang i18n.Language, facultyId int, structureId int) (Page, error) {
-	courses, err := api.GetCourses(facultyId)
+// CreateCoursesListPage creates a page to select the course of the faculty.
+//
+// save is the same as in CreateStructuresListPage.
+func CreateCoursesListPage(ctx context.Context, lang i18n.Language, facultyId int, structureId int, save bool) (Page, error) {
+	courses, err := api.WithContext(ctx).GetCourses(facultyId)
 	if err != nil {
 		return Page{}, err
 	}
 
 	buttons := make([][]gotgbot.InlineKeyboardButton, len(courses)+1)
-	backBtnQuery := "select.schedule.structure#structureId=" + strconv.Itoa(structureId)
+	backBtnQuery := withSave(utils.NewButtonData("select.schedule.structure").SetInt("structureId", structureId), save).String()
 	buttons[0] = []gotgbot.InlineKeyboardButton{{
 		Text:         lang.Button.Back,
 		CallbackData: backBtnQuery,
 	}}
 
 	for i, course := range courses {
-		query := "select.schedule.course#course=" + strconv.Itoa(course.Course) +
-			"&facultyId=" + strconv.Itoa(facultyId) +
-			"&structureId=" + strconv.Itoa(structureId)
+		query := withSave(utils.NewButtonData("select.schedule.course").
+			SetInt("course", course.Course).
+			SetInt("facultyId", facultyId).
+			SetInt("structureId", structureId), save).
+			String()
 		buttons[i+1] = []gotgbot.InlineKeyboardButton{{
 			Text:         strconv.Itoa(course.Course),
 			CallbackData: query,
@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/sirkon/go-format/v2"
+	"time"
+)
+
+// MorningScheduleTimes is a list of morning schedule times available in the settings
+var MorningScheduleTimes = []string{"06:30", "07:00", "07:30", "08:00"}
+
+// EveningScheduleTimes is a list of evening schedule times available in the settings
+var EveningScheduleTimes = []string{"19:00", "20:00", "21:00", "22:00"}
+
+func CreateDailyScheduleSettingsPage(lang i18n.Language, chat *data.Chat) (Page, error) {
+	var (
+		morningNextState string
+		eveningNextState string
+		emptyNextState   string
+	)
+	if chat.MorningSchedule {
+		morningNextState = "0"
+	} else {
+		morningNextState = "1"
+	}
+	if chat.EveningSchedule {
+		eveningNextState = "0"
+	} else {
+		eveningNextState = "1"
+	}
+	if chat.DailyScheduleEmpty {
+		emptyNextState = "0"
+	} else {
+		emptyNextState = "1"
+	}
+
+	buttons := [][]gotgbot.InlineKeyboardButton{{{
+		Text:         format.Formatp(lang.Button.SettingMorningSchedule, utils.GetSettingIcon(chat.MorningSchedule)),
+		CallbackData: utils.NewButtonData("set.daily_schedule").Set("kind", "morning").Set("state", morningNextState).String(),
+	}}}
+
+	if chat.MorningSchedule {
+		buttons = append(buttons, createTimeButtons("morning", MorningScheduleTimes, chat.MorningScheduleTime, data.DefaultMorningScheduleTime))
+	}
+
+	buttons = append(buttons, []gotgbot.InlineKeyboardButton{{
+		Text:         format.Formatp(lang.Button.SettingEveningSchedule, utils.GetSettingIcon(chat.EveningSchedule)),
+		CallbackData: utils.NewButtonData("set.daily_schedule").Set("kind", "evening").Set("state", eveningNextState).String(),
+	}})
+
+	if chat.EveningSchedule {
+		buttons = append(buttons, createTimeButtons("evening", EveningScheduleTimes, chat.EveningScheduleTime, data.DefaultEveningScheduleTime))
+	}
+
+	buttons = append(buttons, []gotgbot.InlineKeyboardButton{{
+		Text:         format.Formatp(lang.Button.SettingDailyScheduleEmpty, utils.GetSettingIcon(chat.DailyScheduleEmpty)),
+		CallbackData: utils.NewButtonData("set.daily_empty").Set("state", emptyNextState).String(),
+	}}, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Back,
+		CallbackData: "open.settings",
+	}})
+
+	page := Page{
+		Text: lang.Page.DailyScheduleSettings,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: buttons,
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// CreateNoClassesPage creates a page that is sent by the daily schedule
+// instead of the schedule if there are no classes on the given date
+func CreateNoClassesPage(lang i18n.Language, date string, tomorrow bool) (Page, error) {
+	date_, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return Page{}, err
+	}
+
+	pageText := lang.Page.NoClassesToday
+	if tomorrow {
+		pageText = lang.Page.NoClassesTomorrow
+	}
+
+	page := Page{
+		Text: format.Formatm(pageText, format.Values{
+			"date": getLocalizedDate(lang, date_, "🗓"),
+		}),
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
+				{
+					Text:         lang.Button.OpenSchedule,
+					CallbackData: utils.NewButtonData("open.schedule.day").Set("from", "notification").Set("date", date).String(),
+				},
+			}},
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// createTimeButtons creates a row of daily schedule time buttons,
+// the selected time is marked
+func createTimeButtons(kind string, times []string, selected string, defaultTime string) []gotgbot.InlineKeyboardButton {
+	if selected == "" {
+		selected = defaultTime
+	}
+
+	buttons := make([]gotgbot.InlineKeyboardButton, 0, len(times))
+	for _, time2 := range times {
+		text := time2
+		if time2 == selected {
+			text = "• " + text + " •"
+		}
+		buttons = append(buttons, gotgbot.InlineKeyboardButton{
+			Text:         text,
+			CallbackData: utils.NewButtonData("set.daily_time").Set("kind", kind).Set("time", time2).String(),
+		})
+	}
+
+	return buttons
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/sirkon/go-format/v2"
+	"strconv"
+	"time"
+)
+
+// DatePickerMonthFormat is a format of the month shown by the date picker
+const DatePickerMonthFormat = "2006-01"
+
+// CreateDatePickerPage creates a month calendar to jump straight to the day schedule.
+//
+// month is the month to show, like "2024-03". back is the date of the schedule
+// the picker was opened from, the back button returns to it. groupId is the group
+// of that schedule, or -1 if it's the chat group.
+func CreateDatePickerPage(lang i18n.Language, chat *data.Chat, month string, back string, groupId int) (Page, error) {
+	monthStart, err := time.Parse(DatePickerMonthFormat, month)
+	if err != nil {
+		return Page{}, err
+	}
+
+	today := utils.NowFor(chat).Format(time.DateOnly)
+
+	// Weekdays header. Weeks start on Monday, like in the university calendar
+	header := make([]gotgbot.InlineKeyboardButton, 0, 7)
+	for i := 1; i <= 7; i++ {
+		header = append(header, gotgbot.InlineKeyboardButton{
+			Text:         getShortWeekDayName(lang, time.Weekday(i%7)),
+			CallbackData: "noop",
+		})
+	}
+
+	// Days grid, with the days outside the month blanked
+	offset := (int(monthStart.Weekday()) + 6) % 7
+	daysCount := monthStart.AddDate(0, 1, -1).Day()
+	cells := make([]gotgbot.InlineKeyboardButton, 0, 42)
+	for i := 0; i < offset; i++ {
+		cells = append(cells, blankDayButton())
+	}
+	for day := 1; day <= daysCount; day++ {
+		date := monthStart.AddDate(0, 0, day-1).Format(time.DateOnly)
+
+		text := strconv.Itoa(day)
+		if date == today {
+			text = "·" + text + "·"
+		}
+
+		cells = append(cells, gotgbot.InlineKeyboardButton{
+			Text:         text,
+			CallbackData: withGroup(utils.NewButtonData("open.schedule.day").Set("date", date), groupId, -1).String(),
+		})
+	}
+	for len(cells)%7 != 0 {
+		cells = append(cells, blankDayButton())
+	}
+
+	keyboard := append([][]gotgbot.InlineKeyboardButton{header}, utils.SplitRows(cells, 7)...)
+
+	// Month navigation
+	pickerButton := func(month time.Time) string {
+		return withGroup(utils.NewButtonData("open.date_picker").
+			Set("month", month.Format(DatePickerMonthFormat)).
+			Set("date", back), groupId, -1).
+			String()
+	}
+	prevMonth := monthStart.AddDate(0, -1, 0)
+	nextMonth := monthStart.AddDate(0, 1, 0)
+	keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{{
+		Text:         "⬅️ " + prevMonth.Format("01.2006"),
+		CallbackData: pickerButton(prevMonth),
+	}, {
+		Text:         nextMonth.Format("01.2006") + " ➡️",
+		CallbackData: pickerButton(nextMonth),
+	}}, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Back,
+		CallbackData: withGroup(utils.NewButtonData("open.schedule.day").Set("date", back), groupId, -1).String(),
+	}})
+
+	page := Page{
+		Text: format.Formatm(lang.Page.DatePicker, format.Values{
+			"month": getMonthName(lang, monthStart.Month()),
+			"year":  monthStart.Year(),
+		}),
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// blankDayButton creates an empty cell of the date picker grid
+func blankDayButton() gotgbot.InlineKeyboardButton {
+	return gotgbot.InlineKeyboardButton{
+		Text:         " ",
+		CallbackData: "noop",
+	}
+}
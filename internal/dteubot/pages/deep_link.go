@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/sirkon/go-format/v2"
+	"time"
+)
+
+// CreateDeepLinkConfirmPage creates a page asking whether to switch
+// to the group from the deep link and show its schedule
+func CreateDeepLinkConfirmPage(lang i18n.Language, link utils.DeepLink) (Page, error) {
+	groupName, err := getGroupName(lang, link.GroupId)
+	if err != nil {
+		return Page{}, err
+	}
+
+	date, err := formatDeepLinkDate(link)
+	if err != nil {
+		return Page{}, err
+	}
+
+	page := Page{
+		Text: format.Formatm(lang.Page.DeepLinkConfirm, format.Values{
+			"group": groupName,
+			"date":  date,
+		}),
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{{
+				Text:         lang.Text.Yes,
+				CallbackData: utils.NewButtonData("set.deep_link").SetInt("groupId", link.GroupId).Set("date", link.Date).String(),
+			}, {
+				Text:         lang.Text.No,
+				CallbackData: "open.menu",
+			}}},
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// CreateShareSchedulePage creates a page with the deep link to the group schedule for the day
+func CreateShareSchedulePage(lang i18n.Language, link utils.DeepLink, botUsername string) (Page, error) {
+	groupName, err := getGroupName(lang, link.GroupId)
+	if err != nil {
+		return Page{}, err
+	}
+
+	date, err := formatDeepLinkDate(link)
+	if err != nil {
+		return Page{}, err
+	}
+
+	page := Page{
+		Text: format.Formatm(lang.Page.ShareSchedule, format.Values{
+			"group": groupName,
+			"date":  date,
+			"link":  utils.EscapeMarkdownV2(link.URL(botUsername)),
+		}),
+		ParseMode:             "MarkdownV2",
+		DisableWebPagePreview: true,
+	}
+
+	return page, nil
+}
+
+// CreateShareGroupPage creates a page with the deep link that selects the group
+func CreateShareGroupPage(lang i18n.Language, groupId int, botUsername string) (Page, error) {
+	groupName, err := getGroupName(lang, groupId)
+	if err != nil {
+		return Page{}, err
+	}
+
+	link := utils.DeepLink{GroupId: groupId}
+	page := Page{
+		Text: format.Formatm(lang.Page.ShareGroup, format.Values{
+			"group": groupName,
+			"link":  utils.EscapeMarkdownV2(link.URL(botUsername)),
+		}),
+		ParseMode:             "MarkdownV2",
+		DisableWebPagePreview: true,
+	}
+
+	return page, nil
+}
+
+// createShareGroupButton creates a button that sends the deep link to the group
+func createShareGroupButton(lang i18n.Language, groupId int) []gotgbot.InlineKeyboardButton {
+	return []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.ShareGroup,
+		CallbackData: utils.NewButtonData("share.group").SetInt("groupId", groupId).String(),
+	}}
+}
+
+// formatDeepLinkDate returns the deep link date like "21\.10\.2024"
+func formatDeepLinkDate(link utils.DeepLink) (string, error) {
+	date, err := time.Parse(time.DateOnly, link.Date)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.EscapeMarkdownV2(date.Format("02.01.2006")), nil
+}
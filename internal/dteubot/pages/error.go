@@ -33,7 +33,7 @@ func CreateErrorPage(lang i18n.Language) (Page, error) {
 		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
 			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
 				{{
-					Text:         lang.Button.Menu,
+					Text:         lang.Button.BackToMenu,
 					CallbackData: "open.menu",
 				}},
 			},
@@ -43,3 +43,13 @@ func CreateErrorPage(lang i18n.Language) (Page, error) {
 
 	return page, nil
 }
+
+// CreateChatNotFoundPage creates the page shown when the chat is missing in the database
+func CreateChatNotFoundPage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text:      lang.Page.ChatNotFound,
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
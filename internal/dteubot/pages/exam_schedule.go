@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"context"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/sirkon/go-format/v2"
+	"time"
+)
+
+// ExamSessionDays is a number of days, starting from today,
+// in which the exams are searched
+const ExamSessionDays = 45
+
+func CreateExamSchedulePage(ctx context.Context, lang i18n.Language, groupId int, loc *time.Location) (Page, error) {
+	if groupId == -1 {
+		return CreateInvalidGroupPage(lang)
+	}
+
+	today := utils.Now().In(loc)
+	dateStart := today.Format(time.DateOnly)
+	dateEnd := today.AddDate(0, 0, ExamSessionDays-1).Format(time.DateOnly)
+
+	exams, err := api.WithContext(ctx).GetGroupExams(groupId, dateStart, dateEnd)
+	if err != nil {
+		return Page{}, err
+	}
+
+	buttons := gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{{
+			Text:         lang.Button.OpenSchedule,
+			CallbackData: "open.schedule.today",
+		}}, {{
+			Text:         lang.Button.Back,
+			CallbackData: "open.menu",
+		}}},
+	}
+
+	if len(exams) == 0 {
+		page := Page{
+			Text:        format.Formatp(lang.Page.NoExamSession, ExamSessionDays),
+			ReplyMarkup: buttons,
+			ParseMode:   "MarkdownV2",
+		}
+
+		return page, nil
+	}
+
+	// Create exams list, grouped by date
+	examsText := ""
+	lastDate := ""
+	for _, exam := range exams {
+		if exam.Date != lastDate {
+			date, err := time.Parse(time.DateOnly, exam.Date)
+			if err != nil {
+				return Page{}, err
+			}
+
+			if lastDate != "" {
+				examsText += "\n"
+			}
+			examsText += getLocalizedDate(lang, date, "🗓") + "\n"
+			lastDate = exam.Date
+		}
+
+		examsText += format.Formatm("`$lesson)` $icon *$name*`[$type]`\n", format.Values{
+			"lesson": exam.Number,
+			"icon":   utils.GetLessonIcon(exam.Period.Type),
+			"name":   utils.EscapeMarkdownV2(exam.Period.DisciplineShortName),
+			"type":   utils.EscapeMarkdownV2(exam.Period.TypeStr),
+		})
+		if exam.Period.TimeStart != "" && exam.Period.TimeEnd != "" {
+			timeStart := utils.ConvertLessonTime(exam.Date, exam.Period.TimeStart, loc)
+			timeEnd := utils.ConvertLessonTime(exam.Date, exam.Period.TimeEnd, loc)
+			examsText += "🕒 `" + utils.EscapeMarkdownV2(timeStart) + "` \\- `" + utils.EscapeMarkdownV2(timeEnd) + "`\n"
+		}
+		if exam.Period.Classroom != "" {
+			examsText += "🏛 " + utils.EscapeMarkdownV2(exam.Period.Classroom) + "\n"
+		}
+		if exam.Period.TeachersNameFull != "" {
+			examsText += "👨‍🏫 " + utils.EscapeMarkdownV2(exam.Period.TeachersNameFull) + "\n"
+		}
+	}
+
+	page := Page{
+		Text: format.Formatm(lang.Page.ExamSchedule, format.Values{
+			"exams": examsText,
+		}),
+		ReplyMarkup: buttons,
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
@@ -23,18 +23,22 @@
 package pages
 
 import (
+	"context"
 	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
 	"github.com/cubicbyte/dteubot/internal/i18n"
-	"strconv"
 )
 
-func CreateFacultiesListPage(lang i18n.Language, structureId int) (Page, error) {
-	faculties, err := api.GetFaculties(structureId)
+// CreateFacultiesListPage creates a page to select the faculty of the structure.
+//
+// save is the same as in CreateStructuresListPage.
+func CreateFacultiesListPage(ctx context.Context, lang i18n.Language, structureId int, groupId int, save bool) (Page, error) {
+	faculties, err := api.WithContext(ctx).GetFaculties(structureId)
 	if err != nil {
 		return Page{}, err
 	}
 
-	structures, err := api.GetStructures()
+	structures, err := api.WithContext(ctx).GetStructures()
 	if err != nil {
 		return Page{}, err
 	}
@@ -43,29 +47,34 @@ func CreateFacultiesListPage(lang i18n.Language, structureId int) (Page, error)
 	var backButton gotgbot.InlineKeyboardButton
 	if len(structures) <= 1 {
 		// Back button = menu
-		backButton = gotgbot.InlineKeyboardButton{
-			Text:         lang.Button.Back,
-			CallbackData: "open.menu#from=group_select",
-		}
+		backButton = createGroupSelectionBackButton(lang, save)
 	} else {
 		// Back button = group selection
 		backButton = gotgbot.InlineKeyboardButton{
 			Text:         lang.Button.Back,
-			CallbackData: "open.select_group",
+			CallbackData: withSave(utils.NewButtonData("open.select_group"), save).String(),
 		}
 	}
 
-	buttons := make([][]gotgbot.InlineKeyboardButton, len(faculties)+1)
+	buttons := make([][]gotgbot.InlineKeyboardButton, len(faculties)+1, len(faculties)+3)
 	buttons[0] = []gotgbot.InlineKeyboardButton{backButton}
 
 	for i, faculty := range faculties {
-		query := "select.schedule.faculty#facultyId=" + strconv.Itoa(faculty.Id) + "&structureId=" + strconv.Itoa(structureId)
+		query := withSave(utils.NewButtonData("select.schedule.faculty").SetInt("facultyId", faculty.Id).SetInt("structureId", structureId), save).String()
 		buttons[i+1] = []gotgbot.InlineKeyboardButton{{
 			Text:         faculty.FullName,
 			CallbackData: query,
 		}}
 	}
 
+	// Search and sharing are about the chat group
+	if !save {
+		buttons = append(buttons, createGroupSearchButton(lang))
+		if groupId != -1 {
+			buttons = append(buttons, createShareGroupButton(lang, groupId))
+		}
+	}
+
 	page := Page{
 		Text:        lang.Page.FacultySelection,
 		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons},
@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/sirkon/go-format/v2"
+)
+
+// CreateFindGroupPage creates a page with the groups whose names are the most
+// similar to the query. Tapping a group selects it.
+func CreateFindGroupPage(lang i18n.Language, query string) (Page, error) {
+	groups, updated, err := FindGroups(query)
+	if err != nil {
+		return Page{}, err
+	}
+
+	backButton := []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.SavedGroups,
+		CallbackData: "open.saved_groups",
+	}}
+
+	var text string
+	var buttons [][]gotgbot.InlineKeyboardButton
+	if len(groups) == 0 {
+		text = format.Formatm(lang.Page.FindGroupEmpty, format.Values{
+			"query": utils.EscapeMarkdownV2(query),
+		})
+		buttons = [][]gotgbot.InlineKeyboardButton{backButton}
+	} else {
+		text = format.Formatm(lang.Page.FindGroup, format.Values{
+			"query": utils.EscapeMarkdownV2(query),
+		})
+
+		buttons = make([][]gotgbot.InlineKeyboardButton, 0, len(groups)+1)
+		for _, group := range groups {
+			buttons = append(buttons, []gotgbot.InlineKeyboardButton{{
+				Text:         group.Name,
+				CallbackData: utils.NewButtonData("select.schedule.group").SetInt("groupId", group.Id).String(),
+			}})
+		}
+		buttons = append(buttons, backButton)
+	}
+
+	if !updated.IsZero() {
+		text += "\n\n_" + utils.EscapeMarkdownV2(getOutdatedDataNote(lang, updated)) + "_"
+	}
+
+	page := Page{
+		Text:        text,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons},
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
@@ -0,0 +1,307 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"context"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/rooms"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"github.com/sirkon/go-format/v2"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetFreeRoomsBuildings returns the list of buildings available
+// in the free rooms finder, from FREE_ROOMS_BUILDINGS env variable
+func GetFreeRoomsBuildings() []string {
+	buildings := make([]string, 0)
+	for _, building := range strings.Split(os.Getenv("FREE_ROOMS_BUILDINGS"), ",") {
+		building = strings.TrimSpace(building)
+		if building != "" {
+			buildings = append(buildings, building)
+		}
+	}
+	return buildings
+}
+
+// freeRoomsSource returns the source of the classroom bookings for the free rooms finder
+func freeRoomsSource() rooms.Source {
+	return rooms.Sources{
+		rooms.GroupsSource{Api: api},
+		rooms.TeachersSource{Api: api},
+	}
+}
+
+// PreloadFreeRooms starts collecting the classrooms usage of the current week
+// in the background, so the free rooms page doesn't ask to wait for it.
+// Does nothing if the free rooms finder is not configured.
+func PreloadFreeRooms() {
+	if len(GetFreeRoomsBuildings()) == 0 {
+		return
+	}
+
+	weekStart := GetWeekStart(utils.NowFor(nil))
+	if err := roomsCache.Preload(weekStart.Format(time.DateOnly), weekStart.AddDate(0, 0, 6).Format(time.DateOnly)); err != nil {
+		slog.Warn("Error preloading free rooms", "error", err)
+	}
+}
+
+// CreateFreeRoomsBuildingsPage creates a page with the building selection
+func CreateFreeRoomsBuildingsPage(lang i18n.Language, date string) (Page, error) {
+	date_, err := api2.ParseISODate(date)
+	if err != nil {
+		return Page{}, err
+	}
+
+	buttons := make([]gotgbot.InlineKeyboardButton, 0)
+	for i, building := range GetFreeRoomsBuildings() {
+		buttons = append(buttons, gotgbot.InlineKeyboardButton{
+			Text:         building,
+			CallbackData: utils.NewButtonData("open.free_rooms").Set("date", date).SetInt("building", i).String(),
+		})
+	}
+
+	keyboard := utils.SplitRows(buttons, 4)
+	keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Back,
+		CallbackData: utils.NewButtonData("open.schedule.extra").Set("date", date).String(),
+	}})
+
+	page := Page{
+		Text: format.Formatm(lang.Page.FreeRoomsBuilding, format.Values{
+			"date": getLocalizedDate(lang, date_, "🗓"),
+		}),
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+func CreateFreeRoomsNotConfiguredPage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text:      lang.Page.FreeRoomsNotConfigured,
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// CreateFreeRoomsLessonsPage creates a page with the lesson selection.
+//
+// building is an index of the building in GetFreeRoomsBuildings list
+func CreateFreeRoomsLessonsPage(ctx context.Context, lang i18n.Language, building int, date string) (Page, error) {
+	date_, err := api2.ParseISODate(date)
+	if err != nil {
+		return Page{}, err
+	}
+
+	buildings := GetFreeRoomsBuildings()
+	if building < 0 || building >= len(buildings) {
+		return CreateFreeRoomsBuildingsPage(lang, date)
+	}
+
+	calls, err := api.WithContext(ctx).GetCallSchedule()
+	if err != nil {
+		return Page{}, err
+	}
+
+	buttons := make([]gotgbot.InlineKeyboardButton, 0, len(calls))
+	for _, call := range calls {
+		buttons = append(buttons, gotgbot.InlineKeyboardButton{
+			Text:         strconv.Itoa(call.Number) + ") " + call.TimeStart,
+			CallbackData: utils.NewButtonData("open.free_rooms").Set("date", date).SetInt("building", building).SetInt("lesson", call.Number).String(),
+		})
+	}
+
+	// Pick another date of the same building
+	dateButton := func(date time.Time) string {
+		return utils.NewButtonData("open.free_rooms").Set("date", date.Format(time.DateOnly)).SetInt("building", building).String()
+	}
+
+	keyboard := utils.SplitRows(buttons, 3)
+	keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.ScheduleNavigationPreviousDay,
+		CallbackData: dateButton(date_.AddDate(0, 0, -1)),
+	}, {
+		Text:         lang.Button.ScheduleNavigationNextDay,
+		CallbackData: dateButton(date_.AddDate(0, 0, 1)),
+	}}, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Back,
+		CallbackData: utils.NewButtonData("open.free_rooms").Set("date", date).String(),
+	}})
+
+	page := Page{
+		Text: format.Formatm(lang.Page.FreeRoomsLesson, format.Values{
+			"date":     getLocalizedDate(lang, date_, "🗓"),
+			"building": utils.EscapeMarkdownV2(buildings[building]),
+		}),
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// CreateRoomsPage creates a page with the classrooms of the building
+// that are occupied at the given lesson, with the groups and subjects,
+// and the ones that are free.
+//
+// Classrooms are taken from the schedules of all the groups and teachers for
+// the week, so only the classrooms used at least once in the week are known.
+// Collecting them takes a while, so until it is completed the page
+// asks to try again later.
+func CreateRoomsPage(ctx context.Context, lang i18n.Language, building string, lessonNumber int, date string) (Page, error) {
+	date_, err := api2.ParseISODate(date)
+	if err != nil {
+		return Page{}, err
+	}
+
+	calls, err := api.WithContext(ctx).GetCallSchedule()
+	if err != nil {
+		return Page{}, err
+	}
+
+	// Buttons of the same building
+	buildingIndex := -1
+	for i, building2 := range GetFreeRoomsBuildings() {
+		if building2 == building {
+			buildingIndex = i
+			break
+		}
+	}
+	lessonButton := func(lesson int) string {
+		return utils.NewButtonData("open.free_rooms").Set("date", date).SetInt("building", buildingIndex).SetInt("lesson", lesson).String()
+	}
+
+	// Navigate between the lessons
+	navigation := make([]gotgbot.InlineKeyboardButton, 0, 2)
+	for _, call := range calls {
+		if call.Number == lessonNumber-1 {
+			navigation = append(navigation, gotgbot.InlineKeyboardButton{
+				Text:         lang.Button.ScheduleNavigationPreviousDay,
+				CallbackData: lessonButton(call.Number),
+			})
+		}
+	}
+	for _, call := range calls {
+		if call.Number == lessonNumber+1 {
+			navigation = append(navigation, gotgbot.InlineKeyboardButton{
+				Text:         lang.Button.ScheduleNavigationNextDay,
+				CallbackData: lessonButton(call.Number),
+			})
+		}
+	}
+
+	keyboard := make([][]gotgbot.InlineKeyboardButton, 0, 2)
+	if len(navigation) != 0 {
+		keyboard = append(keyboard, navigation)
+	}
+	keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Refresh,
+		CallbackData: lessonButton(lessonNumber),
+	}, {
+		Text:         lang.Button.Back,
+		CallbackData: utils.NewButtonData("open.free_rooms").Set("date", date).SetInt("building", buildingIndex).String(),
+	}})
+	buttons := gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+
+	weekStart := GetWeekStart(date_)
+	usage, err := roomsCache.Get(weekStart.Format(time.DateOnly), weekStart.AddDate(0, 0, 6).Format(time.DateOnly))
+	if err != nil {
+		return Page{}, err
+	}
+
+	if usage == nil {
+		page := Page{
+			Text: format.Formatm(lang.Page.RoomsCollecting, format.Values{
+				"date": getLocalizedDate(lang, date_, "🗓"),
+			}),
+			ReplyMarkup: buttons,
+			ParseMode:   "MarkdownV2",
+		}
+
+		return page, nil
+	}
+
+	if len(usage.GetBuildingRooms(building)) == 0 {
+		page := Page{
+			Text: format.Formatm(lang.Page.FreeRoomsUnavailable, format.Values{
+				"date": getLocalizedDate(lang, date_, "🗓"),
+			}),
+			ReplyMarkup: buttons,
+			ParseMode:   "MarkdownV2",
+		}
+
+		return page, nil
+	}
+
+	freeText := lang.Text.FreeRoomsNone
+	if free := usage.GetFreeRooms(building, date, lessonNumber); len(free) != 0 {
+		freeText = utils.EscapeMarkdownV2(strings.Join(free, ", "))
+	}
+
+	occupiedText := lang.Text.OccupiedRoomsNone
+	if occupied := usage.GetOccupiedRooms(building, date, lessonNumber); len(occupied) != 0 {
+		lines := make([]string, len(occupied))
+		for i, room := range occupied {
+			// Consultations and some other lessons have no groups
+			details := strings.Join(room.Groups, ", ")
+			if len(room.Subjects) != 0 {
+				if details != "" {
+					details += ": "
+				}
+				details += strings.Join(room.Subjects, ", ")
+			}
+			if details != "" {
+				details = "— " + details
+			}
+
+			lines[i] = format.Formatm(lang.Text.OccupiedRoom, format.Values{
+				"room":    utils.EscapeMarkdownV2(room.Room),
+				"details": utils.EscapeMarkdownV2(details),
+			})
+		}
+		occupiedText = strings.Join(lines, "\n")
+	}
+
+	page := Page{
+		Text: format.Formatm(lang.Page.FreeRooms, format.Values{
+			"date":     getLocalizedDate(lang, date_, "🗓"),
+			"building": utils.EscapeMarkdownV2(building),
+			"lesson":   lessonNumber,
+			"occupied": occupiedText,
+			"free":     freeText,
+		}),
+		ReplyMarkup: buttons,
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
@@ -23,12 +23,23 @@
 package pages
 
 import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/cubicbyte/dteubot/internal/i18n"
 )
 
-func CreateGreetingPage(lang i18n.Language) (Page, error) {
+// CreateGroupChatSetupPage creates a page for the group chats without
+// the group selected, explaining the shared schedule of the chat
+func CreateGroupChatSetupPage(lang i18n.Language) (Page, error) {
 	page := Page{
-		Text:      lang.Page.Greeting,
+		Text: lang.Page.GroupChatSetup,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
+				{
+					Text:         lang.Button.GroupChatSetup,
+					CallbackData: "setup.group_chat",
+				},
+			}},
+		},
 		ParseMode: "MarkdownV2",
 	}
 
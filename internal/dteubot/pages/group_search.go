@@ -0,0 +1,307 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/groupscache"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/sirkon/go-format/v2"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GroupSearchPageSize is a number of groups shown on one search results page
+const GroupSearchPageSize = 7 * rowSize
+
+// MaxGroupSearchCandidates is a max number of groups with similar names
+// shown if no group name contains the query
+const MaxGroupSearchCandidates = 10
+
+// MaxGroupNameDistance is a max number of typos in the query
+// for the group to be shown as a candidate
+const MaxGroupNameDistance = 2
+
+// MaxFoundGroups is a number of the groups with the most similar names FindGroups returns
+const MaxFoundGroups = 5
+
+// MinGroupNameSimilarity is a min trigram similarity of the group name
+// to the query for the group to be found by FindGroups
+const MinGroupNameSimilarity = 0.2
+
+// groupNameReplacer replaces the Latin letters that look like Cyrillic ones
+// and the letters that look like digits, so Latin "KH-21" matches "КН-21" and
+// "0А-21" matches "ОА-21". Separators are removed, so "кн 21" matches too.
+var groupNameReplacer = strings.NewReplacer(
+	"a", "а", "b", "в", "c", "с", "e", "е", "h", "н", "i", "і", "k", "к",
+	"m", "м", "p", "р", "t", "т", "x", "х", "y", "у",
+	"o", "0", "о", "0", "з", "3",
+	"-", "", "–", "", "_", "", ".", "", " ", "",
+)
+
+// CreateGroupSearchPage creates a page with groups whose name contains the query.
+//
+// pageNum is a number of the results page, starting from 0
+func CreateGroupSearchPage(lang i18n.Language, query string, pageNum int) (Page, error) {
+	groups, err := SearchGroups(query)
+	if err != nil {
+		return Page{}, err
+	}
+
+	if len(groups) == 0 {
+		page := Page{
+			Text: format.Formatm(lang.Page.GroupSearchEmpty, format.Values{
+				"query": utils.EscapeMarkdownV2(query),
+			}),
+			ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+				InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{{
+					Text:         lang.Button.SelectGroup,
+					CallbackData: "open.select_group",
+				}}, {{
+					Text:         lang.Button.Menu,
+					CallbackData: utils.NewButtonData("open.menu").Set("from", "group_search").String(),
+				}}},
+			},
+			ParseMode: "MarkdownV2",
+		}
+
+		return page, nil
+	}
+
+	btns := make([]gotgbot.InlineKeyboardButton, len(groups))
+	for i, group := range groups {
+		btns[i] = gotgbot.InlineKeyboardButton{
+			Text:         group.Name,
+			CallbackData: utils.NewButtonData("select.schedule.group").SetInt("groupId", group.Id).String(),
+		}
+	}
+
+	buttons, err := paginate(lang, btns, pageNum, GroupSearchPageSize, rowSize, utils.NewButtonData("search.group").SetInt("queryHash", utils.QueryHash(query)))
+	if err != nil {
+		return Page{}, err
+	}
+
+	buttons = append(buttons, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Menu,
+		CallbackData: utils.NewButtonData("open.menu").Set("from", "group_search").String(),
+	}})
+
+	page := Page{
+		Text: format.Formatm(lang.Page.GroupSearch, format.Values{
+			"query": utils.EscapeMarkdownV2(query),
+			"count": len(groups),
+		}),
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons},
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// CreateGroupSearchPromptPage creates a page asking to send the group name
+func CreateGroupSearchPromptPage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text: lang.Page.GroupSearchPrompt,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{{
+				Text:         lang.Button.Back,
+				CallbackData: "open.select_group",
+			}}},
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// createGroupSearchButton creates a button to search the group by name
+// instead of selecting it from the lists
+func createGroupSearchButton(lang i18n.Language) []gotgbot.InlineKeyboardButton {
+	return []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.GroupSearch,
+		CallbackData: "open.group_search",
+	}}
+}
+
+// SearchGroups returns groups of all the faculties and courses whose name
+// contains the query, sorted by name. Not case sensitive and doesn't
+// distinguish similar Cyrillic and Latin characters.
+//
+// If there are no such groups, up to MaxGroupSearchCandidates groups
+// with the most similar names are returned, so typos are tolerated.
+//
+// Groups are searched in the shared groups list, so only the first search is slow.
+func SearchGroups(query string) ([]groupscache.Group, error) {
+	query = normalizeGroupName(query)
+	if query == "" {
+		return nil, nil
+	}
+
+	groups, _, err := groupList.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]groupscache.Group, 0)
+	candidates := make([]groupCandidate, 0)
+
+	for _, group := range groups {
+		name := normalizeGroupName(group.Name)
+		if strings.Contains(name, query) {
+			found = append(found, group)
+		} else if len(found) == 0 {
+			if distance := levenshtein(name, query); distance <= MaxGroupNameDistance {
+				candidates = append(candidates, groupCandidate{group, distance})
+			}
+		}
+	}
+
+	if len(found) == 0 {
+		// Show the most similar names first
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].Distance < candidates[j].Distance
+		})
+
+		for _, candidate := range candidates[:min(len(candidates), MaxGroupSearchCandidates)] {
+			found = append(found, candidate.Group)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].Name < found[j].Name
+	})
+
+	return found, nil
+}
+
+// FindGroups returns up to MaxFoundGroups groups with the names most similar to the query,
+// the most similar first. Names are compared by the trigram similarity after the same
+// normalization as in SearchGroups, so the word order and typos matter less.
+//
+// If the groups list is outdated because the API is unavailable, also returns
+// the time it was fetched at. Otherwise, returns zero time.
+func FindGroups(query string) ([]groupscache.Group, time.Time, error) {
+	query = normalizeGroupName(query)
+	if query == "" {
+		return nil, time.Time{}, nil
+	}
+
+	groups, updated, err := groupList.Get()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	queryTrigrams := trigrams(query)
+	candidates := make([]groupSimilarity, 0)
+	for _, group := range groups {
+		similarity := trigramSimilarity(queryTrigrams, trigrams(normalizeGroupName(group.Name)))
+		if similarity >= MinGroupNameSimilarity {
+			candidates = append(candidates, groupSimilarity{group, similarity})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Similarity > candidates[j].Similarity
+	})
+
+	found := make([]groupscache.Group, 0, MaxFoundGroups)
+	for _, candidate := range candidates[:min(len(candidates), MaxFoundGroups)] {
+		found = append(found, candidate.Group)
+	}
+
+	return found, updated, nil
+}
+
+// groupSimilarity is a group with the trigram similarity of its name to the query
+type groupSimilarity struct {
+	Group      groupscache.Group
+	Similarity float64
+}
+
+// trigrams returns the set of the three-character substrings of the string.
+// The string is padded with spaces, so the short strings and their
+// beginnings are compared too.
+func trigrams(s string) map[string]bool {
+	runes := []rune("  " + s + " ")
+	set := make(map[string]bool, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = true
+	}
+	return set
+}
+
+// trigramSimilarity returns the share of the common trigrams of two
+// strings in all their trigrams, from 0 (nothing in common) to 1 (equal)
+func trigramSimilarity(a, b map[string]bool) float64 {
+	common := 0
+	for trigram := range a {
+		if b[trigram] {
+			common++
+		}
+	}
+
+	total := len(a) + len(b) - common
+	if total == 0 {
+		return 0
+	}
+	return float64(common) / float64(total)
+}
+
+// groupCandidate is a group whose name is similar to the search query
+type groupCandidate struct {
+	Group    groupscache.Group
+	Distance int
+}
+
+// levenshtein returns the number of characters to insert,
+// delete or replace to get the string b from the string a
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// normalizeGroupName prepares the group name for comparison
+func normalizeGroupName(name string) string {
+	return groupNameReplacer.Replace(strings.ToLower(strings.TrimSpace(name)))
+}
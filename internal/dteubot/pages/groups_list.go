@@ -23,18 +23,27 @@
 package pages
 
 import (
+	"context"
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/cubicbyte/dteubot/internal/dteubot/groupscache"
 	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
 	"github.com/cubicbyte/dteubot/internal/i18n"
-	"strconv"
+	"log/slog"
 	"time"
 )
 
 const rowSize = 3
 
-func CreateGroupsListPage(lang i18n.Language, facultyId int, course int, structureId int) (Page, error) {
-	groupsList, err := api.GetGroups(facultyId, course)
+// GroupsListPageSize is a number of groups shown on one groups list page.
+// Some faculties have more groups than telegram allows buttons in a keyboard
+const GroupsListPageSize = 10 * rowSize
+
+// CreateGroupsListPage creates a page to select the group of the faculty course.
+//
+// save is the same as in CreateStructuresListPage.
+// pageNum is a number of the groups list page, starting from 0
+func CreateGroupsListPage(ctx context.Context, lang i18n.Language, facultyId int, course int, structureId int, save bool, pageNum int) (Page, error) {
+	groupsList, err := api.WithContext(ctx).GetGroups(facultyId, course)
 	if err != nil {
 		return Page{}, err
 	}
@@ -58,29 +67,34 @@ func CreateGroupsListPage(lang i18n.Language, facultyId int, course int, structu
 	}
 
 	// Create back button
-	rowsCount := len(groupsList)/rowSize + 1
-	if len(groupsList)%rowSize != 0 {
-		rowsCount++
-	}
-	buttons := make([][]gotgbot.InlineKeyboardButton, 1, rowsCount)
-	backBtnQuery := "select.schedule.faculty#facultyId=" + strconv.Itoa(facultyId) +
-		"&structureId=" + strconv.Itoa(structureId)
-	buttons[0] = []gotgbot.InlineKeyboardButton{{
+	backBtnQuery := withSave(utils.NewButtonData("select.schedule.faculty").
+		SetInt("facultyId", facultyId).
+		SetInt("structureId", structureId), save).
+		String()
+	buttons := [][]gotgbot.InlineKeyboardButton{{{
 		Text:         lang.Button.Back,
 		CallbackData: backBtnQuery,
-	}}
+	}}}
 
 	// Create group buttons
 	btns := make([]gotgbot.InlineKeyboardButton, len(groupsList))
 	for i, group := range groupsList {
 		btns[i] = gotgbot.InlineKeyboardButton{
 			Text:         group.Name,
-			CallbackData: "select.schedule.group#groupId=" + strconv.Itoa(group.Id),
+			CallbackData: withSave(utils.NewButtonData("select.schedule.group").SetInt("groupId", group.Id), save).String(),
 		}
 	}
 
 	// Create keyboard rows
-	buttons = append(buttons, utils.SplitRows(btns, rowSize)...)
+	nav := withSave(utils.NewButtonData("select.schedule.course").
+		SetInt("course", course).
+		SetInt("facultyId", facultyId).
+		SetInt("structureId", structureId), save)
+	rows, err := paginate(lang, btns, pageNum, GroupsListPageSize, rowSize, nav)
+	if err != nil {
+		return Page{}, err
+	}
+	buttons = append(buttons, rows...)
 
 	page := Page{
 		Text:        lang.Page.GroupSelection,
@@ -90,3 +104,51 @@ func CreateGroupsListPage(lang i18n.Language, facultyId int, course int, structu
 
 	return page, nil
 }
+
+// CacheGroup adds the selected group to the groups cache, so its name can be
+// shown later. The groups opened from the lists are cached already, the rest
+// are taken from the shared groups list.
+func CacheGroup(groupId int) error {
+	if cached, _ := groupsCache.GetGroupById(groupId); cached != nil {
+		return nil
+	}
+
+	groups, _, err := groupList.Get()
+	if err != nil {
+		slog.Warn("Error getting the groups list", "group_id", groupId, "error", err)
+		return nil
+	}
+
+	for _, group := range groups {
+		if group.Id == groupId {
+			return groupsCache.AddGroup(group)
+		}
+	}
+
+	return nil
+}
+
+// withSave adds the save flag to the group selection button data,
+// so the selected group is added to the saved groups
+func withSave(button *utils.ButtonData, save bool) *utils.ButtonData {
+	if save {
+		button.Set("save", "")
+	}
+	return button
+}
+
+// createGroupSelectionBackButton creates the back button of the first group selection page.
+// It returns to the saved groups the group is selected for, or to the menu.
+func createGroupSelectionBackButton(lang i18n.Language, save bool) gotgbot.InlineKeyboardButton {
+	if save {
+		return gotgbot.InlineKeyboardButton{
+			Text:         lang.Button.Back,
+			CallbackData: "open.saved_groups",
+		}
+	}
+
+	return gotgbot.InlineKeyboardButton{
+		Text:         lang.Button.Back,
+		CallbackData: utils.NewButtonData("open.menu").Set("from", "group_select").String(),
+	}
+}
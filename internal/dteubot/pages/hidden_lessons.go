@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"context"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"github.com/sirkon/go-format/v2"
+	"hash/fnv"
+	"strconv"
+)
+
+// GetLessonId returns the id of the lesson that is the same for all
+// its occurrences: the hash of the discipline, lesson type and teachers
+func GetLessonId(period api2.TimeTablePeriod) string {
+	h := fnv.New32a()
+	h.Write([]byte(period.DisciplineFullName + "\x00" + strconv.Itoa(period.Type) + "\x00" + period.TeachersNameFull))
+	return strconv.FormatUint(uint64(h.Sum32()), 36)
+}
+
+// GetHiddenLesson finds the lesson with the given id in the group schedule
+// for the date. Returns false if the group has no such lesson on the date.
+func GetHiddenLesson(ctx context.Context, groupId int, date string, lessonId string) (data.HiddenLesson, bool, error) {
+	day, _, err := getGroupScheduleDay(ctx, groupId, date)
+	if err != nil || day == nil {
+		return data.HiddenLesson{}, false, err
+	}
+
+	for _, lesson := range day.Lessons {
+		for _, period := range lesson.Periods {
+			if GetLessonId(period) != lessonId {
+				continue
+			}
+
+			hidden := data.HiddenLesson{
+				Id:      lessonId,
+				Name:    period.DisciplineFullName,
+				Type:    period.TypeStr,
+				Teacher: period.TeachersNameFull,
+			}
+			return hidden, true, nil
+		}
+	}
+
+	return data.HiddenLesson{}, false, nil
+}
+
+// HiddenLessonsPageSize is a number of lessons shown on one hidden lessons page
+const HiddenLessonsPageSize = 8
+
+// CreateHiddenLessonsPage creates a page with the lessons
+// the chat has hidden from the schedule to show them again.
+//
+// pageNum is a number of the hidden lessons page, starting from 0
+func CreateHiddenLessonsPage(lang i18n.Language, chat *data.Chat, pageNum int) (Page, error) {
+	var strikeNextState string
+	if chat.StrikeHiddenLessons {
+		strikeNextState = "0"
+	} else {
+		strikeNextState = "1"
+	}
+
+	// Unhide buttons keep the page, so the list stays on it after the lesson is unhidden
+	pageNum = max(min(pageNum, (len(chat.HiddenLessons)-1)/HiddenLessonsPageSize), 0)
+
+	btns := make([]gotgbot.InlineKeyboardButton, len(chat.HiddenLessons))
+	for i, lesson := range chat.HiddenLessons {
+		text := lesson.Name
+		if lesson.Type != "" {
+			text += " (" + lesson.Type + ")"
+		}
+
+		btns[i] = gotgbot.InlineKeyboardButton{
+			Text:         format.Formatp(lang.Button.UnhideLesson, text),
+			CallbackData: utils.NewButtonData("unhide.lesson").Set("lessonId", lesson.Id).SetInt("page", pageNum).String(),
+		}
+	}
+
+	keyboard, err := paginate(lang, btns, pageNum, HiddenLessonsPageSize, 1, utils.NewButtonData("open.hidden_lessons"))
+	if err != nil {
+		return Page{}, err
+	}
+
+	keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{{
+		Text:         format.Formatp(lang.Button.SettingStrikeHiddenLessons, utils.GetSettingIcon(chat.StrikeHiddenLessons)),
+		CallbackData: utils.NewButtonData("set.strike_hidden_lessons").Set("state", strikeNextState).SetInt("page", pageNum).String(),
+	}}, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Back,
+		CallbackData: "open.settings",
+	}})
+
+	text := lang.Page.HiddenLessons
+	if len(chat.HiddenLessons) == 0 {
+		text = format.Formatp(lang.Page.HiddenLessonsEmpty, data.MaxHiddenLessons)
+	}
+
+	page := Page{
+		Text:        text,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/cubicbyte/dteubot/internal/dteubot/calendar"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/sirkon/go-format/v2"
+)
+
+func CreateAddHolidayUsagePage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text:      lang.Page.AddHolidayUsage,
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+func CreateHolidayAddedPage(lang i18n.Language, holiday calendar.Holiday) (Page, error) {
+	page := Page{
+		Text: format.Formatm(lang.Page.HolidayAdded, format.Values{
+			"name": utils.EscapeMarkdownV2(holiday.LocalizedName(lang.Code)),
+			"date": utils.EscapeMarkdownV2(holiday.Date),
+		}),
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"context"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/ical"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"time"
+)
+
+// CreateICSExport creates an iCalendar (.ics) file with the group schedule for the week.
+//
+// weekOffset is the number of weeks from the current one of the chat: 0 - current week, 1 - next week, etc.
+func CreateICSExport(ctx context.Context, chat *data.Chat, groupId int, weekOffset int) ([]byte, error) {
+	weekStart := GetWeekStart(utils.NowFor(chat)).AddDate(0, 0, weekOffset*7)
+	weekEnd := weekStart.AddDate(0, 0, 6)
+
+	schedule, err := api.WithContext(ctx).GetGroupSchedule(
+		groupId,
+		weekStart.Format(time.DateOnly),
+		weekEnd.Format(time.DateOnly),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	calls, err := api.WithContext(ctx).GetCallSchedule()
+	if err != nil {
+		return nil, err
+	}
+
+	return ical.CreateCalendar(schedule, calls, groupId, utils.UniversityLocation())
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/groupscache"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"log/slog"
+)
+
+// FindStudentGroups returns the groups of the student with the given id.
+// The groups are found in the students index, so only the first search
+// after the start is slow if the index is not built by UpdateStudentIndex yet.
+func FindStudentGroups(studentId int) ([]groupscache.Group, error) {
+	return studentIndex.Find(studentId)
+}
+
+// UpdateStudentIndex builds the students index again in the background,
+// so the students moved to other groups are found
+func UpdateStudentIndex() {
+	if err := studentIndex.Update(); err != nil {
+		slog.Warn("Error updating students index", "error", err)
+	}
+}
+
+func CreateIdentifyUsagePage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text:        lang.Page.IdentifyUsage,
+		ReplyMarkup: createIdentifyFallbackMarkup(lang),
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+func CreateIdentifyNotFoundPage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text:        lang.Page.IdentifyNotFound,
+		ReplyMarkup: createIdentifyFallbackMarkup(lang),
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// CreateIdentifyGroupsPage creates a page to select one of the student groups.
+func CreateIdentifyGroupsPage(lang i18n.Language, groups []groupscache.Group) (Page, error) {
+	buttons := make([][]gotgbot.InlineKeyboardButton, 0, len(groups)+1)
+
+	for _, group := range groups {
+		buttons = append(buttons, []gotgbot.InlineKeyboardButton{{
+			Text:         group.Name,
+			CallbackData: utils.NewButtonData("select.schedule.group").SetInt("groupId", group.Id).String(),
+		}})
+	}
+
+	buttons = append(buttons, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Menu,
+		CallbackData: utils.NewButtonData("open.menu").Set("from", "identify").String(),
+	}})
+
+	page := Page{
+		Text:        lang.Page.IdentifyGroups,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons},
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// createIdentifyFallbackMarkup creates buttons to find
+// the group by name or select it from the list instead
+func createIdentifyFallbackMarkup(lang i18n.Language) gotgbot.InlineKeyboardMarkup {
+	return gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+			createGroupSearchButton(lang),
+			{{
+				Text:         lang.Button.SelectGroup,
+				CallbackData: "open.select_group",
+			}},
+		},
+	}
+}
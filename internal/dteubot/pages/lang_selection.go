@@ -24,12 +24,30 @@ package pages
 
 import (
 	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
 	"github.com/cubicbyte/dteubot/internal/i18n"
 	"github.com/sirkon/go-format/v2"
 	"sort"
 )
 
-func CreateLanguageSelectionPage(lang i18n.Language, backButton string) (Page, error) {
+// LanguageSelectionBackButtons maps the back param of the language
+// selection page to the callback data of the page to go back to
+var LanguageSelectionBackButtons = map[string]string{
+	"menu":     utils.NewButtonData("open.menu").Set("from", "language").String(),
+	"settings": utils.NewButtonData("open.settings").Set("from", "select_lang").String(),
+}
+
+// CreateLanguageSelectionPage creates a language selection page.
+//
+// back is a key of LanguageSelectionBackButtons, it's passed to the language
+// buttons to go back to the same page after the language is selected.
+func CreateLanguageSelectionPage(lang i18n.Language, back string) (Page, error) {
+	backButton, ok := LanguageSelectionBackButtons[back]
+	if !ok {
+		back = "settings"
+		backButton = LanguageSelectionBackButtons[back]
+	}
+
 	// Get sorted languages
 	langsCount := len(languages)
 	sortedLangs := make([]string, langsCount)
@@ -49,7 +67,7 @@ func CreateLanguageSelectionPage(lang i18n.Language, backButton string) (Page, e
 		CallbackData: backButton,
 	}, {
 		Text:         lang.Button.Menu,
-		CallbackData: "open.menu#from=language",
+		CallbackData: utils.NewButtonData("open.menu").Set("from", "language").String(),
 	}}
 
 	// Add language buttons
@@ -58,7 +76,7 @@ func CreateLanguageSelectionPage(lang i18n.Language, backButton string) (Page, e
 		lang2 := languages[code]
 		buttons[i] = []gotgbot.InlineKeyboardButton{{
 			Text:         lang2.LangName,
-			CallbackData: "select.lang#lang=" + code,
+			CallbackData: utils.NewButtonData("select.lang").Set("lang", code).Set("back", back).String(),
 		}}
 		i++
 	}
@@ -24,6 +24,7 @@ package pages
 
 import (
 	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
 	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
 	"github.com/cubicbyte/dteubot/internal/i18n"
 	"github.com/sirkon/go-format/v2"
@@ -32,9 +33,9 @@ import (
 	"time"
 )
 
-func CreateLeftPage(lang i18n.Language, groupId int, backButton string) (Page, error) {
+func CreateLeftPage(lang i18n.Language, chat *data.Chat, groupId int, backButton string) (Page, error) {
 	// Get time left
-	status, err := utils.GetCallsStatus(groupId, time.Now(), api)
+	status, err := utils.GetCallsStatus(groupId, utils.NowFor(chat), api)
 	if err != nil {
 		return Page{}, err
 	}
@@ -44,7 +45,7 @@ func CreateLeftPage(lang i18n.Language, groupId int, backButton string) (Page, e
 	if status == nil {
 		pageText = format.Formatp(lang.Page.LeftUnknown, utils.DaysScanLimit)
 	} else {
-		timeLeft := status.Time.Sub(time.Now())
+		timeLeft := time.Until(status.Time)
 		timeLeftStr := utils.FormatDuration(timeLeft, 2, lang)
 
 		switch status.Status {
@@ -67,7 +68,7 @@ func CreateLeftPage(lang i18n.Language, groupId int, backButton string) (Page, e
 					CallbackData: backButton,
 				}, {
 					Text:         lang.Button.Refresh,
-					CallbackData: "open.left#refresh&rnd=" + rand_,
+					CallbackData: utils.NewButtonData("open.left").Set("refresh", "").Set("rnd", rand_).String(),
 				}},
 			},
 		},
@@ -0,0 +1,210 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"context"
+	"errors"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"github.com/sirkon/go-format/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LessonReminderOffsets are the numbers of minutes before the lesson start
+// a one-shot lesson reminder can be sent at
+var LessonReminderOffsets = []int{5, 15, 30}
+
+// CreateLessonReminderPage creates a page to choose when to remind about the lesson.
+// The chosen offset is marked if the chat already has the reminder.
+// defaultGroupId is the group the buttons open without the groupId param.
+func CreateLessonReminderPage(ctx context.Context, lang i18n.Language, chat *data.Chat, groupId int, defaultGroupId int, date string, number int, loc *time.Location) (Page, error) {
+	backButton := withGroup(utils.NewButtonData("open.schedule.extra").Set("date", date), groupId, defaultGroupId).String()
+
+	lesson, err := GetLesson(ctx, groupId, date, number)
+	if err != nil {
+		return Page{}, err
+	}
+	if lesson == nil {
+		return CreateNotFoundPage(lang, backButton)
+	}
+
+	start, err := GetLessonStart(date, lesson)
+	if err != nil {
+		return Page{}, err
+	}
+
+	day, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return Page{}, err
+	}
+
+	pageText := format.Formatm(lang.Page.LessonReminder, format.Values{
+		"lesson": number,
+		"name":   utils.EscapeMarkdownV2(GetLessonName(lesson)),
+		"date":   getLocalizedShortDate(lang, day),
+		"time":   utils.EscapeMarkdownV2(start.In(loc).Format("15:04")),
+	})
+
+	offset := 0
+	if i := chat.LessonReminders.Index(groupId, date, number); i != -1 {
+		offset = chat.LessonReminders[i].Offset
+	}
+
+	offsetButtons := make([]gotgbot.InlineKeyboardButton, 0, len(LessonReminderOffsets))
+	for _, offset2 := range LessonReminderOffsets {
+		text := format.Formatp(lang.Button.SettingReminderOffset, offset2)
+		if offset2 == offset {
+			text = "• " + text + " •"
+		}
+		offsetButtons = append(offsetButtons, gotgbot.InlineKeyboardButton{
+			Text:         text,
+			CallbackData: withGroup(utils.NewButtonData("set.lesson_reminder").Set("date", date).SetInt("lesson", number).SetInt("offset", offset2), groupId, defaultGroupId).String(),
+		})
+	}
+
+	buttons := [][]gotgbot.InlineKeyboardButton{offsetButtons}
+	if offset != 0 {
+		buttons = append(buttons, []gotgbot.InlineKeyboardButton{{
+			Text:         lang.Button.CancelLessonReminder,
+			CallbackData: withGroup(utils.NewButtonData("set.lesson_reminder").Set("date", date).SetInt("lesson", number).SetInt("offset", 0), groupId, defaultGroupId).String(),
+		}})
+	}
+	buttons = append(buttons, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Back,
+		CallbackData: backButton,
+	}})
+
+	page := Page{
+		Text: pageText,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: buttons,
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// CreateLessonReminderRemovedPage creates a page notifying that the
+// reminder is removed, because the lesson is no longer on the schedule
+func CreateLessonReminderRemovedPage(lang i18n.Language, reminder data.LessonReminder) (Page, error) {
+	date, err := time.Parse(time.DateOnly, reminder.Date)
+	if err != nil {
+		return Page{}, err
+	}
+
+	pageText := format.Formatm(lang.Page.LessonReminderRemoved, format.Values{
+		"lesson": reminder.Number,
+		"name":   utils.EscapeMarkdownV2(reminder.Name),
+		"date":   getLocalizedShortDate(lang, date),
+	})
+
+	page := Page{
+		Text: pageText,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{{
+				Text:         lang.Button.OpenSchedule,
+				CallbackData: utils.NewButtonData("open.schedule.day").Set("from", "notification").Set("date", reminder.Date).String(),
+			}}},
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// NewLessonReminder creates a reminder sent offset minutes before the lesson start
+func NewLessonReminder(groupId int, date string, lesson *api2.TimeTableLesson, offset int) (data.LessonReminder, error) {
+	start, err := GetLessonStart(date, lesson)
+	if err != nil {
+		return data.LessonReminder{}, err
+	}
+
+	reminder := data.LessonReminder{
+		GroupId: groupId,
+		Date:    date,
+		Number:  lesson.Number,
+		Name:    GetLessonName(lesson),
+		Offset:  offset,
+		Time:    start.Add(-time.Duration(offset) * time.Minute).Unix(),
+	}
+
+	return reminder, nil
+}
+
+// GetLesson returns the lesson of the group day with the given number,
+// or nil if there is no such lesson
+func GetLesson(ctx context.Context, groupId int, date string, number int) (*api2.TimeTableLesson, error) {
+	day, _, err := getGroupScheduleDay(ctx, groupId, date)
+	if err != nil || day == nil {
+		return nil, err
+	}
+
+	for _, lesson := range day.Lessons {
+		if lesson.Number == number && len(lesson.Periods) != 0 {
+			return &lesson, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// GetLessonName returns the names of the lesson periods,
+// e.g. of the different subgroups, separated by commas
+func GetLessonName(lesson *api2.TimeTableLesson) string {
+	names := make([]string, 0, len(lesson.Periods))
+	for _, period := range lesson.Periods {
+		name := period.DisciplineShortName
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// GetLessonStart returns the start time of the lesson. If the lesson
+// has no start time, it's taken from the call schedule.
+func GetLessonStart(date string, lesson *api2.TimeTableLesson) (time.Time, error) {
+	timeStart := lesson.Periods[0].TimeStart
+	if timeStart == "" {
+		calls, err := api.GetCallSchedule()
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		call := calls.GetCall(lesson.Number)
+		if call == nil {
+			return time.Time{}, errors.New("call not found for lesson " + strconv.Itoa(lesson.Number))
+		}
+		timeStart = call.TimeStart
+	}
+
+	return time.ParseInLocation("2006-01-02 15:04", date+" "+timeStart, utils.UniversityLocation())
+}
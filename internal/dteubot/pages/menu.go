@@ -25,6 +25,7 @@ package pages
 import (
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
 	"github.com/cubicbyte/dteubot/internal/i18n"
 )
 
@@ -37,6 +38,13 @@ func CreateMenuPage(lang i18n.Language, user *data.User) (Page, error) {
 					Text:         lang.Button.Schedule,
 					CallbackData: "open.schedule.today",
 				}},
+				{{
+					Text:         lang.Button.NextLesson,
+					CallbackData: "open.next",
+				}, {
+					Text:         lang.Button.Exams,
+					CallbackData: "open.exams",
+				}},
 				{{
 					Text:         lang.Button.Settings,
 					CallbackData: "open.settings",
@@ -50,7 +58,7 @@ func CreateMenuPage(lang i18n.Language, user *data.User) (Page, error) {
 	}
 
 	// Add admin panel button if the user is admin
-	if user.IsAdmin {
+	if utils.IsBotAdmin(user) {
 		page.ReplyMarkup.InlineKeyboard = append(page.ReplyMarkup.InlineKeyboard, []gotgbot.InlineKeyboardButton{{
 			Text:         lang.Button.AdminPanel,
 			CallbackData: "open.admin_panel",
@@ -25,6 +25,7 @@ package pages
 import (
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/cubicbyte/dteubot/internal/i18n"
+	"os"
 )
 
 func CreateMorePage(lang i18n.Language) (Page, error) {
@@ -43,6 +44,18 @@ func CreateMorePage(lang i18n.Language) (Page, error) {
 					Text:         lang.Button.StudentsList,
 					CallbackData: "open.students_list",
 				}},
+				{{
+					Text:         lang.Button.TeacherSchedule,
+					CallbackData: "open.select_teacher",
+				}},
+				{{
+					Text:         lang.Button.CalendarExport,
+					CallbackData: "export.calendar",
+				}},
+				{{
+					Text:         lang.Button.SemesterOverview,
+					CallbackData: "open.semester_overview",
+				}},
 				{{
 					Text:         lang.Button.Info,
 					CallbackData: "open.info",
@@ -56,5 +69,14 @@ func CreateMorePage(lang i18n.Language) (Page, error) {
 		ParseMode: "MarkdownV2",
 	}
 
+	// Add Mini App button before the back button if it's configured
+	if os.Getenv("WEBAPP_URL") != "" {
+		keyboard := page.ReplyMarkup.InlineKeyboard
+		page.ReplyMarkup.InlineKeyboard = append(keyboard[:len(keyboard)-1:len(keyboard)-1], []gotgbot.InlineKeyboardButton{{
+			Text:         lang.Button.WebApp,
+			CallbackData: "open.webapp",
+		}}, keyboard[len(keyboard)-1])
+	}
+
 	return page, nil
 }
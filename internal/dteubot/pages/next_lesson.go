@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"github.com/sirkon/go-format/v2"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+// CreateNextLessonPage creates a page with the next lesson of the group
+// after the given time. Break and the end of the day are shown separately.
+func CreateNextLessonPage(lang i18n.Language, groupId int, backButton string, chatLoc *time.Location, now time.Time) (Page, error) {
+	if groupId == -1 {
+		return CreateInvalidGroupPage(lang)
+	}
+
+	// Countdown is calculated in the university timezone
+	loc, err := time.LoadLocation(api2.Location)
+	if err != nil {
+		return Page{}, err
+	}
+	now = now.In(loc)
+
+	next, err := utils.GetNextLesson(groupId, now, api)
+	if err != nil {
+		return Page{}, err
+	}
+
+	var pageText string
+	if next == nil {
+		pageText = format.Formatp(lang.Page.NextLessonUnknown, utils.DaysScanLimit)
+	} else {
+		date, err := time.Parse(time.DateOnly, next.Date)
+		if err != nil {
+			return Page{}, err
+		}
+
+		lesson := ""
+		for _, period := range next.Lesson.Periods {
+			lesson += format.Formatm("`$number)` $icon *$name*`[$type]`\n", format.Values{
+				"number": next.Lesson.Number,
+				"icon":   utils.GetLessonIcon(period.Type),
+				"name":   utils.EscapeMarkdownV2(period.DisciplineShortName),
+				"type":   utils.EscapeMarkdownV2(period.TypeStr),
+			})
+			if period.Classroom != "" {
+				lesson += "🏛 " + utils.EscapeMarkdownV2(period.Classroom) + "\n"
+			}
+			if period.TeachersNameFull != "" {
+				lesson += "👨‍🏫 " + utils.EscapeMarkdownV2(period.TeachersNameFull) + "\n"
+			}
+		}
+		lesson += "🕒 `" + next.Start.In(chatLoc).Format("15:04") + "` \\- `" + next.End.In(chatLoc).Format("15:04") + "`\n"
+
+		text := lang.Page.NextLesson
+		if !next.PrevEnd.IsZero() && !next.PrevEnd.After(now) {
+			if next.Date == now.Format(time.DateOnly) {
+				text = lang.Page.NextLessonBreak
+			} else {
+				text = lang.Page.NextLessonDone
+			}
+		}
+
+		pageText = format.Formatm(text, format.Values{
+			"date":   getLocalizedDate(lang, date, "📅"),
+			"lesson": lesson,
+			"left":   utils.FormatDuration(next.Start.Sub(now), 2, lang),
+		})
+	}
+
+	rand_ := strconv.Itoa(rand.Intn(1e6)) // Salt to prevent "Message is not modified" error
+	page := Page{
+		Text: pageText,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+				{{
+					Text:         lang.Button.Back,
+					CallbackData: backButton,
+				}, {
+					Text:         lang.Button.Refresh,
+					CallbackData: utils.NewButtonData("open.next").Set("refresh", "").Set("rnd", rand_).String(),
+				}},
+			},
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
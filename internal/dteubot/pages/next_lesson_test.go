@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"testing"
+	"time"
+)
+
+// nextLessonApi is the api2.Api with a single day of two lessons
+type nextLessonApi struct {
+	api2.Api
+}
+
+func (nextLessonApi) GetCallSchedule() (api2.CallSchedule, error) {
+	return api2.CallSchedule{
+		{Number: 1, TimeStart: "08:00", TimeEnd: "09:20"},
+		{Number: 2, TimeStart: "09:30", TimeEnd: "10:50"},
+	}, nil
+}
+
+func (nextLessonApi) GetGroupSchedule(groupId int, dateStart string, dateEnd string) (api2.Schedule, error) {
+	lessons := []api2.TimeTableLesson{
+		{Number: 1, Periods: []api2.TimeTablePeriod{{DisciplineShortName: "Math"}}},
+		{Number: 2, Periods: []api2.TimeTablePeriod{{DisciplineShortName: "Physics"}}},
+	}
+	var schedule api2.Schedule
+	for _, date := range []string{"2024-10-28", "2024-10-29"} {
+		if date >= dateStart && date <= dateEnd {
+			schedule = append(schedule, api2.TimeTableDate{Date: date, Lessons: lessons})
+		}
+	}
+	return schedule, nil
+}
+
+func TestCreateNextLessonPage(t *testing.T) {
+	prevApi := api
+	api = nextLessonApi{}
+	defer func() { api = prevApi }()
+
+	var lang i18n.Language
+	lang.Page.NextLesson = "next"
+	lang.Page.NextLessonBreak = "break"
+	lang.Page.NextLessonDone = "done"
+	lang.Page.NextLessonUnknown = "unknown"
+
+	loc, err := time.LoadLocation(api2.Location)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		{"before the first lesson", time.Date(2024, time.October, 28, 7, 0, 0, 0, loc), "next"},
+		{"during the lesson", time.Date(2024, time.October, 28, 8, 30, 0, 0, loc), "next"},
+		{"between the lessons", time.Date(2024, time.October, 28, 9, 25, 0, 0, loc), "break"},
+		{"after the last lesson", time.Date(2024, time.October, 28, 12, 0, 0, 0, loc), "done"},
+		{"after the last lesson of the schedule", time.Date(2024, time.October, 29, 12, 0, 0, 0, loc), "unknown"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, err := CreateNextLessonPage(lang, 1, "open.menu", loc, test.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if page.Text != test.want {
+				t.Errorf("page text is %q, want %q", page.Text, test.want)
+			}
+		})
+	}
+}
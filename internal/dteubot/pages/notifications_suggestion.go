@@ -24,6 +24,7 @@ package pages
 
 import (
 	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
 	"github.com/cubicbyte/dteubot/internal/i18n"
 )
 
@@ -34,10 +35,10 @@ func CreateNotificationFeatureSuggestionPage(lang i18n.Language) (Page, error) {
 			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
 				{{
 					Text:         lang.Text.TryIt,
-					CallbackData: "set.cl_notif#time=15m&state=1&suggestion",
+					CallbackData: utils.NewButtonData("set.cl_notif").Set("time", "15m").Set("state", "1").Set("suggestion", "").String(),
 				}, {
 					Text:         lang.Text.NoThanks,
-					CallbackData: "close_page#page=notification_feature_suggestion",
+					CallbackData: utils.NewButtonData("close_page").Set("page", "notification_feature_suggestion").String(),
 				}},
 			},
 		},
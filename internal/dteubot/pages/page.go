@@ -25,36 +25,51 @@ package pages
 import (
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/calendar"
 	"github.com/cubicbyte/dteubot/internal/dteubot/groupscache"
+	"github.com/cubicbyte/dteubot/internal/dteubot/rooms"
 	"github.com/cubicbyte/dteubot/internal/dteubot/teachers"
 	"github.com/cubicbyte/dteubot/internal/i18n"
 	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"strings"
+	"unicode/utf8"
 )
 
+// MaxPageLength is the maximum length of the telegram message text
+const MaxPageLength = 4096
+
 var (
 	chatRepo     data.ChatRepository
 	userRepo     data.UserRepository
 	api          api2.Api
 	groupsCache  *groupscache.Cache
 	teachersList *teachers.TeachersList
+	holidays     *calendar.HolidayCalendar
 	languages    map[string]i18n.Language
+	roomsCache   *rooms.Cache
+	groupList    *groupscache.List
+	studentIndex *groupscache.StudentIndex
 )
 
 // InitPages initializes the pages package. Must be called before using the package
 func InitPages(
-	chatRepo2 data.ChatRepository,
-	userRepo2 data.UserRepository,
+	storage data.Storage,
 	api2 api2.Api,
 	groupsCache2 *groupscache.Cache,
 	teachersList2 *teachers.TeachersList,
+	holidays2 *calendar.HolidayCalendar,
 	languages2 map[string]i18n.Language,
 ) {
-	chatRepo = chatRepo2
-	userRepo = userRepo2
+	chatRepo = storage.Chats()
+	userRepo = storage.Users()
 	api = api2
 	groupsCache = groupsCache2
 	teachersList = teachersList2
+	holidays = holidays2
 	languages = languages2
+	roomsCache = rooms.NewCache(freeRoomsSource(), rooms.CacheTTL)
+	groupList = groupscache.NewList(api2, groupscache.ListTTL)
+	studentIndex = groupscache.NewStudentIndex(api2, groupList)
 }
 
 // Page represents a telegram page
@@ -65,6 +80,11 @@ type Page struct {
 	DisableWebPagePreview bool
 }
 
+// HasButtons checks if the page has the inline keyboard
+func (p Page) HasButtons() bool {
+	return len(p.ReplyMarkup.InlineKeyboard) > 0
+}
+
 // CreateSendMessageOpts creates a telegram message parameters from the page
 func (p Page) CreateSendMessageOpts() gotgbot.SendMessageOpts {
 	return gotgbot.SendMessageOpts{
@@ -84,3 +104,114 @@ func (p Page) CreateEditMessageOpts(chatId int64, messageId int64) gotgbot.EditM
 		ReplyMarkup:           p.ReplyMarkup,
 	}
 }
+
+// SplitLongPage splits the page into the pages with the text not longer than
+// maxLen runes. The text is split by the blank lines, or by the lines if
+// a paragraph is too long, to not break the formatting.
+//
+// Only the last page has the reply markup.
+func SplitLongPage(page Page, maxLen int) []Page {
+	if utf8.RuneCountInString(page.Text) <= maxLen {
+		return []Page{page}
+	}
+
+	parts := make([]string, 0)
+	current := ""
+	add := func(text string, sep string) {
+		if current == "" {
+			current = text
+		} else if utf8.RuneCountInString(current+sep+text) <= maxLen {
+			current += sep + text
+		} else {
+			parts = append(parts, current)
+			current = text
+		}
+	}
+
+	for _, paragraph := range strings.Split(page.Text, "\n\n") {
+		if utf8.RuneCountInString(paragraph) <= maxLen {
+			add(paragraph, "\n\n")
+			continue
+		}
+
+		for i, line := range strings.Split(paragraph, "\n") {
+			// Line can't be split without breaking the formatting, so it is cut
+			if runes := []rune(line); len(runes) > maxLen {
+				cut := maxLen - 1
+				if page.ParseMode == "MarkdownV2" {
+					cut = safeMarkdownV2Cut(runes, cut)
+				}
+				line = string(runes[:cut]) + "…"
+			}
+
+			sep := "\n"
+			if i == 0 {
+				sep = "\n\n"
+			}
+			add(line, sep)
+		}
+	}
+	parts = append(parts, current)
+
+	pages := make([]Page, 0, len(parts))
+	for _, part := range parts {
+		pages = append(pages, Page{
+			Text:                  part,
+			ParseMode:             page.ParseMode,
+			DisableWebPagePreview: page.DisableWebPagePreview,
+		})
+	}
+	pages[len(pages)-1].ReplyMarkup = page.ReplyMarkup
+
+	return pages
+}
+
+// safeMarkdownV2Cut returns the largest position not greater than maxPos
+// the MarkdownV2 text can be cut at without breaking an escape sequence
+// or leaving an entity (bold, italic, link, code, etc.) unclosed.
+func safeMarkdownV2Cut(runes []rune, maxPos int) int {
+	safe := 0
+	open := make(map[string]bool)
+	inLink, inUrl := false, false
+
+	isOpen := func() bool {
+		for _, o := range open {
+			if o {
+				return true
+			}
+		}
+		return inLink || inUrl
+	}
+
+	for i := 0; i < len(runes) && i <= maxPos; i++ {
+		if !isOpen() {
+			safe = i
+		}
+
+		switch r := runes[i]; {
+		case r == '\\':
+			// Escaped character can't be separated from the backslash
+			i++
+		case open["`"] && r != '`':
+			// Nothing but the escapes is parsed inside the code
+		case inUrl:
+			if r == ')' {
+				inUrl = false
+			}
+		case r == '[':
+			inLink = true
+		case r == ']' && inLink && i+1 < len(runes) && runes[i+1] == '(':
+			inLink, inUrl = false, true
+			i++
+		case r == '_' && i+1 < len(runes) && runes[i+1] == '_',
+			r == '|' && i+1 < len(runes) && runes[i+1] == '|':
+			entity := string(runes[i : i+2])
+			open[entity] = !open[entity]
+			i++
+		case r == '*' || r == '_' || r == '~' || r == '`':
+			open[string(r)] = !open[string(r)]
+		}
+	}
+
+	return safe
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSafeMarkdownV2Cut(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		maxPos int
+		want   int
+	}{
+		{"plain", "abcdef", 4, 4},
+		{"escape", `ab\.cd`, 3, 2},
+		{"after escape", `ab\.cd`, 4, 4},
+		{"bold", "a*bcd*e", 3, 1},
+		{"closed bold", "a*b*cde", 5, 5},
+		{"underline", "a__bc__d", 4, 1},
+		{"spoiler", "a||bc||d", 4, 1},
+		{"code with stars", "a`b*c`d*", 6, 6},
+		{"link text", "a[bc](d)e", 3, 1},
+		{"link url", "a[bc](de)f", 7, 1},
+		{"after link", "a[bc](de)fg", 9, 9},
+		{"whole line", "*abcdef*", 5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := safeMarkdownV2Cut([]rune(tt.text), tt.maxPos)
+			if got != tt.want {
+				t.Errorf("safeMarkdownV2Cut(%q, %d) = %d, want %d", tt.text, tt.maxPos, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitLongPage(t *testing.T) {
+	page := Page{
+		Text:      "first\n\nab *bold bold*\n\nlast",
+		ParseMode: "MarkdownV2",
+	}
+
+	want := []string{"first", "ab …\n\nlast"}
+	parts := SplitLongPage(page, 10)
+	if len(parts) != len(want) {
+		t.Fatalf("got %d parts, want %d", len(parts), len(want))
+	}
+
+	for i, part := range parts {
+		if part.Text != want[i] {
+			t.Errorf("part %d is %q, want %q", i, part.Text, want[i])
+		}
+		if n := utf8.RuneCountInString(part.Text); n > 10 {
+			t.Errorf("part %d is %d runes long", i, n)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"fmt"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"strconv"
+)
+
+// paginate creates the keyboard rows of the list page pageNum, starting from 0,
+// with rowSize buttons in a row. If the list doesn't fit on one page,
+// the "⬅️ 2/7 ➡️" navigation row is added.
+//
+// Navigation buttons have the nav button data with the page param set,
+// so nav must have the params the list is created from. The list could change
+// since the page was opened, so out of range pageNum is clamped.
+//
+// Returns utils.ErrButtonDataTooLong if the callback data of any button
+// exceeds the telegram limit.
+func paginate(lang i18n.Language, items []gotgbot.InlineKeyboardButton, pageNum int, pageSize int, rowSize int, nav *utils.ButtonData) ([][]gotgbot.InlineKeyboardButton, error) {
+	pagesCount := max((len(items)+pageSize-1)/pageSize, 1)
+	pageNum = max(min(pageNum, pagesCount-1), 0)
+
+	start := pageNum * pageSize
+	end := min(start+pageSize, len(items))
+
+	for _, item := range items[start:end] {
+		if len(item.CallbackData) > utils.MaxButtonDataLength {
+			return nil, fmt.Errorf("%w: %s", utils.ErrButtonDataTooLong, item.CallbackData)
+		}
+	}
+
+	rows := utils.SplitRows(items[start:end], rowSize)
+	if pagesCount == 1 {
+		return rows, nil
+	}
+
+	// Navigation of the last page has the longest data
+	if _, err := nav.SetInt("page", pagesCount-1).Marshal(); err != nil {
+		return nil, err
+	}
+
+	navigation := make([]gotgbot.InlineKeyboardButton, 0, 3)
+	if pageNum > 0 {
+		navigation = append(navigation, gotgbot.InlineKeyboardButton{
+			Text:         lang.Button.PaginationPrevious,
+			CallbackData: nav.SetInt("page", pageNum-1).String(),
+		})
+	}
+	navigation = append(navigation, gotgbot.InlineKeyboardButton{
+		Text:         strconv.Itoa(pageNum+1) + "/" + strconv.Itoa(pagesCount),
+		CallbackData: nav.SetInt("page", pageNum).String(),
+	})
+	if pageNum < pagesCount-1 {
+		navigation = append(navigation, gotgbot.InlineKeyboardButton{
+			Text:         lang.Button.PaginationNext,
+			CallbackData: nav.SetInt("page", pageNum+1).String(),
+		})
+	}
+
+	return append(rows, navigation), nil
+}
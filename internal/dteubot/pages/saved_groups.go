@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/sirkon/go-format/v2"
+	"log/slog"
+	"strconv"
+)
+
+// CreateSavedGroupsPage creates a page to switch between the saved groups
+func CreateSavedGroupsPage(lang i18n.Language, chat *data.Chat) (Page, error) {
+	backButton := []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Back,
+		CallbackData: "open.settings",
+	}}
+
+	addButton := []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.AddSavedGroup,
+		CallbackData: utils.NewButtonData("open.select_group").Set("save", "").String(),
+	}}
+
+	groups := chat.Groups()
+	if len(groups) == 0 {
+		page := Page{
+			Text: format.Formatm(lang.Page.SavedGroupsEmpty, format.Values{
+				"max": data.MaxSavedGroups,
+			}),
+			ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+				InlineKeyboard: [][]gotgbot.InlineKeyboardButton{addButton, backButton},
+			},
+			ParseMode: "MarkdownV2",
+		}
+
+		return page, nil
+	}
+
+	groupName, err := getGroupName(lang, chat.GroupId)
+	if err != nil {
+		return Page{}, err
+	}
+
+	buttons := make([]gotgbot.InlineKeyboardButton, 0, len(groups))
+	for _, group := range groups {
+		text := getSavedGroupName(group)
+		if group.Id == chat.GroupId {
+			text = "• " + text + " •"
+		}
+		buttons = append(buttons, gotgbot.InlineKeyboardButton{
+			Text:         text,
+			CallbackData: utils.NewButtonData("set.active_group").SetInt("groupId", group.Id).String(),
+		})
+	}
+
+	keyboard := utils.SplitRows(buttons, 2)
+	keyboard = append(keyboard, append(addButton, gotgbot.InlineKeyboardButton{
+		Text:         lang.Button.RemoveSavedGroups,
+		CallbackData: "open.remove_groups",
+	}), backButton)
+
+	page := Page{
+		Text: format.Formatm(lang.Page.SavedGroups, format.Values{
+			"group": groupName,
+		}),
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// CreateRemoveSavedGroupsPage creates a page to remove the saved groups.
+//
+// If the adding group is not nil, the page asks to remove one
+// of the saved groups before adding it, because the list is full.
+func CreateRemoveSavedGroupsPage(lang i18n.Language, chat *data.Chat, adding *data.GroupRef) (Page, error) {
+	if len(chat.SavedGroups) == 0 {
+		return CreateSavedGroupsPage(lang, chat)
+	}
+
+	keyboard := make([][]gotgbot.InlineKeyboardButton, 0, len(chat.SavedGroups)+1)
+	for _, group := range chat.SavedGroups {
+		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{{
+			Text:         "❌ " + getSavedGroupName(group),
+			CallbackData: utils.NewButtonData("del.saved_group").SetInt("groupId", group.Id).String(),
+		}})
+	}
+	keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Back,
+		CallbackData: "open.saved_groups",
+	}})
+
+	var text string
+	if adding == nil {
+		text = lang.Page.RemoveSavedGroups
+	} else {
+		text = format.Formatm(lang.Page.SavedGroupsFull, format.Values{
+			"max":   data.MaxSavedGroups,
+			"group": utils.EscapeMarkdownV2(getSavedGroupName(*adding)),
+		})
+	}
+
+	page := Page{
+		Text:        text,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// getSavedGroupName returns the name of the saved group, or its id if the name is unknown.
+// Groups saved without the name, like the previous chat group, are looked up in the groups cache.
+func getSavedGroupName(group data.GroupRef) string {
+	if group.Name != "" {
+		return group.Name
+	}
+	if cached, _ := groupsCache.GetGroupById(group.Id); cached != nil {
+		return cached.Name
+	}
+	return strconv.Itoa(group.Id)
+}
+
+// getGroupName returns the group name escaped for MarkdownV2
+func getGroupName(lang i18n.Language, groupId int) (string, error) {
+	if groupId == -1 {
+		return lang.Text.NotSelected, nil
+	}
+
+	group, err := groupsCache.GetGroupById(groupId)
+	if err != nil {
+		if group == nil {
+			return "", err
+		}
+		slog.Warn("Error getting group name", "group_id", groupId, "error", err)
+	}
+	if group == nil {
+		return format.Formatp(lang.Text.UnknownGroupName, utils.EscapeMarkdownV2(strconv.Itoa(groupId))), nil
+	}
+
+	return utils.EscapeMarkdownV2(group.Name), nil
+}
@@ -23,13 +23,19 @@
 package pages
 
 import (
+	"context"
 	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
 	"github.com/cubicbyte/dteubot/internal/dteubot/teachers"
 	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/dteubot/weeks"
 	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/cubicbyte/dteubot/internal/logging"
+	"github.com/cubicbyte/dteubot/internal/scheduler"
 	api2 "github.com/cubicbyte/dteubot/pkg/api"
-	"github.com/op/go-logging"
 	"github.com/sirkon/go-format/v2"
+	"log/slog"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -39,21 +45,70 @@ import (
 // Needed to prevent spamming the log with the same warnings.
 var teacherPagesNotFound = make(map[string]bool)
 
-var log = logging.MustGetLogger("Bot")
-
 const ScheduleDateRange = 14
 
-func CreateSchedulePage(lang i18n.Language, groupId int, date string) (Page, error) {
+// SchoolDaySearchRange is how many days FindSchoolDay looks through
+const SchoolDaySearchRange = 31
+
+// viewedSchedules remembers the schedules shown in the chats
+// to highlight the changes since the last view
+var viewedSchedules = scheduler.NewViewedSchedules()
+
+// ScheduleView is how the chat prefers the schedule to be shown
+type ScheduleView struct {
+	// HideEmptyLessons hides the breaks between the lessons
+	HideEmptyLessons bool
+	// HiddenLessons are not shown, or crossed out if StrikeHiddenLessons is true
+	HiddenLessons       data.HiddenLessons
+	StrikeHiddenLessons bool
+}
+
+// ChatScheduleView returns the schedule view settings of the chat
+func ChatScheduleView(chat *data.Chat) ScheduleView {
+	return ScheduleView{
+		HideEmptyLessons:    chat.HideEmptyLessons,
+		HiddenLessons:       chat.HiddenLessons,
+		StrikeHiddenLessons: chat.StrikeHiddenLessons,
+	}
+}
+
+// CreateSchedulePage creates a page with the group schedule for the day.
+//
+// groups are the chat groups to switch between with the button above the schedule,
+// the first one is the chat group. The navigation buttons keep showing the other
+// groups. Pass nil to not show the button.
+//
+// viewerId is the id of the chat the page is shown in. The lessons changed since
+// the chat has seen this day last time are highlighted. Pass 0 to not highlight them.
+//
+// view is how the chat prefers the schedule to be shown. The lessons the group
+// has no classes at between the other lessons are shown as a single break line.
+func CreateSchedulePage(ctx context.Context, lang i18n.Language, groupId int, groups data.GroupRefs, date string, loc *time.Location, viewerId int64, view ScheduleView) (Page, error) {
 	date_, err := api2.ParseISODate(date)
 	if err != nil {
 		return Page{}, err
 	}
 
-	day, err := api.GetGroupScheduleDay(groupId, date)
+	day, cachedAt, err := getGroupScheduleDay(ctx, groupId, date)
 	if err != nil {
 		return Page{}, err
 	}
 
+	// Outdated cached schedule is not compared, it would show the changes backwards
+	var diff scheduler.ScheduleDiff
+	if viewerId != 0 && cachedAt.IsZero() && day != nil {
+		diff, _ = viewedSchedules.View(viewerId, groupId, date, day.Lessons)
+	}
+	if !view.StrikeHiddenLessons {
+		day = removeHiddenLessons(day, view.HiddenLessons)
+	}
+
+	// Group of the buttons without the groupId param
+	defaultGroupId := groupId
+	if len(groups) != 0 {
+		defaultGroupId = groups[0].Id
+	}
+
 	var buttons gotgbot.InlineKeyboardMarkup
 	var pageText string
 
@@ -61,7 +116,7 @@ func CreateSchedulePage(lang i18n.Language, groupId int, date string) (Page, err
 	// 📅 - default
 	// 🎃 - Halloween - 31.10
 	// 🎄 - Christmas, New Year - 25.12 - 07.01
-	now := time.Now()
+	now := utils.Now().In(loc)
 	var eventEmoji string
 	if now.Month() == time.October && now.Day() == 31 {
 		eventEmoji = "🎃"
@@ -71,10 +126,10 @@ func CreateSchedulePage(lang i18n.Language, groupId int, date string) (Page, err
 		eventEmoji = "📅"
 	}
 
-	if isNoLessons(day) {
+	if IsNoLessons(day) {
 		// Get more days if there are no lessons
 		dateStart, dateEnd := api2.GetDateRange(date_, ScheduleDateRange)
-		schedule, err := api.GetGroupSchedule(
+		schedule, err := api.WithContext(ctx).GetGroupSchedule(
 			groupId,
 			dateStart.Format("2006-01-02"),
 			dateEnd.Format("2006-01-02"),
@@ -101,7 +156,13 @@ func CreateSchedulePage(lang i18n.Language, groupId int, date string) (Page, err
 		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 		enableTodayButton := !(nextDayDate.After(today) && today.After(prevDayDate))
 
-		if skipLeft > 1 || skipRight > 1 {
+		if holiday, ok := holidays.IsHoliday(date); ok {
+			// Explain why there are no lessons
+			pageText = format.Formatm(lang.Page.ScheduleHoliday, format.Values{
+				"date": getLocalizedDate(lang, date_, eventEmoji) + getSemesterWeek(lang, date_),
+				"name": utils.EscapeMarkdownV2(holiday.LocalizedName(lang.Code)),
+			})
+		} else if skipLeft > 1 || skipRight > 1 {
 			// Create multiple days empty schedule page
 			pageText = format.Formatm(lang.Page.ScheduleMultipleEmptyDays, format.Values{
 				"dateStart": getLocalizedDate(lang, prevDayDate.AddDate(0, 0, 1), eventEmoji),
@@ -109,64 +170,85 @@ func CreateSchedulePage(lang i18n.Language, groupId int, date string) (Page, err
 			})
 		} else {
 			// Create single day empty schedule page
-			pageText = format.Formatp(lang.Page.ScheduleEmptyDay, getLocalizedDate(lang, date_, eventEmoji))
+			pageText = format.Formatp(lang.Page.ScheduleEmptyDay, getLocalizedDate(lang, date_, eventEmoji)+getSemesterWeek(lang, date_))
 		}
 
+		pageText += getRemovedLessons(lang, diff)
+
 		buttons = gotgbot.InlineKeyboardMarkup{
-			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
-				{{
-					Text:         lang.Button.ScheduleNavigationPreviousDay,
-					CallbackData: "open.schedule.day#date=" + prevDayDate.Format("2006-01-02"),
-				}, {
-					Text:         lang.Button.ScheduleNavigationNextDay,
-					CallbackData: "open.schedule.day#date=" + nextDayDate.Format("2006-01-02"),
-				}},
-				{{
-					Text:         lang.Button.ScheduleNavigationPreviousWeek,
-					CallbackData: "open.schedule.day#date=" + prevWeekDate.Format("2006-01-02"),
-				}, {
-					Text:         lang.Button.ScheduleNavigationNextWeek,
-					CallbackData: "open.schedule.day#date=" + nextWeekDate.Format("2006-01-02"),
-				}},
-				{{
+			InlineKeyboard: append(
+				createNavigationButtons(lang, dayButton(groupId, defaultGroupId), prevDayDate, nextDayDate, prevWeekDate, nextWeekDate),
+				[]gotgbot.InlineKeyboardButton{createBackButton(lang), {
 					Text:         lang.Button.Menu,
 					CallbackData: "open.menu",
+				}, {
+					Text:         lang.Button.ScheduleNavigationWeekView,
+					CallbackData: withGroup(utils.NewButtonData("open.schedule.week").Set("date", date), groupId, defaultGroupId).String(),
 				}},
-			},
+			),
 		}
 
+		// Jump over the holidays
+		buttons.InlineKeyboard = append(buttons.InlineKeyboard, []gotgbot.InlineKeyboardButton{{
+			Text:         lang.Button.ScheduleNavigationPreviousSchoolDay,
+			CallbackData: withGroup(utils.NewButtonData("open.schedule.school_day").Set("date", date).Set("direction", "prev"), groupId, defaultGroupId).String(),
+		}, {
+			Text:         lang.Button.ScheduleNavigationNextSchoolDay,
+			CallbackData: withGroup(utils.NewButtonData("open.schedule.school_day").Set("date", date).Set("direction", "next"), groupId, defaultGroupId).String(),
+		}})
+
 		if enableTodayButton {
 			buttons.InlineKeyboard[len(buttons.InlineKeyboard)-1] = append(
 				buttons.InlineKeyboard[len(buttons.InlineKeyboard)-1],
 				gotgbot.InlineKeyboardButton{
 					Text:         lang.Button.ScheduleNavigationToday,
-					CallbackData: "open.schedule.today",
+					CallbackData: withGroup(utils.NewButtonData("open.schedule.today"), groupId, defaultGroupId).String(),
 				},
 			)
 		}
 	} else {
 		// Create schedule page
-		pageText = getLocalizedDate(lang, date_, eventEmoji) + "\n\n"
+		pageText = getLocalizedDate(lang, date_, eventEmoji) + getSemesterWeek(lang, date_) + "\n\n"
 
+		lastNumber := -1
+		hasBreaks := false
 		for _, lesson := range day.Lessons {
+			if len(lesson.Periods) == 0 {
+				// Cancelled lesson
+				continue
+			}
+
+			if lastNumber != -1 && lesson.Number-lastNumber > 1 {
+				hasBreaks = true
+				if !view.HideEmptyLessons {
+					pageText += getBreak(lang, lastNumber+1, lesson.Number-1)
+				}
+			}
+			lastNumber = lesson.Number
+
 			for _, period := range lesson.Periods {
-				format_ := "`———— ``$timeStart`` ——— ``$timeEnd`` ————`\n`  `$lessonIcon *$disciplineShortName*`[$typeStr]`\n`$lessonNumber `$classroom\n`  `$teachersNameFull\n"
+				format_ := "`———— ``$timeStart`` ——— ``$timeEnd`` ————`\n`  `$lessonIcon *$disciplineShortName*`[$typeStr]`$change\n`$lessonNumber `$classroom\n`  `$teachersNameFull\n"
+				if view.HiddenLessons.Index(GetLessonId(period)) != -1 {
+					// Hidden lessons are shown crossed out
+					format_ = "`———— ``$timeStart`` ——— ``$timeEnd`` ————`\n`  `$lessonIcon ~$disciplineShortName~`[$typeStr]`$change\n`$lessonNumber `$classroom\n`  `$teachersNameFull\n"
+				}
 				lessonNumber := strconv.Itoa(lesson.Number)
 				lessonIcon := utils.GetLessonIcon(period.Type)
 
 				pageText += format.Formatm(format_, format.Values{
-					"timeStart":           utils.EscapeMarkdownV2(period.TimeStart),
-					"timeEnd":             utils.EscapeMarkdownV2(period.TimeEnd),
+					"timeStart":           utils.EscapeMarkdownV2(utils.ConvertLessonTime(date, period.TimeStart, loc)),
+					"timeEnd":             utils.EscapeMarkdownV2(utils.ConvertLessonTime(date, period.TimeEnd, loc)),
 					"disciplineShortName": utils.EscapeMarkdownV2(period.DisciplineShortName),
 					"typeStr":             utils.EscapeMarkdownV2(period.TypeStr),
 					"lessonNumber":        utils.EscapeMarkdownV2(lessonNumber),
 					"classroom":           utils.EscapeMarkdownV2(period.Classroom),
 					"teachersNameFull":    getTeacher(period.TeachersNameFull, teachersList),
 					"lessonIcon":          lessonIcon,
+					"change":              getLessonChangeMark(diff, scheduler.LessonChange{Number: lesson.Number, Period: period}),
 				})
 
 				if lessonIcon == "" {
-					log.Warningf("Could not get lesson icon for lesson type %d (%s)\n", period.Type, period.TypeStr)
+					logging.FromContext(ctx).Warn("Could not get lesson icon", "type", period.Type, "type_name", period.TypeStr)
 				}
 			}
 		}
@@ -177,54 +259,63 @@ func CreateSchedulePage(lang i18n.Language, groupId int, date string) (Page, err
 		nextWeekDate := date_.AddDate(0, 0, 7)
 
 		buttons = gotgbot.InlineKeyboardMarkup{
-			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
-				{{
-					Text:         lang.Button.ScheduleNavigationPreviousDay,
-					CallbackData: "open.schedule.day#date=" + prevDayDate.Format("2006-01-02"),
-				}, {
-					Text:         lang.Button.ScheduleNavigationNextDay,
-					CallbackData: "open.schedule.day#date=" + nextDayDate.Format("2006-01-02"),
-				}},
-				{{
-					Text:         lang.Button.ScheduleNavigationPreviousWeek,
-					CallbackData: "open.schedule.day#date=" + prevWeekDate.Format("2006-01-02"),
-				}, {
-					Text:         lang.Button.ScheduleNavigationNextWeek,
-					CallbackData: "open.schedule.day#date=" + nextWeekDate.Format("2006-01-02"),
-				}},
-				{{
+			InlineKeyboard: append(
+				createNavigationButtons(lang, dayButton(groupId, defaultGroupId), prevDayDate, nextDayDate, prevWeekDate, nextWeekDate),
+				[]gotgbot.InlineKeyboardButton{createBackButton(lang), {
 					Text:         lang.Button.Menu,
 					CallbackData: "open.menu",
+				}, {
+					Text:         lang.Button.ScheduleNavigationWeekView,
+					CallbackData: withGroup(utils.NewButtonData("open.schedule.week").Set("date", date), groupId, defaultGroupId).String(),
 				}},
-			},
+			),
 		}
 
 		// Add today button if needed
-		if date != time.Now().Format("2006-01-02") {
+		if date != utils.Now().In(loc).Format("2006-01-02") {
 			buttons.InlineKeyboard[len(buttons.InlineKeyboard)-1] = append(
 				buttons.InlineKeyboard[len(buttons.InlineKeyboard)-1],
 				gotgbot.InlineKeyboardButton{
 					Text:         lang.Button.ScheduleNavigationToday,
-					CallbackData: "open.schedule.today",
+					CallbackData: withGroup(utils.NewButtonData("open.schedule.today"), groupId, defaultGroupId).String(),
 				},
 			)
 		}
 
-		// Add extra info button if needed
-		if CheckExtraText(day) {
-			// Add to the start
-			buttons.InlineKeyboard = append(
-				[][]gotgbot.InlineKeyboardButton{
-					{{
-						Text:         lang.Button.ScheduleExtra,
-						CallbackData: "open.schedule.extra#date=" + date,
-					}},
-				},
-				buttons.InlineKeyboard...,
-			)
+		// Add lesson details button and the lessons quick info buttons to the start
+		buttons.InlineKeyboard = append(
+			append([][]gotgbot.InlineKeyboardButton{{{
+				Text:         lang.Button.ScheduleExtra,
+				CallbackData: withGroup(utils.NewButtonData("open.schedule.extra").Set("date", date), groupId, defaultGroupId).String(),
+			}}}, createLessonButtons(day, date, groupId, defaultGroupId)...),
+			buttons.InlineKeyboard...,
+		)
+
+		if hasBreaks {
+			buttons.InlineKeyboard = append(buttons.InlineKeyboard, createHideEmptyLessonsButton(lang, view, date, groupId, defaultGroupId))
 		}
 
 		pageText += "`—————————————————————————`"
+		pageText += getRemovedLessons(lang, diff)
+	}
+
+	if !cachedAt.IsZero() {
+		pageText += "\n\n_" + utils.EscapeMarkdownV2(getOutdatedDataNote(lang, cachedAt)) + "_"
+	}
+
+	buttons.InlineKeyboard = append(buttons.InlineKeyboard, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Refresh,
+		CallbackData: withGroup(utils.NewButtonData("refresh.schedule").Set("date", date), groupId, defaultGroupId).String(),
+	}, {
+		Text:         lang.Button.DatePicker,
+		CallbackData: withGroup(utils.NewButtonData("open.date_picker").Set("month", date_.Format(DatePickerMonthFormat)).Set("date", date), groupId, defaultGroupId).String(),
+	}, {
+		Text:         lang.Button.ShareSchedule,
+		CallbackData: utils.NewButtonData("share.schedule").SetInt("groupId", groupId).Set("date", date).String(),
+	}})
+
+	if switchButton, ok := createGroupSwitchButton(lang, groupId, groups, date); ok {
+		buttons.InlineKeyboard = append([][]gotgbot.InlineKeyboardButton{{switchButton}}, buttons.InlineKeyboard...)
 	}
 
 	page := Page{
@@ -237,12 +328,259 @@ func CreateSchedulePage(lang i18n.Language, groupId int, date string) (Page, err
 	return page, nil
 }
 
-// isNoLessons checks if there are no lessons in the given day
-func isNoLessons(day *api2.TimeTableDate) bool {
+// removeHiddenLessons returns the day without the hidden lessons.
+// Lessons with all periods hidden are removed, so they are shown as a break.
+func removeHiddenLessons(day *api2.TimeTableDate, hidden data.HiddenLessons) *api2.TimeTableDate {
+	if day == nil || len(hidden) == 0 {
+		return day
+	}
+
+	lessons := make([]api2.TimeTableLesson, 0, len(day.Lessons))
+	for _, lesson := range day.Lessons {
+		periods := make([]api2.TimeTablePeriod, 0, len(lesson.Periods))
+		for _, period := range lesson.Periods {
+			if hidden.Index(GetLessonId(period)) == -1 {
+				periods = append(periods, period)
+			}
+		}
+
+		if len(periods) != 0 {
+			lessons = append(lessons, api2.TimeTableLesson{Number: lesson.Number, Periods: periods})
+		}
+	}
+
+	return &api2.TimeTableDate{Date: day.Date, Lessons: lessons}
+}
+
+// getBreak returns the line shown in place of the lessons
+// from first to last the group has no classes at
+func getBreak(lang i18n.Language, first int, last int) string {
+	numbers := strconv.Itoa(first)
+	if last != first {
+		numbers += "-" + strconv.Itoa(last)
+	}
+
+	return format.Formatp(lang.Text.ScheduleBreak, utils.EscapeMarkdownV2(numbers)) + "\n"
+}
+
+// createLessonButtons creates a button for every lesson of the day
+// that shows its time, teacher and room without opening a page
+func createLessonButtons(day *api2.TimeTableDate, date string, groupId int, defaultGroupId int) [][]gotgbot.InlineKeyboardButton {
+	buttons := make([]gotgbot.InlineKeyboardButton, 0, len(day.Lessons))
 	for _, lesson := range day.Lessons {
+		icon := ""
+		if len(lesson.Periods) != 0 {
+			icon = utils.GetLessonIcon(lesson.Periods[0].Type)
+		}
+
+		buttons = append(buttons, gotgbot.InlineKeyboardButton{
+			Text:         icon + " " + strconv.Itoa(lesson.Number),
+			CallbackData: withGroup(utils.NewButtonData("show.lesson").Set("date", date).SetInt("lesson", lesson.Number), groupId, defaultGroupId).String(),
+		})
+	}
+
+	return utils.SplitRows(buttons, 4)
+}
+
+// GetLessonSummary returns a short plain text with the lesson time,
+// discipline, room and teacher, to show it as a notification.
+//
+// Returns an empty string if there is no such lesson on the date.
+func GetLessonSummary(ctx context.Context, lang i18n.Language, groupId int, date string, lessonNumber int, loc *time.Location) (string, error) {
+	day, _, err := getGroupScheduleDay(ctx, groupId, date)
+	if err != nil || day == nil {
+		return "", err
+	}
+
+	summaries := make([]string, 0)
+	for _, lesson := range day.Lessons {
+		if lesson.Number != lessonNumber {
+			continue
+		}
+
 		for _, period := range lesson.Periods {
-			name := strings.ToLower(period.DisciplineShortName)
-			if !strings.Contains(name, "приховано") {
+			summary := format.Formatm("$number) $timeStart – $timeEnd\n$discipline", format.Values{
+				"number":     lesson.Number,
+				"timeStart":  utils.ConvertLessonTime(date, period.TimeStart, loc),
+				"timeEnd":    utils.ConvertLessonTime(date, period.TimeEnd, loc),
+				"discipline": period.DisciplineShortName,
+			})
+			if period.TypeStr != "" {
+				summary += " [" + period.TypeStr + "]"
+			}
+			if period.Classroom != "" {
+				summary += "\n" + format.Formatp(lang.Text.LessonDetailsRoom, period.Classroom)
+			}
+			if period.TeachersNameFull != "" {
+				summary += "\n" + format.Formatp(lang.Text.LessonDetailsTeacher, period.TeachersNameFull)
+			}
+			summaries = append(summaries, summary)
+		}
+	}
+
+	return strings.Join(summaries, "\n\n"), nil
+}
+
+// getLessonChangeMark returns a mark for the lesson period
+// that was added or moved since the last view
+func getLessonChangeMark(diff scheduler.ScheduleDiff, lesson scheduler.LessonChange) string {
+	for _, added := range diff.Added {
+		if added == lesson {
+			return " ➕"
+		}
+	}
+	for _, move := range diff.Moved {
+		if move.To == lesson {
+			return " 🔀"
+		}
+	}
+	return ""
+}
+
+// getRemovedLessons returns a section with the lessons
+// removed since the last view, or an empty string
+func getRemovedLessons(lang i18n.Language, diff scheduler.ScheduleDiff) string {
+	if len(diff.Removed) == 0 {
+		return ""
+	}
+
+	text := "\n\n" + lang.Text.ScheduleDiffRemoved + "\n"
+	for _, lesson := range diff.Removed {
+		text += formatLessonChange(lesson)
+	}
+
+	return strings.TrimSuffix(text, "\n")
+}
+
+// dayButton returns a function that creates the day schedule button data for the date
+func dayButton(groupId int, defaultGroupId int) func(date time.Time) string {
+	return func(date time.Time) string {
+		return withGroup(utils.NewButtonData("open.schedule.day").Set("date", date.Format(time.DateOnly)), groupId, defaultGroupId).String()
+	}
+}
+
+// withGroup adds the groupId param to the button data, if the group
+// is not the default one the button handler would use without it
+func withGroup(button *utils.ButtonData, groupId int, defaultGroupId int) *utils.ButtonData {
+	if groupId != defaultGroupId {
+		button.SetInt("groupId", groupId)
+	}
+	return button
+}
+
+// createBackButton creates a button that returns to the page opened
+// in the message before, or to the menu if there is no such page
+func createBackButton(lang i18n.Language) gotgbot.InlineKeyboardButton {
+	return gotgbot.InlineKeyboardButton{
+		Text:         lang.Button.Back,
+		CallbackData: "nav.back",
+	}
+}
+
+// createHideEmptyLessonsButton creates a button that shows or hides
+// the breaks between the lessons and opens the same schedule again
+func createHideEmptyLessonsButton(lang i18n.Language, view ScheduleView, date string, groupId int, defaultGroupId int) []gotgbot.InlineKeyboardButton {
+	nextState := "1"
+	if view.HideEmptyLessons {
+		nextState = "0"
+	}
+
+	return []gotgbot.InlineKeyboardButton{{
+		Text:         format.Formatp(lang.Button.SettingHideEmptyLessons, utils.GetSettingIcon(view.HideEmptyLessons)),
+		CallbackData: withGroup(utils.NewButtonData("set.hide_empty_lessons").Set("state", nextState).Set("date", date), groupId, defaultGroupId).String(),
+	}}
+}
+
+// createGroupSwitchButton creates a button with the group name that opens
+// the day schedule of the next one of the groups, to cycle through them.
+//
+// Returns false if there are no other groups to switch to.
+func createGroupSwitchButton(lang i18n.Language, groupId int, groups data.GroupRefs, date string) (gotgbot.InlineKeyboardButton, bool) {
+	if len(groups) < 2 {
+		return gotgbot.InlineKeyboardButton{}, false
+	}
+
+	// Group that is not in the list, e.g. opened by a link, switches to the first one
+	i := groups.Index(groupId)
+	next := groups[(i+1)%len(groups)]
+
+	current := data.GroupRef{Id: groupId}
+	if i != -1 {
+		current = groups[i]
+	}
+
+	button := gotgbot.InlineKeyboardButton{
+		Text:         format.Formatp(lang.Button.SwitchGroup, getSavedGroupName(current)),
+		CallbackData: withGroup(utils.NewButtonData("open.schedule.day").Set("date", date), next.Id, groups[0].Id).String(),
+	}
+
+	return button, true
+}
+
+// getGroupScheduleDay returns the group schedule for a day.
+//
+// If the API is unavailable and the outdated cached schedule is returned,
+// also returns the time it was cached at. Otherwise, returns zero time.
+func getGroupScheduleDay(ctx context.Context, groupId int, date string) (*api2.TimeTableDate, time.Time, error) {
+	if provider, ok := api.WithContext(ctx).(api2.StaleScheduleProvider); ok {
+		return provider.GetGroupScheduleDayStale(groupId, date)
+	}
+
+	day, err := api.WithContext(ctx).GetGroupScheduleDay(groupId, date)
+	return day, time.Time{}, err
+}
+
+// getOutdatedDataNote returns a note that the page shows the data cached at the given time
+func getOutdatedDataNote(lang i18n.Language, cachedAt time.Time) string {
+	now := utils.Now()
+	if cachedAt.Year() == now.Year() && cachedAt.YearDay() == now.YearDay() {
+		return format.Formatp(lang.Text.OutdatedData, cachedAt.Format("15:04"))
+	}
+	return format.Formatp(lang.Text.OutdatedData, cachedAt.Format("02.01 15:04"))
+}
+
+// createNavigationButtons creates previous/next day and previous/next week buttons.
+//
+// button creates a button data for the date, like "open.schedule.day#date=2023-10-04"
+func createNavigationButtons(lang i18n.Language, button func(date time.Time) string, prevDayDate, nextDayDate, prevWeekDate, nextWeekDate time.Time) [][]gotgbot.InlineKeyboardButton {
+	// There is no schedule before the semester start, so the arrows
+	// leading there are disabled and only show a notification
+	previousButton := func(text string, date time.Time) gotgbot.InlineKeyboardButton {
+		if semesterStart, ok := getSemesterStart(); ok && date.Format(time.DateOnly) < semesterStart.Format(time.DateOnly) {
+			return gotgbot.InlineKeyboardButton{
+				Text:         "🚫",
+				CallbackData: "alert.no_earlier_data",
+			}
+		}
+		return gotgbot.InlineKeyboardButton{
+			Text:         text,
+			CallbackData: button(date),
+		}
+	}
+
+	return [][]gotgbot.InlineKeyboardButton{
+		{
+			previousButton(lang.Button.ScheduleNavigationPreviousDay, prevDayDate),
+			{
+				Text:         lang.Button.ScheduleNavigationNextDay,
+				CallbackData: button(nextDayDate),
+			},
+		},
+		{
+			previousButton(lang.Button.ScheduleNavigationPreviousWeek, prevWeekDate),
+			{
+				Text:         lang.Button.ScheduleNavigationNextWeek,
+				CallbackData: button(nextWeekDate),
+			},
+		},
+	}
+}
+
+// IsNoLessons checks if there are no lessons in the given day
+func IsNoLessons(day *api2.TimeTableDate) bool {
+	for _, lesson := range day.Lessons {
+		for _, period := range lesson.Periods {
+			if !utils.IsPeriodHidden(period) {
 				return false
 			}
 		}
@@ -269,7 +607,7 @@ func getTeacher(teachersNameFull string, teachersList *teachers.TeachersList) st
 	} else {
 		// Log warning if teacher page not found
 		if _, ok := teacherPagesNotFound[firstTeacher]; !ok {
-			log.Warningf("Teacher page not found: %s\n", firstTeacher)
+			slog.Warn("Teacher page not found", "teacher", firstTeacher)
 			teacherPagesNotFound[firstTeacher] = true
 		}
 	}
@@ -282,16 +620,44 @@ func getTeacher(teachersNameFull string, teachersList *teachers.TeachersList) st
 	return teacher
 }
 
-// CheckExtraText checks if there is extra text in any lesson of the given day
-func CheckExtraText(day *api2.TimeTableDate) bool {
-	for _, lesson := range day.Lessons {
-		for _, period := range lesson.Periods {
-			if period.ExtraText {
-				return true
-			}
+// FindSchoolDay finds the nearest day with lessons after the given date,
+// or before it if forward is false. The days are requested at once,
+// SchoolDaySearchRange days in the direction.
+//
+// Returns an empty string if there are no lessons in the range.
+func FindSchoolDay(ctx context.Context, groupId int, date string, forward bool) (string, error) {
+	date_, err := api2.ParseISODate(date)
+	if err != nil {
+		return "", err
+	}
+
+	var dateStart, dateEnd time.Time
+	if forward {
+		dateStart, dateEnd = date_.AddDate(0, 0, 1), date_.AddDate(0, 0, SchoolDaySearchRange)
+	} else {
+		dateStart, dateEnd = date_.AddDate(0, 0, -SchoolDaySearchRange), date_.AddDate(0, 0, -1)
+	}
+
+	start, end := dateStart.Format(time.DateOnly), dateEnd.Format(time.DateOnly)
+	schedule, err := api.WithContext(ctx).GetGroupSchedule(groupId, start, end)
+	if err != nil {
+		return "", err
+	}
+
+	// Nearest day is the first one forward and the last one backward
+	found := ""
+	for i := range schedule {
+		day := &schedule[i]
+		if day.Date < start || day.Date > end || IsNoLessons(day) {
+			continue
+		}
+
+		if found == "" || forward == (day.Date < found) {
+			found = day.Date
 		}
 	}
-	return false
+
+	return found, nil
 }
 
 // ScanEmptyDays scans the days before and after the given date and returns the number of days without lessons
@@ -322,7 +688,7 @@ func CountNoLessonsDays(days []api2.TimeTableDate, date time.Time, directionRigh
 			if date_.Before(date) {
 				continue
 			}
-			if isNoLessons(&day) {
+			if IsNoLessons(&day) {
 				count++
 			} else {
 				break
@@ -349,59 +715,126 @@ func CountNoLessonsDays(days []api2.TimeTableDate, date time.Time, directionRigh
 	return count, nil
 }
 
+// getSemesterStart returns the SEMESTER_START env variable date.
+// Returns false if it is not set or invalid.
+func getSemesterStart() (time.Time, bool) {
+	if os.Getenv("SEMESTER_START") == "" {
+		return time.Time{}, false
+	}
+
+	semesterStart, err := time.Parse(time.DateOnly, os.Getenv("SEMESTER_START"))
+	if err != nil {
+		slog.Warn("Error parsing SEMESTER_START", "error", err)
+		return time.Time{}, false
+	}
+
+	return semesterStart, true
+}
+
+// getSemesterWeek returns a line with the semester week number and parity
+// for the date, e.g. "Week 7 (numerator)", starting with a line break.
+//
+// Returns an empty string if SEMESTER_START env variable is not set
+// or the date is before the semester start.
+func getSemesterWeek(lang i18n.Language, date time.Time) string {
+	semesterStart, ok := getSemesterStart()
+	if !ok {
+		return ""
+	}
+
+	week, parity, err := weeks.CurrentSemesterWeek(semesterStart, date)
+	if err != nil {
+		return ""
+	}
+
+	parityName := lang.Text.Numerator
+	if parity == weeks.Denominator {
+		parityName = lang.Text.Denominator
+	}
+
+	return "\n" + format.Formatm(lang.Text.SemesterWeek, format.Values{
+		"week":   week,
+		"parity": parityName,
+	})
+}
+
 func getLocalizedDate(lang i18n.Language, date time.Time, eventEmoji string) string {
+	return format.Formatm(lang.Text.ScheduleDateFormat, format.Values{
+		"emoji":   eventEmoji,
+		"day":     date.Day(),
+		"month":   getMonthName(lang, date.Month()),
+		"year":    date.Year(),
+		"weekday": getWeekDayName(lang, date.Weekday()),
+	})
+}
+
+func getMonthName(lang i18n.Language, month time.Month) string {
 	// No better way to do this if lang is a struct
-	var month string
-	switch date.Month() {
+	switch month {
 	case time.January:
-		month = lang.Text.ShortMonth1
+		return lang.Text.ShortMonth1
 	case time.February:
-		month = lang.Text.ShortMonth2
+		return lang.Text.ShortMonth2
 	case time.March:
-		month = lang.Text.ShortMonth3
+		return lang.Text.ShortMonth3
 	case time.April:
-		month = lang.Text.ShortMonth4
+		return lang.Text.ShortMonth4
 	case time.May:
-		month = lang.Text.ShortMonth5
+		return lang.Text.ShortMonth5
 	case time.June:
-		month = lang.Text.ShortMonth6
+		return lang.Text.ShortMonth6
 	case time.July:
-		month = lang.Text.ShortMonth7
+		return lang.Text.ShortMonth7
 	case time.August:
-		month = lang.Text.ShortMonth8
+		return lang.Text.ShortMonth8
 	case time.September:
-		month = lang.Text.ShortMonth9
+		return lang.Text.ShortMonth9
 	case time.October:
-		month = lang.Text.ShortMonth10
+		return lang.Text.ShortMonth10
 	case time.November:
-		month = lang.Text.ShortMonth11
+		return lang.Text.ShortMonth11
 	case time.December:
-		month = lang.Text.ShortMonth12
+		return lang.Text.ShortMonth12
 	}
+	return ""
+}
 
-	var weekday string
-	switch date.Weekday() {
+func getWeekDayName(lang i18n.Language, weekday time.Weekday) string {
+	switch weekday {
 	case time.Monday:
-		weekday = lang.Text.WeekDay1
+		return lang.Text.WeekDay1
 	case time.Tuesday:
-		weekday = lang.Text.WeekDay2
+		return lang.Text.WeekDay2
 	case time.Wednesday:
-		weekday = lang.Text.WeekDay3
+		return lang.Text.WeekDay3
 	case time.Thursday:
-		weekday = lang.Text.WeekDay4
+		return lang.Text.WeekDay4
 	case time.Friday:
-		weekday = lang.Text.WeekDay5
+		return lang.Text.WeekDay5
 	case time.Saturday:
-		weekday = lang.Text.WeekDay6
+		return lang.Text.WeekDay6
 	case time.Sunday:
-		weekday = lang.Text.WeekDay7
+		return lang.Text.WeekDay7
 	}
+	return lang.Text.WeekDayUnknown
+}
 
-	return format.Formatm(lang.Text.ScheduleDateFormat, format.Values{
-		"emoji":   eventEmoji,
-		"day":     date.Day(),
-		"month":   month,
-		"year":    date.Year(),
-		"weekday": weekday,
-	})
+func getShortWeekDayName(lang i18n.Language, weekday time.Weekday) string {
+	switch weekday {
+	case time.Monday:
+		return lang.Text.ShortWeekDay1
+	case time.Tuesday:
+		return lang.Text.ShortWeekDay2
+	case time.Wednesday:
+		return lang.Text.ShortWeekDay3
+	case time.Thursday:
+		return lang.Text.ShortWeekDay4
+	case time.Friday:
+		return lang.Text.ShortWeekDay5
+	case time.Saturday:
+		return lang.Text.ShortWeekDay6
+	case time.Sunday:
+		return lang.Text.ShortWeekDay7
+	}
+	return lang.Text.ShortWeekDayUnknown
 }
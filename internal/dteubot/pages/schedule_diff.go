@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/cubicbyte/dteubot/internal/scheduler"
+	"github.com/sirkon/go-format/v2"
+	"strings"
+	"time"
+)
+
+// CreateScheduleDiffPage creates a page with the schedule changes of the days.
+// diffs must not be empty.
+func CreateScheduleDiffPage(lang i18n.Language, diffs []scheduler.ScheduleDiff) (Page, error) {
+	days := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		day, err := formatScheduleDiff(lang, diff)
+		if err != nil {
+			return Page{}, err
+		}
+		days = append(days, day)
+	}
+
+	pageText := format.Formatm(lang.Page.ScheduleDiff, format.Values{
+		"changes": strings.Join(days, "\n\n"),
+	})
+
+	page := Page{
+		Text: pageText,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
+				{
+					Text:         lang.Button.OpenSchedule,
+					CallbackData: utils.NewButtonData("open.schedule.day").Set("from", "schedule_diff").Set("date", diffs[0].Date).String(),
+				}, {
+					Text:         lang.Button.Settings,
+					CallbackData: utils.NewButtonData("open.settings").Set("from", "schedule_diff").String(),
+				},
+			}},
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// formatScheduleDiff formats the changes of a single day
+func formatScheduleDiff(lang i18n.Language, diff scheduler.ScheduleDiff) (string, error) {
+	date, err := time.Parse(time.DateOnly, diff.Date)
+	if err != nil {
+		return "", err
+	}
+
+	sections := make([]string, 0, 3)
+	if len(diff.Added) > 0 {
+		section := lang.Text.ScheduleDiffAdded + "\n"
+		for _, lesson := range diff.Added {
+			section += formatLessonChange(lesson)
+		}
+		sections = append(sections, section)
+	}
+	if len(diff.Removed) > 0 {
+		section := lang.Text.ScheduleDiffRemoved + "\n"
+		for _, lesson := range diff.Removed {
+			section += formatLessonChange(lesson)
+		}
+		sections = append(sections, section)
+	}
+	if len(diff.Moved) > 0 {
+		section := lang.Text.ScheduleDiffMoved + "\n"
+		for _, move := range diff.Moved {
+			section += formatLessonChange(move.From)
+			section += "→ " + formatLessonChange(move.To)
+		}
+		sections = append(sections, section)
+	}
+
+	return getLocalizedDate(lang, date, "🗓") + "\n\n" + strings.TrimSuffix(strings.Join(sections, "\n"), "\n"), nil
+}
+
+// formatLessonChange formats a single changed lesson period as a line of the page
+func formatLessonChange(lesson scheduler.LessonChange) string {
+	line := format.Formatm("`$lesson)` *$name*`[$type]`", format.Values{
+		"lesson": lesson.Number,
+		"name":   utils.EscapeMarkdownV2(lesson.Period.DisciplineShortName),
+		"type":   utils.EscapeMarkdownV2(lesson.Period.TypeStr),
+	})
+
+	if lesson.Period.Classroom != "" {
+		line += " " + utils.EscapeMarkdownV2(lesson.Period.Classroom)
+	}
+
+	return line + "\n"
+}
@@ -23,18 +23,33 @@
 package pages
 
 import (
+	"context"
 	"errors"
 	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/rooms"
 	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
 	"github.com/cubicbyte/dteubot/internal/i18n"
 	api2 "github.com/cubicbyte/dteubot/pkg/api"
 	"github.com/sirkon/go-format/v2"
+	"html"
 	"net/http"
+	"strings"
+	"time"
 	"unicode/utf8"
 )
 
-func CreateScheduleExtraInfoPage(lang i18n.Language, groupId int, date string) (Page, error) {
-	schedule, err := api.GetGroupScheduleDay(groupId, date)
+// CreateScheduleExtraInfoPage creates a page with the details of the day lessons:
+// type, room, teachers and the additional info from the university, if any.
+// Online lessons links are also added as the buttons.
+//
+// Every lesson has a button to hide all its occurrences from the schedule,
+// or to show them again if the lesson is hidden. The lessons that haven't
+// started yet also have a button to set a one-shot reminder.
+//
+// defaultGroupId is the group the buttons open without the groupId param.
+func CreateScheduleExtraInfoPage(ctx context.Context, lang i18n.Language, groupId int, defaultGroupId int, date string, hidden data.HiddenLessons) (Page, error) {
+	schedule, cachedAt, err := getGroupScheduleDay(ctx, groupId, date)
 	if err != nil {
 		return Page{}, err
 	}
@@ -43,46 +58,83 @@ func CreateScheduleExtraInfoPage(lang i18n.Language, groupId int, date string) (
 	shownEntries := make(map[int]bool)
 
 	pageExtraText := ""
+	linkButtons := make([][]gotgbot.InlineKeyboardButton, 0)
+	copyButtons := make([]gotgbot.InlineKeyboardButton, 0, len(schedule.Lessons))
+	remindButtons := make([]gotgbot.InlineKeyboardButton, 0, len(schedule.Lessons))
+	hideButtons := make([]gotgbot.InlineKeyboardButton, 0, len(schedule.Lessons))
+	shownLessons := make(map[string]bool)
 	for _, lesson := range schedule.Lessons {
+		copyButtons = append(copyButtons, gotgbot.InlineKeyboardButton{
+			Text:         format.Formatp(lang.Button.CopyLesson, lesson.Number),
+			CallbackData: withGroup(utils.NewButtonData("copy.lesson").Set("date", date).SetInt("lesson", lesson.Number), groupId, defaultGroupId).String(),
+		})
+
+		// Reminders can be set only for the lessons that haven't started yet
+		if len(lesson.Periods) != 0 {
+			start, err := GetLessonStart(date, &lesson)
+			if err != nil {
+				return Page{}, err
+			}
+			if utils.Now().Before(start) {
+				remindButtons = append(remindButtons, gotgbot.InlineKeyboardButton{
+					Text:         format.Formatp(lang.Button.RemindLesson, lesson.Number),
+					CallbackData: withGroup(utils.NewButtonData("open.lesson_reminder").Set("date", date).SetInt("lesson", lesson.Number), groupId, defaultGroupId).String(),
+				})
+			}
+		}
+
 		for _, period := range lesson.Periods {
-			if !period.ExtraText {
-				continue
+			if lessonId := GetLessonId(period); !shownLessons[lessonId] {
+				shownLessons[lessonId] = true
+				hideButtons = append(hideButtons, createHideLessonButton(lang, period, lessonId, hidden, date, groupId, defaultGroupId))
 			}
 
-			if _, ok := shownEntries[period.R1]; ok {
-				continue
-			} else {
+			extraTextStr := ""
+			if period.ExtraText && !shownEntries[period.R1] {
 				shownEntries[period.R1] = true
-			}
 
-			// Get extra info
-			extraText, err := api.GetScheduleExtraInfo(period.R1, date)
-			if err != nil {
-				var httpApiError *api2.HTTPApiError
-				if errors.As(err, &httpApiError) && httpApiError.Code == http.StatusForbidden {
-					return CreateForbiddenPage(lang, "open.schedule.day#date="+date)
+				// Get extra info
+				extraText, err := api.WithContext(ctx).GetScheduleExtraInfo(period.R1, date)
+				if err != nil {
+					var httpApiError *api2.HTTPApiError
+					if errors.As(err, &httpApiError) && httpApiError.Code == http.StatusForbidden {
+						return CreateForbiddenPage(lang, withGroup(utils.NewButtonData("open.schedule.day").Set("date", date), groupId, defaultGroupId).String())
+					}
+					return Page{}, err
 				}
-				return Page{}, err
-			}
 
-			// Clean HTML from unsupported tags
-			extraTextStr := utils.CleanHTML(extraText.Html)
+				for _, link := range utils.FindMeetingLinks(extraText.Html) {
+					linkButtons = append(linkButtons, []gotgbot.InlineKeyboardButton{{
+						Text: format.Formatp(lang.Button.OnlineLesson, period.DisciplineShortName),
+						Url:  link,
+					}})
+				}
 
-			// Remove all spaces and newlines from the beginning and end of the string
-			extraTextStr = utils.CleanText(extraTextStr)
+				// Clean HTML from unsupported tags
+				extraTextStr = utils.CleanHTML(extraText.Html)
 
-			format_ := "<code>$lesson)</code> 📕 <b>$discipline</b><code>[$type]</code>\n$extra\n\n"
-			pageExtraText += format.Formatm(format_, format.Values{
+				// Remove all spaces and newlines from the beginning and end of the string
+				extraTextStr = utils.CleanText(extraTextStr)
+			}
+
+			pageExtraText += format.Formatm("<code>$lesson)</code> 📕 <b>$discipline</b>\n", format.Values{
 				"lesson":     lesson.Number,
-				"discipline": utils.EscapeMarkdownV2(period.DisciplineFullName),
-				"type":       utils.EscapeMarkdownV2(period.TypeStr),
-				"extra":      extraTextStr,
+				"discipline": html.EscapeString(period.DisciplineFullName),
 			})
+			pageExtraText += getLessonDetails(lang, period)
+			if extraTextStr != "" {
+				pageExtraText += extraTextStr + "\n"
+			}
+			pageExtraText += "\n"
 		}
 	}
 
 	pageText := format.Formatp(lang.Page.ScheduleExtraInfo, pageExtraText)
 
+	if !cachedAt.IsZero() {
+		pageText += "\n\n<i>" + html.EscapeString(getOutdatedDataNote(lang, cachedAt)) + "</i>"
+	}
+
 	if utf8.RuneCountInString(pageText) > 4096 {
 		// FIXME: This is vulnerable to HTML tags and can break the page.
 		//   Also, this counts html tags as runes, which is not correct (make sure).
@@ -91,19 +143,109 @@ func CreateScheduleExtraInfoPage(lang i18n.Language, groupId int, date string) (
 		pageText = pageText[:4093] + "..."
 	}
 
+	buttons := append(linkButtons, utils.SplitRows(copyButtons, rowSize)...)
+	buttons = append(buttons, utils.SplitRows(remindButtons, rowSize)...)
+	buttons = append(buttons, utils.SplitRows(hideButtons, 2)...)
+
 	page := Page{
 		Text: pageText,
 		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
-			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
-				{{
-					Text:         lang.Button.Back,
-					CallbackData: "open.schedule.day#date=" + date,
-				}},
-			},
+			InlineKeyboard: append(buttons, []gotgbot.InlineKeyboardButton{{
+				Text:         lang.Button.Back,
+				CallbackData: utils.NewButtonData("nav.back").Set("date", date).String(),
+			}}),
 		},
 		ParseMode:             "HTML",
 		DisableWebPagePreview: true,
 	}
 
+	// Add free rooms finder if buildings are configured
+	if len(GetFreeRoomsBuildings()) != 0 {
+		last := len(page.ReplyMarkup.InlineKeyboard) - 1
+		page.ReplyMarkup.InlineKeyboard[last] = append(page.ReplyMarkup.InlineKeyboard[last], gotgbot.InlineKeyboardButton{
+			Text:         lang.Button.FreeRooms,
+			CallbackData: utils.NewButtonData("open.free_rooms").Set("date", date).String(),
+		})
+	}
+
 	return page, nil
 }
+
+// createHideLessonButton creates a button to hide the lesson
+// from the schedule, or to show it if it's hidden
+func createHideLessonButton(lang i18n.Language, period api2.TimeTablePeriod, lessonId string, hidden data.HiddenLessons, date string, groupId int, defaultGroupId int) gotgbot.InlineKeyboardButton {
+	if hidden.Index(lessonId) != -1 {
+		return gotgbot.InlineKeyboardButton{
+			Text:         format.Formatp(lang.Button.UnhideLesson, period.DisciplineShortName),
+			CallbackData: withGroup(utils.NewButtonData("unhide.lesson").Set("lessonId", lessonId).Set("date", date), groupId, defaultGroupId).String(),
+		}
+	}
+
+	return gotgbot.InlineKeyboardButton{
+		Text:         format.Formatp(lang.Button.HideLesson, period.DisciplineShortName),
+		CallbackData: withGroup(utils.NewButtonData("hide.lesson").Set("lessonId", lessonId).Set("date", date), groupId, defaultGroupId).String(),
+	}
+}
+
+// GetLessonCopyText returns the lesson details in the format that is easy
+// to copy to the notes: "Subject | Room | Teacher | HH:MM–HH:MM", a line per period.
+// Unknown details are omitted. Returns an empty string if there is no such lesson.
+func GetLessonCopyText(ctx context.Context, groupId int, date string, lessonNumber int, loc *time.Location) (string, error) {
+	day, _, err := getGroupScheduleDay(ctx, groupId, date)
+	if err != nil || day == nil {
+		return "", err
+	}
+
+	lines := make([]string, 0)
+	for _, lesson := range day.Lessons {
+		if lesson.Number != lessonNumber {
+			continue
+		}
+
+		for _, period := range lesson.Periods {
+			fields := make([]string, 0, 4)
+			for _, field := range []string{period.DisciplineFullName, period.Classroom, period.TeachersNameFull} {
+				if field != "" {
+					fields = append(fields, field)
+				}
+			}
+			fields = append(fields, utils.ConvertLessonTime(date, period.TimeStart, loc)+"–"+utils.ConvertLessonTime(date, period.TimeEnd, loc))
+
+			lines = append(lines, strings.Join(fields, " | "))
+		}
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// getLessonDetails returns the lesson type, room, building and teachers lines.
+// Unknown details are omitted.
+func getLessonDetails(lang i18n.Language, period api2.TimeTablePeriod) string {
+	details := ""
+	if period.TypeStr != "" {
+		details += format.Formatp(lang.Text.LessonDetailsType, html.EscapeString(period.TypeStr)) + "\n"
+	}
+	if period.Classroom != "" {
+		details += format.Formatp(lang.Text.LessonDetailsRoom, html.EscapeString(period.Classroom)) + "\n"
+		if building := getClassroomBuilding(period.Classroom); building != "" {
+			details += format.Formatp(lang.Text.LessonDetailsBuilding, html.EscapeString(building)) + "\n"
+		}
+	}
+	if period.TeachersNameFull != "" {
+		details += format.Formatp(lang.Text.LessonDetailsTeacher, html.EscapeString(period.TeachersNameFull)) + "\n"
+	}
+	return details
+}
+
+// getClassroomBuilding returns the building of the classroom from
+// FREE_ROOMS_BUILDINGS list, or an empty string if it is not listed
+func getClassroomBuilding(classroom string) string {
+	building := ""
+	for _, b := range GetFreeRoomsBuildings() {
+		// Prefer the longest match, e.g. "A1" over "A"
+		if rooms.InBuilding(classroom, b) && len(b) > len(building) {
+			building = b
+		}
+	}
+	return building
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"context"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"github.com/sirkon/go-format/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CreateWeekSchedulePage creates a page with the schedule for the whole week
+// (Monday - Sunday) that contains the given date.
+//
+// defaultGroupId is the group the buttons open without the groupId param,
+// the chat group.
+//
+// The page can exceed MaxPageLength, split it with SplitLongPage before sending.
+func CreateWeekSchedulePage(ctx context.Context, lang i18n.Language, groupId int, defaultGroupId int, date string, loc *time.Location) (Page, error) {
+	date_, err := api2.ParseISODate(date)
+	if err != nil {
+		return Page{}, err
+	}
+
+	weekStart := GetWeekStart(date_)
+	weekEnd := weekStart.AddDate(0, 0, 6)
+
+	schedule, err := api.WithContext(ctx).GetGroupSchedule(
+		groupId,
+		weekStart.Format("2006-01-02"),
+		weekEnd.Format("2006-01-02"),
+	)
+	if err != nil {
+		return Page{}, err
+	}
+
+	dateStart := getLocalizedShortDate(lang, weekStart)
+	dateEnd := getLocalizedShortDate(lang, weekEnd)
+
+	var pageText string
+	var days []string
+	noLessons := true
+
+	for i := 0; i < 7; i++ {
+		dayDate := weekStart.AddDate(0, 0, i)
+		dayText := "*" + getShortWeekDayName(lang, dayDate.Weekday()) + "*  " +
+			getLocalizedShortDate(lang, dayDate) + "\n"
+
+		day := schedule.GetDay(dayDate.Format("2006-01-02"))
+		if day == nil || IsNoLessons(day) {
+			days = append(days, dayText+"`  —`\n")
+			continue
+		}
+
+		noLessons = false
+		for _, lesson := range day.Lessons {
+			for _, period := range lesson.Periods {
+				format_ := "`$lessonNumber  $timeStart` $lessonIcon *$disciplineShortName* `[$typeStr]` $classroom\n"
+				dayText += format.Formatm(format_, format.Values{
+					"lessonNumber":        strconv.Itoa(lesson.Number),
+					"timeStart":           utils.EscapeMarkdownV2(utils.ConvertLessonTime(day.Date, period.TimeStart, loc)),
+					"lessonIcon":          utils.GetLessonIcon(period.Type),
+					"disciplineShortName": utils.EscapeMarkdownV2(period.DisciplineShortName),
+					"typeStr":             utils.EscapeMarkdownV2(period.TypeStr),
+					"classroom":           utils.EscapeMarkdownV2(period.Classroom),
+				})
+			}
+		}
+		days = append(days, dayText)
+	}
+
+	if noLessons {
+		pageText = format.Formatm(lang.Page.ScheduleEmptyWeek, format.Values{
+			"dateStart": dateStart,
+			"dateEnd":   dateEnd,
+		})
+	} else {
+		header := format.Formatm(lang.Page.ScheduleWeek, format.Values{
+			"dateStart": dateStart,
+			"dateEnd":   dateEnd,
+		})
+		pageText = header + "\n\n" + strings.Join(days, "\n")
+	}
+
+	prevWeekDate := weekStart.AddDate(0, 0, -7)
+	nextWeekDate := weekStart.AddDate(0, 0, 7)
+
+	// Return to the same day if it is in the current week, otherwise to the week start
+	dayViewDate := date_
+	if dayViewDate.Before(weekStart) || dayViewDate.After(weekEnd) {
+		dayViewDate = weekStart
+	}
+
+	page := Page{
+		Text: pageText,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+				{{
+					Text:         lang.Button.ScheduleNavigationPreviousWeek,
+					CallbackData: withGroup(utils.NewButtonData("open.schedule.week").Set("date", prevWeekDate.Format("2006-01-02")), groupId, defaultGroupId).String(),
+				}, {
+					Text:         lang.Button.ScheduleNavigationNextWeek,
+					CallbackData: withGroup(utils.NewButtonData("open.schedule.week").Set("date", nextWeekDate.Format("2006-01-02")), groupId, defaultGroupId).String(),
+				}},
+				{createBackButton(lang), {
+					Text:         lang.Button.Menu,
+					CallbackData: "open.menu",
+				}, {
+					Text:         lang.Button.ScheduleNavigationDayView,
+					CallbackData: withGroup(utils.NewButtonData("open.schedule.day").Set("date", dayViewDate.Format("2006-01-02")), groupId, defaultGroupId).String(),
+				}},
+				{{
+					Text:         lang.Button.ScheduleNavigationWeekOverview,
+					CallbackData: withGroup(utils.NewButtonData("open.schedule.overview").Set("date", weekStart.Format("2006-01-02")), groupId, defaultGroupId).String(),
+				}, {
+					Text:         lang.Button.CalendarExport,
+					CallbackData: "export.calendar",
+				}},
+			},
+		},
+		ParseMode:             "MarkdownV2",
+		DisableWebPagePreview: true,
+	}
+
+	return page, nil
+}
+
+// GetWeekStart returns the monday of the week that contains the given date
+func GetWeekStart(date time.Time) time.Time {
+	offset := (int(date.Weekday()) + 6) % 7
+	return date.AddDate(0, 0, -offset)
+}
+
+func getLocalizedShortDate(lang i18n.Language, date time.Time) string {
+	return format.Formatm(lang.Text.ShortDateFormat, format.Values{
+		"day":   date.Day(),
+		"month": getMonthName(lang, date.Month()),
+	})
+}
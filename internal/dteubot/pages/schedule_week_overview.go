@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"context"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"github.com/sirkon/go-format/v2"
+	"time"
+)
+
+// CreateWeekOverviewPage creates a compact page with one line per day
+// of the week (Monday - Sunday) that contains the given date.
+// defaultGroupId is the group the buttons open without the groupId param.
+func CreateWeekOverviewPage(ctx context.Context, lang i18n.Language, groupId int, defaultGroupId int, date string, loc *time.Location) (Page, error) {
+	date_, err := api2.ParseISODate(date)
+	if err != nil {
+		return Page{}, err
+	}
+
+	weekStart := GetWeekStart(date_)
+	weekEnd := weekStart.AddDate(0, 0, 6)
+
+	schedule, err := api.WithContext(ctx).GetGroupSchedule(
+		groupId,
+		weekStart.Format("2006-01-02"),
+		weekEnd.Format("2006-01-02"),
+	)
+	if err != nil {
+		return Page{}, err
+	}
+
+	calls, err := api.WithContext(ctx).GetCallSchedule()
+	if err != nil {
+		return Page{}, err
+	}
+
+	pageText := format.Formatm(lang.Page.ScheduleWeek, format.Values{
+		"dateStart": getLocalizedShortDate(lang, weekStart),
+		"dateEnd":   getLocalizedShortDate(lang, weekEnd),
+	}) + "\n\n"
+
+	dayButtons := make([]gotgbot.InlineKeyboardButton, 0, 7)
+
+	for i := 0; i < 7; i++ {
+		dayDate := weekStart.AddDate(0, 0, i)
+		weekday := getShortWeekDayName(lang, dayDate.Weekday())
+
+		dayButtons = append(dayButtons, gotgbot.InlineKeyboardButton{
+			Text:         weekday,
+			CallbackData: withGroup(utils.NewButtonData("open.schedule.day").Set("date", dayDate.Format("2006-01-02")), groupId, defaultGroupId).String(),
+		})
+
+		day := schedule.GetDay(dayDate.Format("2006-01-02"))
+		if day == nil || IsNoLessons(day) {
+			pageText += format.Formatm(lang.Text.WeekOverviewFreeDay, format.Values{
+				"weekday": weekday,
+				"date":    getLocalizedShortDate(lang, dayDate),
+			}) + "\n"
+			continue
+		}
+
+		timeStart, timeEnd := getDayTimeRange(day, calls)
+		pageText += format.Formatm(lang.Text.WeekOverviewDay, format.Values{
+			"weekday":   weekday,
+			"date":      getLocalizedShortDate(lang, dayDate),
+			"count":     len(day.Lessons),
+			"classes":   lang.Plural(len(day.Lessons), lang.Text.PluralClasses),
+			"timeStart": utils.EscapeMarkdownV2(utils.ConvertLessonTime(day.Date, timeStart, loc)),
+			"timeEnd":   utils.EscapeMarkdownV2(utils.ConvertLessonTime(day.Date, timeEnd, loc)),
+		}) + "\n"
+	}
+
+	prevWeekDate := weekStart.AddDate(0, 0, -7)
+	nextWeekDate := weekStart.AddDate(0, 0, 7)
+
+	page := Page{
+		Text: pageText,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+				dayButtons[:4],
+				dayButtons[4:],
+				{{
+					Text:         lang.Button.ScheduleNavigationPreviousWeek,
+					CallbackData: withGroup(utils.NewButtonData("open.schedule.overview").Set("date", prevWeekDate.Format("2006-01-02")), groupId, defaultGroupId).String(),
+				}, {
+					Text:         lang.Button.ScheduleNavigationNextWeek,
+					CallbackData: withGroup(utils.NewButtonData("open.schedule.overview").Set("date", nextWeekDate.Format("2006-01-02")), groupId, defaultGroupId).String(),
+				}},
+				{{
+					Text:         lang.Button.Menu,
+					CallbackData: "open.menu",
+				}, {
+					Text:         lang.Button.ScheduleNavigationWeekView,
+					CallbackData: withGroup(utils.NewButtonData("open.schedule.week").Set("date", weekStart.Format("2006-01-02")), groupId, defaultGroupId).String(),
+				}},
+			},
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// getDayTimeRange returns the start time of the first lesson and
+// the end time of the last lesson of the day.
+//
+// If the API didn't return the lesson time, the call schedule is used.
+func getDayTimeRange(day *api2.TimeTableDate, calls api2.CallSchedule) (string, string) {
+	first := day.Lessons[0]
+	last := day.Lessons[len(day.Lessons)-1]
+
+	var timeStart, timeEnd string
+	if len(first.Periods) > 0 {
+		timeStart = first.Periods[0].TimeStart
+	}
+	if len(last.Periods) > 0 {
+		timeEnd = last.Periods[0].TimeEnd
+	}
+
+	if timeStart == "" {
+		if call := calls.GetCall(first.Number); call != nil {
+			timeStart = call.TimeStart
+		}
+	}
+	if timeEnd == "" {
+		if call := calls.GetCall(last.Number); call != nil {
+			timeEnd = call.TimeEnd
+		}
+	}
+
+	return timeStart, timeEnd
+}
@@ -0,0 +1,222 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/sirkon/go-format/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"time"
+)
+
+// SemesterOverviewWeeks is the number of weeks shown on the semester overview
+const SemesterOverviewWeeks = 22
+
+// Semester overview image layout, in pixels
+const (
+	overviewCell   = 24
+	overviewGap    = 4
+	overviewMargin = 20
+	// overviewLabel is the height of the month labels above the days
+	overviewLabel = 20
+	// overviewLegend is the height of the legend below the days
+	overviewLegend = 48
+)
+
+var (
+	overviewBackground = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	overviewText       = color.RGBA{R: 0x57, G: 0x60, B: 0x6a, A: 0xff}
+	overviewBorder     = color.RGBA{R: 0xd0, G: 0xd7, B: 0xde, A: 0xff}
+	overviewToday      = color.RGBA{R: 0xe5, G: 0x39, B: 0x35, A: 0xff}
+	// overviewLoads are the colors of the days by the number of lessons:
+	// none, 1-2, 3-4 and 5 or more
+	overviewLoads = []color.RGBA{
+		{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		{R: 0x9b, G: 0xe9, B: 0xa8, A: 0xff},
+		{R: 0x40, G: 0xc4, B: 0x63, A: 0xff},
+		{R: 0x21, G: 0x6e, B: 0x39, A: 0xff},
+	}
+	overviewLegendLabels = []string{"0", "1-2", "3-4", "5+"}
+)
+
+// GetSemesterOverviewRange returns the first and the last day of the semester
+// overview: SemesterOverviewWeeks starting from the week of SEMESTER_START.
+// If it's not set, the semester is considered to start on
+// September 1 or February 1, whichever was the last, in the timezone of now.
+func GetSemesterOverviewRange(now time.Time) (time.Time, time.Time) {
+	semesterStart, ok := getSemesterStart()
+	if !ok {
+		semesterStart = time.Date(now.Year(), time.September, 1, 0, 0, 0, 0, now.Location())
+		if now.Before(semesterStart) {
+			semesterStart = time.Date(now.Year(), time.February, 1, 0, 0, 0, 0, now.Location())
+		}
+		if now.Before(semesterStart) {
+			semesterStart = time.Date(now.Year()-1, time.September, 1, 0, 0, 0, 0, now.Location())
+		}
+	}
+
+	start := GetWeekStart(semesterStart)
+	return start, start.AddDate(0, 0, SemesterOverviewWeeks*7-1)
+}
+
+// CreateSemesterOverviewImage creates a PNG calendar of the group semester:
+// a column for each week and a cell for each day, colored by the number
+// of lessons on the day. Today is outlined. The lessons are counted
+// like on the schedule page, without the hidden ones unless they are crossed out.
+//
+// loc is the chat timezone, used to outline today.
+func CreateSemesterOverviewImage(ctx context.Context, groupId int, view ScheduleView, loc *time.Location) ([]byte, error) {
+	now := utils.Now().In(loc)
+	start, end := GetSemesterOverviewRange(now)
+
+	schedule, err := api.WithContext(ctx).GetGroupSchedule(groupId, start.Format(time.DateOnly), end.Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+
+	lessons := make(map[string]int, len(schedule))
+	for i := range schedule {
+		day := &schedule[i]
+		if !view.StrikeHiddenLessons {
+			day = removeHiddenLessons(day, view.HiddenLessons)
+		}
+
+		for _, lesson := range day.Lessons {
+			if len(lesson.Periods) != 0 {
+				lessons[day.Date]++
+			}
+		}
+	}
+
+	width := overviewMargin*2 + SemesterOverviewWeeks*(overviewCell+overviewGap) - overviewGap
+	height := overviewMargin*2 + overviewLabel + 7*(overviewCell+overviewGap) - overviewGap + overviewLegend
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(overviewBackground), image.Point{}, draw.Src)
+
+	today := now.Format(time.DateOnly)
+	labelEnd := 0
+	for i := 0; i < SemesterOverviewWeeks*7; i++ {
+		date := start.AddDate(0, 0, i)
+		x := overviewMargin + i/7*(overviewCell+overviewGap)
+		y := overviewMargin + overviewLabel + i%7*(overviewCell+overviewGap)
+		cell := image.Rect(x, y, x+overviewCell, y+overviewCell)
+
+		border := overviewBorder
+		if date.Format(time.DateOnly) == today {
+			border = overviewToday
+		}
+		drawCell(img, cell, getLoadColor(lessons[date.Format(time.DateOnly)]), border)
+
+		// Label the week the month starts at, if there is space left after the previous label
+		if (date.Day() == 1 || i == 0) && x >= labelEnd {
+			label := fmt.Sprintf("%02d.%02d", date.Day(), date.Month())
+			drawText(img, x, overviewMargin+overviewLabel-6, label)
+			labelEnd = x + font.MeasureString(basicfont.Face7x13, label).Ceil() + overviewGap
+		}
+	}
+
+	// Legend
+	x := overviewMargin
+	y := height - overviewMargin - overviewCell
+	for i, label := range overviewLegendLabels {
+		drawCell(img, image.Rect(x, y, x+overviewCell, y+overviewCell), overviewLoads[i], overviewBorder)
+		x += overviewCell + overviewGap*2
+		drawText(img, x, y+overviewCell/2+4, label)
+		x += font.MeasureString(basicfont.Face7x13, label).Ceil() + overviewGap*6
+	}
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// CreateSemesterOverviewPage creates a caption for the semester overview image
+func CreateSemesterOverviewPage(lang i18n.Language, loc *time.Location) (Page, error) {
+	start, end := GetSemesterOverviewRange(utils.Now().In(loc))
+
+	page := Page{
+		Text: format.Formatm(lang.Page.SemesterOverview, format.Values{
+			"dateStart": getLocalizedShortDate(lang, start),
+			"dateEnd":   getLocalizedShortDate(lang, end),
+		}),
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// CreateSemesterOverviewLoadingPage creates a page shown
+// while the semester overview image is being created
+func CreateSemesterOverviewLoadingPage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text:      lang.Page.SemesterOverviewLoading,
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// getLoadColor returns the color of the day with the given number of lessons
+func getLoadColor(lessons int) color.RGBA {
+	switch {
+	case lessons == 0:
+		return overviewLoads[0]
+	case lessons <= 2:
+		return overviewLoads[1]
+	case lessons <= 4:
+		return overviewLoads[2]
+	default:
+		return overviewLoads[3]
+	}
+}
+
+// drawCell draws the day cell with the 1px border
+func drawCell(img draw.Image, rect image.Rectangle, fill color.Color, border color.Color) {
+	draw.Draw(img, rect, image.NewUniform(border), image.Point{}, draw.Src)
+	draw.Draw(img, rect.Inset(1), image.NewUniform(fill), image.Point{}, draw.Src)
+}
+
+// drawText draws the text with its baseline starting at x, y.
+// The font has only ASCII characters.
+func drawText(img draw.Image, x int, y int, text string) {
+	drawer := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(overviewText),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	drawer.DrawString(text)
+}
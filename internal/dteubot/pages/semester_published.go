@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/cubicbyte/dteubot/internal/scheduler"
+	"github.com/sirkon/go-format/v2"
+	"time"
+)
+
+// CreateSemesterPublishedPage creates a page notifying
+// that the schedule of the new semester is published
+func CreateSemesterPublishedPage(lang i18n.Language, event scheduler.SemesterPublished) (Page, error) {
+	date, err := time.Parse(time.DateOnly, event.Date)
+	if err != nil {
+		return Page{}, err
+	}
+
+	pageText := format.Formatm(lang.Page.SemesterPublished, format.Values{
+		"semester": event.Semester,
+		"date":     getLocalizedShortDate(lang, date),
+	})
+
+	page := Page{
+		Text: pageText,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
+				{
+					Text:         lang.Button.OpenSchedule,
+					CallbackData: utils.NewButtonData("open.schedule.day").Set("from", "semester_published").Set("date", event.Date).String(),
+				}, {
+					Text:         lang.Button.Settings,
+					CallbackData: utils.NewButtonData("open.settings").Set("from", "semester_published").String(),
+				},
+			}},
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
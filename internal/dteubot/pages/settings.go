@@ -28,9 +28,11 @@ import (
 	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
 	"github.com/cubicbyte/dteubot/internal/i18n"
 	"github.com/sirkon/go-format/v2"
-	"strconv"
 )
 
+// ReminderOffsets is a list of class reminder offsets (in minutes) available in the settings
+var ReminderOffsets = []int{5, 10, 15, 30}
+
 func CreateSettingsPage(lang i18n.Language, chat *data.Chat) (Page, error) {
 	// Mark settings as seen
 	if !chat.SeenSettings {
@@ -41,23 +43,9 @@ func CreateSettingsPage(lang i18n.Language, chat *data.Chat) (Page, error) {
 	}
 
 	// Get group name
-	var groupName string
-	if chat.GroupId == -1 {
-		groupName = lang.Text.NotSelected
-	} else {
-		group, err := groupsCache.GetGroupById(chat.GroupId)
-		if err != nil {
-			if group == nil {
-				return Page{}, err
-			}
-			log.Warningf("Error getting %d group name: %s", chat.GroupId, err)
-		}
-		if group == nil {
-			groupId := utils.EscapeMarkdownV2(strconv.Itoa(chat.GroupId))
-			groupName = format.Formatp(lang.Text.UnknownGroupName, groupId)
-		} else {
-			groupName = utils.EscapeMarkdownV2(group.Name)
-		}
+	groupName, err := getGroupName(lang, chat.GroupId)
+	if err != nil {
+		return Page{}, err
 	}
 
 	// Get classes notification states
@@ -82,6 +70,13 @@ func CreateSettingsPage(lang i18n.Language, chat *data.Chat) (Page, error) {
 		notifNextPartNextState = "1"
 	}
 
+	var reminderNextState string
+	if chat.ClassesReminder {
+		reminderNextState = "0"
+	} else {
+		reminderNextState = "1"
+	}
+
 	pageText := format.Formatm(lang.Page.Settings, format.Values{
 		"group": groupName,
 	})
@@ -97,26 +92,140 @@ func CreateSettingsPage(lang i18n.Language, chat *data.Chat) (Page, error) {
 					Text:         lang.Button.SelectLang,
 					CallbackData: "open.select_lang",
 				}},
+				{{
+					Text:         lang.Button.SavedGroups,
+					CallbackData: "open.saved_groups",
+				}},
 				{{
 					Text:         format.Formatp(lang.Button.SettingClNotif15m, utils.GetSettingIcon(chat.ClassesNotification15m)),
-					CallbackData: "set.cl_notif#time=15m&state=" + notif15mNextState,
+					CallbackData: utils.NewButtonData("set.cl_notif").Set("time", "15m").Set("state", notif15mNextState).String(),
 				}},
 				{{
 					Text:         format.Formatp(lang.Button.SettingClNotif1m, utils.GetSettingIcon(chat.ClassesNotification1m)),
-					CallbackData: "set.cl_notif#time=1m&state=" + notif1mNextState,
+					CallbackData: utils.NewButtonData("set.cl_notif").Set("time", "1m").Set("state", notif1mNextState).String(),
 				}},
 				{{
 					Text:         format.Formatp(lang.Button.SettingClNotifNextPart, utils.GetSettingIcon(chat.ClassesNotificationNextPart)),
-					CallbackData: "set.cl_notif_next_part#state=" + notifNextPartNextState,
+					CallbackData: utils.NewButtonData("set.cl_notif_next_part").Set("state", notifNextPartNextState).String(),
 				}},
 				{{
-					Text:         lang.Button.Back,
-					CallbackData: "open.menu",
+					Text:         format.Formatp(lang.Button.SettingClReminder, utils.GetSettingIcon(chat.ClassesReminder)),
+					CallbackData: utils.NewButtonData("set.cl_reminder").Set("state", reminderNextState).String(),
 				}},
 			},
 		},
 		ParseMode: "MarkdownV2",
 	}
 
+	// Add reminder offset selection
+	if chat.ClassesReminder {
+		offset := chat.ReminderOffset
+		if offset <= 0 {
+			offset = data.DefaultReminderOffset
+		}
+
+		offsetButtons := make([]gotgbot.InlineKeyboardButton, 0, len(ReminderOffsets))
+		for _, offset2 := range ReminderOffsets {
+			text := format.Formatp(lang.Button.SettingReminderOffset, offset2)
+			if offset2 == offset {
+				text = "• " + text + " •"
+			}
+			offsetButtons = append(offsetButtons, gotgbot.InlineKeyboardButton{
+				Text:         text,
+				CallbackData: utils.NewButtonData("set.reminder_offset").SetInt("offset", offset2).String(),
+			})
+		}
+
+		page.ReplyMarkup.InlineKeyboard = append(page.ReplyMarkup.InlineKeyboard, offsetButtons)
+	}
+
+	var notifyChangesNextState string
+	if chat.NotifyChanges {
+		notifyChangesNextState = "0"
+	} else {
+		notifyChangesNextState = "1"
+	}
+
+	page.ReplyMarkup.InlineKeyboard = append(page.ReplyMarkup.InlineKeyboard, []gotgbot.InlineKeyboardButton{{
+		Text:         format.Formatp(lang.Button.SettingNotifyChanges, utils.GetSettingIcon(chat.NotifyChanges)),
+		CallbackData: utils.NewButtonData("set.notify_changes").Set("state", notifyChangesNextState).String(),
+	}})
+
+	var hideEmptyLessonsNextState string
+	if chat.HideEmptyLessons {
+		hideEmptyLessonsNextState = "0"
+	} else {
+		hideEmptyLessonsNextState = "1"
+	}
+
+	page.ReplyMarkup.InlineKeyboard = append(page.ReplyMarkup.InlineKeyboard, []gotgbot.InlineKeyboardButton{{
+		Text:         format.Formatp(lang.Button.SettingHideEmptyLessons, utils.GetSettingIcon(chat.HideEmptyLessons)),
+		CallbackData: utils.NewButtonData("set.hide_empty_lessons").Set("state", hideEmptyLessonsNextState).String(),
+	}, {
+		Text:         lang.Button.HiddenLessons,
+		CallbackData: "open.hidden_lessons",
+	}})
+
+	var cleanupPagesNextState string
+	if chat.CleanupPages {
+		cleanupPagesNextState = "0"
+	} else {
+		cleanupPagesNextState = "1"
+	}
+
+	page.ReplyMarkup.InlineKeyboard = append(page.ReplyMarkup.InlineKeyboard, []gotgbot.InlineKeyboardButton{{
+		Text:         format.Formatp(lang.Button.SettingCleanupPages, utils.GetSettingIcon(chat.CleanupPages)),
+		CallbackData: utils.NewButtonData("set.cleanup_pages").Set("state", cleanupPagesNextState).String(),
+	}})
+
+	page.ReplyMarkup.InlineKeyboard = append(page.ReplyMarkup.InlineKeyboard, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.DailySchedule,
+		CallbackData: "open.daily_schedule",
+	}, {
+		Text:         lang.Button.Timezone,
+		CallbackData: "open.timezone",
+	}})
+
+	// Group chat ids are negative
+	if chat.Id < 0 {
+		// Allow admins to lock the group chat settings
+		var settingsLockNextState string
+		if chat.SettingsLocked {
+			settingsLockNextState = "0"
+		} else {
+			settingsLockNextState = "1"
+		}
+
+		page.ReplyMarkup.InlineKeyboard = append(page.ReplyMarkup.InlineKeyboard, []gotgbot.InlineKeyboardButton{{
+			Text:         format.Formatp(lang.Button.SettingSettingsLock, utils.GetSettingIcon(chat.SettingsLocked)),
+			CallbackData: utils.NewButtonData("set.settings_lock").Set("state", settingsLockNextState).String(),
+		}})
+	} else {
+		// Allow user to see their own group schedule in group chats
+		user, err := userRepo.GetById(chat.Id)
+		if err != nil {
+			return Page{}, err
+		}
+
+		if user != nil {
+			var ownGroupNextState string
+			if user.UseOwnGroup {
+				ownGroupNextState = "0"
+			} else {
+				ownGroupNextState = "1"
+			}
+
+			page.ReplyMarkup.InlineKeyboard = append(page.ReplyMarkup.InlineKeyboard, []gotgbot.InlineKeyboardButton{{
+				Text:         format.Formatp(lang.Button.SettingOwnGroup, utils.GetSettingIcon(user.UseOwnGroup)),
+				CallbackData: utils.NewButtonData("set.own_group").Set("state", ownGroupNextState).String(),
+			}})
+		}
+	}
+
+	page.ReplyMarkup.InlineKeyboard = append(page.ReplyMarkup.InlineKeyboard, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Back,
+		CallbackData: "open.menu",
+	}})
+
 	return page, nil
 }
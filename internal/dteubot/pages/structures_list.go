@@ -23,30 +23,40 @@
 package pages
 
 import (
+	"context"
 	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
 	"github.com/cubicbyte/dteubot/internal/i18n"
-	"strconv"
 )
 
-func CreateStructuresListPage(lang i18n.Language) (Page, error) {
-	structures, err := api.GetStructures()
+// CreateStructuresListPage creates the first page of the group selection.
+//
+// If save is true, the selected group is added to the saved groups
+// instead of becoming the chat group.
+func CreateStructuresListPage(ctx context.Context, lang i18n.Language, groupId int, save bool) (Page, error) {
+	structures, err := api.WithContext(ctx).GetStructures()
 	if err != nil {
 		return Page{}, err
 	}
 
-	buttons := make([][]gotgbot.InlineKeyboardButton, len(structures)+1)
-	buttons[0] = []gotgbot.InlineKeyboardButton{{
-		Text:         lang.Button.Back,
-		CallbackData: "open.menu#from=group_select",
-	}}
+	buttons := make([][]gotgbot.InlineKeyboardButton, len(structures)+1, len(structures)+3)
+	buttons[0] = []gotgbot.InlineKeyboardButton{createGroupSelectionBackButton(lang, save)}
 
 	for i, structure := range structures {
 		buttons[i+1] = []gotgbot.InlineKeyboardButton{{
 			Text:         structure.FullName,
-			CallbackData: "select.schedule.structure#structureId=" + strconv.Itoa(structure.Id),
+			CallbackData: withSave(utils.NewButtonData("select.schedule.structure").SetInt("structureId", structure.Id), save).String(),
 		}}
 	}
 
+	// Search and sharing are about the chat group
+	if !save {
+		buttons = append(buttons, createGroupSearchButton(lang))
+		if groupId != -1 {
+			buttons = append(buttons, createShareGroupButton(lang, groupId))
+		}
+	}
+
 	page := Page{
 		Text:        lang.Page.StructureSelection,
 		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons},
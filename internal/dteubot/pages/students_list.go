@@ -23,14 +23,16 @@
 package pages
 
 import (
+	"context"
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
 	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/cubicbyte/dteubot/internal/logging"
 	"github.com/sirkon/go-format/v2"
 	"strconv"
 )
 
-func CreateStudentsListPage(lang i18n.Language, groupId int) (Page, error) {
+func CreateStudentsListPage(ctx context.Context, lang i18n.Language, groupId int) (Page, error) {
 	// Send error if group is not selected
 	if groupId < 0 {
 		return CreateInvalidGroupPage(lang)
@@ -44,7 +46,7 @@ func CreateStudentsListPage(lang i18n.Language, groupId int) (Page, error) {
 		if group == nil {
 			return Page{}, err
 		}
-		log.Warningf("Error getting %d group name: %s", groupId, err)
+		logging.FromContext(ctx).Warn("Error getting group name", "group_id", groupId, "error", err)
 	}
 	if group == nil {
 		groupId := utils.EscapeMarkdownV2(strconv.Itoa(groupId))
@@ -54,7 +56,7 @@ func CreateStudentsListPage(lang i18n.Language, groupId int) (Page, error) {
 	}
 
 	// Get students
-	students, err := api.GetGroupStudents(groupId)
+	students, err := api.WithContext(ctx).GetGroupStudents(groupId)
 	if err != nil {
 		return Page{}, err
 	}
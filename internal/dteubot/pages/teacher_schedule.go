@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"context"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"github.com/sirkon/go-format/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func CreateTeacherSchedulePage(ctx context.Context, lang i18n.Language, teacherId int, date string, loc *time.Location) (Page, error) {
+	date_, err := api2.ParseISODate(date)
+	if err != nil {
+		return Page{}, err
+	}
+
+	schedule, err := api.WithContext(ctx).GetTeacherSchedule(teacherId, date, date)
+	if err != nil {
+		return Page{}, err
+	}
+
+	day := schedule.GetDay(date)
+
+	var pageText string
+
+	if day == nil || IsNoLessons(day) {
+		pageText = format.Formatp(lang.Page.ScheduleEmptyDay, getLocalizedDate(lang, date_, "👨‍🏫"))
+	} else {
+		pageText = getLocalizedDate(lang, date_, "👨‍🏫") + "\n"
+		if teacher := getTeacherName(day); teacher != "" {
+			pageText += "*" + utils.EscapeMarkdownV2(teacher) + "*\n"
+		}
+		pageText += "\n"
+
+		for _, lesson := range day.Lessons {
+			for _, period := range mergeTeacherPeriods(lesson.Periods) {
+				format_ := "`———— ``$timeStart`` ——— ``$timeEnd`` ————`\n`  `$lessonIcon *$disciplineShortName*`[$typeStr]`\n`$lessonNumber `$classroom\n`  `$groups\n"
+				pageText += format.Formatm(format_, format.Values{
+					"timeStart":           utils.EscapeMarkdownV2(utils.ConvertLessonTime(date, period.TimeStart, loc)),
+					"timeEnd":             utils.EscapeMarkdownV2(utils.ConvertLessonTime(date, period.TimeEnd, loc)),
+					"disciplineShortName": utils.EscapeMarkdownV2(period.DisciplineShortName),
+					"typeStr":             utils.EscapeMarkdownV2(period.TypeStr),
+					"lessonNumber":        utils.EscapeMarkdownV2(strconv.Itoa(lesson.Number)),
+					"classroom":           utils.EscapeMarkdownV2(period.Classroom),
+					"groups":              utils.EscapeMarkdownV2(period.Groups),
+					"lessonIcon":          utils.GetLessonIcon(period.Type),
+				})
+			}
+		}
+
+		pageText += "`—————————————————————————`"
+	}
+
+	dateButton := func(date time.Time) string {
+		return utils.NewButtonData("open.schedule.teacher").
+			SetInt("teacher", teacherId).
+			Set("date", date.Format(time.DateOnly)).
+			String()
+	}
+	buttons := gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: append(
+			createNavigationButtons(lang, dateButton,
+				date_.AddDate(0, 0, -1), date_.AddDate(0, 0, 1),
+				date_.AddDate(0, 0, -7), date_.AddDate(0, 0, 7),
+			),
+			[]gotgbot.InlineKeyboardButton{createBackButton(lang), {
+				Text:         lang.Button.Menu,
+				CallbackData: "open.menu",
+			}},
+		),
+	}
+
+	// Add today button if needed
+	today := utils.Now().In(loc)
+	if date != today.Format("2006-01-02") {
+		buttons.InlineKeyboard[len(buttons.InlineKeyboard)-1] = append(
+			buttons.InlineKeyboard[len(buttons.InlineKeyboard)-1],
+			gotgbot.InlineKeyboardButton{
+				Text:         lang.Button.ScheduleNavigationToday,
+				CallbackData: dateButton(today),
+			},
+		)
+	}
+
+	page := Page{
+		Text:                  pageText,
+		ReplyMarkup:           buttons,
+		ParseMode:             "MarkdownV2",
+		DisableWebPagePreview: true,
+	}
+
+	return page, nil
+}
+
+// mergeTeacherPeriods merges periods of the same class into one.
+//
+// Teacher can have a class with multiple groups at the same time,
+// and API returns a separate period for each group.
+func mergeTeacherPeriods(periods []api2.TimeTablePeriod) []api2.TimeTablePeriod {
+	merged := make([]api2.TimeTablePeriod, 0, len(periods))
+
+PERIODS_LOOP:
+	for _, period := range periods {
+		for i, period2 := range merged {
+			if period2.DisciplineId == period.DisciplineId && period2.Classroom == period.Classroom && period2.Type == period.Type {
+				if period2.Groups == "" {
+					merged[i].Groups = period.Groups
+				} else if period.Groups != "" && !strings.Contains(period2.Groups, period.Groups) {
+					merged[i].Groups += ", " + period.Groups
+				}
+				continue PERIODS_LOOP
+			}
+		}
+		merged = append(merged, period)
+	}
+
+	return merged
+}
+
+// getTeacherName returns teacher full name from the teacher schedule
+func getTeacherName(day *api2.TimeTableDate) string {
+	for _, lesson := range day.Lessons {
+		for _, period := range lesson.Periods {
+			if period.TeachersNameFull != "" {
+				return strings.Split(period.TeachersNameFull, ", ")[0]
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,218 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"context"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"github.com/sirkon/go-format/v2"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TeacherSearchPageSize is a number of teachers shown on one search results page
+const TeacherSearchPageSize = 8
+
+// MaxTeacherSearchResults is a max number of found teachers to show.
+// If more teachers are found, user is asked to refine the query.
+const MaxTeacherSearchResults = 30
+
+// apostropheReplacer replaces different apostrophe characters used
+// in Ukrainian names with the regular one, so "Мар'яна" matches "Мар’яна"
+var apostropheReplacer = strings.NewReplacer("’", "'", "ʼ", "'", "‘", "'", "`", "'")
+
+// CreateTeacherSearchPage creates a page with teachers whose name contains the query.
+//
+// pageNum is a number of the results page, starting from 0
+func CreateTeacherSearchPage(ctx context.Context, lang i18n.Language, chat *data.Chat, query string, pageNum int) (Page, error) {
+	teachers, err := SearchTeachers(ctx, query)
+	if err != nil {
+		return Page{}, err
+	}
+
+	if len(teachers) == 0 {
+		page := Page{
+			Text: format.Formatm(lang.Page.TeacherSearchEmpty, format.Values{
+				"query": utils.EscapeMarkdownV2(query),
+			}),
+			ReplyMarkup: createTeacherSearchFallbackMarkup(lang),
+			ParseMode:   "MarkdownV2",
+		}
+
+		return page, nil
+	}
+
+	if len(teachers) > MaxTeacherSearchResults {
+		page := Page{
+			Text: format.Formatm(lang.Page.TeacherSearchTooMany, format.Values{
+				"query": utils.EscapeMarkdownV2(query),
+				"count": len(teachers),
+			}),
+			ReplyMarkup: createTeacherSearchFallbackMarkup(lang),
+			ParseMode:   "MarkdownV2",
+		}
+
+		return page, nil
+	}
+
+	today := utils.NowFor(chat).Format(time.DateOnly)
+	btns := make([]gotgbot.InlineKeyboardButton, len(teachers))
+	for i, teacher := range teachers {
+		btns[i] = gotgbot.InlineKeyboardButton{
+			Text:         teacher.GetFullName(),
+			CallbackData: utils.NewButtonData("open.schedule.teacher").SetInt("teacher", teacher.Id).Set("date", today).String(),
+		}
+	}
+
+	buttons, err := paginate(lang, btns, pageNum, TeacherSearchPageSize, 1, utils.NewButtonData("search.teacher").SetInt("queryHash", utils.QueryHash(query)))
+	if err != nil {
+		return Page{}, err
+	}
+
+	buttons = append(buttons, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Menu,
+		CallbackData: utils.NewButtonData("open.menu").Set("from", "teacher_search").String(),
+	}})
+
+	page := Page{
+		Text: format.Formatm(lang.Page.TeacherSearch, format.Values{
+			"query": utils.EscapeMarkdownV2(query),
+			"count": len(teachers),
+		}),
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons},
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// CreateTeacherSearchQueryPage creates a page with the typed teacher search query.
+// Teachers are searched only when the search button is pressed,
+// so the query can be corrected without searching every time.
+func CreateTeacherSearchQueryPage(lang i18n.Language, query string) (Page, error) {
+	buttons := [][]gotgbot.InlineKeyboardButton{{{
+		Text:         lang.Button.TeacherSearch,
+		CallbackData: utils.NewButtonData("search.teacher").SetInt("queryHash", utils.QueryHash(query)).String(),
+	}}}
+
+	page := Page{
+		Text: format.Formatm(lang.Page.TeacherSearchQuery, format.Values{
+			"query": utils.EscapeMarkdownV2(query),
+		}),
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: append(buttons, createTeacherSearchFallbackMarkup(lang).InlineKeyboard...),
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+func CreateTeacherSearchUsagePage(lang i18n.Language) (Page, error) {
+	page := Page{
+		Text:        lang.Page.TeacherSearchUsage,
+		ReplyMarkup: createTeacherSearchFallbackMarkup(lang),
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// createTeacherSearchFallbackMarkup creates buttons to find the teacher
+// by department instead, if the search has failed
+func createTeacherSearchFallbackMarkup(lang i18n.Language) gotgbot.InlineKeyboardMarkup {
+	return gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{{
+			Text:         lang.Button.TeacherSchedule,
+			CallbackData: "open.select_teacher",
+		}}, {{
+			Text:         lang.Button.Menu,
+			CallbackData: utils.NewButtonData("open.menu").Set("from", "teacher_search").String(),
+		}}},
+	}
+}
+
+// SearchTeachers returns teachers of all the chairs whose full name
+// contains the query, sorted by name. Not case sensitive
+// and doesn't distinguish different apostrophe characters.
+//
+// API responses are cached, so only the first search is slow.
+func SearchTeachers(ctx context.Context, query string) ([]api2.Teacher, error) {
+	query = normalizeTeacherName(query)
+
+	structures, err := api.WithContext(ctx).GetStructures()
+	if err != nil {
+		return nil, err
+	}
+
+	// Teacher can work at multiple chairs
+	found := make(map[int]api2.Teacher)
+
+	for _, structure := range structures {
+		faculties, err := api.WithContext(ctx).GetFaculties(structure.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, faculty := range faculties {
+			chairs, err := api.WithContext(ctx).GetChairs(structure.Id, faculty.Id)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, chair := range chairs {
+				teachers, err := api.WithContext(ctx).GetChairTeachers(structure.Id, faculty.Id, chair.Id)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, teacher := range teachers {
+					if strings.Contains(normalizeTeacherName(teacher.GetFullName()), query) {
+						found[teacher.Id] = teacher
+					}
+				}
+			}
+		}
+	}
+
+	result := make([]api2.Teacher, 0, len(found))
+	for _, teacher := range found {
+		result = append(result, teacher)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GetFullName() < result[j].GetFullName()
+	})
+
+	return result, nil
+}
+
+// normalizeTeacherName prepares the teacher name for comparison
+func normalizeTeacherName(name string) string {
+	return apostropheReplacer.Replace(strings.ToLower(strings.TrimSpace(name)))
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"context"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"time"
+)
+
+func CreateTeacherStructuresListPage(ctx context.Context, lang i18n.Language) (Page, error) {
+	structures, err := api.WithContext(ctx).GetStructures()
+	if err != nil {
+		return Page{}, err
+	}
+
+	buttons := make([][]gotgbot.InlineKeyboardButton, len(structures)+1)
+	buttons[0] = []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Back,
+		CallbackData: utils.NewButtonData("open.more").Set("from", "teacher_select").String(),
+	}}
+
+	for i, structure := range structures {
+		buttons[i+1] = []gotgbot.InlineKeyboardButton{{
+			Text:         structure.FullName,
+			CallbackData: utils.NewButtonData("select.teacher_structure").SetInt("structureId", structure.Id).String(),
+		}}
+	}
+
+	page := Page{
+		Text:        lang.Page.StructureSelection,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons},
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+func CreateTeacherFacultiesListPage(ctx context.Context, lang i18n.Language, structureId int) (Page, error) {
+	faculties, err := api.WithContext(ctx).GetFaculties(structureId)
+	if err != nil {
+		return Page{}, err
+	}
+
+	structures, err := api.WithContext(ctx).GetStructures()
+	if err != nil {
+		return Page{}, err
+	}
+
+	// Create back button: if structures list <= 1, go back to "more" page, else go back to structures list
+	var backButton gotgbot.InlineKeyboardButton
+	if len(structures) <= 1 {
+		backButton = gotgbot.InlineKeyboardButton{
+			Text:         lang.Button.Back,
+			CallbackData: utils.NewButtonData("open.more").Set("from", "teacher_select").String(),
+		}
+	} else {
+		backButton = gotgbot.InlineKeyboardButton{
+			Text:         lang.Button.Back,
+			CallbackData: "open.select_teacher",
+		}
+	}
+
+	buttons := make([][]gotgbot.InlineKeyboardButton, len(faculties)+1)
+	buttons[0] = []gotgbot.InlineKeyboardButton{backButton}
+
+	for i, faculty := range faculties {
+		query := utils.NewButtonData("select.teacher_faculty").SetInt("facultyId", faculty.Id).SetInt("structureId", structureId).String()
+		buttons[i+1] = []gotgbot.InlineKeyboardButton{{
+			Text:         faculty.FullName,
+			CallbackData: query,
+		}}
+	}
+
+	page := Page{
+		Text:        lang.Page.FacultySelection,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons},
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+func CreateChairsListPage(ctx context.Context, lang i18n.Language, structureId int, facultyId int) (Page, error) {
+	chairs, err := api.WithContext(ctx).GetChairs(structureId, facultyId)
+	if err != nil {
+		return Page{}, err
+	}
+
+	buttons := make([][]gotgbot.InlineKeyboardButton, len(chairs)+1)
+	buttons[0] = []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Back,
+		CallbackData: utils.NewButtonData("select.teacher_structure").SetInt("structureId", structureId).String(),
+	}}
+
+	for i, chair := range chairs {
+		query := utils.NewButtonData("select.teacher_chair").
+			SetInt("chairId", chair.Id).
+			SetInt("facultyId", facultyId).
+			SetInt("structureId", structureId).
+			String()
+		buttons[i+1] = []gotgbot.InlineKeyboardButton{{
+			Text:         chair.FullName,
+			CallbackData: query,
+		}}
+	}
+
+	page := Page{
+		Text:        lang.Page.ChairSelection,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons},
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// TeachersListPageSize is a number of teachers shown on one chair teachers page
+const TeachersListPageSize = 10
+
+// CreateTeachersListPage creates a page with the chair teachers, pageNum starts from 0
+func CreateTeachersListPage(ctx context.Context, lang i18n.Language, chat *data.Chat, structureId int, facultyId int, chairId int, pageNum int) (Page, error) {
+	teachers, err := api.WithContext(ctx).GetChairTeachers(structureId, facultyId, chairId)
+	if err != nil {
+		return Page{}, err
+	}
+
+	today := utils.NowFor(chat).Format(time.DateOnly)
+	btns := make([]gotgbot.InlineKeyboardButton, len(teachers))
+	for i, teacher := range teachers {
+		btns[i] = gotgbot.InlineKeyboardButton{
+			Text:         teacher.GetFullName(),
+			CallbackData: utils.NewButtonData("open.schedule.teacher").SetInt("teacher", teacher.Id).Set("date", today).String(),
+		}
+	}
+
+	nav := utils.NewButtonData("select.teacher_chair").
+		SetInt("chairId", chairId).
+		SetInt("facultyId", facultyId).
+		SetInt("structureId", structureId)
+	rows, err := paginate(lang, btns, pageNum, TeachersListPageSize, 1, nav)
+	if err != nil {
+		return Page{}, err
+	}
+
+	buttons := append([][]gotgbot.InlineKeyboardButton{{{
+		Text:         lang.Button.Back,
+		CallbackData: utils.NewButtonData("select.teacher_faculty").SetInt("facultyId", facultyId).SetInt("structureId", structureId).String(),
+	}}}, rows...)
+
+	page := Page{
+		Text:        lang.Page.TeacherSelection,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: buttons},
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
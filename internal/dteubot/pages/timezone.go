@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"github.com/sirkon/go-format/v2"
+	"strings"
+)
+
+// Timezones is a list of timezones available in the settings.
+// The first one is the university timezone.
+var Timezones = []string{
+	api2.Location,
+	"Europe/Warsaw",
+	"Europe/Berlin",
+	"Europe/Prague",
+	"Europe/London",
+	"Europe/Lisbon",
+	"Europe/Istanbul",
+	"America/New_York",
+	"America/Toronto",
+}
+
+// CreateTimezoneSelectionPage creates a page to select the chat timezone
+func CreateTimezoneSelectionPage(lang i18n.Language, chat *data.Chat) (Page, error) {
+	current := chat.Timezone
+	if current == "" {
+		current = api2.Location
+	}
+
+	buttons := make([]gotgbot.InlineKeyboardButton, 0, len(Timezones))
+	for _, timezone := range Timezones {
+		text := getTimezoneName(timezone)
+		if timezone == current {
+			text = "• " + text + " •"
+		}
+		buttons = append(buttons, gotgbot.InlineKeyboardButton{
+			Text:         text,
+			CallbackData: utils.NewButtonData("set.timezone").Set("timezone", timezone).String(),
+		})
+	}
+
+	keyboard := utils.SplitRows(buttons, 3)
+	keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.OtherTimezone,
+		CallbackData: "open.timezone_input",
+	}}, []gotgbot.InlineKeyboardButton{{
+		Text:         lang.Button.Back,
+		CallbackData: "open.settings",
+	}})
+
+	page := Page{
+		Text: format.Formatm(lang.Page.Timezone, format.Values{
+			"timezone": utils.EscapeMarkdownV2(current),
+		}),
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+		ParseMode:   "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// CreateTimezonePromptPage creates a page asking to send the timezone name,
+// for the timezones that are not in the Timezones list
+func CreateTimezonePromptPage(lang i18n.Language) (Page, error) {
+	return createTimezoneInputPage(lang.Page.TimezonePrompt, lang)
+}
+
+// CreateTimezoneInvalidPage creates a page reporting that the timezone
+// sent by the user is unknown, and asking to send it again
+func CreateTimezoneInvalidPage(lang i18n.Language) (Page, error) {
+	return createTimezoneInputPage(lang.Page.TimezoneInvalid, lang)
+}
+
+// createTimezoneInputPage creates a timezone input page with the given text
+func createTimezoneInputPage(text string, lang i18n.Language) (Page, error) {
+	page := Page{
+		Text: text,
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{{
+				Text:         lang.Button.Back,
+				CallbackData: "open.timezone",
+			}}},
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// getTimezoneName returns the city name of the timezone, e.g. "New York" for "America/New_York"
+func getTimezoneName(timezone string) string {
+	name := timezone[strings.LastIndex(timezone, "/")+1:]
+	return strings.ReplaceAll(name, "_", " ")
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pages
+
+import (
+	"bytes"
+	"encoding/csv"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/sirkon/go-format/v2"
+	"sort"
+	"strconv"
+)
+
+// UsageStatsTopGroups is the number of the most viewed groups shown on the usage stats page
+const UsageStatsTopGroups = 10
+
+// UsageStatsTopGroupsDays is the number of days the most viewed groups are counted for
+const UsageStatsTopGroupsDays = 30
+
+// CreateUsageStatsPage creates a page with the bot usage for the admins:
+// today, the last 7 and 30 days, and the most viewed groups
+func CreateUsageStatsPage(lang i18n.Language, stats *data.UsageStats) (Page, error) {
+	periods := []struct {
+		name string
+		days int
+	}{
+		{lang.Text.UsageStatsToday, 1},
+		{lang.Text.UsageStatsWeek, 7},
+		{lang.Text.UsageStatsMonth, 30},
+	}
+
+	periodsText := ""
+	for _, period := range periods {
+		summary, err := stats.Summary(period.days)
+		if err != nil {
+			return Page{}, err
+		}
+		periodsText += format.Formatm(lang.Text.UsageStatsPeriod, format.Values{
+			"name":     period.name,
+			"chats":    summary.ActiveChats,
+			"commands": summary.Commands,
+			"buttons":  summary.Buttons,
+			"errors":   summary.ApiErrors,
+		}) + "\n"
+	}
+
+	groupsSummary, err := stats.Summary(UsageStatsTopGroupsDays)
+	if err != nil {
+		return Page{}, err
+	}
+
+	groupsText := ""
+	for i, group := range getTopGroups(groupsSummary.Groups, UsageStatsTopGroups) {
+		groupsText += format.Formatm("`$n.` $name — $views\n", format.Values{
+			"n":     i + 1,
+			"name":  utils.EscapeMarkdownV2(getSavedGroupName(data.GroupRef{Id: group.id})),
+			"views": group.views,
+		})
+	}
+	if groupsText == "" {
+		groupsText = lang.Text.UsageStatsNoGroups
+	}
+
+	page := Page{
+		Text: format.Formatm(lang.Page.UsageStats, format.Values{
+			"periods": periodsText,
+			"days":    UsageStatsTopGroupsDays,
+			"groups":  groupsText,
+		}),
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
+			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{{
+				Text:         lang.Button.UsageStatsCsv,
+				CallbackData: "admin.stats_csv",
+			}}},
+		},
+		ParseMode: "MarkdownV2",
+	}
+
+	return page, nil
+}
+
+// CreateUsageStatsCSV creates a CSV file with all the kept daily usage stats,
+// a row for each counter: date, counter name, key (like the command name) and value
+func CreateUsageStatsCSV(stats *data.UsageStats) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write([]string{"date", "counter", "key", "value"}); err != nil {
+		return nil, err
+	}
+
+	counters, err := stats.Counters(0)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, counter := range counters {
+		row := []string{counter.Date, counter.Kind, counter.Key, strconv.Itoa(counter.Count)}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+type groupViews struct {
+	id    int
+	views int
+}
+
+// getTopGroups returns up to n groups with the most views, the most viewed first
+func getTopGroups(groups map[int]int, n int) []groupViews {
+	top := make([]groupViews, 0, len(groups))
+	for id, views := range groups {
+		top = append(top, groupViews{id: id, views: views})
+	}
+
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].views != top[j].views {
+			return top[i].views > top[j].views
+		}
+		return top[i].id < top[j].id
+	})
+
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Limiter is a token bucket rate limiter with a separate bucket for every key.
+//
+// Each bucket holds up to Burst tokens and is refilled at Rate tokens per second.
+// Every request takes one token, requests are rejected when the bucket is empty.
+//
+// Should be created via NewLimiter.
+type Limiter struct {
+	// Burst is the maximum number of requests that can be made at once
+	Burst int
+	// Rate is the number of tokens added to the bucket per second
+	Rate float64
+
+	mu        sync.Mutex
+	buckets   map[int64]*bucket
+	lastSweep time.Time
+	throttled atomic.Uint64
+}
+
+type bucket struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+// NewLimiter creates a new instance of Limiter.
+func NewLimiter(burst int, rate float64) *Limiter {
+	return &Limiter{
+		Burst:     burst,
+		Rate:      rate,
+		buckets:   make(map[int64]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes a token from the bucket of the key.
+// Returns false if there are no tokens left.
+func (l *Limiter) Allow(key int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{Tokens: float64(l.Burst), Updated: now}
+		l.buckets[key] = b
+	} else {
+		b.Tokens = l.refill(b, now)
+		b.Updated = now
+	}
+
+	if b.Tokens < 1 {
+		l.throttled.Add(1)
+		return false
+	}
+
+	b.Tokens--
+	return true
+}
+
+// Throttled returns the number of requests rejected since the limiter was created.
+func (l *Limiter) Throttled() uint64 {
+	return l.throttled.Load()
+}
+
+// refill returns the number of tokens in the bucket at the given time
+func (l *Limiter) refill(b *bucket, now time.Time) float64 {
+	tokens := b.Tokens + now.Sub(b.Updated).Seconds()*l.Rate
+	if tokens > float64(l.Burst) {
+		tokens = float64(l.Burst)
+	}
+	return tokens
+}
+
+// sweep removes the full buckets, they are the same as the new ones.
+// This way the keys that are idle for a while do not take up memory.
+// Runs at most once a minute.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if l.refill(b, now) >= float64(l.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package rooms
+
+import (
+	"github.com/cubicbyte/dteubot/internal/dteubot/lifecycle"
+	"sync"
+	"time"
+)
+
+// CacheTTL is how long the collected classrooms usage is used before it is collected again
+const CacheTTL = 30 * time.Minute
+
+// Cache keeps the classrooms usage collected from the source.
+//
+// Collecting the usage takes a lot of API requests, so it is done in the
+// background and the callers don't wait for it. The usage of all the
+// classrooms is collected for the whole period, so it is shared by all
+// the buildings, dates and lessons of the period.
+//
+// Should be created via NewCache.
+type Cache struct {
+	Source Source
+	TTL    time.Duration
+
+	mu      sync.Mutex
+	entries map[cacheKey]*cacheEntry
+}
+
+type cacheKey struct {
+	DateStart string
+	DateEnd   string
+}
+
+type cacheEntry struct {
+	Usage      *Usage
+	Err        error
+	Updated    time.Time
+	Collecting bool
+}
+
+// NewCache creates a new instance of Cache.
+func NewCache(source Source, ttl time.Duration) *Cache {
+	return &Cache{
+		Source:  source,
+		TTL:     ttl,
+		entries: make(map[cacheKey]*cacheEntry),
+	}
+}
+
+// Get returns the classrooms usage from dateStart to dateEnd (inclusive).
+//
+// If the usage is not collected yet or is outdated, starts collecting it in
+// the background. Until the first collection is completed, nil is returned,
+// so the caller should ask to try again later. Outdated usage is
+// returned while it is being collected again.
+//
+// If the collection has failed, its error is returned once,
+// and the next call starts collecting again.
+func (c *Cache) Get(dateStart string, dateEnd string) (*Usage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := cacheKey{dateStart, dateEnd}
+	entry := c.getEntry(key)
+
+	if entry.Err != nil {
+		err := entry.Err
+		entry.Err = nil
+		return entry.Usage, err
+	}
+
+	if !entry.Collecting && time.Since(entry.Updated) > c.TTL {
+		if err := c.collect(key, entry); err != nil {
+			return entry.Usage, err
+		}
+	}
+
+	return entry.Usage, nil
+}
+
+// Preload starts collecting the usage from dateStart to dateEnd (inclusive)
+// in the background if it is not collected yet or is outdated,
+// so it's ready when the users request it.
+func (c *Cache) Preload(dateStart string, dateEnd string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := cacheKey{dateStart, dateEnd}
+	entry := c.getEntry(key)
+	if entry.Collecting || time.Since(entry.Updated) <= c.TTL {
+		return nil
+	}
+
+	return c.collect(key, entry)
+}
+
+// getEntry returns the entry of the key, creating it if needed.
+// Must be called with c.mu locked.
+func (c *Cache) getEntry(key cacheKey) *cacheEntry {
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &cacheEntry{}
+		c.entries[key] = entry
+	}
+	return entry
+}
+
+// collect starts collecting the usage of the entry in the background.
+// Must be called with c.mu locked.
+func (c *Cache) collect(key cacheKey, entry *cacheEntry) error {
+	entry.Collecting = true
+
+	err := lifecycle.Go(func() {
+		log.Infof("Collecting classrooms usage from %s to %s", key.DateStart, key.DateEnd)
+		start := time.Now()
+		usage, err := GetUsage(c.Source, "", key.DateStart, key.DateEnd)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		entry.Collecting = false
+		if err != nil {
+			log.Warningf("Error collecting classrooms usage from %s to %s: %s", key.DateStart, key.DateEnd, err)
+			entry.Err = err
+			return
+		}
+
+		log.Infof("Collected classrooms usage from %s to %s in %s", key.DateStart, key.DateEnd, time.Since(start))
+		entry.Usage = usage
+		entry.Updated = time.Now()
+		c.removeExpired()
+	})
+	if err != nil {
+		entry.Collecting = false
+	}
+	return err
+}
+
+// removeExpired removes the entries that are not used for a while,
+// like the ones of the past weeks. Must be called with c.mu locked.
+func (c *Cache) removeExpired() {
+	for key, entry := range c.entries {
+		if !entry.Collecting && time.Since(entry.Updated) > 2*c.TTL {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,435 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package rooms
+
+import (
+	"github.com/cubicbyte/dteubot/pkg/api"
+	"github.com/op/go-logging"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var log = logging.MustGetLogger("rooms")
+
+// Workers is a number of schedules requested at the same time
+const Workers = 8
+
+// Booking is a classroom occupied at the lesson
+type Booking struct {
+	Room   string
+	Date   string
+	Lesson int
+	// Groups are the names of the groups at the lesson, separated by comma
+	Groups string
+	// Subject is the short name of the discipline
+	Subject string
+}
+
+// Source lists the classroom bookings from the university schedules
+type Source interface {
+	// GetBookings returns the bookings of the building
+	// classrooms from dateStart to dateEnd (inclusive)
+	GetBookings(building string, dateStart string, dateEnd string) ([]Booking, error)
+}
+
+// GroupsSource lists the bookings from the schedules of all the groups.
+//
+// Groups whose schedule can't be received are skipped. Error
+// is returned only if no schedules were received at all.
+type GroupsSource struct {
+	Api api.Api
+}
+
+func (s GroupsSource) GetBookings(building string, dateStart string, dateEnd string) ([]Booking, error) {
+	groups, err := GetAllGroups(s.Api)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectBookings(groups, building, func(groupId int) (api.Schedule, error) {
+		return s.Api.GetGroupSchedule(groupId, dateStart, dateEnd)
+	})
+}
+
+// TeachersSource lists the bookings from the schedules of all the teachers.
+// It finds the classrooms of the lessons missing from the group schedules,
+// like consultations.
+//
+// Teachers whose schedule can't be received are skipped. Error
+// is returned only if no schedules were received at all.
+type TeachersSource struct {
+	Api api.Api
+}
+
+func (s TeachersSource) GetBookings(building string, dateStart string, dateEnd string) ([]Booking, error) {
+	teachers, err := GetAllTeachers(s.Api)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectBookings(teachers, building, func(teacherId int) (api.Schedule, error) {
+		return s.Api.GetTeacherSchedule(teacherId, dateStart, dateEnd)
+	})
+}
+
+// Sources combines the bookings of multiple sources.
+//
+// Sources that fail are skipped. Error is returned only if all of them fail.
+type Sources []Source
+
+func (s Sources) GetBookings(building string, dateStart string, dateEnd string) ([]Booking, error) {
+	bookings := make([]Booking, 0)
+	var lastErr error
+	received := 0
+
+	for _, source := range s {
+		sourceBookings, err := source.GetBookings(building, dateStart, dateEnd)
+		if err != nil {
+			log.Warningf("Error getting %T bookings: %s", source, err)
+			lastErr = err
+			continue
+		}
+
+		bookings = append(bookings, sourceBookings...)
+		received++
+	}
+
+	if received == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	return bookings, nil
+}
+
+// Usage contains classrooms booked in the university schedules
+type Usage struct {
+	// Rooms is a set of all the known classrooms
+	Rooms map[string]bool
+	// Occupied contains the bookings of occupied classrooms by date, lesson number and classroom
+	Occupied map[string]map[int]map[string][]Booking
+}
+
+// RoomUsage is a classroom occupied at the lesson with the groups studying in it
+type RoomUsage struct {
+	Room     string
+	Groups   []string
+	Subjects []string
+}
+
+// GetUsage collects the building classrooms usage
+// from the source from dateStart to dateEnd (inclusive).
+// Empty building means all the classrooms.
+func GetUsage(source Source, building string, dateStart string, dateEnd string) (*Usage, error) {
+	bookings, err := source.GetBookings(building, dateStart, dateEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &Usage{
+		Rooms:    make(map[string]bool),
+		Occupied: make(map[string]map[int]map[string][]Booking),
+	}
+
+	for _, booking := range bookings {
+		usage.add(booking)
+	}
+
+	return usage, nil
+}
+
+// collectBookings requests the schedules by ids and returns
+// the bookings of the building classrooms from them
+func collectBookings(ids []int, building string, getSchedule func(id int) (api.Schedule, error)) ([]Booking, error) {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		received int
+		lastErr  error
+		bookings = make([]Booking, 0)
+	)
+
+	jobs := make(chan int)
+	for i := 0; i < Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				schedule, err := getSchedule(id)
+
+				mu.Lock()
+				if err != nil {
+					log.Warningf("Error getting schedule %d: %s", id, err)
+					lastErr = err
+				} else {
+					bookings = append(bookings, getBookings(schedule, building)...)
+					received++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, id := range ids {
+		jobs <- id
+	}
+	close(jobs)
+	wg.Wait()
+
+	if received == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	return bookings, nil
+}
+
+// getBookings returns the bookings of the building classrooms from the schedule
+func getBookings(schedule api.Schedule, building string) []Booking {
+	bookings := make([]Booking, 0)
+	for _, day := range schedule {
+		for _, lesson := range day.Lessons {
+			for _, period := range lesson.Periods {
+				room := strings.TrimSpace(period.Classroom)
+				if room == "" || !InBuilding(room, building) {
+					continue
+				}
+
+				bookings = append(bookings, Booking{
+					Room:    room,
+					Date:    day.Date,
+					Lesson:  lesson.Number,
+					Groups:  strings.TrimSpace(period.Groups),
+					Subject: strings.TrimSpace(period.DisciplineShortName),
+				})
+			}
+		}
+	}
+	return bookings
+}
+
+// GetAllGroups returns ids of all the groups of the university
+func GetAllGroups(api2 api.Api) ([]int, error) {
+	structures, err := api2.GetStructures()
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]int, 0)
+	for _, structure := range structures {
+		faculties, err := api2.GetFaculties(structure.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, faculty := range faculties {
+			courses, err := api2.GetCourses(faculty.Id)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, course := range courses {
+				courseGroups, err := api2.GetGroups(faculty.Id, course.Course)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, group := range courseGroups {
+					groups = append(groups, group.Id)
+				}
+			}
+		}
+	}
+
+	return groups, nil
+}
+
+// GetAllTeachers returns ids of all the teachers of the university.
+//
+// Teachers of the chairs are requested by Workers at the same time. Chairs
+// whose teachers can't be received are skipped. Error is returned only
+// if no chair teachers were received at all.
+func GetAllTeachers(api2 api.Api) ([]int, error) {
+	structures, err := api2.GetStructures()
+	if err != nil {
+		return nil, err
+	}
+
+	chairs := make([]chairRef, 0)
+	for _, structure := range structures {
+		faculties, err := api2.GetFaculties(structure.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, faculty := range faculties {
+			facultyChairs, err := api2.GetChairs(structure.Id, faculty.Id)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, chair := range facultyChairs {
+				chairs = append(chairs, chairRef{structure.Id, faculty.Id, chair.Id})
+			}
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		received int
+		lastErr  error
+		// Teacher can work at multiple chairs
+		seen     = make(map[int]bool)
+		teachers = make([]int, 0)
+	)
+
+	jobs := make(chan chairRef)
+	for i := 0; i < Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chair := range jobs {
+				chairTeachers, err := api2.GetChairTeachers(chair.StructureId, chair.FacultyId, chair.Id)
+
+				mu.Lock()
+				if err != nil {
+					log.Warningf("Error getting chair %d teachers: %s", chair.Id, err)
+					lastErr = err
+				} else {
+					for _, teacher := range chairTeachers {
+						if !seen[teacher.Id] {
+							seen[teacher.Id] = true
+							teachers = append(teachers, teacher.Id)
+						}
+					}
+					received++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, chair := range chairs {
+		jobs <- chair
+	}
+	close(jobs)
+	wg.Wait()
+
+	if received == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	return teachers, nil
+}
+
+// chairRef identifies the chair to request its teachers
+type chairRef struct {
+	StructureId int
+	FacultyId   int
+	Id          int
+}
+
+// InBuilding checks if the classroom belongs to the building.
+//
+// Classroom belongs to the building if its name starts with the building name.
+func InBuilding(room string, building string) bool {
+	return strings.HasPrefix(strings.ToLower(room), strings.ToLower(building))
+}
+
+// add adds the booked classroom to the usage.
+// The same lesson can be found in the schedules of multiple groups
+// and the teacher, so duplicate bookings are skipped.
+func (u *Usage) add(booking Booking) {
+	u.Rooms[booking.Room] = true
+
+	if _, ok := u.Occupied[booking.Date]; !ok {
+		u.Occupied[booking.Date] = make(map[int]map[string][]Booking)
+	}
+	if _, ok := u.Occupied[booking.Date][booking.Lesson]; !ok {
+		u.Occupied[booking.Date][booking.Lesson] = make(map[string][]Booking)
+	}
+
+	lessonRooms := u.Occupied[booking.Date][booking.Lesson]
+	if !slices.Contains(lessonRooms[booking.Room], booking) {
+		lessonRooms[booking.Room] = append(lessonRooms[booking.Room], booking)
+	}
+}
+
+// GetBuildingRooms returns sorted classrooms of the building
+func (u *Usage) GetBuildingRooms(building string) []string {
+	rooms := make([]string, 0)
+	for room := range u.Rooms {
+		if InBuilding(room, building) {
+			rooms = append(rooms, room)
+		}
+	}
+
+	sort.Strings(rooms)
+	return rooms
+}
+
+// GetFreeRooms returns sorted classrooms of the building
+// that are not occupied on the given date and lesson number
+func (u *Usage) GetFreeRooms(building string, date string, lessonNumber int) []string {
+	occupied := u.Occupied[date][lessonNumber]
+
+	free := make([]string, 0)
+	for _, room := range u.GetBuildingRooms(building) {
+		if len(occupied[room]) == 0 {
+			free = append(free, room)
+		}
+	}
+
+	return free
+}
+
+// GetOccupiedRooms returns sorted classrooms of the building that are
+// occupied on the given date and lesson number, with the groups and subjects
+func (u *Usage) GetOccupiedRooms(building string, date string, lessonNumber int) []RoomUsage {
+	occupied := u.Occupied[date][lessonNumber]
+
+	result := make([]RoomUsage, 0)
+	for _, room := range u.GetBuildingRooms(building) {
+		if len(occupied[room]) == 0 {
+			continue
+		}
+
+		usage := RoomUsage{Room: room}
+		for _, booking := range occupied[room] {
+			for _, group := range strings.Split(booking.Groups, ",") {
+				if group = strings.TrimSpace(group); group != "" && !slices.Contains(usage.Groups, group) {
+					usage.Groups = append(usage.Groups, group)
+				}
+			}
+			if booking.Subject != "" && !slices.Contains(usage.Subjects, booking.Subject) {
+				usage.Subjects = append(usage.Subjects, booking.Subject)
+			}
+		}
+
+		sort.Strings(usage.Groups)
+		result = append(result, usage)
+	}
+
+	return result
+}
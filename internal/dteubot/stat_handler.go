@@ -25,20 +25,48 @@ package dteubot
 import (
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/errorhandler"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"slices"
+	"strconv"
+	"strings"
 )
 
+// scheduleCommands are the commands that show the group schedule
+var scheduleCommands = []string{"today", "t", "tomorrow", "tt"}
+
+// knownCommands are the commands the bot handles. Other ones are counted
+// as "unknown", so the usage stats are not flooded with random text
+var knownCommands []string
+
 // CommandStatisticHandler saves command to statistics.
 func CommandStatisticHandler(_ *gotgbot.Bot, ctx *ext.Context) error {
+	command := getCommandName(ctx.EffectiveMessage)
+	if !slices.Contains(knownCommands, command) {
+		command = "unknown"
+	}
+	usageStats.CountCommand(ctx.EffectiveChat.Id, command)
+	if slices.Contains(scheduleCommands, command) {
+		countGroupView(ctx, nil)
+	}
+
 	return statLogger.LogCommand(
 		ctx.EffectiveChat.Id,
 		ctx.EffectiveUser.Id,
 		int(ctx.EffectiveMessage.MessageId),
-		ctx.EffectiveMessage.Text,
+		utils.ScrubCommandText(ctx.EffectiveMessage.Text),
 	)
 }
 
 // ButtonStatisticHandler saves button click to statistics.
 func ButtonStatisticHandler(_ *gotgbot.Bot, ctx *ext.Context) error {
+	action := utils.ButtonAction(ctx.CallbackQuery.Data)
+	usageStats.CountButton(ctx.EffectiveChat.Id, action)
+	if strings.HasPrefix(action, "open.schedule.") && action != "open.schedule.teacher" {
+		button, _ := utils.UnmarshalButtonData(ctx.CallbackQuery.Data)
+		countGroupView(ctx, button)
+	}
+
 	return statLogger.LogButtonClick(
 		ctx.EffectiveChat.Id,
 		ctx.EffectiveUser.Id,
@@ -46,3 +74,42 @@ func ButtonStatisticHandler(_ *gotgbot.Bot, ctx *ext.Context) error {
 		ctx.CallbackQuery.Data,
 	)
 }
+
+// FlushUsageStats saves the counted usage stats to the storage,
+// so they are not lost if the bot is killed
+func FlushUsageStats() {
+	if err := usageStats.Flush(); err != nil {
+		log.Errorf("Error saving usage stats: %s", err)
+		errorhandler.SendErrorToTelegram(err, bot)
+	}
+}
+
+// countGroupView counts the view of the group schedule shown by the update:
+// the group from the button, if it's set, or the group the handler loaded
+// the settings with. Nothing is counted if the handler failed before that.
+func countGroupView(ctx *ext.Context, button *utils.ButtonData) {
+	if button != nil {
+		if groupId, err := strconv.Atoi(button.Params["groupId"]); err == nil {
+			usageStats.CountGroupView(groupId)
+			return
+		}
+	}
+
+	if settings, ok := utils.UpdateSettings(ctx); ok && settings.GroupId != -1 {
+		usageStats.CountGroupView(settings.GroupId)
+	}
+}
+
+// getCommandName returns the command name without the slash
+// and the bot username, like "today" for "/today@bot". Commands
+// can also be sent in the file caption, like /restore
+func getCommandName(message *gotgbot.Message) string {
+	text := message.Text
+	if text == "" {
+		text = message.Caption
+	}
+
+	command, _, _ := strings.Cut(strings.TrimPrefix(text, "/"), " ")
+	command, _, _ = strings.Cut(command, "@")
+	return strings.ToLower(strings.TrimSpace(command))
+}
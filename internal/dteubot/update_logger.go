@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package dteubot
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/dteubot/errorhandler"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"time"
+)
+
+// LogUpdate logs the incoming update with the request logger, see
+// middleware.RequestLogger. Must be called before the update is passed to the handlers.
+func LogUpdate(b *gotgbot.Bot, ctx *ext.Context) error {
+	switch {
+	case ctx.CallbackQuery != nil:
+		utils.Logger(ctx).Info("Handling button", "data", ctx.CallbackQuery.Data)
+	case ctx.InlineQuery != nil:
+		utils.Logger(ctx).Info("Handling inline query", "query", ctx.InlineQuery.Query)
+	case ctx.EffectiveMessage != nil:
+		text := ctx.EffectiveMessage.Text
+		if text == "" {
+			text = ctx.EffectiveMessage.Caption
+		}
+		utils.Logger(ctx).Info("Handling command", "text", utils.ScrubCommandText(text))
+	}
+
+	return nil
+}
+
+// logHandler wraps the update handler to log its entry and exit.
+// Errors returned by the handler are wrapped in errorhandler.HandlerError
+// and logged by the dispatcher error handler.
+func logHandler(name string, handler func(*gotgbot.Bot, *ext.Context) error) func(*gotgbot.Bot, *ext.Context) error {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		logger := utils.Logger(ctx).With("handler", name)
+		logger.Debug("Handler started")
+
+		start := time.Now()
+		err := handler(b, ctx)
+
+		duration := time.Since(start)
+
+		if err != nil {
+			logger.Debug("Handler failed", "duration", duration)
+			return &errorhandler.HandlerError{Handler: name, Duration: duration, Err: err}
+		}
+
+		logger.Debug("Handler completed", "duration", duration)
+		return nil
+	}
+}
@@ -22,41 +22,407 @@
 
 package utils
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ButtonDataVersion is the current version of the button data encoding.
+//
+// Version 0 (legacy) is "action#key=value&key2=value2" with raw values.
+// Version 1 is "action#1k=v&k2=v2" with short keys, escaped
+// string values and ints and dates packed into base64 digits.
+const ButtonDataVersion = 1
+
+// MaxButtonDataLength is the Telegram callback data length limit in bytes
+const MaxButtonDataLength = 64
+
+// ErrInvalidButtonData is returned when the button data can't be parsed
+// or doesn't contain the required params. Usually it means that the button
+// was created by the older version of the bot.
+var ErrInvalidButtonData = errors.New("invalid button data")
+
+// ErrButtonDataTooLong is returned when the encoded
+// button data exceeds MaxButtonDataLength
+var ErrButtonDataTooLong = errors.New("button data is too long")
+
+// ErrAmbiguousButtonParam is returned when the unknown button param
+// is named like the short key of the known one, so it can't be decoded
+var ErrAmbiguousButtonParam = errors.New("ambiguous button param")
+
+// InvalidButtonAction is returned by ButtonAction for invalid button data
+const InvalidButtonAction = "invalid"
+
+// base64Digits is the alphabet of packed ints, url-safe base64
+const base64Digits = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// rawValuePrefix marks int and date values that can't be packed
+const rawValuePrefix = "~"
+
+type paramKind int
+
+const (
+	stringParam paramKind = iota
+	intParam
+	dateParam
+)
+
+// buttonParam is a known button param with its short key used in the encoding.
+// Short keys must be unique, otherwise the params can't be decoded
+type buttonParam struct {
+	Short string
+	Kind  paramKind
+}
+
+var buttonParams = map[string]buttonParam{
+	"back":        {"b", stringParam},
+	"building":    {"bd", intParam},
+	"chairId":     {"c", intParam},
+	"class":       {"cl", intParam},
+	"course":      {"co", intParam},
+	"date":        {"d", dateParam},
+	"direction":   {"dr", stringParam},
+	"facultyId":   {"f", intParam},
+	"from":        {"fr", stringParam},
+	"groupId":     {"g", intParam},
+	"kind":        {"k", stringParam},
+	"lang":        {"l", stringParam},
+	"lesson":      {"ls", intParam},
+	"lessonId":    {"li", stringParam},
+	"month":       {"m", stringParam},
+	"offset":      {"o", intParam},
+	"page":        {"p", stringParam},
+	"queryHash":   {"q", intParam},
+	"refresh":     {"r", stringParam},
+	"rnd":         {"rn", intParam},
+	"save":        {"sv", stringParam},
+	"state":       {"s", stringParam},
+	"structureId": {"st", intParam},
+	"suggestion":  {"sg", stringParam},
+	"teacher":     {"t", intParam},
+	"time":        {"tm", stringParam},
+	"timezone":    {"z", stringParam},
+}
+
+// buttonActions are the known button actions, see RegisterButtonActions
+var buttonActions map[string]bool
+
+// buttonParamsByShort maps the short keys back to the param names
+var buttonParamsByShort = func() map[string]string {
+	m := make(map[string]string, len(buttonParams))
+	for name, param := range buttonParams {
+		m[param.Short] = name
+	}
+	return m
+}()
 
 type ButtonData struct {
 	Action string
 	Params map[string]string
+	// Version is the encoding version the data was parsed from
+	Version int
+	// keys keeps the params order for the encoding
+	keys []string
+}
+
+// RegisterButtonActions sets the known button actions. The button data with
+// other actions is invalid. Must be called before the buttons are handled.
+func RegisterButtonActions(actions ...string) {
+	buttonActions = make(map[string]bool, len(actions))
+	for _, action := range actions {
+		buttonActions[action] = true
+	}
+}
+
+// NewButtonData creates a button data with the given action.
+//
+// Example: NewButtonData("open.schedule.day").Set("date", "2023-10-04").String()
+func NewButtonData(action string) *ButtonData {
+	return &ButtonData{
+		Action:  action,
+		Params:  make(map[string]string),
+		Version: ButtonDataVersion,
+	}
+}
+
+// Set sets the param value. Empty value means the param is a flag
+func (b *ButtonData) Set(key string, value string) *ButtonData {
+	if _, ok := b.Params[key]; !ok {
+		b.keys = append(b.keys, key)
+	}
+	b.Params[key] = value
+	return b
+}
+
+// SetInt sets the int param value
+func (b *ButtonData) SetInt(key string, value int) *ButtonData {
+	return b.Set(key, strconv.Itoa(value))
+}
+
+// Param returns the param value, or ErrInvalidButtonData if it is not found
+func (b *ButtonData) Param(key string) (string, error) {
+	value, ok := b.Params[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s param not found", ErrInvalidButtonData, key)
+	}
+	return value, nil
+}
+
+// Marshal encodes the button data into the telegram button callback data.
+// Returns ErrButtonDataTooLong if the result exceeds MaxButtonDataLength,
+// or ErrAmbiguousButtonParam if the unknown param can't be decoded.
+func (b *ButtonData) Marshal() (string, error) {
+	data, err := b.encode()
+	if err != nil {
+		return data, err
+	}
+	if len(data) > MaxButtonDataLength {
+		return data, fmt.Errorf("%w: %s", ErrButtonDataTooLong, data)
+	}
+	return data, nil
+}
+
+// String is like Marshal, but panics on error.
+//
+// Use it for the buttons with the data that fits the limit whatever the param
+// values are: Telegram rejects the whole message with too long button data,
+// so it's better to find such buttons in tests. Use Marshal otherwise.
+func (b *ButtonData) String() string {
+	data, err := b.Marshal()
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func (b *ButtonData) encode() (string, error) {
+	if len(b.Params) == 0 {
+		return b.Action, nil
+	}
+
+	// Params set directly to the map are encoded after the ordered ones
+	keys := b.keys
+	if len(keys) != len(b.Params) {
+		keys = make([]string, 0, len(b.Params))
+		seen := make(map[string]bool, len(b.Params))
+		for _, key := range b.keys {
+			if _, ok := b.Params[key]; ok && !seen[key] {
+				keys = append(keys, key)
+				seen[key] = true
+			}
+		}
+		for key := range b.Params {
+			if !seen[key] {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(b.Action)
+	sb.WriteString("#")
+	sb.WriteString(strconv.Itoa(ButtonDataVersion))
+
+	for i, key := range keys {
+		if i != 0 {
+			sb.WriteString("&")
+		}
+
+		param, known := buttonParams[key]
+		if known {
+			sb.WriteString(param.Short)
+		} else if _, ok := buttonParamsByShort[url.QueryEscape(key)]; ok {
+			return "", fmt.Errorf("%w: %s", ErrAmbiguousButtonParam, key)
+		} else {
+			sb.WriteString(url.QueryEscape(key))
+		}
+
+		value := b.Params[key]
+		if value == "" {
+			continue
+		}
+
+		sb.WriteString("=")
+		sb.WriteString(encodeParamValue(value, param.Kind))
+	}
+
+	return sb.String(), nil
 }
 
-// ParseButtonData parses the telegram button callback Action string.
+// UnmarshalButtonData parses the telegram button callback data
+// created by Marshal. The legacy format is supported as well.
+//
+// Callback data is sent by the client, so it can be anything: ErrInvalidButtonData
+// is returned if it's too long, or the action is not registered with RegisterButtonActions.
+// Unknown params are kept, the handlers use only the ones they need.
 //
-// Params example: "open.schedule.day#date=2023-10-04&day=1"
+// Example: "open.schedule.day#date=2023-10-04&day=1"
 //
 // Returns ButtonData: Action = "open.schedule.day",
 // Params = {"date": "2023-10-04", "day": "1"}
-func ParseButtonData(buttonData string) *ButtonData {
-	if !strings.Contains(buttonData, "#") {
-		return &ButtonData{Action: buttonData}
+func UnmarshalButtonData(buttonData string) (*ButtonData, error) {
+	if len(buttonData) > MaxButtonDataLength {
+		return nil, fmt.Errorf("%w: too long: %q", ErrInvalidButtonData, buttonData)
 	}
 
-	parsed := strings.Split(buttonData, "#")
-	action := parsed[0]
+	action, paramsStr, found := strings.Cut(buttonData, "#")
+	if err := validateButtonAction(action); err != nil {
+		return nil, err
+	}
 
-	if len(parsed) == 1 {
-		return &ButtonData{Action: action}
+	data := &ButtonData{Action: action, Params: make(map[string]string)}
+	if !found || paramsStr == "" {
+		data.Version = ButtonDataVersion
+		return data, nil
 	}
 
-	params := make(map[string]string)
+	// Legacy params start with a key, never with a digit
+	if paramsStr[0] < '0' || paramsStr[0] > '9' {
+		for _, v := range strings.Split(paramsStr, "&") {
+			key, value, _ := strings.Cut(v, "=")
+			data.Params[key] = value
+			data.keys = append(data.keys, key)
+		}
+		return data, nil
+	}
 
-	for _, v := range strings.Split(parsed[1], "&") {
-		if !strings.Contains(v, "=") {
-			params[v] = ""
-			continue
+	if paramsStr[0] != '0'+ButtonDataVersion {
+		return nil, fmt.Errorf("%w: unknown version %c: %s", ErrInvalidButtonData, paramsStr[0], buttonData)
+	}
+	data.Version = ButtonDataVersion
+
+	paramsStr = paramsStr[1:]
+	if paramsStr == "" {
+		return data, nil
+	}
+
+	for _, v := range strings.Split(paramsStr, "&") {
+		key, value, _ := strings.Cut(v, "=")
+
+		name, known := buttonParamsByShort[key]
+		if !known {
+			var err error
+			name, err = url.QueryUnescape(key)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidButtonData, err)
+			}
+		}
+
+		kind := buttonParams[name].Kind
+		if value == "" && kind != stringParam {
+			// Truncated data, only string params can be flags
+			return nil, fmt.Errorf("%w: %s param is empty", ErrInvalidButtonData, name)
 		}
-		p := strings.SplitN(v, "=", 2)
-		params[p[0]] = p[1]
+
+		if value != "" {
+			var err error
+			value, err = decodeParamValue(value, kind)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s param: %s", ErrInvalidButtonData, name, err)
+			}
+		}
+
+		data.Params[name] = value
+		data.keys = append(data.keys, name)
 	}
 
-	return &ButtonData{Action: action, Params: params}
+	return data, nil
+}
+
+// ButtonAction returns the action of the button data, or InvalidButtonAction
+// if the data is invalid. The params are not parsed.
+//
+// Used for logs and metrics, so the data sent by the client
+// can't make up any number of different actions.
+func ButtonAction(buttonData string) string {
+	action, _, _ := strings.Cut(buttonData, "#")
+	if len(buttonData) > MaxButtonDataLength || validateButtonAction(action) != nil {
+		return InvalidButtonAction
+	}
+	return action
+}
+
+// validateButtonAction checks if the action is not empty
+// and is registered with RegisterButtonActions
+func validateButtonAction(action string) error {
+	if action == "" {
+		return fmt.Errorf("%w: empty action", ErrInvalidButtonData)
+	}
+	if buttonActions != nil && !buttonActions[action] {
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidButtonData, action)
+	}
+	return nil
+}
+
+func encodeParamValue(value string, kind paramKind) string {
+	switch kind {
+	case intParam:
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 && strconv.Itoa(n) == value {
+			return packInt(n)
+		}
+		return rawValuePrefix + url.QueryEscape(value)
+	case dateParam:
+		if date, err := time.Parse(time.DateOnly, value); err == nil && date.Unix() >= 0 {
+			return packInt(int(date.Unix() / 86400))
+		}
+		return rawValuePrefix + url.QueryEscape(value)
+	default:
+		return url.QueryEscape(value)
+	}
+}
+
+func decodeParamValue(value string, kind paramKind) (string, error) {
+	if kind == stringParam {
+		return url.QueryUnescape(value)
+	}
+	if strings.HasPrefix(value, rawValuePrefix) {
+		return url.QueryUnescape(value[len(rawValuePrefix):])
+	}
+
+	n, err := unpackInt(value)
+	if err != nil {
+		return "", err
+	}
+
+	if kind == dateParam {
+		return time.Unix(int64(n)*86400, 0).UTC().Format(time.DateOnly), nil
+	}
+	return strconv.Itoa(n), nil
+}
+
+// packInt encodes the non-negative int into base64 digits
+func packInt(n int) string {
+	if n == 0 {
+		return base64Digits[:1]
+	}
+
+	var buf [11]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = base64Digits[n%64]
+		n /= 64
+	}
+	return string(buf[i:])
+}
+
+// unpackInt decodes the int encoded by packInt
+func unpackInt(s string) (int, error) {
+	if len(s) > 10 {
+		return 0, errors.New("packed int is too long")
+	}
+
+	n := 0
+	for i := 0; i < len(s); i++ {
+		digit := strings.IndexByte(base64Digits, s[i])
+		if digit == -1 {
+			return 0, fmt.Errorf("invalid packed int %q", s)
+		}
+		n = n*64 + digit
+	}
+	return n, nil
 }
@@ -0,0 +1,224 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestButtonParamsShortKeysAreUnique(t *testing.T) {
+	names := make(map[string]string, len(buttonParams))
+	for name, param := range buttonParams {
+		if param.Short == "" {
+			t.Errorf("%s param has no short key", name)
+		}
+		if other, ok := names[param.Short]; ok {
+			t.Errorf("%s and %s params have the same short key %q", name, other, param.Short)
+		}
+		names[param.Short] = name
+	}
+}
+
+func TestButtonDataRoundTrip(t *testing.T) {
+	button := NewButtonData("open.schedule.day")
+	for name, param := range buttonParams {
+		switch param.Kind {
+		case intParam:
+			button.SetInt(name, 1234)
+		case dateParam:
+			button.Set(name, "2024-10-27")
+		default:
+			button.Set(name, "a b")
+		}
+
+		data := button.String()
+		parsed, err := UnmarshalButtonData(data)
+		if err != nil {
+			t.Fatalf("UnmarshalButtonData(%q): %s", data, err)
+		}
+		if parsed.Params[name] != button.Params[name] {
+			t.Errorf("%s param: got %q, want %q", name, parsed.Params[name], button.Params[name])
+		}
+
+		delete(button.Params, name)
+	}
+}
+
+func TestMarshalTooLong(t *testing.T) {
+	_, err := NewButtonData("open.schedule.day").Set("back", strings.Repeat("a", MaxButtonDataLength)).Marshal()
+	if !errors.Is(err, ErrButtonDataTooLong) {
+		t.Errorf("got %v, want ErrButtonDataTooLong", err)
+	}
+}
+
+func TestMarshalAmbiguousParam(t *testing.T) {
+	// "d" is the short key of the date param
+	_, err := NewButtonData("open.schedule.day").Set("d", "1").Marshal()
+	if !errors.Is(err, ErrAmbiguousButtonParam) {
+		t.Errorf("got %v, want ErrAmbiguousButtonParam", err)
+	}
+}
+
+func TestStringPanicsOnTooLong(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("String did not panic")
+		}
+	}()
+
+	_ = NewButtonData("open.schedule.day").Set("back", strings.Repeat("a", MaxButtonDataLength)).String()
+}
+
+// registerTestButtonActions registers the actions until the test ends
+func registerTestButtonActions(t *testing.T, actions ...string) {
+	prev := buttonActions
+	RegisterButtonActions(actions...)
+	t.Cleanup(func() {
+		buttonActions = prev
+	})
+}
+
+func TestButtonAction(t *testing.T) {
+	registerTestButtonActions(t, "open.schedule.day", "open.schedule.day_extra", "admin.get_logs")
+
+	withGroup := NewButtonData("open.schedule.day").SetInt("groupId", 1234).String()
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"without params", "open.schedule.day", "open.schedule.day"},
+		{"with params", withGroup, "open.schedule.day"},
+		{"legacy params", "open.schedule.day#date=2024-10-27", "open.schedule.day"},
+		{"action with the same prefix", "open.schedule.day_extra#1", "open.schedule.day_extra"},
+		{"prefix of the action", "open.schedule", InvalidButtonAction},
+		{"unknown action", "admin.send_logs", InvalidButtonAction},
+		{"empty", "", InvalidButtonAction},
+		{"only params", "#1", InvalidButtonAction},
+		{"too long", "admin.get_logs#" + strings.Repeat("a", MaxButtonDataLength), InvalidButtonAction},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ButtonAction(tt.data); got != tt.want {
+				t.Errorf("ButtonAction(%q) = %q, want %q", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalInvalidButtonData(t *testing.T) {
+	registerTestButtonActions(t, "open.schedule.day")
+
+	withGroup := NewButtonData("open.schedule.day").SetInt("groupId", 1234).String()
+	withDate := NewButtonData("open.schedule.day").Set("date", "2024-10-27").String()
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"unknown action", "open.schedule"},
+		{"empty action", "#1"},
+		{"unknown version", "open.schedule.day#9"},
+		{"truncated int param", withGroup[:strings.Index(withGroup, "=")+1]},
+		{"truncated date param", withDate[:strings.Index(withDate, "=")+1]},
+		{"invalid int param", withGroup[:strings.Index(withGroup, "=")+1] + "!"},
+		{"invalid escape", "open.schedule.day#1%zz=1"},
+		{"too long", "open.schedule.day#" + strings.Repeat("a", MaxButtonDataLength)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := UnmarshalButtonData(tt.data); !errors.Is(err, ErrInvalidButtonData) {
+				t.Errorf("UnmarshalButtonData(%q): got %v, want ErrInvalidButtonData", tt.data, err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalExtraButtonParams(t *testing.T) {
+	registerTestButtonActions(t, "open.schedule.day")
+
+	tests := []struct {
+		name string
+		data string
+		want map[string]string
+	}{
+		{"unknown param", NewButtonData("open.schedule.day").Set("date", "2024-10-27").Set("extra", "a b").String(),
+			map[string]string{"date": "2024-10-27", "extra": "a b"}},
+		{"unknown flag", NewButtonData("open.schedule.day").Set("extra", "").String(),
+			map[string]string{"extra": ""}},
+		{"known param the action doesn't use", NewButtonData("open.schedule.day").SetInt("teacher", 1234).String(),
+			map[string]string{"teacher": "1234"}},
+		{"legacy unknown param", "open.schedule.day#date=2024-10-27&extra=1",
+			map[string]string{"date": "2024-10-27", "extra": "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			button, err := UnmarshalButtonData(tt.data)
+			if err != nil {
+				t.Fatalf("UnmarshalButtonData(%q): %s", tt.data, err)
+			}
+			if len(button.Params) != len(tt.want) {
+				t.Errorf("got params %v, want %v", button.Params, tt.want)
+			}
+			for key, value := range tt.want {
+				if got, ok := button.Params[key]; !ok || got != value {
+					t.Errorf("%s param: got %q, want %q", key, got, value)
+				}
+			}
+		})
+	}
+}
+
+func TestMissingButtonParam(t *testing.T) {
+	_, err := NewButtonData("open.schedule.day").Param("date")
+	if !errors.Is(err, ErrInvalidButtonData) {
+		t.Errorf("got %v, want ErrInvalidButtonData", err)
+	}
+}
+
+func TestQueryHashButton(t *testing.T) {
+	if QueryHash("ПІ-21") == QueryHash("ПІ-22") {
+		t.Error("different queries have the same hash")
+	}
+
+	button := NewButtonData("search.teacher").SetInt("queryHash", QueryHash("Іваненко")).SetInt("page", 99)
+	data, err := button.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := UnmarshalButtonData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Params["queryHash"] != strconv.Itoa(QueryHash("Іваненко")) {
+		t.Errorf("queryHash param: got %q, want %d", parsed.Params["queryHash"], QueryHash("Іваненко"))
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeepLinkMaxAge is how many days in the past the deep link date can be.
+// Older links are treated as expired.
+const DeepLinkMaxAge = 30
+
+// ErrInvalidDeepLink is returned when the /start parameter
+// is not a deep link, is malformed or expired
+var ErrInvalidDeepLink = errors.New("invalid deep link")
+
+// DeepLink is a link shared with the /start parameter. It leads to:
+//   - the group schedule for the day, like "group_123_2024-10-21"
+//   - the group, selected in the chat, like "group_123". Date is empty
+//   - the teacher schedule, like "teacher_45". GroupId is 0
+type DeepLink struct {
+	GroupId   int
+	Date      string
+	TeacherId int
+}
+
+// Payload encodes the deep link into the /start parameter
+func (l DeepLink) Payload() string {
+	if l.TeacherId != 0 {
+		return "teacher_" + strconv.Itoa(l.TeacherId)
+	}
+	if l.Date == "" {
+		return "group_" + strconv.Itoa(l.GroupId)
+	}
+	return "group_" + strconv.Itoa(l.GroupId) + "_" + l.Date
+}
+
+// URL returns the link that opens the bot with the deep link
+func (l DeepLink) URL(botUsername string) string {
+	return "https://t.me/" + botUsername + "?start=" + l.Payload()
+}
+
+// UnmarshalDeepLink parses the /start parameter created by DeepLink.Payload.
+//
+// Example: "group_123_2024-10-21"
+//
+// Returns DeepLink: GroupId = 123, Date = "2024-10-21"
+//
+// Example: "teacher_45"
+//
+// Returns DeepLink: TeacherId = 45
+func UnmarshalDeepLink(payload string) (DeepLink, error) {
+	parts := strings.Split(payload, "_")
+	if len(parts) < 2 || len(parts) > 3 || (parts[0] != "group" && parts[0] != "teacher") {
+		return DeepLink{}, fmt.Errorf("%w: %s", ErrInvalidDeepLink, payload)
+	}
+
+	id, err := strconv.Atoi(parts[1])
+	if err != nil || id <= 0 {
+		return DeepLink{}, fmt.Errorf("%w: invalid %s id: %s", ErrInvalidDeepLink, parts[0], payload)
+	}
+
+	if parts[0] == "teacher" {
+		if len(parts) != 2 {
+			return DeepLink{}, fmt.Errorf("%w: %s", ErrInvalidDeepLink, payload)
+		}
+		return DeepLink{TeacherId: id}, nil
+	}
+	if len(parts) == 2 {
+		return DeepLink{GroupId: id}, nil
+	}
+
+	date, err := time.Parse(time.DateOnly, parts[2])
+	if err != nil {
+		return DeepLink{}, fmt.Errorf("%w: invalid date: %s", ErrInvalidDeepLink, payload)
+	}
+
+	if time.Since(date) > DeepLinkMaxAge*24*time.Hour {
+		return DeepLink{}, fmt.Errorf("%w: expired: %s", ErrInvalidDeepLink, payload)
+	}
+
+	return DeepLink{GroupId: id, Date: parts[2]}, nil
+}
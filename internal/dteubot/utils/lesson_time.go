@@ -43,6 +43,8 @@ const (
 type CallsStatus struct {
 	Status CallStatusType
 	Time   time.Time
+	// Number is the number of the current or the next lesson
+	Number int
 }
 
 // DaysScanLimit - limit of days to scan for the start of the next lesson in /left command
@@ -104,6 +106,7 @@ func getCallsStatus(day api.TimeTableDate, calls api.CallSchedule, time2 time.Ti
 		return &CallsStatus{
 			Status: CallStatusBeforeStart,
 			Time:   firstCallStart,
+			Number: day.Lessons[0].Number,
 		}, nil
 	}
 
@@ -141,6 +144,7 @@ func getCallsStatus(day api.TimeTableDate, calls api.CallSchedule, time2 time.Ti
 			return &CallsStatus{
 				Status: CallStatusDuring,
 				Time:   callEnd,
+				Number: lesson.Number,
 			}, nil
 		}
 
@@ -149,6 +153,7 @@ func getCallsStatus(day api.TimeTableDate, calls api.CallSchedule, time2 time.Ti
 			return &CallsStatus{
 				Status: CallStatusBefore,
 				Time:   callStart,
+				Number: lesson.Number,
 			}, nil
 		}
 	}
@@ -156,6 +161,58 @@ func getCallsStatus(day api.TimeTableDate, calls api.CallSchedule, time2 time.Ti
 	return nil, nil
 }
 
+// GetCallScheduleStatus returns the time relative to the current call
+// of the call schedule, regardless of the group schedule.
+// Returns nil after the last call and on weekends.
+//
+// Time is calculated in time2 location.
+func GetCallScheduleStatus(calls api.CallSchedule, time2 time.Time) (*CallsStatus, error) {
+	if IsWeekend(time2) {
+		return nil, nil
+	}
+
+	date := time2.Format(time.DateOnly)
+	for i, call := range calls {
+		callStart, err := time.ParseInLocation("2006-01-02 15:04", date+" "+call.TimeStart, time2.Location())
+		if err != nil {
+			return nil, err
+		}
+		callEnd, err := time.ParseInLocation("2006-01-02 15:04", date+" "+call.TimeEnd, time2.Location())
+		if err != nil {
+			return nil, err
+		}
+
+		// Check if the current time is before the call, e.g. during the break
+		if time2.Before(callStart) {
+			status := CallStatusBefore
+			if i == 0 {
+				status = CallStatusBeforeStart
+			}
+			return &CallsStatus{
+				Status: status,
+				Time:   callStart,
+				Number: call.Number,
+			}, nil
+		}
+
+		// Check if the current time is during the call
+		if time2.Before(callEnd) {
+			return &CallsStatus{
+				Status: CallStatusDuring,
+				Time:   callEnd,
+				Number: call.Number,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// IsWeekend checks if the date is Saturday or Sunday
+func IsWeekend(date time.Time) bool {
+	return date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+}
+
 // FormatDuration formats time.Duration into a readable format
 //
 // Example:
@@ -0,0 +1,135 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package utils
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/logging"
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// requestIdKey is the update context data key of the request id
+const requestIdKey = "request_id"
+
+// RequestId returns the random id of the update handling set by
+// the middleware.RequestLogger, or "" if it's not set. Unlike the update id,
+// it's different if the same update is handled again, e.g. after the restart.
+func RequestId(ctx *ext.Context) string {
+	requestId, _ := ctx.Data[requestIdKey].(string)
+	return requestId
+}
+
+// SetRequestLogger sets the request id of the update and adds the logger
+// with the update fields to its context, see Logger and UpdateContext
+func SetRequestLogger(ctx *ext.Context, requestId string) {
+	ctx.Data[requestIdKey] = requestId
+	SetUpdateContext(ctx, logging.WithLogger(UpdateContext(ctx), NewUpdateLogger(ctx)))
+}
+
+// Logger returns the logger of the update set by the middleware.RequestLogger.
+// Its records have the update fields, so the handling of a single update
+// can be traced through the logs.
+func Logger(ctx *ext.Context) *slog.Logger {
+	return logging.FromContext(UpdateContext(ctx))
+}
+
+// NewUpdateLogger returns the default logger with the update fields:
+// update_id, request_id, chat_id, user_id and the button action
+func NewUpdateLogger(ctx *ext.Context) *slog.Logger {
+	attrs := []any{slog.Int64("update_id", ctx.UpdateId)}
+	if requestId := RequestId(ctx); requestId != "" {
+		attrs = append(attrs, slog.String("request_id", requestId))
+	}
+	if ctx.EffectiveChat != nil {
+		attrs = append(attrs, slog.Int64("chat_id", ctx.EffectiveChat.Id))
+	}
+	if ctx.EffectiveUser != nil {
+		attrs = append(attrs, slog.Int64("user_id", ctx.EffectiveUser.Id))
+	}
+	if ctx.CallbackQuery != nil {
+		attrs = append(attrs, slog.String("action", ButtonAction(ctx.CallbackQuery.Data)))
+	}
+
+	return slog.Default().With(attrs...)
+}
+
+// LogFields returns the update fields to be added to the error reports
+// sent to Telegram, like "update=123 request=5f2a chat=-100 user=42 action=open.menu".
+//
+// The request id is the one of the update logs, so
+// the report can be found in the logs by it.
+func LogFields(ctx *ext.Context) string {
+	var sb strings.Builder
+	sb.WriteString("update=")
+	sb.WriteString(strconv.FormatInt(ctx.UpdateId, 10))
+
+	if requestId := RequestId(ctx); requestId != "" {
+		sb.WriteString(" request=")
+		sb.WriteString(requestId)
+	}
+	if ctx.EffectiveChat != nil {
+		sb.WriteString(" chat=")
+		sb.WriteString(strconv.FormatInt(ctx.EffectiveChat.Id, 10))
+	}
+	if ctx.EffectiveUser != nil {
+		sb.WriteString(" user=")
+		sb.WriteString(strconv.FormatInt(ctx.EffectiveUser.Id, 10))
+	}
+	if ctx.CallbackQuery != nil {
+		sb.WriteString(" action=")
+		sb.WriteString(ButtonAction(ctx.CallbackQuery.Data))
+	}
+
+	return sb.String()
+}
+
+// SensitiveCommands are the commands whose arguments must not be logged or saved,
+// like the student id of /identify
+var SensitiveCommands = []string{"identify"}
+
+// ScrubCommandText hides the arguments of the sensitive commands in the message text,
+// so "/identify 12345" becomes "/identify [hidden]". Other texts are returned as is.
+func ScrubCommandText(text string) string {
+	if !strings.HasPrefix(text, "/") {
+		return text
+	}
+
+	i := strings.IndexFunc(text, unicode.IsSpace)
+	if i == -1 || strings.TrimSpace(text[i:]) == "" {
+		return text
+	}
+	command := text[:i]
+
+	// Command can be sent with the bot username, like /identify@bot
+	name, _, _ := strings.Cut(command[1:], "@")
+	for _, sensitive := range SensitiveCommands {
+		if strings.EqualFold(name, sensitive) {
+			return command + " [hidden]"
+		}
+	}
+
+	return text
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package utils
+
+import (
+	"fmt"
+	"github.com/cubicbyte/dteubot/pkg/api"
+	"strings"
+	"time"
+)
+
+// NextLesson is the next upcoming lesson of the group
+type NextLesson struct {
+	Date   string
+	Lesson api.TimeTableLesson
+	Start  time.Time
+	End    time.Time
+	// PrevEnd is the end of the last lesson of the same day that
+	// started before the requested time. Zero if there are no such lessons
+	PrevEnd time.Time
+}
+
+// GetNextLesson returns the next lesson that starts after time2, looking
+// up to DaysScanLimit days ahead. Returns nil if there are no such lessons.
+//
+// Lesson time is taken from the schedule, or from the call schedule
+// if the schedule doesn't contain it. Time is calculated in time2 location.
+func GetNextLesson(groupId int, time2 time.Time, api2 api.Api) (*NextLesson, error) {
+	calls, err := api2.GetCallSchedule()
+	if err != nil {
+		return nil, err
+	}
+
+	dateStart := time2.Format("2006-01-02")
+	dateEnd := time2.AddDate(0, 0, DaysScanLimit).Format("2006-01-02")
+	schedule, err := api2.GetGroupSchedule(groupId, dateStart, dateEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	var prevEnd time.Time
+	date := time2
+	for i := 0; i <= DaysScanLimit; i++ {
+		day := schedule.GetDay(date.Format("2006-01-02"))
+		date = date.AddDate(0, 0, 1)
+		if day == nil {
+			continue
+		}
+
+		for _, lesson := range day.Lessons {
+			if IsLessonHidden(lesson) {
+				continue
+			}
+
+			start, end, err := GetLessonTime(day.Date, lesson, calls, time2.Location())
+			if err != nil {
+				return nil, err
+			}
+
+			if start.After(time2) {
+				return &NextLesson{
+					Date:    day.Date,
+					Lesson:  lesson,
+					Start:   start,
+					End:     end,
+					PrevEnd: prevEnd,
+				}, nil
+			}
+			if i == 0 {
+				prevEnd = end
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// GetLessonTime returns the start and end time of the lesson.
+//
+// Time from the lesson periods is used if present,
+// otherwise the time is taken from the call schedule.
+func GetLessonTime(date string, lesson api.TimeTableLesson, calls api.CallSchedule, loc *time.Location) (time.Time, time.Time, error) {
+	var timeStart, timeEnd string
+	if len(lesson.Periods) > 0 {
+		timeStart = lesson.Periods[0].TimeStart
+		timeEnd = lesson.Periods[0].TimeEnd
+	}
+
+	if timeStart == "" || timeEnd == "" {
+		call := calls.GetCall(lesson.Number)
+		if call == nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("call not found for lesson %d", lesson.Number)
+		}
+		if timeStart == "" {
+			timeStart = call.TimeStart
+		}
+		if timeEnd == "" {
+			timeEnd = call.TimeEnd
+		}
+	}
+
+	start, err := time.ParseInLocation("2006-01-02 15:04", date+" "+timeStart, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := time.ParseInLocation("2006-01-02 15:04", date+" "+timeEnd, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return start, end, nil
+}
+
+// IsLessonHidden checks if the lesson has no periods
+// or is hidden, like "приховано з **"
+func IsLessonHidden(lesson api.TimeTableLesson) bool {
+	if len(lesson.Periods) == 0 {
+		return true
+	}
+	return IsPeriodHidden(lesson.Periods[0])
+}
+
+// IsPeriodHidden checks if the lesson period is hidden, like "приховано з **"
+func IsPeriodHidden(period api.TimeTablePeriod) bool {
+	return strings.Contains(strings.ToLower(period.DisciplineShortName), "приховано")
+}
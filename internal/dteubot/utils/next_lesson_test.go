@@ -0,0 +1,152 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package utils
+
+import (
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/pkg/api"
+	"testing"
+	"time"
+)
+
+// scheduleApi is the api.Api that returns the fixed call schedule and group schedule
+type scheduleApi struct {
+	api.Api
+	calls    api.CallSchedule
+	schedule api.Schedule
+}
+
+func (a scheduleApi) GetCallSchedule() (api.CallSchedule, error) {
+	return a.calls, nil
+}
+
+func (a scheduleApi) GetGroupSchedule(groupId int, dateStart string, dateEnd string) (api.Schedule, error) {
+	var schedule api.Schedule
+	for _, day := range a.schedule {
+		if day.Date >= dateStart && day.Date <= dateEnd {
+			schedule = append(schedule, day)
+		}
+	}
+	return schedule, nil
+}
+
+func testLesson(number int, name string) api.TimeTableLesson {
+	return api.TimeTableLesson{
+		Number:  number,
+		Periods: []api.TimeTablePeriod{{DisciplineShortName: name}},
+	}
+}
+
+var testScheduleApi = scheduleApi{
+	calls: api.CallSchedule{
+		{Number: 1, TimeStart: "08:00", TimeEnd: "09:20"},
+		{Number: 2, TimeStart: "09:30", TimeEnd: "10:50"},
+		{Number: 3, TimeStart: "11:10", TimeEnd: "12:30"},
+	},
+	schedule: api.Schedule{
+		{Date: "2024-10-28", Lessons: []api.TimeTableLesson{
+			testLesson(1, "Math"),
+			testLesson(2, "Physics"),
+			testLesson(3, "Приховано з 01.10"),
+		}},
+		{Date: "2024-10-29", Lessons: []api.TimeTableLesson{
+			{Number: 2, Periods: []api.TimeTablePeriod{{DisciplineShortName: "History", TimeStart: "09:40", TimeEnd: "11:00"}}},
+		}},
+	},
+}
+
+func TestGetNextLesson(t *testing.T) {
+	loc := UniversityLocation()
+	at := func(date string, clock string) time.Time {
+		t2, err := time.ParseInLocation("2006-01-02 15:04", date+" "+clock, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return t2
+	}
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		date    string
+		number  int
+		start   time.Time
+		prevEnd time.Time
+	}{
+		{"before the first lesson", at("2024-10-28", "07:00"), "2024-10-28", 1, at("2024-10-28", "08:00"), time.Time{}},
+		{"during the lesson", at("2024-10-28", "08:30"), "2024-10-28", 2, at("2024-10-28", "09:30"), at("2024-10-28", "09:20")},
+		{"between the lessons", at("2024-10-28", "09:25"), "2024-10-28", 2, at("2024-10-28", "09:30"), at("2024-10-28", "09:20")},
+		{"after the last lesson", at("2024-10-28", "11:00"), "2024-10-29", 2, at("2024-10-29", "09:40"), at("2024-10-28", "10:50")},
+		{"day without lessons", at("2024-10-27", "12:00"), "2024-10-28", 1, at("2024-10-28", "08:00"), time.Time{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			next, err := GetNextLesson(1, test.now, testScheduleApi)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next == nil {
+				t.Fatal("next lesson not found")
+			}
+			if next.Date != test.date || next.Lesson.Number != test.number {
+				t.Errorf("next lesson is %d on %s, want %d on %s", next.Lesson.Number, next.Date, test.number, test.date)
+			}
+			if !next.Start.Equal(test.start) {
+				t.Errorf("next lesson starts at %s, want %s", next.Start, test.start)
+			}
+			if !next.PrevEnd.Equal(test.prevEnd) {
+				t.Errorf("previous lesson ends at %s, want %s", next.PrevEnd, test.prevEnd)
+			}
+		})
+	}
+}
+
+func TestGetNextLessonNotFound(t *testing.T) {
+	now := time.Date(2024, time.October, 29, 12, 0, 0, 0, UniversityLocation())
+
+	next, err := GetNextLesson(1, now, testScheduleApi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != nil {
+		t.Errorf("found lesson %d on %s after the last one", next.Lesson.Number, next.Date)
+	}
+}
+
+func TestNowForUsesClock(t *testing.T) {
+	fixed := time.Date(2024, time.October, 28, 6, 30, 0, 0, time.UTC)
+	Now = func() time.Time { return fixed }
+	defer func() { Now = time.Now }()
+
+	now := NowFor(&data.Chat{Timezone: "America/New_York"})
+	if !now.Equal(fixed) {
+		t.Errorf("NowFor returned %s, want %s", now, fixed)
+	}
+	if date := now.Format(time.DateOnly); date != "2024-10-28" {
+		t.Errorf("chat date is %s, want 2024-10-28", date)
+	}
+	if clock := now.Format("15:04"); clock != "02:30" {
+		t.Errorf("chat time is %s, want 02:30", clock)
+	}
+}
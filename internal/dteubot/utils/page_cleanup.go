@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package utils
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/op/go-logging"
+)
+
+var log = logging.MustGetLogger("Utils")
+
+// TrackPage must be called after the page is sent or edited. If the chat has
+// enabled the pages cleanup, it remembers the last message with buttons, and
+// when another one is sent, the previous is removed so the old pages
+// don't clutter the chat.
+//
+// Errors are only logged, because the page itself is already sent.
+func TrackPage(bot *gotgbot.Bot, chatRepo data.ChatRepository, chatId int64, messageId int64, interactive bool) {
+	chat, err := chatRepo.GetById(chatId)
+	if err != nil {
+		log.Errorf("Error getting chat %d to clean up pages: %s", chatId, err)
+		return
+	}
+	if chat == nil || !chat.CleanupPages {
+		return
+	}
+
+	prevId := chat.LastPageId
+	switch {
+	case interactive && messageId != prevId:
+		chat.LastPageId = messageId
+	case !interactive && messageId == prevId:
+		// The page is edited and has no buttons anymore
+		chat.LastPageId = 0
+		prevId = 0
+	default:
+		return
+	}
+
+	if err := chatRepo.Update(chat); err != nil {
+		log.Errorf("Error updating chat %d last page: %s", chatId, err)
+		return
+	}
+
+	if prevId != 0 {
+		RemovePage(bot, chatId, prevId)
+	}
+}
+
+// RemovePage deletes the page message. If the bot can't delete it,
+// e.g. it's not an admin of the group, or the message is older
+// than 48 hours, only the buttons are removed.
+func RemovePage(bot *gotgbot.Bot, chatId int64, messageId int64) {
+	_, err := bot.DeleteMessage(chatId, messageId, nil)
+	if err == nil || IsMessageToDeleteNotFound(err) {
+		// Already deleted by the user
+		return
+	}
+
+	if IsMessageCantBeDeleted(err) {
+		_, _, err = bot.EditMessageReplyMarkup(&gotgbot.EditMessageReplyMarkupOpts{
+			ChatId:    chatId,
+			MessageId: messageId,
+		})
+		if err == nil || IsMessageToEditNotFound(err) || IsMessageNotModified(err) {
+			return
+		}
+	}
+
+	log.Warningf("Error removing page %d in chat %d: %s", messageId, chatId, err)
+}
@@ -0,0 +1,215 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package utils
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Settings are the effective settings used to handle the update
+type Settings struct {
+	GroupId      int
+	LanguageCode string
+	// PersonalGroup is true if the group is taken from the user settings
+	// instead of the group chat settings
+	PersonalGroup bool
+}
+
+// ResolveSettings returns the settings that should be used to handle the update.
+//
+// In private chats the user's own settings apply. In group chats the chat
+// settings apply, but members that enabled data.User.UseOwnGroup see the schedule
+// of their own group on their button presses, unless admins locked the chat settings.
+//
+// user can be nil, e.g. for updates from channels.
+func ResolveSettings(ctx *ext.Context, chat *data.Chat, user *data.User) Settings {
+	settings := Settings{
+		GroupId:      chat.GroupId,
+		LanguageCode: chat.LanguageCode,
+	}
+
+	if user == nil || user.GroupId == -1 {
+		return settings
+	}
+
+	if ctx.EffectiveChat.Type == "private" {
+		settings.GroupId = user.GroupId
+		return settings
+	}
+
+	if user.UseOwnGroup && !chat.SettingsLocked {
+		settings.GroupId = user.GroupId
+		settings.PersonalGroup = true
+	}
+
+	return settings
+}
+
+// settingsKey is the update context data key of the settings loaded by LoadSettings
+const settingsKey = "settings"
+
+// LoadSettings returns the effective settings for the update sent to the chat,
+// loading the settings of the user that sent it. See ResolveSettings.
+//
+// The settings are kept in the update context, see UpdateSettings.
+func LoadSettings(ctx *ext.Context, chat *data.Chat, userRepo data.UserRepository) (Settings, error) {
+	var user *data.User
+	if ctx.EffectiveUser != nil {
+		var err error
+		user, err = userRepo.GetById(ctx.EffectiveUser.Id)
+		if err != nil {
+			return Settings{}, err
+		}
+	}
+
+	settings := ResolveSettings(ctx, chat, user)
+	ctx.Data[settingsKey] = settings
+	return settings, nil
+}
+
+// UpdateSettings returns the settings the update was handled with,
+// so the later handler groups don't load the chat and user again.
+//
+// Returns false if the handler didn't load the settings.
+func UpdateSettings(ctx *ext.Context) (Settings, bool) {
+	settings, ok := ctx.Data[settingsKey].(Settings)
+	return settings, ok
+}
+
+// UpdateUserGroup sets the user group to the chat group selected in the private chat,
+// so it's also used in inline mode. Does nothing in group chats.
+func UpdateUserGroup(ctx *ext.Context, userRepo data.UserRepository, groupId int) error {
+	if ctx.EffectiveChat.Type != "private" {
+		return nil
+	}
+
+	user, err := userRepo.GetById(ctx.EffectiveUser.Id)
+	if err != nil {
+		return err
+	}
+
+	user.GroupId = groupId
+	return userRepo.Update(user)
+}
+
+// ScheduleGroups returns the groups to switch between on the schedule page:
+// the settings group followed by the chat saved groups.
+//
+// Returns nil if the group is not selected.
+func (s Settings) ScheduleGroups(chat *data.Chat) data.GroupRefs {
+	if s.GroupId == -1 {
+		return nil
+	}
+	return chat.SavedGroups.WithFirst(s.GroupId)
+}
+
+// IsChatAdmin checks if the user is the administrator or the creator of the chat
+func IsChatAdmin(bot *gotgbot.Bot, chatId int64, userId int64) (bool, error) {
+	member, err := bot.GetChatMember(chatId, userId, nil)
+	if err != nil {
+		return false, err
+	}
+
+	switch member.GetStatus() {
+	case "creator", "administrator":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// HasSettingsAccess checks if the user can change the chat settings.
+//
+// Everyone can change settings in private chats and in group chats
+// with unlocked settings, otherwise only chat admins can.
+func HasSettingsAccess(bot *gotgbot.Bot, ctx *ext.Context, chat *data.Chat) (bool, error) {
+	if ctx.EffectiveChat.Type == "private" || !chat.SettingsLocked {
+		return true, nil
+	}
+
+	if ctx.EffectiveUser == nil {
+		return false, nil
+	}
+
+	return IsChatAdmin(bot, chat.Id, ctx.EffectiveUser.Id)
+}
+
+// RequireSettingsAccess wraps the handler that changes the chat settings,
+// so it is not called if the chat settings are locked by admins
+// and the user is not a chat admin. The user is answered with deny instead.
+func RequireSettingsAccess(
+	chatRepo data.ChatRepository,
+	languages map[string]i18n.Language,
+	deny func(bot *gotgbot.Bot, ctx *ext.Context, text string) error,
+	handler func(*gotgbot.Bot, *ext.Context) error,
+) func(*gotgbot.Bot, *ext.Context) error {
+	return func(bot *gotgbot.Bot, ctx *ext.Context) error {
+		// Get chat
+		chat, err := chatRepo.GetById(ctx.EffectiveChat.Id)
+		if err != nil {
+			return err
+		}
+
+		access, err := HasSettingsAccess(bot, ctx, chat)
+		if err != nil {
+			return err
+		}
+		if access {
+			return handler(bot, ctx)
+		}
+
+		lang, err := GetLang(chat.LanguageCode, languages)
+		if err != nil {
+			return err
+		}
+
+		return deny(bot, ctx, lang.Alert.SettingsLocked)
+	}
+}
+
+// IsBotAdmin checks if the user is the bot administrator:
+// marked as admin in the database or listed in ADMIN_IDS env variable
+func IsBotAdmin(user *data.User) bool {
+	if user == nil {
+		return false
+	}
+	return user.IsAdmin || IsAdminId(user.Id)
+}
+
+// IsAdminId checks if the user is listed in ADMIN_IDS env variable.
+// Unlike IsBotAdmin, it doesn't need the user record.
+func IsAdminId(userId int64) bool {
+	for _, id := range strings.Split(os.Getenv("ADMIN_IDS"), ",") {
+		if id = strings.TrimSpace(id); id == strconv.FormatInt(userId, 10) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package utils
+
+import (
+	"errors"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"strings"
+)
+
+// IsMessageNotModified checks if the error is returned by Telegram
+// when the message is edited with the same content.
+//
+// It's not a real error: the button is pressed twice, or the page
+// has not changed, so it should be handled as a success.
+func IsMessageNotModified(err error) bool {
+	return isBadRequest(err, "Bad Request: message is not modified")
+}
+
+// IsMessageToEditNotFound checks if the error is returned by Telegram
+// when the edited message is deleted.
+func IsMessageToEditNotFound(err error) bool {
+	return isBadRequest(err, "Bad Request: message to edit not found")
+}
+
+// IsMessageCantBeEdited checks if the error is returned by Telegram
+// when the edited message is too old.
+func IsMessageCantBeEdited(err error) bool {
+	return isBadRequest(err, "Bad Request: message can't be edited")
+}
+
+// IsMessageToDeleteNotFound checks if the error is returned by Telegram
+// when the deleted message is already deleted.
+func IsMessageToDeleteNotFound(err error) bool {
+	return isBadRequest(err, "Bad Request: message to delete not found")
+}
+
+// IsMessageCantBeDeleted checks if the error is returned by Telegram
+// when the bot has no rights to delete the message, or it's too old.
+func IsMessageCantBeDeleted(err error) bool {
+	return isBadRequest(err, "Bad Request: message can't be deleted")
+}
+
+// isBadRequest checks if the error is the Telegram 400 error
+// with the description starting with prefix
+func isBadRequest(err error, prefix string) bool {
+	var tgError *gotgbot.TelegramError
+	if !errors.As(err, &tgError) {
+		return false
+	}
+
+	return tgError.Code == 400 && strings.HasPrefix(tgError.Description, prefix)
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package utils
+
+import (
+	"github.com/cubicbyte/dteubot/internal/data"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"strings"
+	"time"
+)
+
+// MaxTimezoneLength is a max length of the timezone name typed by the user
+const MaxTimezoneLength = 64
+
+// Now returns the current time. Tests replace it with a fixed
+// time to check the "today" and the next lesson logic.
+var Now = time.Now
+
+// ChatLocation returns the timezone of the chat.
+// Chats without the timezone set use the university timezone.
+func ChatLocation(chat *data.Chat) *time.Location {
+	if chat != nil && chat.Timezone != "" {
+		if loc, err := time.LoadLocation(chat.Timezone); err == nil {
+			return loc
+		}
+	}
+
+	return UniversityLocation()
+}
+
+// NowFor returns the current time in the timezone of the chat, so "today"
+// and "tomorrow" are the days of the chat, not of the server, around midnight.
+// Nil chat gets the current time of the university.
+func NowFor(chat *data.Chat) time.Time {
+	return Now().In(ChatLocation(chat))
+}
+
+// ParseTimezone checks the IANA timezone name typed by the user,
+// like "Europe/Kyiv" or "America/New York", and returns it as
+// expected by time.LoadLocation. Returns false if the timezone is unknown.
+func ParseTimezone(name string) (string, bool) {
+	name = strings.ReplaceAll(strings.TrimSpace(name), " ", "_")
+
+	// Empty name is the UTC timezone for LoadLocation
+	// and "Local" is the server one, so both are rejected
+	if name == "" || name == "Local" || len(name) > MaxTimezoneLength {
+		return "", false
+	}
+
+	if _, err := time.LoadLocation(name); err != nil {
+		return "", false
+	}
+
+	return name, true
+}
+
+// UniversityLocation returns the timezone of the university,
+// in which the lesson times are returned by the API
+func UniversityLocation() *time.Location {
+	loc, err := time.LoadLocation(api2.Location)
+	if err != nil {
+		return time.Local
+	}
+
+	return loc
+}
+
+// ConvertLessonTime converts the lesson time, e.g. "08:20", on the given date
+// from the university timezone to loc.
+//
+// Returns the time as is if it can't be parsed.
+func ConvertLessonTime(date string, lessonTime string, loc *time.Location) string {
+	t, err := time.ParseInLocation("2006-01-02 15:04", date+" "+lessonTime, UniversityLocation())
+	if err != nil {
+		return lessonTime
+	}
+
+	return t.In(loc).Format("15:04")
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package utils
+
+import (
+	"github.com/cubicbyte/dteubot/internal/data"
+	"testing"
+	"time"
+)
+
+func TestNowForBoundaries(t *testing.T) {
+	tests := []struct {
+		name     string
+		now      time.Time
+		timezone string
+		want     string
+	}{
+		// Kyiv is UTC+3 in summer, UTC+2 in winter
+		{"after midnight in the university", time.Date(2024, time.October, 26, 21, 30, 0, 0, time.UTC), "", "2024-10-27 00:30"},
+		{"before midnight in the university", time.Date(2024, time.October, 26, 20, 59, 0, 0, time.UTC), "", "2024-10-26 23:59"},
+		{"early morning in the university", time.Date(2024, time.December, 2, 0, 59, 0, 0, time.UTC), "", "2024-12-02 02:59"},
+		{"after midnight in the chat timezone", time.Date(2024, time.October, 28, 4, 30, 0, 0, time.UTC), "America/New_York", "2024-10-28 00:30"},
+		{"previous day in the chat timezone", time.Date(2024, time.October, 28, 2, 30, 0, 0, time.UTC), "America/New_York", "2024-10-27 22:30"},
+		{"next day in the chat timezone", time.Date(2024, time.October, 27, 15, 30, 0, 0, time.UTC), "Asia/Tokyo", "2024-10-28 00:30"},
+		{"unknown chat timezone", time.Date(2024, time.October, 26, 21, 30, 0, 0, time.UTC), "Mars/Olympus", "2024-10-27 00:30"},
+
+		// Autumn DST end: 04:00 EEST is 03:00 EET, 03:00-04:00 comes twice
+		{"before autumn DST end", time.Date(2024, time.October, 27, 0, 30, 0, 0, time.UTC), "", "2024-10-27 03:30"},
+		{"after autumn DST end", time.Date(2024, time.October, 27, 1, 30, 0, 0, time.UTC), "", "2024-10-27 03:30"},
+		// Spring DST start: 03:00 EET is 04:00 EEST, 03:00-04:00 is skipped
+		{"before spring DST start", time.Date(2024, time.March, 31, 0, 59, 0, 0, time.UTC), "", "2024-03-31 02:59"},
+		{"after spring DST start", time.Date(2024, time.March, 31, 1, 0, 0, 0, time.UTC), "", "2024-03-31 04:00"},
+		{"DST end in the chat timezone only", time.Date(2024, time.November, 3, 5, 30, 0, 0, time.UTC), "America/New_York", "2024-11-03 01:30"},
+		{"DST end in the chat timezone an hour later", time.Date(2024, time.November, 3, 6, 30, 0, 0, time.UTC), "America/New_York", "2024-11-03 01:30"},
+	}
+
+	defer func() { Now = time.Now }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Now = func() time.Time { return tt.now }
+
+			if got := NowFor(&data.Chat{Timezone: tt.timezone}).Format("2006-01-02 15:04"); got != tt.want {
+				t.Errorf("NowFor at %s in %q = %s, want %s", tt.now, tt.timezone, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNowForNilChat(t *testing.T) {
+	fixed := time.Date(2024, time.October, 26, 21, 30, 0, 0, time.UTC)
+	Now = func() time.Time { return fixed }
+	defer func() { Now = time.Now }()
+
+	if got := NowFor(nil).Format(time.DateOnly); got != "2024-10-27" {
+		t.Errorf("NowFor(nil) date is %s, want the university date 2024-10-27", got)
+	}
+}
+
+func TestParseTimezone(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   string
+		wantOk bool
+	}{
+		{"Europe/Kyiv", "Europe/Kyiv", true},
+		{"  America/New York ", "America/New_York", true},
+		{"UTC", "UTC", true},
+		{"", "", false},
+		{"   ", "", false},
+		{"Local", "", false},
+		{"Mars/Olympus", "", false},
+		{"../../etc/passwd", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseTimezone(tt.name)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("ParseTimezone(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestParseTimezoneDST(t *testing.T) {
+	name, ok := ParseTimezone("Europe/Kyiv")
+	if !ok {
+		t.Fatal("Europe/Kyiv is not parsed")
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// UTC offset changes with DST, so it must be the named zone, not a fixed offset
+	_, summer := time.Date(2024, time.July, 1, 12, 0, 0, 0, loc).Zone()
+	_, winter := time.Date(2024, time.January, 1, 12, 0, 0, 0, loc).Zone()
+	if summer != 3*3600 || winter != 2*3600 {
+		t.Errorf("offsets are %d and %d, want %d in summer and %d in winter", summer, winter, 3*3600, 2*3600)
+	}
+}
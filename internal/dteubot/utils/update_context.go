@@ -0,0 +1,48 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package utils
+
+import (
+	"context"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// updateContextKey is the update context data key of the update context.Context
+const updateContextKey = "context"
+
+// SetUpdateContext sets the context of the update returned by UpdateContext
+func SetUpdateContext(ctx *ext.Context, updateCtx context.Context) {
+	ctx.Data[updateContextKey] = updateCtx
+}
+
+// UpdateContext returns the context the update is handled within, set by the
+// middleware.Timeout. It's done when the update takes too long, and is
+// passed to the API so its requests are stopped too.
+//
+// Returns context.Background() if the context is not set.
+func UpdateContext(ctx *ext.Context) context.Context {
+	if updateCtx, ok := ctx.Data[updateContextKey].(context.Context); ok {
+		return updateCtx
+	}
+	return context.Background()
+}
@@ -25,6 +25,8 @@ package utils
 import (
 	"github.com/cubicbyte/dteubot/internal/i18n"
 	"github.com/dlclark/regexp2"
+	"hash/fnv"
+	"html"
 	"os"
 	"strings"
 	"time"
@@ -113,6 +115,15 @@ func SetLocation(time2 time.Time, location *time.Location) time.Time {
 	return time.Date(time2.Year(), time2.Month(), time2.Day(), time2.Hour(), time2.Minute(), time2.Second(), time2.Nanosecond(), location)
 }
 
+// QueryHash returns the short hash of the search query, set to the search
+// result buttons, so the results are not paged through after the query changed.
+// Only 24 bits are kept, which is enough to tell the chat queries apart.
+func QueryHash(query string) int {
+	h := fnv.New32a()
+	h.Write([]byte(query))
+	return int(h.Sum32() & 0xffffff)
+}
+
 // SplitRows splits the given slice into rows of given size.
 //
 // slice is the slice to split
@@ -179,3 +190,25 @@ func GetLessonIcon(lessonType int) string {
 		return ""
 	}
 }
+
+// meetingLinkRegex matches the Zoom, Teams and Google Meet links
+var meetingLinkRegex = regexp2.MustCompile(`https?://([\w-]+\.)*(zoom\.us|teams\.microsoft\.com|teams\.live\.com|meet\.google\.com)(/[^\s"'<>]*)?`, regexp2.IgnoreCase)
+
+// FindMeetingLinks returns the unique online meeting links found in the text
+func FindMeetingLinks(text string) []string {
+	links := make([]string, 0)
+	seen := make(map[string]bool)
+
+	m, _ := meetingLinkRegex.FindStringMatch(text)
+	for m != nil {
+		// Punctuation after the link in the text is not a part of it
+		link := strings.TrimRight(html.UnescapeString(m.String()), ".,;:!?)")
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+		m, _ = meetingLinkRegex.FindNextMatch(m)
+	}
+
+	return links
+}
@@ -0,0 +1,231 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package dteubot
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// WebhookSecretHeader is the header Telegram sends the webhook secret token in
+const WebhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// MaxUpdateSize is the max size of the update request body accepted by the webhook
+const MaxUpdateSize = 1 << 20
+
+// startWebhook sets the webhook and starts the server that receives the updates
+// from Telegram and passes them to the worker pool, like the polling does.
+// The certificate is loaded and the address is listened before the webhook
+// is set, so the bot can fall back to polling if any of them fails.
+//
+// Returns the function that stops receiving updates and, if WEBHOOK_DELETE
+// is true, deletes the webhook, and the channel that receives the error
+// if the server stops serving before that.
+func startWebhook() (func() error, <-chan error, error) {
+	webhookUrl, err := url.Parse(os.Getenv("WEBHOOK_URL"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsConfig, err := loadWebhookTLS(os.Getenv("WEBHOOK_CERT"), os.Getenv("WEBHOOK_KEY"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	listener, err := net.Listen("tcp", os.Getenv("WEBHOOK_LISTEN"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts := &gotgbot.SetWebhookOpts{
+		DropPendingUpdates: true,
+		SecretToken:        os.Getenv("WEBHOOK_SECRET"),
+	}
+
+	// Self-signed certificate must be uploaded to Telegram
+	if os.Getenv("WEBHOOK_CERT") != "" {
+		cert, err := os.Open(os.Getenv("WEBHOOK_CERT"))
+		if err != nil {
+			_ = listener.Close()
+			return nil, nil, err
+		}
+		defer cert.Close()
+		opts.Certificate = cert
+	}
+
+	// Updates sent right after the webhook is set wait
+	// in the listener until the server is started
+	if _, err := bot.SetWebhook(webhookUrl.String(), opts); err != nil {
+		_ = listener.Close()
+		return nil, nil, err
+	}
+
+	updates := &updateQueue{updates: make(chan json.RawMessage)}
+	mux := http.NewServeMux()
+	mux.Handle(webhookUrl.EscapedPath(), webhookHandler(os.Getenv("WEBHOOK_SECRET"), updates))
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         tlsConfig,
+	}
+
+	serveErr := make(chan error, 1)
+	go pool.Start(bot, updates.updates)
+	go func() {
+		log.Infof("Receiving updates on %s", listener.Addr())
+
+		var err error
+		if tlsConfig != nil {
+			// The certificate is already in the TLSConfig
+			err = server.ServeTLS(listener, "", "")
+		} else {
+			err = server.Serve(listener)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+	}()
+
+	stop := func() error {
+		var errs []error
+		if os.Getenv("WEBHOOK_DELETE") == "true" {
+			if _, err := bot.DeleteWebhook(nil); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		// Received updates are passed to the worker pool before the server stops.
+		// Handlers still running after the timeout are waited for by the queue.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+		updates.Close()
+		pool.Stop()
+
+		return errors.Join(errs...)
+	}
+
+	return stop, serveErr, nil
+}
+
+// loadWebhookTLS loads the webhook certificate and key, so the wrong
+// files are found before the webhook is set. Returns nil config if
+// certFile is empty, the TLS is terminated by the reverse proxy then.
+func loadWebhookTLS(certFile string, keyFile string) (*tls.Config, error) {
+	if certFile == "" {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+// webhookHandler passes the updates sent by Telegram to the updates channel.
+// Requests without the secret token are rejected, if it's set.
+func webhookHandler(secret string, updates *updateQueue) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		if secret != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get(WebhookSecretHeader)), []byte(secret)) != 1 {
+			log.Warningf("Rejected webhook request from %s: wrong secret token", r.RemoteAddr)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxUpdateSize))
+		if err != nil {
+			log.Warningf("Error reading webhook update: %s", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var update gotgbot.Update
+		if err := json.Unmarshal(body, &update); err != nil {
+			log.Warningf("Error decoding webhook update: %s", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Telegram sends the update again later
+		if !updates.Send(body) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}
+}
+
+// updateQueue passes the webhook updates to the worker pool.
+// It's closed once, after the running senders are done,
+// so no update is sent to the closed channel.
+type updateQueue struct {
+	updates chan json.RawMessage
+	closed  bool
+	mu      sync.RWMutex
+	once    sync.Once
+}
+
+// Send passes the update to the worker pool.
+// Returns false if the queue is closed.
+func (q *updateQueue) Send(update json.RawMessage) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return false
+	}
+
+	q.updates <- update
+	return true
+}
+
+// Close waits for the running senders and closes the updates channel.
+// The worker pool must still be receiving the updates.
+func (q *updateQueue) Close() {
+	q.once.Do(func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		q.closed = true
+		close(q.updates)
+	})
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package dteubot
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUpdateQueueClose(t *testing.T) {
+	queue := &updateQueue{updates: make(chan json.RawMessage)}
+
+	received := make(chan int)
+	go func() {
+		count := 0
+		for range queue.updates {
+			count++
+		}
+		received <- count
+	}()
+
+	// Senders still running while the queue is closed must not panic
+	var wg sync.WaitGroup
+	sent := make(chan bool, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sent <- queue.Send(json.RawMessage(`{"update_id":1}`))
+		}()
+	}
+
+	queue.Close()
+	queue.Close()
+	wg.Wait()
+	close(sent)
+
+	accepted := 0
+	for ok := range sent {
+		if ok {
+			accepted++
+		}
+	}
+	if count := <-received; count != accepted {
+		t.Errorf("received %d updates, want %d", count, accepted)
+	}
+
+	if queue.Send(json.RawMessage(`{"update_id":2}`)) {
+		t.Error("Send after Close returned true")
+	}
+}
+
+func TestWebhookHandlerClosed(t *testing.T) {
+	queue := &updateQueue{updates: make(chan json.RawMessage)}
+	queue.Close()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"update_id":1}`))
+	webhookHandler("", queue)(w, r)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
+
+// writeTestCert writes the self-signed certificate and its key to the dir
+func writeTestCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestLoadWebhookTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir)
+
+	config, err := loadWebhookTLS(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config == nil || len(config.Certificates) != 1 {
+		t.Errorf("got %+v config, want the loaded certificate", config)
+	}
+
+	// Without the certificate the TLS is terminated by the proxy
+	if config, err := loadWebhookTLS("", ""); config != nil || err != nil {
+		t.Errorf("loadWebhookTLS(\"\", \"\") = %v, %v, want nil, nil", config, err)
+	}
+
+	// Wrong files must fail before the webhook is set
+	if _, err := loadWebhookTLS(certFile, filepath.Join(dir, "missing.pem")); err == nil {
+		t.Error("missing key is loaded without an error")
+	}
+	if _, err := loadWebhookTLS(keyFile, keyFile); err == nil {
+		t.Error("key as a certificate is loaded without an error")
+	}
+}
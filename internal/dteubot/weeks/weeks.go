@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package weeks
+
+import (
+	"errors"
+	"time"
+)
+
+// Parity is the type of the semester week. Some classes
+// are held only on the numerator or only on the denominator weeks.
+type Parity int8
+
+const (
+	// Numerator - odd weeks of the semester: 1, 3, 5...
+	Numerator Parity = iota
+	// Denominator - even weeks of the semester: 2, 4, 6...
+	Denominator
+)
+
+// ErrBeforeSemester is returned when the date is before the semester start
+var ErrBeforeSemester = errors.New("date is before the semester start")
+
+// CurrentSemesterWeek returns the number of the semester week, starting from 1,
+// that contains the given date, and its parity.
+//
+// Weeks start on Monday, so the week that contains the semester start is the first one,
+// even if the semester starts in the middle of the week.
+func CurrentSemesterWeek(semesterStart time.Time, date time.Time) (int, Parity, error) {
+	start := weekStart(semesterStart)
+	current := weekStart(date)
+
+	if current.Before(start) {
+		return 0, Numerator, ErrBeforeSemester
+	}
+
+	// Week starts are in UTC, so every day is exactly 24 hours long
+	days := int(current.Sub(start).Hours() / 24)
+	week := days/7 + 1
+
+	if week%2 == 0 {
+		return week, Denominator, nil
+	}
+	return week, Numerator, nil
+}
+
+// weekStart returns the Monday of the week that contains the date, in UTC
+func weekStart(date time.Time) time.Time {
+	offset := (int(date.Weekday()) + 6) % 7
+	return time.Date(date.Year(), date.Month(), date.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
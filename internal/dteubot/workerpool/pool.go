@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package workerpool
+
+import (
+	"encoding/json"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/op/go-logging"
+	"sync"
+	"time"
+)
+
+var log = logging.MustGetLogger("WorkerPool")
+
+// QueueSize is the number of updates waiting for each worker
+// before receiving the next updates is blocked
+const QueueSize = 100
+
+// Pool processes the updates concurrently with the fixed number of workers.
+// The updates from the same chat are processed by the same worker one by one
+// in the order they are received, so the rapid button presses of the user
+// don't edit the message out of order.
+//
+// Implements ext.UpdateDispatcher. Should be created via NewPool.
+type Pool struct {
+	dispatcher   *ext.Dispatcher
+	size         int
+	drainTimeout time.Duration
+	workers      sync.WaitGroup
+}
+
+// NewPool creates a new pool of size workers that pass the updates to dispatcher.
+// On Stop, the pool waits up to drainTimeout for the received updates to be processed.
+func NewPool(dispatcher *ext.Dispatcher, size int, drainTimeout time.Duration) *Pool {
+	return &Pool{
+		dispatcher:   dispatcher,
+		size:         size,
+		drainTimeout: drainTimeout,
+	}
+}
+
+// Start distributes the updates between the workers until the updates channel is closed
+func (p *Pool) Start(b *gotgbot.Bot, updates <-chan json.RawMessage) {
+	queues := make([]chan *gotgbot.Update, p.size)
+	for i := range queues {
+		queues[i] = make(chan *gotgbot.Update, QueueSize)
+
+		p.workers.Add(1)
+		go p.work(b, queues[i])
+	}
+
+	for raw := range updates {
+		update := new(gotgbot.Update)
+		if err := json.Unmarshal(raw, update); err != nil {
+			log.Errorf("Error decoding update: %s", err)
+			continue
+		}
+
+		queues[workerIndex(update, p.size)] <- update
+	}
+
+	for _, queue := range queues {
+		close(queue)
+	}
+}
+
+// Stop waits for the workers to process the received updates.
+// Updates not processed in the drain timeout are left to the workers,
+// which skip them once the bot is shutting down.
+func (p *Pool) Stop() {
+	done := make(chan struct{})
+	go func() {
+		p.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(p.drainTimeout):
+		log.Warning("Drain timeout exceeded, some updates are not processed")
+	}
+}
+
+// work processes the updates from the queue one by one
+func (p *Pool) work(b *gotgbot.Bot, queue <-chan *gotgbot.Update) {
+	defer p.workers.Done()
+
+	for update := range queue {
+		if err := p.dispatcher.ProcessUpdate(b, update, nil); err != nil {
+			log.Errorf("Error processing update %d: %s", update.UpdateId, err)
+		}
+	}
+}
+
+// workerIndex returns the index of the worker that processes the update.
+// Updates without the chat, like inline queries, are distributed by the sender.
+func workerIndex(update *gotgbot.Update, size int) int {
+	ctx := ext.NewContext(update, nil)
+
+	var id int64
+	switch {
+	case ctx.EffectiveChat != nil:
+		id = ctx.EffectiveChat.Id
+	case ctx.EffectiveSender != nil:
+		id = ctx.EffectiveSender.Id()
+	default:
+		id = update.UpdateId
+	}
+
+	index := int(id % int64(size))
+	if index < 0 {
+		index += size
+	}
+
+	return index
+}
@@ -24,8 +24,10 @@ package i18n
 
 import (
 	"embed"
+	"fmt"
 	"github.com/op/go-logging"
 	"gopkg.in/yaml.v3"
+	"reflect"
 )
 
 //go:embed langs/*.yaml
@@ -33,6 +35,10 @@ var langsFS embed.FS
 
 var log = logging.MustGetLogger("i18n")
 
+// FallbackLanguage is the language used for the strings
+// that are missing in the partially translated languages
+const FallbackLanguage = "en"
+
 // LoadLangs loads all languages from given path.
 // All language files should have .yaml extension and be in langs/ directory
 func LoadLangs() (map[string]Language, error) {
@@ -54,12 +60,64 @@ func LoadLangs() (map[string]Language, error) {
 			return nil, err
 		}
 		fileName := langFile.Name()[0 : len(langFile.Name())-5]
+		lang.Code = fileName
 		obj[fileName] = *lang
 	}
 
+	// Fill missing strings from the fallback language
+	if fallback, ok := obj[FallbackLanguage]; ok {
+		for code, lang := range obj {
+			if code == FallbackLanguage {
+				continue
+			}
+
+			missing := fillMissing(reflect.ValueOf(&lang).Elem(), reflect.ValueOf(fallback), "")
+			if missing > 0 {
+				log.Warningf("Language %s is missing %d strings, using %s for them", code, missing, FallbackLanguage)
+			}
+			obj[code] = lang
+		}
+	} else {
+		log.Warningf("Fallback language %s not found", FallbackLanguage)
+	}
+
+	for code, lang := range obj {
+		if _, ok := pluralRules[lang.PluralRule]; !ok {
+			return nil, fmt.Errorf("unknown plural rule %q in language %s", lang.PluralRule, code)
+		}
+	}
+
 	return obj, nil
 }
 
+// fillMissing sets the empty strings of dst struct to the values from
+// fallback struct of the same type. Returns the number of filled strings.
+func fillMissing(dst reflect.Value, fallback reflect.Value, path string) int {
+	switch dst.Kind() {
+	case reflect.String:
+		if dst.String() == "" && fallback.String() != "" {
+			log.Debugf("Missing string: %s", path)
+			dst.SetString(fallback.String())
+			return 1
+		}
+		return 0
+
+	case reflect.Struct:
+		missing := 0
+		for i := 0; i < dst.NumField(); i++ {
+			name := dst.Type().Field(i).Tag.Get("yaml")
+			if path != "" {
+				name = path + "." + name
+			}
+			missing += fillMissing(dst.Field(i), fallback.Field(i), name)
+		}
+		return missing
+
+	default:
+		return 0
+	}
+}
+
 // loadLang loads language from given path.
 func loadLang(path string) (*Language, error) {
 	// Read file
@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package i18n
+
+import (
+	"strings"
+)
+
+// pluralRules contains the functions that return the index
+// of the plural form for the number, by the plural rule name
+// set in the language file
+var pluralRules = map[string]func(n int) int{
+	// 1 class, 2 classes
+	"one_other": func(n int) int {
+		if n == 1 || n == -1 {
+			return 0
+		}
+		return 1
+	},
+	// 1 пара, 2 пари, 5 пар, 11 пар, 21 пара
+	"east_slavic": func(n int) int {
+		if n < 0 {
+			n = -n
+		}
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return 1
+		default:
+			return 2
+		}
+	},
+}
+
+// Plural returns the form of the word for the number n.
+//
+// forms contains the forms of the word separated by "|", in the order
+// defined by the language plural rule, e.g. "пара|пари|пар" for Ukrainian.
+// If there are fewer forms than needed, the last one is used.
+func (l Language) Plural(n int, forms string) string {
+	rule, ok := pluralRules[l.PluralRule]
+	if !ok {
+		rule = pluralRules["one_other"]
+	}
+
+	split := strings.Split(forms, "|")
+	i := rule(n)
+	if i >= len(split) {
+		i = len(split) - 1
+	}
+
+	return split[i]
+}
@@ -35,87 +35,172 @@ func (e *LanguageNotFoundError) Error() string {
 // Language is a struct that contains all the localization
 // strings for a specific language needed for the bot.
 type Language struct {
+	// Code is the language code, like "uk", set from the file name
+	Code     string `yaml:"-"`
 	LangName string `yaml:"lang_name"`
-	Text     struct {
-		Yes                 string `yaml:"yes"`
-		No                  string `yaml:"no"`
-		TryIt               string `yaml:"try_it"`
-		NoThanks            string `yaml:"no_thanks"`
-		NotSelected         string `yaml:"not_selected"`
-		Unknown             string `yaml:"unknown"`
-		UnknownGroupName    string `yaml:"unknown_group_name"`
-		WeekDay1            string `yaml:"week_day.1"`
-		WeekDay2            string `yaml:"week_day.2"`
-		WeekDay3            string `yaml:"week_day.3"`
-		WeekDay4            string `yaml:"week_day.4"`
-		WeekDay5            string `yaml:"week_day.5"`
-		WeekDay6            string `yaml:"week_day.6"`
-		WeekDay7            string `yaml:"week_day.7"`
-		WeekDayUnknown      string `yaml:"week_day.unknown"`
-		ShortMonth1         string `yaml:"short_month.1"`
-		ShortMonth2         string `yaml:"short_month.2"`
-		ShortMonth3         string `yaml:"short_month.3"`
-		ShortMonth4         string `yaml:"short_month.4"`
-		ShortMonth5         string `yaml:"short_month.5"`
-		ShortMonth6         string `yaml:"short_month.6"`
-		ShortMonth7         string `yaml:"short_month.7"`
-		ShortMonth8         string `yaml:"short_month.8"`
-		ShortMonth9         string `yaml:"short_month.9"`
-		ShortMonth10        string `yaml:"short_month.10"`
-		ShortMonth11        string `yaml:"short_month.11"`
-		ShortMonth12        string `yaml:"short_month.12"`
-		ShortWeekDay1       string `yaml:"short_week_day.1"`
-		ShortWeekDay2       string `yaml:"short_week_day.2"`
-		ShortWeekDay3       string `yaml:"short_week_day.3"`
-		ShortWeekDay4       string `yaml:"short_week_day.4"`
-		ShortWeekDay5       string `yaml:"short_week_day.5"`
-		ShortWeekDay6       string `yaml:"short_week_day.6"`
-		ShortWeekDay7       string `yaml:"short_week_day.7"`
-		ShortWeekDayUnknown string `yaml:"short_week_day.unknown"`
-		TimeAgo             string `yaml:"time_ago"`
-		TimeSeconds         string `yaml:"time.seconds"`
-		TimeMinutes         string `yaml:"time.minutes"`
-		TimeHours           string `yaml:"time.hours"`
-		TimeDays            string `yaml:"time.days"`
-		ShortTimeSeconds    string `yaml:"short_time.seconds"`
-		ShortTimeMinutes    string `yaml:"short_time.minutes"`
-		ShortTimeHours      string `yaml:"short_time.hours"`
-		ShortTimeDays       string `yaml:"short_time.days"`
-		ScheduleDateFormat  string `yaml:"schedule_date_format"`
+	// PluralRule is the name of the rule used to choose the plural form
+	// of the word, see Language.Plural
+	PluralRule string `yaml:"plural_rule"`
+	Text       struct {
+		Yes                   string `yaml:"yes"`
+		No                    string `yaml:"no"`
+		TryIt                 string `yaml:"try_it"`
+		NoThanks              string `yaml:"no_thanks"`
+		NotSelected           string `yaml:"not_selected"`
+		Unknown               string `yaml:"unknown"`
+		UnknownGroupName      string `yaml:"unknown_group_name"`
+		WeekDay1              string `yaml:"week_day.1"`
+		WeekDay2              string `yaml:"week_day.2"`
+		WeekDay3              string `yaml:"week_day.3"`
+		WeekDay4              string `yaml:"week_day.4"`
+		WeekDay5              string `yaml:"week_day.5"`
+		WeekDay6              string `yaml:"week_day.6"`
+		WeekDay7              string `yaml:"week_day.7"`
+		WeekDayUnknown        string `yaml:"week_day.unknown"`
+		ShortMonth1           string `yaml:"short_month.1"`
+		ShortMonth2           string `yaml:"short_month.2"`
+		ShortMonth3           string `yaml:"short_month.3"`
+		ShortMonth4           string `yaml:"short_month.4"`
+		ShortMonth5           string `yaml:"short_month.5"`
+		ShortMonth6           string `yaml:"short_month.6"`
+		ShortMonth7           string `yaml:"short_month.7"`
+		ShortMonth8           string `yaml:"short_month.8"`
+		ShortMonth9           string `yaml:"short_month.9"`
+		ShortMonth10          string `yaml:"short_month.10"`
+		ShortMonth11          string `yaml:"short_month.11"`
+		ShortMonth12          string `yaml:"short_month.12"`
+		ShortWeekDay1         string `yaml:"short_week_day.1"`
+		ShortWeekDay2         string `yaml:"short_week_day.2"`
+		ShortWeekDay3         string `yaml:"short_week_day.3"`
+		ShortWeekDay4         string `yaml:"short_week_day.4"`
+		ShortWeekDay5         string `yaml:"short_week_day.5"`
+		ShortWeekDay6         string `yaml:"short_week_day.6"`
+		ShortWeekDay7         string `yaml:"short_week_day.7"`
+		ShortWeekDayUnknown   string `yaml:"short_week_day.unknown"`
+		TimeAgo               string `yaml:"time_ago"`
+		TimeSeconds           string `yaml:"time.seconds"`
+		TimeMinutes           string `yaml:"time.minutes"`
+		TimeHours             string `yaml:"time.hours"`
+		TimeDays              string `yaml:"time.days"`
+		ShortTimeSeconds      string `yaml:"short_time.seconds"`
+		ShortTimeMinutes      string `yaml:"short_time.minutes"`
+		ShortTimeHours        string `yaml:"short_time.hours"`
+		ShortTimeDays         string `yaml:"short_time.days"`
+		ScheduleDateFormat    string `yaml:"schedule_date_format"`
+		ShortDateFormat       string `yaml:"short_date_format"`
+		InlineNoGroup         string `yaml:"inline_no_group"`
+		InlineUsage           string `yaml:"inline_usage"`
+		OutdatedData          string `yaml:"outdated_data"`
+		ScheduleDiffAdded     string `yaml:"schedule_diff_added"`
+		ScheduleDiffRemoved   string `yaml:"schedule_diff_removed"`
+		ScheduleDiffMoved     string `yaml:"schedule_diff_moved"`
+		WeekOverviewDay       string `yaml:"week_overview_day"`
+		WeekOverviewFreeDay   string `yaml:"week_overview_free_day"`
+		FreeRoomsNone         string `yaml:"free_rooms_none"`
+		PluralClasses         string `yaml:"plural.classes"`
+		PluralChats           string `yaml:"plural.chats"`
+		SemesterWeek          string `yaml:"semester_week"`
+		Numerator             string `yaml:"numerator"`
+		Denominator           string `yaml:"denominator"`
+		AdminApiStats         string `yaml:"admin_api_stats"`
+		LessonDetailsType     string `yaml:"lesson_details.type"`
+		LessonDetailsRoom     string `yaml:"lesson_details.room"`
+		LessonDetailsBuilding string `yaml:"lesson_details.building"`
+		LessonDetailsTeacher  string `yaml:"lesson_details.teacher"`
+		OccupiedRoom          string `yaml:"occupied_room"`
+		OccupiedRoomsNone     string `yaml:"occupied_rooms_none"`
+		BroadcastErrors       string `yaml:"broadcast_errors"`
+		BroadcastError        string `yaml:"broadcast_error"`
+		ScheduleBreak         string `yaml:"schedule_break"`
+		UsageStatsToday       string `yaml:"usage_stats_today"`
+		UsageStatsWeek        string `yaml:"usage_stats_week"`
+		UsageStatsMonth       string `yaml:"usage_stats_month"`
+		UsageStatsPeriod      string `yaml:"usage_stats_period"`
+		UsageStatsNoGroups    string `yaml:"usage_stats_no_groups"`
 	} `yaml:"text"`
 	Button struct {
-		ClearCache                     string `yaml:"clear_cache"`
-		GetLogs                        string `yaml:"get_logs"`
-		ClearLogs                      string `yaml:"clear_logs"`
-		AdminPanel                     string `yaml:"admin_panel"`
-		CallsSchedule                  string `yaml:"calls_schedule"`
-		TimeLeft                       string `yaml:"time_left"`
-		WriteMe                        string `yaml:"write_me"`
-		ClosePage                      string `yaml:"close_page"`
-		Menu                           string `yaml:"menu"`
-		Back                           string `yaml:"back"`
-		More                           string `yaml:"more"`
-		Info                           string `yaml:"info"`
-		Refresh                        string `yaml:"refresh"`
-		SelectGroup                    string `yaml:"select_group"`
-		SelectLang                     string `yaml:"select_lang"`
-		OpenSchedule                   string `yaml:"open_schedule"`
-		StudentsList                   string `yaml:"students_list"`
-		Settings                       string `yaml:"settings"`
-		SettingClNotif15m              string `yaml:"setting.cl_notif_15m"`
-		SettingClNotif1m               string `yaml:"setting.cl_notif_1m"`
-		SettingClNotifNextPart         string `yaml:"setting.cl_notif_next_part"`
-		Schedule                       string `yaml:"schedule"`
-		ScheduleToday                  string `yaml:"schedule.today"`
-		ScheduleTomorrow               string `yaml:"schedule.tomorrow"`
-		ScheduleWeek                   string `yaml:"schedule.week"`
-		ScheduleNextWeek               string `yaml:"schedule.next_week"`
-		ScheduleExtra                  string `yaml:"schedule.extra"`
-		ScheduleNavigationToday        string `yaml:"schedule_navigation.today"`
-		ScheduleNavigationNextDay      string `yaml:"schedule_navigation.next_day"`
-		ScheduleNavigationNextWeek     string `yaml:"schedule_navigation.next_week"`
-		ScheduleNavigationPreviousDay  string `yaml:"schedule_navigation.previous_day"`
-		ScheduleNavigationPreviousWeek string `yaml:"schedule_navigation.previous_week"`
+		ClearCache                          string `yaml:"clear_cache"`
+		GetLogs                             string `yaml:"get_logs"`
+		ClearLogs                           string `yaml:"clear_logs"`
+		AdminPanel                          string `yaml:"admin_panel"`
+		CallsSchedule                       string `yaml:"calls_schedule"`
+		TimeLeft                            string `yaml:"time_left"`
+		WriteMe                             string `yaml:"write_me"`
+		ClosePage                           string `yaml:"close_page"`
+		Menu                                string `yaml:"menu"`
+		Back                                string `yaml:"back"`
+		More                                string `yaml:"more"`
+		Info                                string `yaml:"info"`
+		Refresh                             string `yaml:"refresh"`
+		SelectGroup                         string `yaml:"select_group"`
+		SelectLang                          string `yaml:"select_lang"`
+		OpenSchedule                        string `yaml:"open_schedule"`
+		StudentsList                        string `yaml:"students_list"`
+		CalendarExport                      string `yaml:"calendar_export"`
+		Settings                            string `yaml:"settings"`
+		SettingClNotif15m                   string `yaml:"setting.cl_notif_15m"`
+		SettingClNotif1m                    string `yaml:"setting.cl_notif_1m"`
+		SettingClNotifNextPart              string `yaml:"setting.cl_notif_next_part"`
+		Schedule                            string `yaml:"schedule"`
+		ScheduleToday                       string `yaml:"schedule.today"`
+		ScheduleTomorrow                    string `yaml:"schedule.tomorrow"`
+		ScheduleWeek                        string `yaml:"schedule.week"`
+		ScheduleNextWeek                    string `yaml:"schedule.next_week"`
+		ScheduleExtra                       string `yaml:"schedule.extra"`
+		ScheduleNavigationToday             string `yaml:"schedule_navigation.today"`
+		ScheduleNavigationNextDay           string `yaml:"schedule_navigation.next_day"`
+		ScheduleNavigationNextWeek          string `yaml:"schedule_navigation.next_week"`
+		ScheduleNavigationPreviousDay       string `yaml:"schedule_navigation.previous_day"`
+		ScheduleNavigationPreviousWeek      string `yaml:"schedule_navigation.previous_week"`
+		ScheduleNavigationWeekView          string `yaml:"schedule_navigation.week_view"`
+		ScheduleNavigationDayView           string `yaml:"schedule_navigation.day_view"`
+		SnoozeReminder                      string `yaml:"snooze_reminder"`
+		SettingClReminder                   string `yaml:"setting.cl_reminder"`
+		SettingReminderOffset               string `yaml:"setting.reminder_offset"`
+		TeacherSchedule                     string `yaml:"teacher_schedule"`
+		SettingMorningSchedule              string `yaml:"setting.morning_schedule"`
+		DailySchedule                       string `yaml:"daily_schedule"`
+		SettingEveningSchedule              string `yaml:"setting.evening_schedule"`
+		SettingDailyScheduleEmpty           string `yaml:"setting.daily_schedule_empty"`
+		InlineSetup                         string `yaml:"inline_setup"`
+		SettingNotifyChanges                string `yaml:"setting.notify_changes"`
+		Exams                               string `yaml:"exams"`
+		SettingSettingsLock                 string `yaml:"setting.settings_lock"`
+		SettingOwnGroup                     string `yaml:"setting.own_group"`
+		ScheduleNavigationWeekOverview      string `yaml:"schedule_navigation.week_overview"`
+		NextLesson                          string `yaml:"next_lesson"`
+		FreeRooms                           string `yaml:"free_rooms"`
+		Timezone                            string `yaml:"timezone"`
+		SavedGroups                         string `yaml:"saved_groups"`
+		RemoveSavedGroups                   string `yaml:"remove_saved_groups"`
+		DatePicker                          string `yaml:"date_picker"`
+		ShareSchedule                       string `yaml:"share_schedule"`
+		ScheduleNavigationPreviousSchoolDay string `yaml:"schedule_navigation.previous_school_day"`
+		ScheduleNavigationNextSchoolDay     string `yaml:"schedule_navigation.next_school_day"`
+		OnlineLesson                        string `yaml:"online_lesson"`
+		GroupSearch                         string `yaml:"group_search"`
+		CopyLesson                          string `yaml:"copy_lesson"`
+		BackToMenu                          string `yaml:"back_to_menu"`
+		ShareGroup                          string `yaml:"share_group"`
+		AddSavedGroup                       string `yaml:"add_saved_group"`
+		SwitchGroup                         string `yaml:"switch_group"`
+		WebApp                              string `yaml:"webapp"`
+		OpenWebApp                          string `yaml:"open_webapp"`
+		SettingHideEmptyLessons             string `yaml:"setting.hide_empty_lessons"`
+		HideLesson                          string `yaml:"hide_lesson"`
+		UnhideLesson                        string `yaml:"unhide_lesson"`
+		HiddenLessons                       string `yaml:"hidden_lessons"`
+		SettingStrikeHiddenLessons          string `yaml:"setting.strike_hidden_lessons"`
+		SemesterOverview                    string `yaml:"semester_overview"`
+		SettingCleanupPages                 string `yaml:"setting.cleanup_pages"`
+		RemindLesson                        string `yaml:"remind_lesson"`
+		CancelLessonReminder                string `yaml:"cancel_lesson_reminder"`
+		OtherTimezone                       string `yaml:"other_timezone"`
+		UsageStatsCsv                       string `yaml:"usage_stats_csv"`
+		GroupChatSetup                      string `yaml:"group_chat_setup"`
+		PaginationPrevious                  string `yaml:"pagination.previous"`
+		PaginationNext                      string `yaml:"pagination.next"`
+		TeacherSearch                       string `yaml:"teacher_search"`
 	} `yaml:"button"`
 	Alert struct {
 		Done                     string `yaml:"done"`
@@ -124,9 +209,29 @@ type Language struct {
 		ClNotifEnabledTooltip    string `yaml:"cl_notif_enabled_tooltip"`
 		MessageTooOld            string `yaml:"message_too_old"`
 		FloodControl             string `yaml:"flood_control"`
+		ReminderSnoozed          string `yaml:"reminder_snoozed"`
+		ScheduleUpToDate         string `yaml:"schedule_up_to_date"`
+		SettingsLocked           string `yaml:"settings_locked"`
+		NotChatAdmin             string `yaml:"not_chat_admin"`
+		ButtonOutdated           string `yaml:"button_outdated"`
+		InvalidTimezone          string `yaml:"invalid_timezone"`
+		TooManyRequests          string `yaml:"too_many_requests"`
+		NoLessonsNextMonth       string `yaml:"no_lessons_next_month"`
+		NoLessonsPreviousMonth   string `yaml:"no_lessons_previous_month"`
+		NoEarlierData            string `yaml:"no_earlier_data"`
+		LessonNotFound           string `yaml:"lesson_not_found"`
+		MessageCantBeEdited      string `yaml:"message_cant_be_edited"`
+		WebAppPrivateOnly        string `yaml:"webapp_private_only"`
+		HiddenLessonsFull        string `yaml:"hidden_lessons_full"`
+		LessonReminderSet        string `yaml:"lesson_reminder_set"`
+		LessonReminderCancelled  string `yaml:"lesson_reminder_cancelled"`
+		LessonReminderTooLate    string `yaml:"lesson_reminder_too_late"`
+		LessonRemindersFull      string `yaml:"lesson_reminders_full"`
+		TooFast                  string `yaml:"too_fast"`
+		SearchOutdated           string `yaml:"search_outdated"`
 	} `yaml:"alert"`
 	Page struct {
-		Greeting                      string `yaml:"greeting"`
+		Onboarding                    string `yaml:"onboarding"`
 		StructureSelection            string `yaml:"structure_selection"`
 		FacultySelection              string `yaml:"faculty_selection"`
 		CourseSelection               string `yaml:"course_selection"`
@@ -139,6 +244,11 @@ type Language struct {
 		Info                          string `yaml:"info"`
 		ApiUnavailable                string `yaml:"api_unavailable"`
 		CallSchedule                  string `yaml:"call_schedule"`
+		CallScheduleBefore            string `yaml:"call_schedule.before"`
+		CallScheduleDuring            string `yaml:"call_schedule.during"`
+		CallScheduleBreak             string `yaml:"call_schedule.break"`
+		CallScheduleOver              string `yaml:"call_schedule.over"`
+		CallScheduleWeekend           string `yaml:"call_schedule.weekend"`
 		AdminPanel                    string `yaml:"admin_panel"`
 		LeftToClassesStart            string `yaml:"left.to_classes_start"`
 		LeftToLessonStart             string `yaml:"left.to_lesson_start"`
@@ -148,11 +258,106 @@ type Language struct {
 		ScheduleExtraInfo             string `yaml:"schedule.extra_info"`
 		ScheduleEmptyDay              string `yaml:"schedule.empty_day"`
 		ScheduleMultipleEmptyDays     string `yaml:"schedule.multiple_empty_days"`
+		ScheduleWeek                  string `yaml:"schedule.week"`
+		ScheduleEmptyWeek             string `yaml:"schedule.empty_week"`
 		NotificationFeatureSuggestion string `yaml:"notification_feature_suggestion"`
 		ClassesNotification           string `yaml:"classes_notification"`
 		ClassesNotificationNextPart   string `yaml:"classes_notification_next_part"`
 		Error                         string `yaml:"error"`
 		Forbidden                     string `yaml:"forbidden"`
 		NotFound                      string `yaml:"not_found"`
+		CalendarExport                string `yaml:"calendar_export"`
+		ClassReminder                 string `yaml:"class_reminder"`
+		ChairSelection                string `yaml:"chair_selection"`
+		TeacherSelection              string `yaml:"teacher_selection"`
+		WeekExport                    string `yaml:"week_export"`
+		DailyScheduleSettings         string `yaml:"daily_schedule_settings"`
+		NoClassesToday                string `yaml:"no_classes_today"`
+		NoClassesTomorrow             string `yaml:"no_classes_tomorrow"`
+		InlineNoGroup                 string `yaml:"inline_no_group"`
+		InlineUsage                   string `yaml:"inline_usage"`
+		TeacherSearch                 string `yaml:"teacher_search"`
+		TeacherSearchEmpty            string `yaml:"teacher_search_empty"`
+		TeacherSearchUsage            string `yaml:"teacher_search_usage"`
+		ScheduleDiff                  string `yaml:"schedule_diff"`
+		TeacherSearchTooMany          string `yaml:"teacher_search_too_many"`
+		ExamSchedule                  string `yaml:"exam_schedule"`
+		NoExamSession                 string `yaml:"no_exam_session"`
+		NextLesson                    string `yaml:"next_lesson"`
+		NextLessonUnknown             string `yaml:"next_lesson_unknown"`
+		NextLessonBreak               string `yaml:"next_lesson_break"`
+		NextLessonDone                string `yaml:"next_lesson_done"`
+		FreeRoomsBuilding             string `yaml:"free_rooms_building"`
+		FreeRoomsLesson               string `yaml:"free_rooms_lesson"`
+		FreeRooms                     string `yaml:"free_rooms"`
+		FreeRoomsUnavailable          string `yaml:"free_rooms_unavailable"`
+		Backup                        string `yaml:"backup"`
+		RestoreUsage                  string `yaml:"restore_usage"`
+		RestoreInvalid                string `yaml:"restore_invalid"`
+		RestoreInvalidGroup           string `yaml:"restore_invalid_group"`
+		RestoreDone                   string `yaml:"restore_done"`
+		BroadcastUsage                string `yaml:"broadcast_usage"`
+		BroadcastStarted              string `yaml:"broadcast_started"`
+		BroadcastRunning              string `yaml:"broadcast_running"`
+		BroadcastNotRunning           string `yaml:"broadcast_not_running"`
+		BroadcastCancelling           string `yaml:"broadcast_cancelling"`
+		BroadcastDone                 string `yaml:"broadcast_done"`
+		BroadcastCancelled            string `yaml:"broadcast_cancelled"`
+		Timezone                      string `yaml:"timezone"`
+		SavedGroups                   string `yaml:"saved_groups"`
+		SavedGroupsEmpty              string `yaml:"saved_groups_empty"`
+		SavedGroupsFull               string `yaml:"saved_groups_full"`
+		RemoveSavedGroups             string `yaml:"remove_saved_groups"`
+		AddGroupUsage                 string `yaml:"add_group_usage"`
+		ApiDown                       string `yaml:"api_down"`
+		DatePicker                    string `yaml:"date_picker"`
+		DeepLinkConfirm               string `yaml:"deep_link_confirm"`
+		ShareSchedule                 string `yaml:"share_schedule"`
+		ScheduleHoliday               string `yaml:"schedule.holiday"`
+		AddHolidayUsage               string `yaml:"add_holiday_usage"`
+		HolidayAdded                  string `yaml:"holiday_added"`
+		GroupSearch                   string `yaml:"group_search"`
+		GroupSearchEmpty              string `yaml:"group_search_empty"`
+		GroupSearchPrompt             string `yaml:"group_search_prompt"`
+		IdentifyUsage                 string `yaml:"identify_usage"`
+		IdentifyNotFound              string `yaml:"identify_not_found"`
+		IdentifyGroups                string `yaml:"identify_groups"`
+		RoomsCollecting               string `yaml:"rooms_collecting"`
+		FreeRoomsNotConfigured        string `yaml:"free_rooms_not_configured"`
+		ChatNotFound                  string `yaml:"chat_not_found"`
+		ShareGroup                    string `yaml:"share_group"`
+		WebApp                        string `yaml:"webapp"`
+		FindGroup                     string `yaml:"find_group"`
+		FindGroupEmpty                string `yaml:"find_group_empty"`
+		FindGroupUsage                string `yaml:"find_group_usage"`
+		HiddenLessons                 string `yaml:"hidden_lessons"`
+		HiddenLessonsEmpty            string `yaml:"hidden_lessons_empty"`
+		SemesterOverview              string `yaml:"semester_overview"`
+		SemesterOverviewLoading       string `yaml:"semester_overview_loading"`
+		SemesterPublished             string `yaml:"semester_published"`
+		LessonReminder                string `yaml:"lesson_reminder"`
+		LessonReminderRemoved         string `yaml:"lesson_reminder_removed"`
+		TimezonePrompt                string `yaml:"timezone_prompt"`
+		TimezoneInvalid               string `yaml:"timezone_invalid"`
+		UsageStats                    string `yaml:"usage_stats"`
+		GroupChatSetup                string `yaml:"group_chat_setup"`
+		ExportUsage                   string `yaml:"export_usage"`
+		TeacherSearchQuery            string `yaml:"teacher_search_query"`
 	} `yaml:"page"`
+	Command struct {
+		Today     string `yaml:"today"`
+		Tomorrow  string `yaml:"tomorrow"`
+		Next      string `yaml:"next"`
+		Left      string `yaml:"left"`
+		Calls     string `yaml:"calls"`
+		Teacher   string `yaml:"teacher"`
+		Rooms     string `yaml:"rooms"`
+		Students  string `yaml:"students"`
+		Calendar  string `yaml:"calendar"`
+		Group     string `yaml:"group"`
+		Identify  string `yaml:"identify"`
+		Settings  string `yaml:"settings"`
+		Lang      string `yaml:"lang"`
+		FindGroup string `yaml:"findgroup"`
+	} `yaml:"command"`
 }
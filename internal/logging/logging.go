@@ -24,73 +24,78 @@ package logging
 
 import (
 	"github.com/op/go-logging"
+	"io"
+	"log/slog"
 	"os"
 )
 
-var fileFormat = logging.MustStringFormatter(
-	`%{time:2006-01-02 15:04:05.000} %{level} %{module}: %{message}`,
-)
-
-var cmdFormat = logging.MustStringFormatter(
-	`%{color}%{time:15:04:05.000} %{level} %{module} ▶%{color:reset} %{message}`,
-)
-
 const LogFilePath = "debug.log"
 
 var LogFile *os.File
 
-// Init initializes logging system
+// LevelCritical is the slog level of the go-logging CRITICAL records
+const LevelCritical = slog.LevelError + 4
+
+// levels are the slog levels of the LOG_LEVEL values
+var levels = map[string]slog.Level{
+	"DEBUG":    slog.LevelDebug,
+	"INFO":     slog.LevelInfo,
+	"WARNING":  slog.LevelWarn,
+	"ERROR":    slog.LevelError,
+	"CRITICAL": LevelCritical,
+}
+
+// Init initializes logging system.
+//
+// The logs are written by the slog handler, the JSON one if LOG_FORMAT is json.
+// It's the slog default logger, and the go-logging loggers write to it as well.
 func Init() error {
 	logLevel := os.Getenv("LOG_LEVEL")
 	if logLevel == "" {
 		logLevel = "DISABLED"
 	}
 
-	// Disable logging if needed
-	if logLevel == "DISABLED" {
-		backend := logging.NewLogBackend(os.Stderr, "", 0)
-		backendLeveled := logging.AddModuleLevel(backend)
-		backendLeveled.SetLevel(logging.INFO, "")
-		logging.SetBackend(backendLeveled)
-		return nil
-	}
+	// Log only to the console if the logging is disabled
+	out := io.Writer(os.Stderr)
+	level := slog.LevelInfo
+	if logLevel != "DISABLED" {
+		// Open log file
+		var err error
+		LogFile, err = os.OpenFile(LogFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
 
-	// Open log file
-	var err error
-	LogFile, err = os.OpenFile(LogFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
+		out = io.MultiWriter(LogFile, os.Stderr)
+		level = levels[logLevel]
 	}
 
-	// Create file and cmd loggers
-	fsBackend := logging.NewLogBackend(LogFile, "", 0)
-	fsBackendFormatter := logging.NewBackendFormatter(fsBackend, fileFormat)
-	fsBackendLeveled := logging.AddModuleLevel(fsBackendFormatter)
-
-	cmdBackend := logging.NewLogBackend(os.Stderr, "", 0)
-	cmdBackendFormatter := logging.NewBackendFormatter(cmdBackend, cmdFormat)
-	cmdBackendLeveled := logging.AddModuleLevel(cmdBackendFormatter)
-
-	// Set log level
-	switch logLevel {
-	case "DEBUG":
-		fsBackendLeveled.SetLevel(logging.DEBUG, "")
-		cmdBackendLeveled.SetLevel(logging.DEBUG, "")
-	case "INFO":
-		fsBackendLeveled.SetLevel(logging.INFO, "")
-		cmdBackendLeveled.SetLevel(logging.INFO, "")
-	case "WARNING":
-		fsBackendLeveled.SetLevel(logging.WARNING, "")
-		cmdBackendLeveled.SetLevel(logging.WARNING, "")
-	case "ERROR":
-		fsBackendLeveled.SetLevel(logging.ERROR, "")
-		cmdBackendLeveled.SetLevel(logging.ERROR, "")
-	case "CRITICAL":
-		fsBackendLeveled.SetLevel(logging.CRITICAL, "")
-		cmdBackendLeveled.SetLevel(logging.CRITICAL, "")
+	opts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: replaceLevel,
+	}
+	var handler slog.Handler
+	if os.Getenv("LOG_FORMAT") == "json" {
+		handler = slog.NewJSONHandler(out, opts)
+	} else {
+		handler = slog.NewTextHandler(out, opts)
 	}
+	slog.SetDefault(slog.New(handler))
 
-	logging.SetBackend(fsBackendLeveled, cmdBackendLeveled)
+	// The handler filters the records by the level
+	backend := logging.AddModuleLevel(newSlogBackend(handler))
+	backend.SetLevel(logging.DEBUG, "")
+	logging.SetBackend(backend)
 
 	return nil
 }
+
+// replaceLevel names the LevelCritical records CRITICAL instead of ERROR+4
+func replaceLevel(_ []string, attr slog.Attr) slog.Attr {
+	if attr.Key == slog.LevelKey {
+		if level, ok := attr.Value.Any().(slog.Level); ok && level == LevelCritical {
+			attr.Value = slog.StringValue("CRITICAL")
+		}
+	}
+	return attr
+}
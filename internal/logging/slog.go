@@ -0,0 +1,70 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package logging
+
+import (
+	"context"
+	"github.com/op/go-logging"
+	"log/slog"
+	"strings"
+)
+
+// slogBackend passes the go-logging records to the slog handler,
+// so the packages that use go-logging write the logs in the same format.
+// The logger module is written as the "module" attribute.
+type slogBackend struct {
+	handler slog.Handler
+}
+
+func newSlogBackend(handler slog.Handler) *slogBackend {
+	return &slogBackend{handler: handler}
+}
+
+func (b *slogBackend) Log(level logging.Level, _ int, rec *logging.Record) error {
+	slogLevel := slogLevel(level)
+
+	ctx := context.Background()
+	if !b.handler.Enabled(ctx, slogLevel) {
+		return nil
+	}
+
+	record := slog.NewRecord(rec.Time, slogLevel, strings.TrimRight(rec.Message(), "\n"), 0)
+	record.AddAttrs(slog.String("module", rec.Module))
+	return b.handler.Handle(ctx, record)
+}
+
+// slogLevel returns the slog level of the go-logging level
+func slogLevel(level logging.Level) slog.Level {
+	switch level {
+	case logging.CRITICAL:
+		return LevelCritical
+	case logging.ERROR:
+		return slog.LevelError
+	case logging.WARNING:
+		return slog.LevelWarn
+	case logging.NOTICE, logging.INFO:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
@@ -0,0 +1,254 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package notifier
+
+import (
+	"errors"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/errorhandler"
+	"github.com/cubicbyte/dteubot/internal/dteubot/lifecycle"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DailyScheduleClaimTimeout is how long the daily schedule claimed
+// for sending is not sent by the other bot instances. If it is not
+// marked as sent in this time, e.g. the bot crashed, it is sent again.
+const DailyScheduleClaimTimeout = 5 * time.Minute
+
+// dailyScheduleFailures are the daily schedules that failed to send and are retried
+var dailyScheduleFailures = newFailureStreaks()
+
+// SendDailySchedules sends the schedule to chats that subscribed to the daily
+// schedule and whose chosen time has come.
+//
+// kind is "morning" for today's schedule or "evening" for tomorrow's one.
+//
+// Called every minute. The chosen time is in the chat timezone.
+// The schedule is claimed before sending and marked as sent after it, so
+// it's sent only once a day, even after the bot restart. The claims of the
+// schedules that were not sent, e.g. if the bot crashed, expire and are sent again.
+func SendDailySchedules(kind string, chatRepo data.ChatRepository, api api2.Api, bot *gotgbot.Bot, langs map[string]i18n.Language) {
+	chats, err := GetDailyScheduleChats(kind, chatRepo)
+	if err != nil {
+		log.Errorf("Error getting chats with enabled %s schedule: %s", kind, err)
+		return
+	}
+
+	// Schedules of the groups by date, to not request the same schedule twice
+	schedules := make(map[string]*api2.TimeTableDate)
+
+	sentCount := 0
+	for _, chat := range chats {
+		loc := utils.ChatLocation(chat)
+		curTime := time.Now().In(loc)
+		date := curTime.Format(time.DateOnly)
+
+		// Date of the schedule to send
+		scheduleDate := date
+		if kind == "evening" {
+			scheduleDate = curTime.AddDate(0, 0, 1).Format(time.DateOnly)
+		}
+
+		sendTime, sentDate := getDailyScheduleSettings(kind, chat)
+		if chat.GroupId == -1 || !chat.Accessible || sentDate == date {
+			continue
+		}
+
+		sendTime2, err := time.ParseInLocation("2006-01-02 15:04", date+" "+sendTime, loc)
+		if err != nil {
+			log.Errorf("Error parsing %s schedule time for chat %d: %s", kind, chat.Id, err)
+			continue
+		}
+		if curTime.Before(sendTime2) {
+			continue
+		}
+
+		scheduleKey := strconv.Itoa(chat.GroupId) + " " + scheduleDate
+		schedule, ok := schedules[scheduleKey]
+		if !ok {
+			schedule, err = api.GetGroupScheduleDay(chat.GroupId, scheduleDate)
+			if err != nil {
+				// Check if api connection error or the api is down
+				var urlError *url.Error
+				if errors.As(err, &urlError) || errors.Is(err, api2.ErrAPIUnavailable) {
+					log.Warningf("Error getting result from API for chat %d: %s", chat.Id, err)
+					continue
+				}
+
+				log.Errorf("Error getting group schedule day for chat %d: %s", chat.Id, err)
+				continue
+			}
+			schedules[scheduleKey] = schedule
+		}
+
+		// Claim the schedule before sending it,
+		// so the overlapping bot instances don't send it twice
+		claimed, err := claimDailySchedule(kind, chat, chatRepo, date)
+		if err != nil {
+			log.Errorf("Error claiming %s schedule for chat %d: %s", kind, chat.Id, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		// Don't disturb the chat if there are no lessons
+		noLessons := pages.IsNoLessons(schedule)
+		if noLessons && !chat.DailyScheduleEmpty {
+			completeDailySchedule(kind, chat, chatRepo, date)
+			continue
+		}
+
+		lang, err := utils.GetLang(chat.LanguageCode, langs)
+		if err != nil {
+			log.Errorf("Error getting language for chat %d: %s", chat.Id, err)
+			continue
+		}
+
+		var page pages.Page
+		if noLessons {
+			page, err = pages.CreateNoClassesPage(lang, scheduleDate, kind == "evening")
+		} else {
+			page, err = pages.CreateSchedulePage(lifecycle.Context(), lang, chat.GroupId, nil, scheduleDate, loc, chat.Id, pages.ChatScheduleView(chat))
+		}
+		failureKey := kind + " " + strconv.FormatInt(chat.Id, 10)
+		if err != nil {
+			log.Errorf("Error creating %s schedule page for chat %d: %s", kind, chat.Id, err)
+			if dailyScheduleFailures.Fail(failureKey) {
+				errorhandler.SendErrorToTelegram(err, bot)
+			}
+			continue
+		}
+
+		// Not completed claim expires, so the schedule is sent again later
+		if err := SendDailySchedule(chat, chatRepo, bot, page); err != nil {
+			log.Warningf("Error sending %s schedule to chat %d: %s", kind, chat.Id, err)
+			if dailyScheduleFailures.Fail(failureKey) {
+				errorhandler.SendErrorToTelegram(err, bot)
+			}
+			continue
+		}
+		dailyScheduleFailures.Reset(failureKey)
+		completeDailySchedule(kind, chat, chatRepo, date)
+
+		sentCount++
+	}
+
+	if sentCount != 0 {
+		log.Infof("Sent %s schedule to %d chats", kind, sentCount)
+	}
+}
+
+// SendDailySchedule sends daily schedule page to chat
+func SendDailySchedule(chat *data.Chat, chatRepo data.ChatRepository, bot *gotgbot.Bot, page pages.Page) error {
+	log.Debugf("Sending daily schedule to chat %d", chat.Id)
+
+	opts := page.CreateSendMessageOpts()
+	_, err := bot.SendMessage(chat.Id, page.Text, &opts)
+	if err != nil {
+		// Check if user blocked bot
+		var tgError *gotgbot.TelegramError
+		if errors.As(err, &tgError) && tgError.Code == 403 {
+			log.Infof("Bot blocked in chat %d", chat.Id)
+			chat.MorningSchedule = false
+			chat.EveningSchedule = false
+			if err = MakeChatUnavailable(chat, chatRepo); err != nil {
+				log.Errorf("Error making chat %d unavailable: %s", chat.Id, err)
+				errorhandler.SendErrorToTelegram(err, bot)
+			}
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// GetDailyScheduleChats returns chats subscribed to the daily schedule of the given kind
+func GetDailyScheduleChats(kind string, chatRepo data.ChatRepository) ([]*data.Chat, error) {
+	switch kind {
+	case "morning":
+		return chatRepo.GetChatsWithEnabledMorningSchedule()
+	case "evening":
+		return chatRepo.GetChatsWithEnabledEveningSchedule()
+	default:
+		return nil, errors.New("invalid daily schedule kind: " + kind)
+	}
+}
+
+// getDailyScheduleSettings returns the send time and the last sent date
+// of the daily schedule of the given kind
+func getDailyScheduleSettings(kind string, chat *data.Chat) (string, string) {
+	if kind == "evening" {
+		if chat.EveningScheduleTime == "" {
+			return data.DefaultEveningScheduleTime, chat.EveningScheduleSent
+		}
+		return chat.EveningScheduleTime, chat.EveningScheduleSent
+	}
+
+	if chat.MorningScheduleTime == "" {
+		return data.DefaultMorningScheduleTime, chat.MorningScheduleSent
+	}
+	return chat.MorningScheduleTime, chat.MorningScheduleSent
+}
+
+// claimDailySchedule marks the daily schedule of the given kind as being sent.
+//
+// Returns false if it was already sent or is being sent by another bot instance.
+func claimDailySchedule(kind string, chat *data.Chat, chatRepo data.ChatRepository, date string) (bool, error) {
+	var claimed bool
+	var err error
+	if kind == "evening" {
+		claimed, err = chatRepo.ClaimEveningSchedule(chat.Id, date, DailyScheduleClaimTimeout)
+		chat.EveningScheduleClaimed = time.Now().Unix()
+	} else {
+		claimed, err = chatRepo.ClaimMorningSchedule(chat.Id, date, DailyScheduleClaimTimeout)
+		chat.MorningScheduleClaimed = time.Now().Unix()
+	}
+
+	return claimed, err
+}
+
+// completeDailySchedule marks the claimed daily schedule of the given kind as sent
+func completeDailySchedule(kind string, chat *data.Chat, chatRepo data.ChatRepository, date string) {
+	var err error
+	if kind == "evening" {
+		err = chatRepo.CompleteEveningSchedule(chat.Id, date)
+		chat.EveningScheduleSent = date
+	} else {
+		err = chatRepo.CompleteMorningSchedule(chat.Id, date)
+		chat.MorningScheduleSent = date
+	}
+
+	if err != nil {
+		log.Errorf("Error marking %s schedule as sent for chat %d: %s", kind, chat.Id, err)
+	}
+}
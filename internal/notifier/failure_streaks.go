@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package notifier
+
+import "sync"
+
+// failureStreaks remembers the failing notifications that are retried
+// on every run, so their error is reported to the admins only once,
+// and not every minute until the notification is sent
+type failureStreaks struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func newFailureStreaks() *failureStreaks {
+	return &failureStreaks{keys: make(map[string]bool)}
+}
+
+// Fail records the failure of the notification.
+// Returns true if it's the first failure since the notification was last sent.
+func (f *failureStreaks) Fail(key string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.keys[key] {
+		return false
+	}
+	f.keys[key] = true
+	return true
+}
+
+// Reset ends the failure streak of the sent notification
+func (f *failureStreaks) Reset(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.keys, key)
+}
@@ -0,0 +1,276 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package notifier
+
+import (
+	"errors"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/errorhandler"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/cubicbyte/dteubot/internal/scheduler"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SendLessonReminders sends the one-shot lesson reminders that are due.
+//
+// Called every minute. Sent reminders are removed from the chat, and so are
+// the reminders of the lessons that already started, e.g. while the bot was down.
+// If the lesson is no longer on the schedule, the chat is notified instead.
+func SendLessonReminders(chatRepo data.ChatRepository, api api2.Api, bot *gotgbot.Bot, langs map[string]i18n.Language, calls api2.CallSchedule) {
+	chats, err := chatRepo.GetChatsWithLessonReminders()
+	if err != nil {
+		log.Errorf("Error getting chats with lesson reminders: %s", err)
+		return
+	}
+
+	loc, err := time.LoadLocation(Location)
+	if err != nil {
+		log.Errorf("Error loading location: %s", err)
+		return
+	}
+
+	now := time.Now().In(loc)
+
+	// Schedules of the group days, to not request the same day twice
+	schedules := make(map[string]*api2.TimeTableDate)
+
+	sentCount := 0
+	for _, chat := range chats {
+		if !chat.Accessible {
+			continue
+		}
+
+		lang, err := utils.GetLang(chat.LanguageCode, langs)
+		if err != nil {
+			log.Errorf("Error getting language for chat %d: %s", chat.Id, err)
+			continue
+		}
+
+		handled := make([]data.LessonReminder, 0)
+		for _, reminder := range chat.LessonReminders {
+			if reminder.Time > now.Unix() {
+				continue
+			}
+
+			key := strconv.Itoa(reminder.GroupId) + "/" + reminder.Date
+			schedule, ok := schedules[key]
+			if !ok {
+				schedule, err = api.GetGroupScheduleDay(reminder.GroupId, reminder.Date)
+				if err != nil {
+					// Check if api connection error or the api is down.
+					// The reminder is kept and sent on the next run
+					var urlError *url.Error
+					if errors.As(err, &urlError) || errors.Is(err, api2.ErrAPIUnavailable) {
+						log.Warningf("Error getting result from API for chat %d: %s", chat.Id, err)
+						continue
+					}
+
+					log.Errorf("Error getting group schedule day for chat %d: %s", chat.Id, err)
+					continue
+				}
+				schedules[key] = schedule
+			}
+
+			sent, err := sendLessonReminder(chat, chatRepo, lang, bot, schedule, calls, reminder, now)
+			if err != nil {
+				log.Warningf("Error sending lesson reminder to chat %d: %s", chat.Id, err)
+				errorhandler.SendErrorToTelegram(err, bot)
+			}
+			handled = append(handled, reminder)
+
+			if !sent {
+				break
+			}
+			sentCount++
+		}
+
+		if len(handled) != 0 {
+			if err := removeLessonReminders(chat.Id, chatRepo, handled); err != nil {
+				log.Errorf("Error removing lesson reminders of chat %d: %s", chat.Id, err)
+				errorhandler.SendErrorToTelegram(err, bot)
+			}
+		}
+	}
+
+	if sentCount != 0 {
+		log.Infof("Sent %d lesson reminders", sentCount)
+	}
+}
+
+// sendLessonReminder sends the lesson reminder, or the notification that
+// the lesson is removed from the schedule. Nothing is sent if the lesson already started.
+//
+// Returns false if the bot is blocked in the chat.
+func sendLessonReminder(chat *data.Chat, chatRepo data.ChatRepository, lang i18n.Language, bot *gotgbot.Bot, schedule *api2.TimeTableDate, calls api2.CallSchedule, reminder data.LessonReminder, now time.Time) (bool, error) {
+	lesson := schedule.GetLesson(reminder.Number)
+	if lesson == nil || utils.IsLessonHidden(*lesson) {
+		page, err := pages.CreateLessonReminderRemovedPage(lang, reminder)
+		if err != nil {
+			return true, err
+		}
+		return SendLessonReminderPage(chat, chatRepo, bot, page)
+	}
+
+	start, end, err := utils.GetLessonTime(reminder.Date, *lesson, calls, now.Location())
+	if err != nil {
+		return true, err
+	}
+	if !start.After(now) {
+		log.Debugf("Lesson %d of chat %d already started, reminder is dropped", reminder.Number, chat.Id)
+		return true, nil
+	}
+
+	remaining := int(math.Ceil(start.Sub(now).Minutes()))
+	page, err := pages.CreateLessonReminderNotificationPage(lang, withLessonTime(*lesson, start, end), reminder.Date, remaining, utils.ChatLocation(chat))
+	if err != nil {
+		return true, err
+	}
+
+	return SendLessonReminderPage(chat, chatRepo, bot, page)
+}
+
+// SendLessonReminderPage sends the lesson reminder page to chat.
+//
+// Returns false if the bot is blocked in the chat.
+func SendLessonReminderPage(chat *data.Chat, chatRepo data.ChatRepository, bot *gotgbot.Bot, page pages.Page) (bool, error) {
+	log.Debugf("Sending lesson reminder to chat %d", chat.Id)
+
+	opts := page.CreateSendMessageOpts()
+	_, err := bot.SendMessage(chat.Id, page.Text, &opts)
+	if err != nil {
+		// Check if user blocked bot
+		var tgError *gotgbot.TelegramError
+		if errors.As(err, &tgError) && tgError.Code == 403 {
+			log.Infof("Bot blocked in chat %d", chat.Id)
+			if err = MakeChatUnavailable(chat, chatRepo); err != nil {
+				log.Errorf("Error making chat %d unavailable: %s", chat.Id, err)
+				errorhandler.SendErrorToTelegram(err, bot)
+			}
+			return false, nil
+		}
+
+		return true, err
+	}
+
+	return true, nil
+}
+
+// cancelLessonReminders removes the reminders of the lessons that are removed from
+// the group schedule and notifies the chats. Lesson is considered removed if it's
+// removed or moved away in the diff and there is nothing left in its place.
+func cancelLessonReminders(chatRepo data.ChatRepository, bot *gotgbot.Bot, langs map[string]i18n.Language, chats []*data.Chat, groupId int, diffs []scheduler.ScheduleDiff, schedule api2.Schedule) {
+	for _, chat := range chats {
+		cancelled := make([]data.LessonReminder, 0)
+		for _, reminder := range chat.LessonReminders {
+			if reminder.GroupId == groupId && isLessonCancelled(diffs, schedule, reminder.Date, reminder.Number) {
+				cancelled = append(cancelled, reminder)
+			}
+		}
+		if len(cancelled) == 0 {
+			continue
+		}
+
+		log.Infof("Cancelling %d lesson reminders of chat %d", len(cancelled), chat.Id)
+		if err := removeLessonReminders(chat.Id, chatRepo, cancelled); err != nil {
+			log.Errorf("Error removing lesson reminders of chat %d: %s", chat.Id, err)
+			errorhandler.SendErrorToTelegram(err, bot)
+			continue
+		}
+
+		lang, err := utils.GetLang(chat.LanguageCode, langs)
+		if err != nil {
+			log.Errorf("Error getting language for chat %d: %s", chat.Id, err)
+			errorhandler.SendErrorToTelegram(err, bot)
+			continue
+		}
+
+		for _, reminder := range cancelled {
+			page, err := pages.CreateLessonReminderRemovedPage(lang, reminder)
+			if err != nil {
+				log.Errorf("Error creating lesson reminder removed page for chat %d: %s", chat.Id, err)
+				errorhandler.SendErrorToTelegram(err, bot)
+				break
+			}
+
+			sent, err := SendLessonReminderPage(chat, chatRepo, bot, page)
+			if err != nil {
+				log.Warningf("Error sending lesson reminder removed page to chat %d: %s", chat.Id, err)
+				errorhandler.SendErrorToTelegram(err, bot)
+			}
+			if !sent {
+				break
+			}
+		}
+	}
+}
+
+// isLessonCancelled checks if the lesson is removed or moved away in the diffs,
+// and the fresh schedule has no lesson with the same number that day
+func isLessonCancelled(diffs []scheduler.ScheduleDiff, schedule api2.Schedule, date string, number int) bool {
+	changed := false
+	for _, diff := range diffs {
+		if diff.Date != date {
+			continue
+		}
+		for _, change := range diff.Removed {
+			changed = changed || change.Number == number
+		}
+		for _, move := range diff.Moved {
+			changed = changed || move.From.Number == number
+		}
+	}
+	if !changed {
+		return false
+	}
+
+	if day := schedule.GetDay(date); day != nil {
+		if lesson := day.GetLesson(number); lesson != nil && !utils.IsLessonHidden(*lesson) {
+			return false
+		}
+	}
+	return true
+}
+
+// removeLessonReminders removes the reminders from the chat.
+//
+// The chat is read again, so the reminders set by the user
+// in the meantime are not lost.
+func removeLessonReminders(chatId int64, chatRepo data.ChatRepository, reminders []data.LessonReminder) error {
+	chat, err := chatRepo.GetById(chatId)
+	if err != nil || chat == nil {
+		return err
+	}
+
+	for _, reminder := range reminders {
+		chat.LessonReminders = chat.LessonReminders.Remove(reminder.GroupId, reminder.Date, reminder.Number)
+	}
+
+	return chatRepo.Update(chat)
+}
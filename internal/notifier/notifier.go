@@ -36,7 +36,7 @@ import (
 	"github.com/op/go-logging"
 	"github.com/sirkon/go-format/v2"
 	"net/url"
-	"strings"
+	"runtime/debug"
 	"time"
 )
 
@@ -44,8 +44,12 @@ var Location = "Europe/Kiev"
 
 var log = logging.MustGetLogger("Notifier")
 
-// Setup initializes notifier and starts cron Scheduler
-func Setup(api api2.Api, bot *gotgbot.Bot, langs map[string]i18n.Language, chatRepo data.ChatRepository) (*gocron.Scheduler, error) {
+// Setup initializes notifier and starts cron Scheduler.
+//
+// snapshots are the last received group schedules, the fresh ones are compared with.
+// changesInterval is an interval in minutes between the schedule changes checks,
+// the new semester schedule is checked along with them.
+func Setup(api api2.Api, bot *gotgbot.Bot, langs map[string]i18n.Language, chatRepo data.ChatRepository, snapshots *data.ScheduleSnapshots, changesInterval int) (*gocron.Scheduler, error) {
 	log.Info("Setting up notifier")
 
 	// Setup cron scheduler
@@ -56,6 +60,11 @@ func Setup(api api2.Api, bot *gotgbot.Bot, langs map[string]i18n.Language, chatR
 	}
 	scheduler := gocron.NewScheduler(location)
 
+	// Panicking job must not crash the bot, the next runs are still scheduled
+	gocron.SetPanicHandler(func(jobName string, r interface{}) {
+		log.Errorf("Panic in %s job: %s\n%s", jobName, r, string(debug.Stack()))
+	})
+
 	// Get calls
 	calls, err := api.GetCallSchedule()
 	if err != nil {
@@ -92,6 +101,26 @@ func Setup(api api2.Api, bot *gotgbot.Bot, langs map[string]i18n.Language, chatR
 	if err != nil {
 		return nil, err
 	}
+	_, err = scheduler.Cron("* * * * *").Do(SendReminders, chatRepo, api, bot, langs, calls)
+	if err != nil {
+		return nil, err
+	}
+	_, err = scheduler.Cron("* * * * *").Do(SendLessonReminders, chatRepo, api, bot, langs, calls)
+	if err != nil {
+		return nil, err
+	}
+	_, err = scheduler.Cron("* * * * *").Do(SendDailySchedules, "morning", chatRepo, api, bot, langs)
+	if err != nil {
+		return nil, err
+	}
+	_, err = scheduler.Cron("* * * * *").Do(SendDailySchedules, "evening", chatRepo, api, bot, langs)
+	if err != nil {
+		return nil, err
+	}
+	_, err = scheduler.Every(changesInterval).Minutes().SingletonMode().Do(CheckScheduleChanges, chatRepo, api, bot, langs, snapshots)
+	if err != nil {
+		return nil, err
+	}
 
 	return scheduler, nil
 }
@@ -124,9 +153,9 @@ CHATS_LOOP:
 		// Get group schedule
 		schedule, err := api.GetGroupScheduleDay(chat.GroupId, curTime.Format(time.DateOnly))
 		if err != nil {
-			// Check if api connection error
+			// Check if api connection error or the api is down
 			var urlError *url.Error
-			if errors.As(err, &urlError) {
+			if errors.As(err, &urlError) || errors.Is(err, api2.ErrAPIUnavailable) {
 				log.Warningf("Error getting result from API for chat %d: %s", chat.Id, err)
 				continue
 			}
@@ -271,10 +300,10 @@ func SendNotification(chat *data.Chat, chatRepo data.ChatRepository, lang i18n.L
 		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
 			{
 				Text:         lang.Button.OpenSchedule,
-				CallbackData: "open.schedule.day#from=notification&date=" + schedule.Date,
+				CallbackData: utils.NewButtonData("open.schedule.day").Set("from", "notification").Set("date", schedule.Date).String(),
 			}, {
 				Text:         lang.Button.Settings,
-				CallbackData: "open.settings#from=notification",
+				CallbackData: utils.NewButtonData("open.settings").Set("from", "notification").String(),
 			},
 		}},
 	}
@@ -328,8 +357,7 @@ func IsGroupHaveClasses(schedule *api2.TimeTableDate, calls api2.CallSchedule, t
 	// Check if first lesson is actually first lesson
 	// Needed because university can add lesson like "приховано з **"
 	// Can be removed if it is not the case anymore
-	lessonName := strings.ToLower(firstLesson.Periods[0].DisciplineShortName)
-	if strings.Contains(lessonName, "приховано") {
+	if utils.IsLessonHidden(firstLesson) {
 		return false, nil
 	}
 
@@ -350,8 +378,7 @@ func IsGroupHaveNextClassesPart(schedule *api2.TimeTableDate, calls api2.CallSch
 	for i, lesson := range schedule.Lessons {
 		// Skip if lesson is hidden like "приховано з **"
 		// Can be removed if it is not the case anymore
-		lessonName := strings.ToLower(lesson.Periods[0].DisciplineShortName)
-		if strings.Contains(lessonName, "приховано") || lesson.Periods[0].TimeEnd == "" {
+		if utils.IsLessonHidden(lesson) || lesson.Periods[0].TimeEnd == "" {
 			continue
 		}
 
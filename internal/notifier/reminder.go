@@ -0,0 +1,222 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package notifier
+
+import (
+	"errors"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/errorhandler"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"net/url"
+	"time"
+)
+
+// SendReminders sends class reminders to chats that have a class
+// starting in exactly chat.ReminderOffset minutes.
+//
+// Called every minute, so changing the offset takes effect immediately.
+// Errors are logged and don't stop the sweep.
+func SendReminders(chatRepo data.ChatRepository, api api2.Api, bot *gotgbot.Bot, langs map[string]i18n.Language, calls api2.CallSchedule) {
+	chats, err := chatRepo.GetChatsWithEnabledReminder()
+	if err != nil {
+		log.Errorf("Error getting chats with enabled reminders: %s", err)
+		return
+	}
+
+	loc, err := time.LoadLocation(Location)
+	if err != nil {
+		log.Errorf("Error loading location: %s", err)
+		return
+	}
+
+	curTime := time.Now().In(loc).Truncate(time.Minute)
+	date := curTime.Format(time.DateOnly)
+
+	// Schedules of the groups, to not request the same group twice
+	schedules := make(map[int]*api2.TimeTableDate)
+
+	sentCount := 0
+	for _, chat := range chats {
+		if chat.GroupId == -1 || !chat.Accessible {
+			continue
+		}
+
+		schedule, ok := schedules[chat.GroupId]
+		if !ok {
+			schedule, err = api.GetGroupScheduleDay(chat.GroupId, date)
+			if err != nil {
+				// Check if api connection error or the api is down
+				var urlError *url.Error
+				if errors.As(err, &urlError) || errors.Is(err, api2.ErrAPIUnavailable) {
+					log.Warningf("Error getting result from API for chat %d: %s", chat.Id, err)
+					continue
+				}
+
+				log.Errorf("Error getting group schedule day for chat %d: %s", chat.Id, err)
+				continue
+			}
+			schedules[chat.GroupId] = schedule
+		}
+
+		offset := chat.ReminderOffset
+		if offset <= 0 {
+			offset = data.DefaultReminderOffset
+		}
+
+		lesson, err := getLessonStartingAt(schedule, calls, curTime.Add(time.Duration(offset)*time.Minute))
+		if err != nil {
+			log.Errorf("Error getting lesson for chat %d: %s", chat.Id, err)
+			continue
+		}
+		if lesson == nil {
+			continue
+		}
+
+		// Don't remind about the next lesson while the previous one is going on
+		inProgress, err := isLessonInProgress(schedule, calls, curTime)
+		if err != nil {
+			log.Errorf("Error checking lesson in progress for chat %d: %s", chat.Id, err)
+			continue
+		}
+		if inProgress {
+			continue
+		}
+
+		lang, err := utils.GetLang(chat.LanguageCode, langs)
+		if err != nil {
+			log.Errorf("Error getting language for chat %d: %s", chat.Id, err)
+			continue
+		}
+
+		if err := SendReminder(chat, chatRepo, lang, bot, lesson, date, offset); err != nil {
+			log.Warningf("Error sending reminder to chat %d: %s", chat.Id, err)
+			errorhandler.SendErrorToTelegram(err, bot)
+			continue
+		}
+
+		sentCount++
+	}
+
+	if sentCount != 0 {
+		log.Infof("Sent reminders to %d chats", sentCount)
+	}
+}
+
+// SendReminder sends class reminder to chat
+func SendReminder(chat *data.Chat, chatRepo data.ChatRepository, lang i18n.Language, bot *gotgbot.Bot, lesson *api2.TimeTableLesson, date string, offset int) error {
+	log.Debugf("Sending class reminder to chat %d", chat.Id)
+
+	page, err := pages.CreateClassReminderPage(lang, lesson, date, offset, utils.ChatLocation(chat))
+	if err != nil {
+		return err
+	}
+
+	opts := page.CreateSendMessageOpts()
+	_, err = bot.SendMessage(chat.Id, page.Text, &opts)
+	if err != nil {
+		// Check if user blocked bot
+		var tgError *gotgbot.TelegramError
+		if errors.As(err, &tgError) && tgError.Code == 403 {
+			log.Infof("Bot blocked in chat %d", chat.Id)
+			if err = MakeChatUnavailable(chat, chatRepo); err != nil {
+				log.Errorf("Error making chat %d unavailable: %s", chat.Id, err)
+				errorhandler.SendErrorToTelegram(err, bot)
+			}
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// getLessonStartingAt returns the lesson that starts at the given time,
+// or nil if there is no such lesson.
+//
+// Empty period times of the returned lesson are filled from the call schedule.
+func getLessonStartingAt(schedule *api2.TimeTableDate, calls api2.CallSchedule, time2 time.Time) (*api2.TimeTableLesson, error) {
+	for _, lesson := range schedule.Lessons {
+		if utils.IsLessonHidden(lesson) {
+			continue
+		}
+
+		start, end, err := utils.GetLessonTime(schedule.Date, lesson, calls, time2.Location())
+		if err != nil {
+			return nil, err
+		}
+
+		if !start.Equal(time2) {
+			continue
+		}
+
+		return withLessonTime(lesson, start, end), nil
+	}
+
+	return nil, nil
+}
+
+// isLessonInProgress checks if any lesson is going on at the given time.
+//
+// Used to not send reminders in the middle of the previous lesson,
+// when lessons go one after another without a long break.
+func isLessonInProgress(schedule *api2.TimeTableDate, calls api2.CallSchedule, time2 time.Time) (bool, error) {
+	for _, lesson := range schedule.Lessons {
+		if utils.IsLessonHidden(lesson) {
+			continue
+		}
+
+		start, end, err := utils.GetLessonTime(schedule.Date, lesson, calls, time2.Location())
+		if err != nil {
+			return false, err
+		}
+
+		if !time2.Before(start) && time2.Before(end) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// withLessonTime returns the copy of the lesson with the empty period times
+// set to start and end, so the cached schedule is not modified
+func withLessonTime(lesson api2.TimeTableLesson, start time.Time, end time.Time) *api2.TimeTableLesson {
+	periods := make([]api2.TimeTablePeriod, len(lesson.Periods))
+	copy(periods, lesson.Periods)
+	for i := range periods {
+		if periods[i].TimeStart == "" {
+			periods[i].TimeStart = start.Format("15:04")
+		}
+		if periods[i].TimeEnd == "" {
+			periods[i].TimeEnd = end.Format("15:04")
+		}
+	}
+	lesson.Periods = periods
+
+	return &lesson
+}
@@ -0,0 +1,295 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package notifier
+
+import (
+	"errors"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/errorhandler"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/cubicbyte/dteubot/internal/scheduler"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"net/url"
+	"time"
+)
+
+// ScheduleChangesDays is a number of days, starting from today,
+// in which the schedule changes are tracked
+const ScheduleChangesDays = 7
+
+// CheckScheduleChanges fetches schedules of the groups that have chats subscribed
+// to the schedule changes, compares them with the snapshots and notifies chats
+// if the schedule has changed. All the changes of the group are sent in a single message.
+//
+// Pending lesson reminders of the cancelled lessons are removed as well.
+//
+// The next week is fetched too, to notify the chats with any notifications
+// enabled when the schedule of the new semester is published.
+func CheckScheduleChanges(chatRepo data.ChatRepository, api api2.Api, bot *gotgbot.Bot, langs map[string]i18n.Language, snapshots *data.ScheduleSnapshots) error {
+	log.Info("Checking schedule changes")
+
+	chats, err := chatRepo.GetChatsWithEnabledChangesNotification()
+	if err != nil {
+		return err
+	}
+	log.Debugf("Got %d chats with schedule changes notifications enabled", len(chats))
+
+	// Group chats by group
+	groupChats := make(map[int][]*data.Chat)
+	for _, chat := range chats {
+		if !chat.NotifyChanges || chat.GroupId == -1 {
+			continue
+		}
+		groupChats[chat.GroupId] = append(groupChats[chat.GroupId], chat)
+	}
+
+	// Lessons of the pending lesson reminders
+	// are checked for being cancelled too
+	reminderChats, err := chatRepo.GetChatsWithLessonReminders()
+	if err != nil {
+		return err
+	}
+
+	reminderGroups := make(map[int][]*data.Chat)
+	for _, chat := range reminderChats {
+		groups := make(map[int]bool)
+		for _, reminder := range chat.LessonReminders {
+			if !groups[reminder.GroupId] {
+				groups[reminder.GroupId] = true
+				reminderGroups[reminder.GroupId] = append(reminderGroups[reminder.GroupId], chat)
+			}
+		}
+	}
+
+	// The new semester schedule is sent regardless of the changes notifications setting
+	accessibleChats, err := chatRepo.GetAccessibleChats()
+	if err != nil {
+		return err
+	}
+
+	semesterGroups := make(map[int][]*data.Chat)
+	for _, chat := range accessibleChats {
+		if chat.GroupId == -1 || !hasNotifications(chat) {
+			continue
+		}
+		semesterGroups[chat.GroupId] = append(semesterGroups[chat.GroupId], chat)
+	}
+
+	groupIds := make(map[int]bool)
+	for groupId := range groupChats {
+		groupIds[groupId] = true
+	}
+	for groupId := range reminderGroups {
+		groupIds[groupId] = true
+	}
+	for groupId := range semesterGroups {
+		groupIds[groupId] = true
+	}
+
+	// Forget groups that no one is subscribed to
+	snapshots.Retain(func(groupId int) bool {
+		return groupIds[groupId]
+	})
+	defer func() {
+		if err := snapshots.Save(); err != nil {
+			log.Errorf("Error saving schedule snapshots: %s", err)
+			errorhandler.SendErrorToTelegram(err, bot)
+		}
+	}()
+
+	loc, err := time.LoadLocation(Location)
+	if err != nil {
+		return err
+	}
+
+	today := time.Now().In(loc)
+	dateStart := today.Format(time.DateOnly)
+	changesEnd := today.AddDate(0, 0, ScheduleChangesDays-1).Format(time.DateOnly)
+
+	// Schedule is fetched until the end of the next week at least
+	monday := scheduler.NextMonday(today)
+	dateEnd := max(changesEnd, monday.AddDate(0, 0, 6).Format(time.DateOnly))
+
+	sentCount := 0
+	semesterCount := 0
+	for groupId := range groupIds {
+		diffs, err := getGroupScheduleDiffs(api, snapshots, groupId, dateStart, changesEnd, dateEnd)
+		if err != nil {
+			// Check if api connection error or the api is down
+			var urlError *url.Error
+			if errors.As(err, &urlError) || errors.Is(err, api2.ErrAPIUnavailable) {
+				log.Warningf("Error getting result from API for group %d: %s", groupId, err)
+				continue
+			}
+
+			log.Errorf("Error getting schedule changes for group %d: %s", groupId, err)
+			errorhandler.SendErrorToTelegram(err, bot)
+			continue
+		}
+
+		if chats, ok := semesterGroups[groupId]; ok {
+			semesterCount += checkSemesterPublished(chatRepo, bot, langs, snapshots, chats, groupId, monday)
+		}
+
+		if len(diffs) == 0 {
+			continue
+		}
+		log.Infof("Schedule of group %d changed on %d days", groupId, len(diffs))
+
+		if chats, ok := reminderGroups[groupId]; ok {
+			snapshot, _ := snapshots.Get(groupId)
+			cancelLessonReminders(chatRepo, bot, langs, chats, groupId, diffs, snapshot.Schedule)
+		}
+
+		for _, chat := range groupChats[groupId] {
+			lang, err := utils.GetLang(chat.LanguageCode, langs)
+			if err != nil {
+				log.Errorf("Error getting language for chat %d: %s", chat.Id, err)
+				errorhandler.SendErrorToTelegram(err, bot)
+				continue
+			}
+
+			page, err := pages.CreateScheduleDiffPage(lang, diffs)
+			if err != nil {
+				log.Errorf("Error creating schedule diff page for chat %d: %s", chat.Id, err)
+				errorhandler.SendErrorToTelegram(err, bot)
+				continue
+			}
+
+			// Changes of many days may not fit in a single message
+			sent := false
+			for _, part := range pages.SplitLongPage(page, pages.MaxPageLength) {
+				sent, err = SendScheduleDiff(chat, chatRepo, bot, part)
+				if err != nil {
+					log.Warningf("Error sending schedule diff to chat %d: %s", chat.Id, err)
+					errorhandler.SendErrorToTelegram(err, bot)
+					break
+				}
+				if !sent {
+					// Bot is blocked in the chat
+					break
+				}
+			}
+
+			if sent {
+				sentCount++
+			}
+		}
+	}
+
+	log.Infof("Sent schedule changes to %d chats", sentCount)
+	if semesterCount != 0 {
+		log.Infof("Sent new semester schedules to %d chats", semesterCount)
+	}
+
+	return nil
+}
+
+// getGroupScheduleDiffs gets the fresh group schedule from dateStart to dateEnd
+// and compares it with the snapshot. Returns changes of the days that have changed,
+// from dateStart to changesEnd, so the past days are not compared.
+func getGroupScheduleDiffs(api api2.Api, snapshots *data.ScheduleSnapshots, groupId int, dateStart string, changesEnd string, dateEnd string) ([]scheduler.ScheduleDiff, error) {
+	schedule, err := getFreshGroupSchedule(api, groupId, dateStart, dateEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	old, ok := snapshots.Get(groupId)
+	snapshots.Set(groupId, data.ScheduleSnapshot{DateEnd: dateEnd, Schedule: schedule, EmptySince: old.EmptySince})
+	if !ok {
+		// Nothing to compare with yet
+		return nil, nil
+	}
+
+	start, err := time.Parse(time.DateOnly, dateStart)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make([]scheduler.ScheduleDiff, 0)
+
+	// Compare only days that are present in both schedules
+	compareEnd := min(old.DateEnd, changesEnd)
+	for date := start; date.Format(time.DateOnly) <= compareEnd; date = date.AddDate(0, 0, 1) {
+		dateStr := date.Format(time.DateOnly)
+
+		var oldLessons, newLessons []api2.TimeTableLesson
+		if day := old.Schedule.GetDay(dateStr); day != nil {
+			oldLessons = day.Lessons
+		}
+		if day := schedule.GetDay(dateStr); day != nil {
+			newLessons = day.Lessons
+		}
+
+		diff := scheduler.DiffSchedule(oldLessons, newLessons)
+		if diff.IsEmpty() {
+			continue
+		}
+
+		diff.Date = dateStr
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, nil
+}
+
+// getFreshGroupSchedule gets the group schedule, making sure the cached one is not used
+func getFreshGroupSchedule(api api2.Api, groupId int, dateStart string, dateEnd string) (api2.Schedule, error) {
+	if expirer, ok := api.(api2.ScheduleExpirer); ok {
+		if err := expirer.ExpireGroupSchedule(groupId, dateStart, dateEnd); err != nil {
+			return nil, err
+		}
+	}
+
+	return api.GetGroupSchedule(groupId, dateStart, dateEnd)
+}
+
+// SendScheduleDiff sends the schedule changes page to chat.
+//
+// Returns false if the bot is blocked in the chat.
+func SendScheduleDiff(chat *data.Chat, chatRepo data.ChatRepository, bot *gotgbot.Bot, page pages.Page) (bool, error) {
+	log.Debugf("Sending schedule changes to chat %d", chat.Id)
+
+	opts := page.CreateSendMessageOpts()
+	_, err := bot.SendMessage(chat.Id, page.Text, &opts)
+	if err != nil {
+		// Check if user blocked bot
+		var tgError *gotgbot.TelegramError
+		if errors.As(err, &tgError) && tgError.Code == 403 {
+			log.Infof("Bot blocked in chat %d", chat.Id)
+			chat.NotifyChanges = false
+			if err = MakeChatUnavailable(chat, chatRepo); err != nil {
+				log.Errorf("Error making chat %d unavailable: %s", chat.Id, err)
+				errorhandler.SendErrorToTelegram(err, bot)
+			}
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
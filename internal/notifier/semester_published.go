@@ -0,0 +1,122 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package notifier
+
+import (
+	"errors"
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/cubicbyte/dteubot/internal/data"
+	"github.com/cubicbyte/dteubot/internal/dteubot/errorhandler"
+	"github.com/cubicbyte/dteubot/internal/dteubot/pages"
+	"github.com/cubicbyte/dteubot/internal/dteubot/utils"
+	"github.com/cubicbyte/dteubot/internal/i18n"
+	"github.com/cubicbyte/dteubot/internal/scheduler"
+	"time"
+)
+
+// checkSemesterPublished checks the next week of the group schedule snapshot,
+// just fetched by CheckScheduleChanges, and notifies the chats if the schedule
+// of the new semester is published. The semester gap is saved to the snapshot,
+// so it's not lost on restart.
+//
+// Returns the number of chats notified.
+func checkSemesterPublished(chatRepo data.ChatRepository, bot *gotgbot.Bot, langs map[string]i18n.Language, snapshots *data.ScheduleSnapshots, chats []*data.Chat, groupId int, monday time.Time) int {
+	snapshot, ok := snapshots.Get(groupId)
+	if !ok {
+		return 0
+	}
+
+	emptySince, event, published := scheduler.DetectSemesterPublished(groupId, monday, snapshot.Schedule, snapshot.EmptySince)
+	snapshot.EmptySince = emptySince
+	snapshots.Set(groupId, snapshot)
+	if !published {
+		return 0
+	}
+	log.Infof("Schedule of group %d for semester %d is published", groupId, event.Semester)
+
+	sentCount := 0
+	for _, chat := range chats {
+		lang, err := utils.GetLang(chat.LanguageCode, langs)
+		if err != nil {
+			log.Errorf("Error getting language for chat %d: %s", chat.Id, err)
+			errorhandler.SendErrorToTelegram(err, bot)
+			continue
+		}
+
+		page, err := pages.CreateSemesterPublishedPage(lang, event)
+		if err != nil {
+			log.Errorf("Error creating semester published page for chat %d: %s", chat.Id, err)
+			errorhandler.SendErrorToTelegram(err, bot)
+			continue
+		}
+
+		sent, err := SendSemesterPublished(chat, chatRepo, bot, page)
+		if err != nil {
+			log.Warningf("Error sending semester published page to chat %d: %s", chat.Id, err)
+			errorhandler.SendErrorToTelegram(err, bot)
+			continue
+		}
+
+		if sent {
+			sentCount++
+		}
+	}
+
+	return sentCount
+}
+
+// hasNotifications checks if the chat is subscribed to any of the notifications
+func hasNotifications(chat *data.Chat) bool {
+	return chat.ClassesNotification15m ||
+		chat.ClassesNotification1m ||
+		chat.ClassesReminder ||
+		chat.MorningSchedule ||
+		chat.EveningSchedule ||
+		chat.NotifyChanges
+}
+
+// SendSemesterPublished sends the new semester schedule page to chat.
+//
+// Returns false if the bot is blocked in the chat.
+func SendSemesterPublished(chat *data.Chat, chatRepo data.ChatRepository, bot *gotgbot.Bot, page pages.Page) (bool, error) {
+	log.Debugf("Sending new semester schedule to chat %d", chat.Id)
+
+	opts := page.CreateSendMessageOpts()
+	_, err := bot.SendMessage(chat.Id, page.Text, &opts)
+	if err != nil {
+		// Check if user blocked bot
+		var tgError *gotgbot.TelegramError
+		if errors.As(err, &tgError) && tgError.Code == 403 {
+			log.Infof("Bot blocked in chat %d", chat.Id)
+			if err = MakeChatUnavailable(chat, chatRepo); err != nil {
+				log.Errorf("Error making chat %d unavailable: %s", chat.Id, err)
+				errorhandler.SendErrorToTelegram(err, bot)
+			}
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
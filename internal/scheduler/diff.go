@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package scheduler
+
+import (
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+)
+
+// LessonChange is a single period of the lesson that was added or removed
+type LessonChange struct {
+	Number int
+	Period api2.TimeTablePeriod
+}
+
+// LessonMove is a period that was moved to another lesson number,
+// time or classroom
+type LessonMove struct {
+	From LessonChange
+	To   LessonChange
+}
+
+// ScheduleDiff is a difference between two schedules of the same day
+type ScheduleDiff struct {
+	Date    string
+	Added   []LessonChange
+	Removed []LessonChange
+	Moved   []LessonMove
+}
+
+// IsEmpty checks if there are no changes in the diff
+func (d *ScheduleDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Moved) == 0
+}
+
+// DiffSchedule compares lessons of the same day and returns the changes.
+//
+// Periods are considered the same if they have the same discipline, type and teacher.
+// Same period with the different lesson number, time or classroom is considered moved.
+func DiffSchedule(old, new []api2.TimeTableLesson) ScheduleDiff {
+	oldPeriods := flattenLessons(old)
+	newPeriods := flattenLessons(new)
+
+	oldMatched := make([]bool, len(oldPeriods))
+	newMatched := make([]bool, len(newPeriods))
+
+	// Find unchanged periods
+	for i, oldPeriod := range oldPeriods {
+		for j, newPeriod := range newPeriods {
+			if newMatched[j] || !isSamePeriod(oldPeriod, newPeriod) || !isSamePlace(oldPeriod, newPeriod) {
+				continue
+			}
+
+			oldMatched[i] = true
+			newMatched[j] = true
+			break
+		}
+	}
+
+	diff := ScheduleDiff{}
+
+	// Find moved periods
+	for i, oldPeriod := range oldPeriods {
+		if oldMatched[i] {
+			continue
+		}
+
+		for j, newPeriod := range newPeriods {
+			if newMatched[j] || !isSamePeriod(oldPeriod, newPeriod) {
+				continue
+			}
+
+			oldMatched[i] = true
+			newMatched[j] = true
+			diff.Moved = append(diff.Moved, LessonMove{From: oldPeriod, To: newPeriod})
+			break
+		}
+	}
+
+	// All the remaining periods are removed or added
+	for i, oldPeriod := range oldPeriods {
+		if !oldMatched[i] {
+			diff.Removed = append(diff.Removed, oldPeriod)
+		}
+	}
+	for j, newPeriod := range newPeriods {
+		if !newMatched[j] {
+			diff.Added = append(diff.Added, newPeriod)
+		}
+	}
+
+	return diff
+}
+
+// flattenLessons returns all the periods of the lessons with their lesson numbers
+func flattenLessons(lessons []api2.TimeTableLesson) []LessonChange {
+	periods := make([]LessonChange, 0, len(lessons))
+	for _, lesson := range lessons {
+		for _, period := range lesson.Periods {
+			periods = append(periods, LessonChange{Number: lesson.Number, Period: period})
+		}
+	}
+	return periods
+}
+
+// isSamePeriod checks if both periods are the same class
+func isSamePeriod(a, b LessonChange) bool {
+	return a.Period.DisciplineId == b.Period.DisciplineId &&
+		a.Period.DisciplineFullName == b.Period.DisciplineFullName &&
+		a.Period.Type == b.Period.Type &&
+		a.Period.TeachersNameFull == b.Period.TeachersNameFull
+}
+
+// isSamePlace checks if both periods take place at the same time and classroom
+func isSamePlace(a, b LessonChange) bool {
+	return a.Number == b.Number &&
+		a.Period.TimeStart == b.Period.TimeStart &&
+		a.Period.TimeEnd == b.Period.TimeEnd &&
+		a.Period.Classroom == b.Period.Classroom
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package scheduler
+
+import (
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"time"
+)
+
+// SemesterPublished is the event emitted when the schedule
+// of the group for the new semester is uploaded
+type SemesterPublished struct {
+	GroupId int
+	// Semester is 1 for the autumn semester and 2 for the spring one
+	Semester int
+	// Date is the first day with lessons, in time.DateOnly format
+	Date string
+}
+
+// SemesterGapWeeks is the min number of the weeks in a row without lessons
+// between the semesters. Shorter gaps, like the holidays, are not a new semester.
+const SemesterGapWeeks = 2
+
+// DetectSemesterPublished checks the lessons of the group week starting on monday,
+// to detect the new semester schedule being published: between the semesters
+// the API returns the empty schedule, until the new timetable is uploaded.
+//
+// emptySince is the Monday of the first checked week in a row without lessons,
+// in time.DateOnly format, or "" if the last checked week had lessons.
+// Returns the emptySince to save for the next check, and the event if the week
+// is the first one with lessons after at least SemesterGapWeeks empty weeks.
+func DetectSemesterPublished(groupId int, monday time.Time, schedule api2.Schedule, emptySince string) (string, SemesterPublished, bool) {
+	weekStart := monday.Format(time.DateOnly)
+	weekEnd := monday.AddDate(0, 0, 6).Format(time.DateOnly)
+
+	firstDay := ""
+	for _, day := range schedule {
+		if day.Date < weekStart || day.Date > weekEnd || countLessons(api2.Schedule{day}) == 0 {
+			continue
+		}
+		if firstDay == "" || day.Date < firstDay {
+			firstDay = day.Date
+		}
+	}
+
+	if firstDay == "" {
+		if emptySince == "" {
+			return weekStart, SemesterPublished{}, false
+		}
+		return emptySince, SemesterPublished{}, false
+	}
+
+	if emptySince == "" {
+		return "", SemesterPublished{}, false
+	}
+
+	since, err := time.ParseInLocation(time.DateOnly, emptySince, monday.Location())
+	if err != nil || daysBetween(since, monday) < SemesterGapWeeks*7 {
+		return "", SemesterPublished{}, false
+	}
+
+	event := SemesterPublished{
+		GroupId:  groupId,
+		Semester: GetSemester(monday),
+		Date:     firstDay,
+	}
+	return "", event, true
+}
+
+// GetSemester returns the number of the semester the date belongs to:
+// 1 from August to December, 2 from January to July
+func GetSemester(date time.Time) int {
+	if date.Month() >= time.August {
+		return 1
+	}
+	return 2
+}
+
+// NextMonday returns the start of the Monday after the date
+func NextMonday(date time.Time) time.Time {
+	days := (8 - int(date.Weekday())) % 7
+	if days == 0 {
+		days = 7
+	}
+
+	return time.Date(date.Year(), date.Month(), date.Day()+days, 0, 0, 0, 0, date.Location())
+}
+
+// daysBetween returns the number of the calendar days from a to b,
+// not affected by the daylight saving time changes
+func daysBetween(a time.Time, b time.Time) int {
+	a = time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	b = time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// countLessons returns the number of periods in the schedule
+func countLessons(schedule api2.Schedule) int {
+	count := 0
+	for _, day := range schedule {
+		for _, lesson := range day.Lessons {
+			count += len(lesson.Periods)
+		}
+	}
+	return count
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package scheduler
+
+import (
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"testing"
+	"time"
+)
+
+// weekSchedule returns the schedule of the week with a lesson on each of the days
+func weekSchedule(days ...string) api2.Schedule {
+	schedule := make(api2.Schedule, 0, len(days))
+	for _, day := range days {
+		schedule = append(schedule, api2.TimeTableDate{
+			Date:    day,
+			Lessons: []api2.TimeTableLesson{{Number: 1, Periods: []api2.TimeTablePeriod{{}}}},
+		})
+	}
+	return schedule
+}
+
+func TestDetectSemesterPublished(t *testing.T) {
+	type check struct {
+		monday   string
+		schedule api2.Schedule
+	}
+
+	tests := []struct {
+		name   string
+		checks []check
+		// want is the date of the published event, "" if there is none
+		want string
+	}{{
+		name: "first week after the semester gap",
+		checks: []check{
+			{"2025-01-06", weekSchedule("2025-01-06")},
+			{"2025-01-13", nil},
+			{"2025-01-20", nil},
+			{"2025-01-27", weekSchedule("2025-01-28", "2025-01-29")},
+		},
+		want: "2025-01-28",
+	}, {
+		name: "empty week that gains the lessons later",
+		checks: []check{
+			{"2025-03-03", nil},
+			{"2025-03-03", weekSchedule("2025-03-04")},
+		},
+	}, {
+		name: "mid-semester holiday week",
+		checks: []check{
+			{"2025-03-03", weekSchedule("2025-03-03")},
+			{"2025-03-10", nil},
+			{"2025-03-17", weekSchedule("2025-03-17")},
+		},
+	}, {
+		name: "lessons outside of the week",
+		checks: []check{
+			{"2025-01-06", nil},
+			{"2025-01-13", nil},
+			{"2025-01-20", weekSchedule("2025-01-19", "2025-01-27")},
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emptySince := ""
+			got := ""
+			for _, c := range tt.checks {
+				monday, err := time.Parse(time.DateOnly, c.monday)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var event SemesterPublished
+				var ok bool
+				emptySince, event, ok = DetectSemesterPublished(1, monday, c.schedule, emptySince)
+				if ok {
+					if got != "" {
+						t.Fatalf("second event on %s", event.Date)
+					}
+					got = event.Date
+				}
+			}
+
+			if got != tt.want {
+				t.Errorf("got event on %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectSemesterPublishedOnce(t *testing.T) {
+	monday := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+	emptySince := "2025-08-11"
+
+	// Checked many times while the week is the next one, including after the restart
+	emptySince, event, ok := DetectSemesterPublished(1, monday, weekSchedule("2025-09-01"), emptySince)
+	if !ok || event.Semester != 1 || event.Date != "2025-09-01" {
+		t.Fatalf("got %+v %v, want the event of semester 1 on 2025-09-01", event, ok)
+	}
+
+	if _, _, ok := DetectSemesterPublished(1, monday, weekSchedule("2025-09-01"), emptySince); ok {
+		t.Error("event is emitted again on the next check")
+	}
+}
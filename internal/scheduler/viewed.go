@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package scheduler
+
+import (
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"sync"
+	"time"
+)
+
+// ViewedScheduleTTL is how long the viewed schedule is remembered
+// after the chat has seen it for the last time
+const ViewedScheduleTTL = 14 * 24 * time.Hour
+
+// viewedKey identifies the schedule of the group day seen in the chat
+type viewedKey struct {
+	ChatId  int64
+	GroupId int
+	Date    string
+}
+
+// viewedSchedule is the schedule of the day as the chat has seen it
+type viewedSchedule struct {
+	Lessons []api2.TimeTableLesson
+	Viewed  time.Time
+}
+
+// ViewedSchedules remembers the schedules the chats have seen,
+// to show what has changed since the last time.
+//
+// Stored in memory, so after the restart the first view
+// of each day only saves the schedule.
+//
+// Should be created via NewViewedSchedules.
+type ViewedSchedules struct {
+	mu        sync.Mutex
+	schedules map[viewedKey]viewedSchedule
+	lastSweep time.Time
+}
+
+// NewViewedSchedules creates a new instance of ViewedSchedules.
+func NewViewedSchedules() *ViewedSchedules {
+	return &ViewedSchedules{
+		schedules: make(map[viewedKey]viewedSchedule),
+		lastSweep: time.Now(),
+	}
+}
+
+// View saves the lessons of the group day the chat is seeing now and returns
+// the changes since the previous view. ok is false if the chat sees this day
+// for the first time, so there is nothing to compare with.
+func (v *ViewedSchedules) View(chatId int64, groupId int, date string, lessons []api2.TimeTableLesson) (diff ScheduleDiff, ok bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := time.Now()
+	v.sweep(now)
+
+	key := viewedKey{ChatId: chatId, GroupId: groupId, Date: date}
+	prev, ok := v.schedules[key]
+
+	// Copy the lessons, they can be shared with the API cache
+	saved := make([]api2.TimeTableLesson, len(lessons))
+	for i, lesson := range lessons {
+		saved[i] = api2.TimeTableLesson{
+			Number:  lesson.Number,
+			Periods: append([]api2.TimeTablePeriod(nil), lesson.Periods...),
+		}
+	}
+	v.schedules[key] = viewedSchedule{Lessons: saved, Viewed: now}
+
+	if !ok {
+		return ScheduleDiff{Date: date}, false
+	}
+
+	diff = DiffSchedule(prev.Lessons, lessons)
+	diff.Date = date
+	return diff, true
+}
+
+// sweep removes the schedules that were not viewed for ViewedScheduleTTL.
+// Runs at most once an hour.
+func (v *ViewedSchedules) sweep(now time.Time) {
+	if now.Sub(v.lastSweep) < time.Hour {
+		return
+	}
+	v.lastSweep = now
+
+	for key, schedule := range v.schedules {
+		if now.Sub(schedule.Viewed) > ViewedScheduleTTL {
+			delete(v.schedules, key)
+		}
+	}
+}
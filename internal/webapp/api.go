@@ -0,0 +1,227 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package webapp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"github.com/op/go-logging"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var log = logging.MustGetLogger("WebApp")
+
+// InitDataHeader is the request header the Mini App sends its init data in
+const InitDataHeader = "X-Telegram-Init-Data"
+
+// InitDataMaxAge is how long the init data is accepted after Telegram issued it
+const InitDataMaxAge = 24 * time.Hour
+
+// ErrInvalidInitData is returned when the init data is not signed
+// with the bot token or is expired
+var ErrInvalidInitData = errors.New("invalid init data")
+
+// Config is the configuration of the Mini App API
+type Config struct {
+	// Api is the API to get the schedule from, usually the cached one
+	Api api2.Api
+	// BotToken is used to check the init data signature
+	BotToken string
+	// Origin is the only origin the Mini App requests are allowed from,
+	// like "https://example.com"
+	Origin string
+}
+
+// NewHandler creates the Mini App API handler.
+//
+// Endpoints:
+//
+//	GET /api/schedule?group=1234&date=2023-10-04 - the group schedule for the day
+func NewHandler(config Config) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/api/schedule", requireInitData(config.BotToken, scheduleHandler(config.Api)))
+
+	return cors(config.Origin, mux)
+}
+
+// StartServer starts serving the Mini App API on addr in the background.
+//
+// Returns the function that stops the server.
+func StartServer(addr string, config Config) func() error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           NewHandler(config),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		log.Infof("Serving the Mini App API on %s", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("Error serving the Mini App API: %s", err)
+		}
+	}()
+
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(ctx)
+	}
+}
+
+// ValidateInitData checks the Mini App init data signature,
+// as described in https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app
+//
+// Returns the init data fields, like "user" and "auth_date".
+func ValidateInitData(initData string, botToken string, now time.Time) (url.Values, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInitData, err)
+	}
+
+	hash := values.Get("hash")
+	if hash == "" {
+		return nil, fmt.Errorf("%w: no hash", ErrInvalidInitData)
+	}
+
+	// Data-check-string is the sorted fields except the hash, separated by line breaks
+	fields := make([]string, 0, len(values))
+	for key := range values {
+		if key != "hash" {
+			fields = append(fields, key+"="+values.Get(key))
+		}
+	}
+	sort.Strings(fields)
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	signature := hmac.New(sha256.New, secret.Sum(nil))
+	signature.Write([]byte(strings.Join(fields, "\n")))
+
+	expected, err := hex.DecodeString(hash)
+	if err != nil || !hmac.Equal(signature.Sum(nil), expected) {
+		return nil, fmt.Errorf("%w: wrong signature", ErrInvalidInitData)
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid auth_date", ErrInvalidInitData)
+	}
+	if now.Sub(time.Unix(authDate, 0)) > InitDataMaxAge {
+		return nil, fmt.Errorf("%w: expired", ErrInvalidInitData)
+	}
+
+	return values, nil
+}
+
+// requireInitData responds with 401 to the requests without the valid init data
+func requireInitData(botToken string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := ValidateInitData(r.Header.Get(InitDataHeader), botToken, time.Now()); err != nil {
+			log.Debugf("Rejected the Mini App request: %s", err)
+			writeError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// scheduleHandler responds with the group schedule for the day
+func scheduleHandler(api api2.Api) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		groupId, err := strconv.Atoi(r.URL.Query().Get("group"))
+		if err != nil || groupId <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid group")
+			return
+		}
+
+		date := r.URL.Query().Get("date")
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid date")
+			return
+		}
+
+		day, err := api.WithContext(r.Context()).GetGroupScheduleDay(groupId, date)
+		if err != nil {
+			log.Warningf("Error getting %d group schedule for the Mini App: %s", groupId, err)
+			writeError(w, http.StatusBadGateway, "schedule is unavailable")
+			return
+		}
+		if day == nil {
+			day = &api2.TimeTableDate{Date: date}
+		}
+		if day.Lessons == nil {
+			day.Lessons = []api2.TimeTableLesson{}
+		}
+
+		writeJSON(w, http.StatusOK, day)
+	}
+}
+
+// cors allows the requests only from the Mini App origin and answers the preflight requests
+func cors(origin string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Origin")
+
+		if r.Header.Get("Origin") == origin {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", InitDataHeader)
+		}
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Warningf("Error writing the Mini App response: %s", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
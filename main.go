@@ -23,11 +23,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/cubicbyte/dteubot/internal/config"
 	"github.com/cubicbyte/dteubot/internal/dteubot"
+	"github.com/cubicbyte/dteubot/internal/dteubot/lifecycle"
 	"github.com/cubicbyte/dteubot/internal/logging"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 	// Fixes "Unknown time zone" error. https://github.com/golang/go/issues/55899
 	// Will increase binary size by ~550KB
 	_ "time/tzdata"
@@ -52,6 +58,42 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Copy the data from files to the database and exit
+	if len(os.Args) > 1 && os.Args[1] == "migrate-to-postgres" {
+		if err := dteubot.MigrateToPostgres(); err != nil {
+			fmt.Printf("Error migrating to PostgreSQL: %s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate-to-sqlite" {
+		if err := dteubot.MigrateToSQLite(); err != nil {
+			fmt.Printf("Error migrating to SQLite: %s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Run until SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+
 	dteubot.Setup()
-	dteubot.Run()
+	dteubot.Run(ctx)
+
+	// The second signal kills the bot without waiting for the shutdown
+	stop()
+
+	timeout, err := strconv.Atoi(os.Getenv("SHUTDOWN_TIMEOUT"))
+	if err != nil {
+		fmt.Printf("Error parsing SHUTDOWN_TIMEOUT: %s\n", err)
+		os.Exit(1)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	if err := lifecycle.Shutdown(shutdownCtx); err != nil {
+		fmt.Printf("Error shutting down: %s\n", err)
+		os.Exit(1)
+	}
 }
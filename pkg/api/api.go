@@ -24,6 +24,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/op/go-logging"
@@ -36,9 +37,36 @@ var log = logging.MustGetLogger("DefaultApi")
 
 const Location = "Europe/Kyiv"
 
+// DefaultCallSchedule is the university call schedule,
+// used when it can't be requested from the API
+var DefaultCallSchedule = CallSchedule{
+	{Number: 1, TimeStart: "08:20", TimeEnd: "09:40", Length: 80},
+	{Number: 2, TimeStart: "09:50", TimeEnd: "11:10", Length: 80},
+	{Number: 3, TimeStart: "11:30", TimeEnd: "12:50", Length: 80},
+	{Number: 4, TimeStart: "13:00", TimeEnd: "14:20", Length: 80},
+	{Number: 5, TimeStart: "14:30", TimeEnd: "15:50", Length: 80},
+	{Number: 6, TimeStart: "16:00", TimeEnd: "17:20", Length: 80},
+	{Number: 7, TimeStart: "17:30", TimeEnd: "18:50", Length: 80},
+	{Number: 8, TimeStart: "19:00", TimeEnd: "20:20", Length: 80},
+}
+
 type DefaultApi struct {
 	Url     string
 	Timeout time.Duration
+	// RetryAttempts is a number of attempts to make a request.
+	// 0 means the request is not retried
+	RetryAttempts int
+	// RetryDelay is a delay before the first retry
+	RetryDelay time.Duration
+	// Breaker is a circuit breaker of the requests. nil means no breaker
+	Breaker *Breaker
+	// Counters are the request counters. nil means no counting
+	Counters *Counters
+	// Observer is notified about the requests. nil means no observer
+	Observer Observer
+	// Context cancels the requests and stops their retries when done.
+	// nil means context.Background()
+	Context context.Context
 }
 
 // Api is a wrapper for mkr.org.ua API requests.
@@ -68,12 +96,80 @@ type Api interface {
 	//
 	// Alias for GetGroupSchedule(groupId, date, date).GetDay(date)
 	GetGroupScheduleDay(groupId int, date string) (*TimeTableDate, error)
+	// GetChairs returns a list of chairs (departments) in a faculty
+	GetChairs(structureId int, facultyId int) ([]Chair, error)
+	// GetChairTeachers returns a list of teachers in a chair
+	GetChairTeachers(structureId int, facultyId int, chairId int) ([]Teacher, error)
+	// GetTeacherSchedule returns a schedule for a teacher
+	// from dateStart to dateEnd (inclusive)
+	GetTeacherSchedule(teacherId int, dateStart string, dateEnd string) (Schedule, error)
+	// GetGroupExams returns a list of exams, credits and exam consultations
+	// for a group from dateStart to dateEnd (inclusive)
+	GetGroupExams(groupId int, dateStart string, dateEnd string) ([]Exam, error)
+	// WithContext returns the Api that makes the requests with ctx instead of
+	// the configured Context, e.g. to stop them when the update takes too long.
+	// The returned Api shares the caches and the counters with this one.
+	WithContext(ctx context.Context) Api
+}
+
+// ScheduleExpirer is implemented by Api implementations that cache
+// the schedule, to allow to refresh it before the cache expires.
+type ScheduleExpirer interface {
+	// ExpireGroupSchedule marks the cached schedule for a group
+	// from dateStart to dateEnd (inclusive) as outdated
+	ExpireGroupSchedule(groupId int, dateStart string, dateEnd string) error
+}
+
+// ScheduleAgeProvider is implemented by Api implementations that cache the schedule,
+// to tell how old the cached schedule is without requesting it.
+type ScheduleAgeProvider interface {
+	// GroupScheduleCachedAt returns the time the oldest cached day of the group
+	// schedule from dateStart to dateEnd (inclusive) was cached at.
+	// Returns zero time if some of the days are not cached or expired.
+	GroupScheduleCachedAt(groupId int, dateStart string, dateEnd string) (time.Time, error)
+}
+
+// StaleScheduleProvider is implemented by Api implementations that return
+// the outdated cached schedule when the API is unavailable.
+type StaleScheduleProvider interface {
+	// GetGroupScheduleDayStale is like Api.GetGroupScheduleDay, but also returns
+	// the time the schedule was cached at, if the outdated schedule was returned.
+	// Otherwise, returns zero time.
+	GetGroupScheduleDayStale(groupId int, date string) (*TimeTableDate, time.Time, error)
+}
+
+// StatsProvider is implemented by Api implementations that count the requests
+type StatsProvider interface {
+	// Stats returns the request counters and the circuit breaker state
+	Stats() Stats
 }
 
 // NewApi creates a new DefaultApi instance
 func NewApi(url string) Api {
 	return &DefaultApi{
-		Url: url,
+		Url:           url,
+		RetryAttempts: DefaultRetryAttempts,
+		RetryDelay:    DefaultRetryDelay,
+	}
+}
+
+// WithContext returns the copy of the Api with the given Context
+func (a DefaultApi) WithContext(ctx context.Context) Api {
+	a.Context = ctx
+	return &a
+}
+
+// Client returns the client to make the API requests with
+func (a DefaultApi) Client() *Client {
+	return &Client{
+		Timeout:       a.Timeout,
+		RetryAttempts: a.RetryAttempts,
+		RetryDelay:    a.RetryDelay,
+		RetryUnsafe:   true,
+		Breaker:       a.Breaker,
+		Counters:      a.Counters,
+		Observer:      a.Observer,
+		Context:       a.Context,
 	}
 }
 
@@ -82,27 +178,27 @@ func NewApi(url string) Api {
 func (a DefaultApi) makeRequest(method string, path string, body string, result any) error {
 	log.Debugf("Making request: %s %s %s", method, path, body)
 
-	// Generate request body
-	var reqBody io.Reader
-	if body == "" {
-		reqBody = nil
-	} else {
-		reqBody = bytes.NewBuffer([]byte(body))
-	}
+	newRequest := func(ctx context.Context) (*http.Request, error) {
+		// Generate request body
+		var reqBody io.Reader
+		if body == "" {
+			reqBody = nil
+		} else {
+			reqBody = bytes.NewBuffer([]byte(body))
+		}
 
-	req, err := http.NewRequest(method, a.Url+path, reqBody)
-	if err != nil {
-		return err
-	}
+		req, err := http.NewRequestWithContext(ctx, method, a.Url+path, reqBody)
+		if err != nil {
+			return nil, err
+		}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Accept-Language", "uk")
-	client := &http.Client{
-		Timeout: a.Timeout,
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Accept-Language", "uk")
+		return req, nil
 	}
 
-	res, err := client.Do(req)
+	res, err := a.Client().Do(newRequest)
 	if err != nil {
 		return err
 	}
@@ -256,3 +352,57 @@ func (a DefaultApi) GetGroupScheduleDay(groupId int, date string) (*TimeTableDat
 
 	return schedule.GetDay(date), err
 }
+
+func (a DefaultApi) GetChairs(structureId int, facultyId int) ([]Chair, error) {
+	var chairs []Chair
+	body := fmt.Sprintf(`{"structureId":%d,"facultyId":%d}`, structureId, facultyId)
+
+	err := a.makeRequest("POST", "/list/chairs", body, &chairs)
+	if err != nil {
+		return nil, err
+	}
+
+	return chairs, nil
+}
+
+func (a DefaultApi) GetChairTeachers(structureId int, facultyId int, chairId int) ([]Teacher, error) {
+	var teachers []Teacher
+	body := fmt.Sprintf(`{"structureId":%d,"facultyId":%d,"chairId":%d}`, structureId, facultyId, chairId)
+
+	err := a.makeRequest("POST", "/list/teachers-by-chair", body, &teachers)
+	if err != nil {
+		return nil, err
+	}
+
+	return teachers, nil
+}
+
+func (a DefaultApi) GetTeacherSchedule(teacherId int, dateStart string, dateEnd string) (Schedule, error) {
+	var timeTableDate []TimeTableDate
+	body := fmt.Sprintf(`{"teacherId":%d,"dateStart":"%s","dateEnd":"%s"}`, teacherId, dateStart, dateEnd)
+
+	err := a.makeRequest("POST", "/time-table/teacher", body, &timeTableDate)
+	if err != nil {
+		return nil, err
+	}
+
+	err = FillEmptyDates(&timeTableDate, dateStart, dateEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	return timeTableDate, nil
+}
+
+// GetGroupExams returns exams of the group.
+//
+// API has no separate endpoint for the exam session,
+// so the exams are taken from the group schedule.
+func (a DefaultApi) GetGroupExams(groupId int, dateStart string, dateEnd string) ([]Exam, error) {
+	schedule, err := a.GetGroupSchedule(groupId, dateStart, dateEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetScheduleExams(schedule), nil
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package api
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrAPIUnavailable is returned instead of making the request
+// while the circuit breaker is open
+var ErrAPIUnavailable = errors.New("api is temporarily unavailable")
+
+// DefaultBreakerWindow is a default period the error rate is calculated for
+const DefaultBreakerWindow = time.Minute
+
+// DefaultBreakerMinRequests is a default minimum number of requests
+// in the window to open the breaker, so a single failed request doesn't open it
+const DefaultBreakerMinRequests = 10
+
+// DefaultBreakerOpenTimeout is a default time the breaker stays open
+// before letting a trial request through
+const DefaultBreakerOpenTimeout = time.Minute
+
+// BreakerState is a state of the circuit breaker
+type BreakerState int
+
+const (
+	// BreakerClosed means the requests are made as usual
+	BreakerClosed BreakerState = iota
+	// BreakerOpen means the requests fail immediately with ErrAPIUnavailable
+	BreakerOpen
+	// BreakerHalfOpen means a single trial request is made
+	// to check if the API is back
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker is a circuit breaker for the API requests.
+//
+// It opens when the share of failed requests in the Window reaches ErrorRate,
+// so the requests fail fast while the API is down instead of waiting for timeouts.
+// After OpenTimeout a trial request is made, and the breaker is closed if it succeeds.
+//
+// Should be created via NewBreaker.
+type Breaker struct {
+	// ErrorRate is a share of failed requests, from 0 to 1, that opens the breaker
+	ErrorRate float64
+	// MinRequests is a minimum number of requests in the window to open the breaker
+	MinRequests int
+	// Window is a period the error rate is calculated for
+	Window time.Duration
+	// OpenTimeout is a time the breaker stays open
+	OpenTimeout time.Duration
+
+	mu          sync.Mutex
+	state       BreakerState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probing     bool
+}
+
+// NewBreaker creates a new instance of Breaker with the default settings
+func NewBreaker(errorRate float64) *Breaker {
+	return &Breaker{
+		ErrorRate:   errorRate,
+		MinRequests: DefaultBreakerMinRequests,
+		Window:      DefaultBreakerWindow,
+		OpenTimeout: DefaultBreakerOpenTimeout,
+		windowStart: time.Now(),
+	}
+}
+
+// State returns the current state of the breaker
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.OpenTimeout {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// Allow checks if the request can be made.
+// Every allowed request must be followed by the Done call.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.OpenTimeout {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = false
+		fallthrough
+	case BreakerHalfOpen:
+		// Only one trial request at a time
+		if b.probing {
+			return false
+		}
+		b.probing = true
+	}
+
+	return true
+}
+
+// Done records the result of the allowed request
+func (b *Breaker) Done(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+
+	if b.state == BreakerHalfOpen {
+		b.probing = false
+		if failed {
+			log.Warningf("API is still unavailable, keeping the circuit breaker open for %s", b.OpenTimeout)
+			b.open(now)
+		} else {
+			log.Info("API is available again, closing the circuit breaker")
+			b.state = BreakerClosed
+			b.resetWindow(now)
+		}
+		return
+	}
+
+	if now.Sub(b.windowStart) >= b.Window {
+		b.resetWindow(now)
+	}
+
+	b.requests++
+	if failed {
+		b.failures++
+	}
+
+	if b.state == BreakerClosed && b.requests >= b.MinRequests &&
+		float64(b.failures)/float64(b.requests) >= b.ErrorRate {
+		log.Warningf("API is unavailable, opening the circuit breaker for %s (%d/%d requests failed)", b.OpenTimeout, b.failures, b.requests)
+		b.open(now)
+	}
+}
+
+// open opens the breaker. b.mu must be held
+func (b *Breaker) open(now time.Time) {
+	b.state = BreakerOpen
+	b.openedAt = now
+	b.resetWindow(now)
+}
+
+// resetWindow starts a new error rate window. b.mu must be held
+func (b *Breaker) resetWindow(now time.Time) {
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+}
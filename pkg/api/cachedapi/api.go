@@ -25,8 +25,6 @@ package cachedapi
 import (
 	"bytes"
 	"context"
-	"database/sql"
-	_ "embed"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
@@ -34,33 +32,40 @@ import (
 	"github.com/gregjones/httpcache/leveldbcache"
 	"github.com/op/go-logging"
 	"io"
-	_ "modernc.org/sqlite"
 	"net/http"
+	"slices"
+	"sync"
 	"time"
 )
 
 var log = logging.MustGetLogger("api")
 
-// Load embedded SQL files
-//
-//go:embed sql/setup.sql
-var setupSql string
-
-//go:embed sql/get_schedule.sql
-var getScheduleSql string
-
-//go:embed sql/update_schedule.sql
-var updateScheduleSql string
-
 type CachedApi struct {
-	Url             string
-	Expires         time.Duration
-	Timeout         time.Duration
-	cache           *leveldbcache.Cache
-	db              *sql.DB
-	conn            *sql.Conn
-	api             *api2.DefaultApi
-	getScheduleStmt *sql.Stmt
+	Url     string
+	Expires time.Duration
+	Timeout time.Duration
+	// RetryAttempts is a number of attempts to make a request
+	RetryAttempts int
+	// RetryDelay is a delay before the first retry
+	RetryDelay time.Duration
+	cache      *leveldbcache.Cache
+	store      ScheduleStore
+	api        *api2.DefaultApi
+	// ExamsExpires is an exams cache expiration time
+	ExamsExpires time.Duration
+	// CallsExpires is a call schedule cache expiration time
+	CallsExpires time.Duration
+	// callSchedule replaces the requested call schedule if not nil
+	callSchedule api2.CallSchedule
+	exams        map[string]cachedExams
+	examsMu      *sync.Mutex
+	observer     api2.Observer
+}
+
+// cachedExams is a cached list of group exams
+type cachedExams struct {
+	Exams   []api2.Exam
+	Updated time.Time
 }
 
 // CachedDate is a cached schedule date
@@ -69,6 +74,8 @@ type CachedDate struct {
 	Date    string `db:"date"`
 	Lessons string `db:"lessons"`
 	Updated int64  `db:"updated"`
+	// Expired is true if the day must be requested again, regardless of Expires
+	Expired bool `db:"expired"`
 }
 
 type ApiConfig struct {
@@ -80,6 +87,29 @@ type ApiConfig struct {
 	Expires time.Duration
 	// Timeout is a request timeout. Default is 10 seconds
 	Timeout time.Duration
+	// ScheduleStore is a storage of the cached schedules.
+	// Default is SQLiteScheduleStore at SQLiteDbPath
+	ScheduleStore ScheduleStore
+	// ExamsExpires is an exams cache expiration time. Default is 6 hours
+	ExamsExpires time.Duration
+	// CallsExpires is a call schedule cache expiration time.
+	// The call schedule rarely changes, so default is 30 days
+	CallsExpires time.Duration
+	// CallSchedule replaces the call schedule from the API. Default is nil, the API one is used
+	CallSchedule api2.CallSchedule
+	// RetryAttempts is a number of attempts to make a request
+	// if it fails with a timeout or 5xx status code. 0 means the
+	// request is not retried. Default (nil) is DefaultRetryAttempts
+	RetryAttempts *int
+	// RetryDelay is a delay before the first retry, doubled
+	// after every failed attempt. Default is 500 milliseconds
+	RetryDelay time.Duration
+	// Breaker is a circuit breaker of the requests. Default is no breaker
+	Breaker *api2.Breaker
+	// Observer is notified about the requests and the cache lookups. Default is no observer
+	Observer api2.Observer
+	// Context stops the request retries when done, e.g. on shutdown. Default is no context
+	Context context.Context
 }
 
 // New creates a new CachedApi instance
@@ -100,54 +130,93 @@ func New(url string, config *ApiConfig) (*CachedApi, error) {
 	if config.Timeout == 0 {
 		config.Timeout = 10 * time.Second
 	}
-
-	cache, err := leveldbcache.New(config.LevelDBPath)
-	if err != nil {
-		return nil, err
+	if config.ExamsExpires == 0 {
+		config.ExamsExpires = 6 * time.Hour
 	}
-
-	// Create sqlite3 connection
-	db, err := sql.Open("sqlite", config.SQLiteDbPath)
-	if err != nil {
-		return nil, err
+	if config.CallsExpires == 0 {
+		config.CallsExpires = 30 * 24 * time.Hour
 	}
-
-	// Setup sqlite3 database
-	// TODO: Use sqlx
-	if _, err := db.Exec(setupSql); err != nil {
-		return nil, err
+	retryAttempts := api2.DefaultRetryAttempts
+	if config.RetryAttempts != nil {
+		retryAttempts = *config.RetryAttempts
+	}
+	if config.RetryDelay == 0 {
+		config.RetryDelay = api2.DefaultRetryDelay
 	}
 
-	conn, err := db.Conn(context.Background())
+	cache, err := leveldbcache.New(config.LevelDBPath)
 	if err != nil {
 		return nil, err
 	}
 
-	stmt, err := conn.PrepareContext(context.Background(), getScheduleSql)
-	if err != nil {
-		return nil, err
+	store := config.ScheduleStore
+	if store == nil {
+		store, err = NewSQLiteScheduleStore(config.SQLiteDbPath)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &CachedApi{
-		Url:     url,
-		Expires: config.Expires,
-		Timeout: config.Timeout,
-		cache:   cache,
-		db:      db,
-		conn:    conn,
+		Url:           url,
+		Expires:       config.Expires,
+		Timeout:       config.Timeout,
+		RetryAttempts: retryAttempts,
+		RetryDelay:    config.RetryDelay,
+		cache:         cache,
+		store:         store,
 		api: &api2.DefaultApi{
-			Url:     url,
-			Timeout: config.Timeout,
+			Url:           url,
+			Timeout:       config.Timeout,
+			RetryAttempts: retryAttempts,
+			RetryDelay:    config.RetryDelay,
+			Breaker:       config.Breaker,
+			Counters:      &api2.Counters{},
+			Observer:      config.Observer,
+			Context:       config.Context,
 		},
-		getScheduleStmt: stmt,
+		observer:     config.Observer,
+		ExamsExpires: config.ExamsExpires,
+		CallsExpires: config.CallsExpires,
+		callSchedule: config.CallSchedule,
+		exams:        make(map[string]cachedExams),
+		examsMu:      &sync.Mutex{},
 	}, nil
 }
 
+// WithContext returns the copy of the Api that makes the requests with ctx.
+// The copy shares the caches with this Api.
+func (api *CachedApi) WithContext(ctx context.Context) api2.Api {
+	inner := *api.api
+	inner.Context = ctx
+
+	copied := *api
+	copied.api = &inner
+	return &copied
+}
+
 func (api *CachedApi) Close() error {
-	return api.db.Close()
+	return api.store.Close()
+}
+
+// observeCache notifies the observer about the cache lookup
+func (api *CachedApi) observeCache(hit bool) {
+	if api.observer != nil {
+		api.observer.ObserveCache(hit)
+	}
+}
+
+// Stats returns the request counters and the circuit breaker state
+func (api *CachedApi) Stats() api2.Stats {
+	return api.api.Client().Stats()
 }
 
 func (api *CachedApi) makeRequest(method string, path string, body string, result any) error {
+	return api.makeRequestExpires(method, path, body, result, api.Expires)
+}
+
+// makeRequestExpires is like makeRequest, but the cached response expires after the given time
+func (api *CachedApi) makeRequestExpires(method string, path string, body string, result any, expires time.Duration) error {
 	log.Debugf("Making request: %s %s %s", method, path, body)
 
 	// Get response from cache
@@ -166,9 +235,11 @@ func (api *CachedApi) makeRequest(method string, path string, body string, resul
 		cacheDataBytes = cachedData[8:]
 
 		// Check if response is expired
-		if time.Since(cacheTimestamp) > api.Expires {
+		if time.Since(cacheTimestamp) > expires {
 			log.Debug("Response is expired")
 		} else {
+			api.observeCache(true)
+
 			// Return cached response
 			if err := json.Unmarshal(cacheDataBytes, &result); err != nil {
 				return err
@@ -177,27 +248,29 @@ func (api *CachedApi) makeRequest(method string, path string, body string, resul
 		}
 	}
 
-	// Generate request body
-	var reqBody io.Reader
-	if body == "" {
-		reqBody = nil
-	} else {
-		reqBody = bytes.NewBuffer([]byte(body))
-	}
+	api.observeCache(false)
 
-	req, err := http.NewRequest(method, api.Url+path, reqBody)
-	if err != nil {
-		return err
-	}
+	newRequest := func(ctx context.Context) (*http.Request, error) {
+		// Generate request body
+		var reqBody io.Reader
+		if body == "" {
+			reqBody = nil
+		} else {
+			reqBody = bytes.NewBuffer([]byte(body))
+		}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Accept-Language", "uk")
-	client := &http.Client{
-		Timeout: api.Timeout,
+		req, err := http.NewRequestWithContext(ctx, method, api.Url+path, reqBody)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Accept-Language", "uk")
+		return req, nil
 	}
 
-	resp, err := client.Do(req)
+	resp, err := api.api.Client().Do(newRequest)
 	if err != nil || resp.StatusCode != http.StatusOK {
 		if err != nil {
 			log.Warningf("Error making request. err: %s", err)
@@ -358,13 +431,22 @@ func (api *CachedApi) GetGroupStudents(groupId int) ([]api2.Student, error) {
 	return students, nil
 }
 
-// GetCallSchedule returns a call schedule
+// GetCallSchedule returns a call schedule.
+//
+// The call schedule is cached for CallsExpires. If it can't
+// be requested and isn't cached, a copy of api2.DefaultCallSchedule is returned.
+// The call schedule set in the config is copied too, so the callers can't change it.
 func (api *CachedApi) GetCallSchedule() (api2.CallSchedule, error) {
+	if api.callSchedule != nil {
+		return slices.Clone(api.callSchedule), nil
+	}
+
 	var callSchedule []api2.CallScheduleEntry
 
-	err := api.makeRequest("POST", "/time-table/call-schedule", "", &callSchedule)
+	err := api.makeRequestExpires("POST", "/time-table/call-schedule", "", &callSchedule, api.CallsExpires)
 	if err != nil {
-		return nil, err
+		log.Warningf("Error getting call schedule, using the default one: %s", err)
+		return slices.Clone(api2.DefaultCallSchedule), nil
 	}
 
 	return callSchedule, nil
@@ -373,27 +455,44 @@ func (api *CachedApi) GetCallSchedule() (api2.CallSchedule, error) {
 // GetGroupSchedule returns a schedule for a group
 // from dateStart to dateEnd (inclusive)
 func (api *CachedApi) GetGroupSchedule(groupId int, dateStart string, dateEnd string) (api2.Schedule, error) {
+	schedule, _, err := api.getGroupSchedule(groupId, dateStart, dateEnd)
+	return schedule, err
+}
+
+// GetGroupScheduleDayStale is like GetGroupScheduleDay, but also returns
+// the time the schedule was cached at if the outdated cached schedule
+// was returned because the API is unavailable. Otherwise, returns zero time.
+func (api *CachedApi) GetGroupScheduleDayStale(groupId int, date string) (*api2.TimeTableDate, time.Time, error) {
+	schedule, cachedAt, err := api.getGroupSchedule(groupId, date, date)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return schedule.GetDay(date), cachedAt, nil
+}
+
+// getGroupSchedule returns a schedule for a group from dateStart to dateEnd (inclusive)
+// and the time the oldest day was cached at, if the outdated schedule was returned.
+func (api *CachedApi) getGroupSchedule(groupId int, dateStart string, dateEnd string) (api2.Schedule, time.Time, error) {
 	log.Debugf("Getting schedule for group %d from %s to %s", groupId, dateStart, dateEnd)
 
 	// Get dates range
 	start, err := time.Parse(time.DateOnly, dateStart)
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
 	end, err := time.Parse(time.DateOnly, dateEnd)
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
 	datesRange := int(end.Sub(start).Hours()/24) + 1
 
 	// Get schedule from cache
-	rows, err := api.getScheduleStmt.Query(groupId, dateStart, dateEnd)
+	days, err := api.store.GetSchedule(groupId, dateStart, dateEnd)
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
 
-	defer rows.Close()
-
 	// Check if we need to update the schedule
 	var updateNeeded bool
 
@@ -401,22 +500,21 @@ func (api *CachedApi) GetGroupSchedule(groupId int, dateStart string, dateEnd st
 	expires := int64(api.Expires.Seconds())
 	count := 0
 	schedule := make([]api2.TimeTableDate, datesRange)
+	oldestUpdate := curTime
 
-	for rows.Next() {
-		day := new(CachedDate)
-		if err := rows.Scan(&day.GroupId, &day.Date, &day.Lessons, &day.Updated); err != nil {
-			return nil, err
-		}
-
+	for _, day := range days {
 		// Check if schedule is outdated
-		if curTime-day.Updated > expires {
+		if curTime-day.Updated > expires || day.Expired {
 			updateNeeded = true
 		}
+		if day.Updated < oldestUpdate {
+			oldestUpdate = day.Updated
+		}
 
 		// Add day to schedule
 		day2 := new(api2.TimeTableDate)
 		if err := json.Unmarshal([]byte(day.Lessons), &day2.Lessons); err != nil {
-			return nil, err
+			return nil, time.Time{}, err
 		}
 		day2.Date = day.Date
 		schedule[count] = *day2
@@ -424,16 +522,13 @@ func (api *CachedApi) GetGroupSchedule(groupId int, dateStart string, dateEnd st
 		count++
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
 	if count != datesRange {
 		updateNeeded = true
 	}
 
+	api.observeCache(!updateNeeded)
 	if !updateNeeded {
-		return schedule, nil
+		return schedule, time.Time{}, nil
 	}
 
 	// Update schedule
@@ -445,40 +540,56 @@ func (api *CachedApi) GetGroupSchedule(groupId int, dateStart string, dateEnd st
 		if count == datesRange {
 			// Return cached schedule if request failed
 			log.Warningf("Error updating schedule: %s", err)
-			return schedule, nil
+			return schedule, time.Unix(oldestUpdate, 0), nil
 		}
-		return nil, err
+		return nil, time.Time{}, err
 	}
 
-	tx, err := api.conn.BeginTx(context.Background(), nil)
-	if err != nil {
-		return nil, err
+	// Save schedule to cache
+	if err := api.store.SetSchedule(groupId, newSchedule, curTime); err != nil {
+		return nil, time.Time{}, err
 	}
 
-	stmt, err := tx.PrepareContext(context.Background(), updateScheduleSql)
+	return newSchedule, time.Time{}, nil
+}
+
+// GroupScheduleCachedAt returns the time the oldest cached day of the group
+// schedule from dateStart to dateEnd (inclusive) was cached at.
+// Returns zero time if some of the days are not cached or expired.
+func (api *CachedApi) GroupScheduleCachedAt(groupId int, dateStart string, dateEnd string) (time.Time, error) {
+	start, err := time.Parse(time.DateOnly, dateStart)
 	if err != nil {
-		return nil, err
+		return time.Time{}, err
+	}
+	end, err := time.Parse(time.DateOnly, dateEnd)
+	if err != nil {
+		return time.Time{}, err
 	}
 
-	// Save schedule to cache
-	for _, day := range newSchedule {
-		// Convert lessons to JSON string
-		lessons, err := json.Marshal(day.Lessons)
-		if err != nil {
-			return nil, err
-		}
-
-		_, err = stmt.Exec(groupId, day.Date, string(lessons), curTime)
-		if err != nil {
-			return nil, err
-		}
+	days, err := api.store.GetSchedule(groupId, dateStart, dateEnd)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(days) != int(end.Sub(start).Hours()/24)+1 {
+		return time.Time{}, nil
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, err
+	oldest := days[0].Updated
+	for _, day := range days {
+		if day.Expired {
+			return time.Time{}, nil
+		}
+		oldest = min(oldest, day.Updated)
 	}
+	return time.Unix(oldest, 0), nil
+}
 
-	return newSchedule, nil
+// ExpireGroupSchedule marks the cached schedule for a group from dateStart
+// to dateEnd (inclusive) as outdated, so it will be requested again.
+//
+// If the API is unavailable, the outdated schedule is still returned.
+func (api *CachedApi) ExpireGroupSchedule(groupId int, dateStart string, dateEnd string) error {
+	return api.store.ExpireSchedule(groupId, dateStart, dateEnd)
 }
 
 // GetScheduleExtraInfo returns a extra info for a schedule,
@@ -504,3 +615,87 @@ func (api *CachedApi) GetGroupScheduleDay(groupId int, date string) (*api2.TimeT
 
 	return schedule.GetDay(date), nil
 }
+
+// GetChairs returns a list of chairs (departments) in a faculty
+func (api *CachedApi) GetChairs(structureId int, facultyId int) ([]api2.Chair, error) {
+	var chairs []api2.Chair
+	body := fmt.Sprintf(`{"structureId":%d,"facultyId":%d}`, structureId, facultyId)
+
+	err := api.makeRequest("POST", "/list/chairs", body, &chairs)
+	if err != nil {
+		return nil, err
+	}
+
+	return chairs, nil
+}
+
+// GetChairTeachers returns a list of teachers in a chair
+func (api *CachedApi) GetChairTeachers(structureId int, facultyId int, chairId int) ([]api2.Teacher, error) {
+	var teachers []api2.Teacher
+	body := fmt.Sprintf(`{"structureId":%d,"facultyId":%d,"chairId":%d}`, structureId, facultyId, chairId)
+
+	err := api.makeRequest("POST", "/list/teachers-by-chair", body, &teachers)
+	if err != nil {
+		return nil, err
+	}
+
+	return teachers, nil
+}
+
+// GetTeacherSchedule returns a schedule for a teacher
+// from dateStart to dateEnd (inclusive)
+func (api *CachedApi) GetTeacherSchedule(teacherId int, dateStart string, dateEnd string) (api2.Schedule, error) {
+	var schedule []api2.TimeTableDate
+	body := fmt.Sprintf(`{"teacherId":%d,"dateStart":"%s","dateEnd":"%s"}`, teacherId, dateStart, dateEnd)
+
+	err := api.makeRequest("POST", "/time-table/teacher", body, &schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	err = api2.FillEmptyDates(&schedule, dateStart, dateEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// GetGroupExams returns a list of exams, credits and exam consultations
+// for a group from dateStart to dateEnd (inclusive).
+//
+// Exams are cached in memory for ExamsExpires.
+func (api *CachedApi) GetGroupExams(groupId int, dateStart string, dateEnd string) ([]api2.Exam, error) {
+	cacheKey := fmt.Sprintf("%d:%s:%s", groupId, dateStart, dateEnd)
+
+	api.examsMu.Lock()
+	cached, cacheExist := api.exams[cacheKey]
+	api.examsMu.Unlock()
+
+	if cacheExist && time.Since(cached.Updated) <= api.ExamsExpires {
+		log.Debug("Got exams from cache")
+		return cached.Exams, nil
+	}
+
+	exams, err := api.api.GetGroupExams(groupId, dateStart, dateEnd)
+	if err != nil {
+		// Return cached exams if request failed
+		if cacheExist {
+			log.Warningf("Error updating exams: %s", err)
+			return cached.Exams, nil
+		}
+		return nil, err
+	}
+
+	api.examsMu.Lock()
+	// Remove expired exams of the previous days
+	for key, cached := range api.exams {
+		if time.Since(cached.Updated) > api.ExamsExpires {
+			delete(api.exams, key)
+		}
+	}
+	api.exams[cacheKey] = cachedExams{Exams: exams, Updated: time.Now()}
+	api.examsMu.Unlock()
+
+	return exams, nil
+}
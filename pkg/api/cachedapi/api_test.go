@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package cachedapi
+
+import (
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestGetCallScheduleReturnsDefaultCopy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	retryAttempts := 0
+	cachedApi, err := New(server.URL, &ApiConfig{
+		LevelDBPath:   filepath.Join(dir, "leveldb"),
+		SQLiteDbPath:  filepath.Join(dir, "api.sqlite"),
+		RetryAttempts: &retryAttempts,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cachedApi.Close()
+
+	calls, err := cachedApi.GetCallSchedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(calls, api2.DefaultCallSchedule) {
+		t.Fatalf("got %v call schedule, want the default one", calls)
+	}
+
+	calls[0].TimeStart = "00:00"
+	if api2.DefaultCallSchedule[0].TimeStart == "00:00" {
+		t.Error("changing the returned call schedule changed the default one")
+	}
+}
@@ -0,0 +1,10 @@
+INSERT OR IGNORE INTO
+    expired_schedule
+SELECT
+    group_id,
+    date
+FROM
+    group_schedule
+WHERE
+    group_id = ? AND
+    date BETWEEN ? AND ?;
@@ -1,6 +1,15 @@
-SELECT *
+SELECT
+    s.group_id,
+    s.date,
+    s.lessons,
+    s.updated,
+    EXISTS (
+        SELECT 1
+        FROM expired_schedule e
+        WHERE e.group_id = s.group_id AND e.date = s.date
+    ) AS expired
 FROM
-    group_schedule
+    group_schedule s
 WHERE
-    group_id = ? AND
-    date BETWEEN ? AND ?;
+    s.group_id = ? AND
+    s.date BETWEEN ? AND ?;
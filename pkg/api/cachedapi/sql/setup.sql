@@ -7,3 +7,8 @@ CREATE TABLE IF NOT EXISTS group_schedule (
 );
 CREATE INDEX IF NOT EXISTS group_schedule_date_idx ON group_schedule (date);
 CREATE INDEX IF NOT EXISTS group_schedule_group_id_idx ON group_schedule (group_id);
+CREATE TABLE IF NOT EXISTS expired_schedule (
+    group_id INTEGER,
+    date TEXT,
+    PRIMARY KEY (group_id, date)
+);
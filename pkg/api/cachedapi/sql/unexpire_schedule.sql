@@ -0,0 +1,5 @@
+DELETE FROM
+    expired_schedule
+WHERE
+    group_id = ? AND
+    date = ?;
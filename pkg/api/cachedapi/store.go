@@ -0,0 +1,262 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package cachedapi
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	api2 "github.com/cubicbyte/dteubot/pkg/api"
+	_ "modernc.org/sqlite"
+	"sync"
+	"time"
+)
+
+// Load embedded SQL files
+//
+//go:embed sql/setup.sql
+var setupSql string
+
+//go:embed sql/get_schedule.sql
+var getScheduleSql string
+
+//go:embed sql/update_schedule.sql
+var updateScheduleSql string
+
+//go:embed sql/expire_schedule.sql
+var expireScheduleSql string
+
+//go:embed sql/unexpire_schedule.sql
+var unexpireScheduleSql string
+
+// ScheduleStore is a storage of the cached group schedules.
+type ScheduleStore interface {
+	// GetSchedule returns cached days of a group schedule
+	// from dateStart to dateEnd (inclusive)
+	GetSchedule(groupId int, dateStart string, dateEnd string) ([]CachedDate, error)
+	// SetSchedule saves days of a group schedule with the given update timestamp
+	// and removes their expired mark
+	SetSchedule(groupId int, schedule api2.Schedule, updated int64) error
+	// ExpireSchedule marks cached days of a group schedule as expired,
+	// so they will be requested again from the API. The update timestamp
+	// is kept, as the days are still returned if the API is unavailable
+	ExpireSchedule(groupId int, dateStart string, dateEnd string) error
+	// Close closes the store
+	Close() error
+}
+
+// SQLiteScheduleStore implements ScheduleStore using sqlite3 database.
+//
+// Should be created via NewSQLiteScheduleStore.
+type SQLiteScheduleStore struct {
+	db              *sql.DB
+	conn            *sql.Conn
+	getScheduleStmt *sql.Stmt
+}
+
+// NewSQLiteScheduleStore creates a new SQLiteScheduleStore using the database file at path
+func NewSQLiteScheduleStore(path string) (*SQLiteScheduleStore, error) {
+	// Create sqlite3 connection
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Setup sqlite3 database
+	// TODO: Use sqlx
+	if _, err := db.Exec(setupSql); err != nil {
+		return nil, err
+	}
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := conn.PrepareContext(context.Background(), getScheduleSql)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteScheduleStore{
+		db:              db,
+		conn:            conn,
+		getScheduleStmt: stmt,
+	}, nil
+}
+
+func (s *SQLiteScheduleStore) GetSchedule(groupId int, dateStart string, dateEnd string) ([]CachedDate, error) {
+	rows, err := s.getScheduleStmt.Query(groupId, dateStart, dateEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	days := make([]CachedDate, 0)
+	for rows.Next() {
+		day := CachedDate{}
+		if err := rows.Scan(&day.GroupId, &day.Date, &day.Lessons, &day.Updated, &day.Expired); err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return days, nil
+}
+
+func (s *SQLiteScheduleStore) SetSchedule(groupId int, schedule api2.Schedule, updated int64) error {
+	tx, err := s.conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(context.Background(), updateScheduleSql)
+	if err != nil {
+		return err
+	}
+
+	unexpireStmt, err := tx.PrepareContext(context.Background(), unexpireScheduleSql)
+	if err != nil {
+		return err
+	}
+
+	for _, day := range schedule {
+		// Convert lessons to JSON string
+		lessons, err := json.Marshal(day.Lessons)
+		if err != nil {
+			return err
+		}
+
+		_, err = stmt.Exec(groupId, day.Date, string(lessons), updated)
+		if err != nil {
+			return err
+		}
+
+		_, err = unexpireStmt.Exec(groupId, day.Date)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteScheduleStore) ExpireSchedule(groupId int, dateStart string, dateEnd string) error {
+	_, err := s.conn.ExecContext(context.Background(), expireScheduleSql, groupId, dateStart, dateEnd)
+	return err
+}
+
+func (s *SQLiteScheduleStore) Close() error {
+	return s.db.Close()
+}
+
+// MemoryScheduleStore implements ScheduleStore using in-memory map.
+// Cache is lost on restart.
+//
+// Should be created via NewMemoryScheduleStore.
+type MemoryScheduleStore struct {
+	mu   sync.Mutex
+	days map[int]map[string]CachedDate
+}
+
+// NewMemoryScheduleStore creates a new MemoryScheduleStore
+func NewMemoryScheduleStore() *MemoryScheduleStore {
+	return &MemoryScheduleStore{
+		days: make(map[int]map[string]CachedDate),
+	}
+}
+
+func (s *MemoryScheduleStore) GetSchedule(groupId int, dateStart string, dateEnd string) ([]CachedDate, error) {
+	start, err := time.Parse(time.DateOnly, dateStart)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.Parse(time.DateOnly, dateEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	days := make([]CachedDate, 0)
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		day, ok := s.days[groupId][date.Format(time.DateOnly)]
+		if ok {
+			days = append(days, day)
+		}
+	}
+
+	return days, nil
+}
+
+func (s *MemoryScheduleStore) SetSchedule(groupId int, schedule api2.Schedule, updated int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.days[groupId]; !ok {
+		s.days[groupId] = make(map[string]CachedDate)
+	}
+
+	for _, day := range schedule {
+		// Convert lessons to JSON string, like in the database,
+		// so the cached schedule can't be modified outside
+		lessons, err := json.Marshal(day.Lessons)
+		if err != nil {
+			return err
+		}
+
+		s.days[groupId][day.Date] = CachedDate{
+			GroupId: groupId,
+			Date:    day.Date,
+			Lessons: string(lessons),
+			Updated: updated,
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryScheduleStore) ExpireSchedule(groupId int, dateStart string, dateEnd string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for date, day := range s.days[groupId] {
+		if date >= dateStart && date <= dateEnd {
+			day.Expired = true
+			s.days[groupId][date] = day
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryScheduleStore) Close() error {
+	return nil
+}
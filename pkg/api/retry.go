@@ -0,0 +1,331 @@
+/*
+ * Copyright (c) 2022 Bohdan Marukhnenko
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultRetryAttempts is a default number of attempts to make a request
+const DefaultRetryAttempts = 3
+
+// DefaultRetryDelay is a default delay before the first retry.
+// The delay is doubled after every failed attempt
+const DefaultRetryDelay = 500 * time.Millisecond
+
+// MaxRetryDelay is the maximum delay between the attempts, before the jitter
+const MaxRetryDelay = 8 * time.Second
+
+// retryableStatusError is returned by the request attempt that failed
+// with 5xx status code, so it's retried
+type retryableStatusError struct {
+	code int
+}
+
+func (e *retryableStatusError) Error() string {
+	return "status code " + strconv.Itoa(e.code)
+}
+
+// finalError stops the retries and is returned unwrapped
+type finalError struct {
+	err error
+}
+
+func (e *finalError) Error() string {
+	return e.err.Error()
+}
+
+// RetryDo calls fn until it succeeds or fails with the error that is not retryable
+// (see IsRetryable), at most maxAttempts times. The delay between the attempts
+// starts at DefaultRetryDelay and doubles up to MaxRetryDelay, with a random jitter.
+//
+// If ctx is done while waiting for the next attempt, ctx error is returned.
+func RetryDo(ctx context.Context, maxAttempts int, fn func() error) error {
+	return retry(ctx, maxAttempts, DefaultRetryDelay, func(int) error {
+		return fn()
+	})
+}
+
+// retry is RetryDo with the given delay before the first retry.
+// fn receives the attempt number, starting from 1.
+func retry(ctx context.Context, maxAttempts int, delay time.Duration, fn func(attempt int) error) error {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+
+		var final *finalError
+		if errors.As(err, &final) {
+			return final.err
+		}
+
+		var statusError *retryableStatusError
+		if err == nil || attempt == maxAttempts || !IsRetryable(err) && !errors.As(err, &statusError) {
+			return err
+		}
+
+		wait := withJitter(delay)
+		log.Debugf("Attempt %d/%d failed, retrying in %s: %s", attempt, maxAttempts, wait, err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		delay = min(delay*2, MaxRetryDelay)
+	}
+}
+
+// IsRetryable checks if the failed request can be retried.
+// Timeouts, connection errors and 5xx status codes are retryable,
+// while 4xx status codes are not: the request itself is invalid.
+func IsRetryable(err error) bool {
+	var urlError *url.Error
+	if errors.As(err, &urlError) {
+		return true
+	}
+
+	var httpApiError *HTTPApiError
+	if errors.As(err, &httpApiError) {
+		return httpApiError.Code/100 == 5
+	}
+
+	return false
+}
+
+// Counters counts the API requests made by the Client.
+// Safe for concurrent use.
+type Counters struct {
+	// Attempts is a number of sent requests, including retries
+	Attempts atomic.Int64
+	// Retries is a number of retried requests
+	Retries atomic.Int64
+	// Failures is a number of requests that failed after all the attempts
+	Failures atomic.Int64
+	// Rejected is a number of requests rejected by the open circuit breaker
+	Rejected atomic.Int64
+}
+
+// Stats is a snapshot of the Client counters and the circuit breaker state
+type Stats struct {
+	Attempts int64
+	Retries  int64
+	Failures int64
+	Rejected int64
+	Breaker  BreakerState
+}
+
+// Client sends the API requests with retries and an optional circuit breaker.
+type Client struct {
+	// Timeout is a timeout of every attempt, including reading the response body
+	Timeout time.Duration
+	// RetryAttempts is a number of attempts to make a request
+	RetryAttempts int
+	// RetryDelay is a delay before the first retry.
+	// The delay is doubled after every failed attempt, with a random jitter
+	RetryDelay time.Duration
+	// RetryUnsafe allows to retry the requests with non-idempotent methods, like POST.
+	// The university API uses POST requests to read the data, so they are safe to retry
+	RetryUnsafe bool
+	// Breaker is a circuit breaker shared by the API clients. nil means no breaker
+	Breaker *Breaker
+	// Counters are the request counters shared by the API clients. nil means no counting
+	Counters *Counters
+	// Observer is notified about every request attempt. nil means no observer
+	Observer Observer
+	// Context cancels the attempt in progress and stops the retries when done,
+	// e.g. on shutdown or when the update takes too long. nil means context.Background()
+	Context context.Context
+}
+
+// Observer is notified about the API requests, e.g. to collect metrics
+type Observer interface {
+	// ObserveRequest is called after every request attempt.
+	// endpoint is the request URL path, like "/list/groups"
+	ObserveRequest(endpoint string, duration time.Duration, err error)
+	// ObserveCache is called when the cached response is looked up
+	ObserveCache(hit bool)
+}
+
+// Stats returns the snapshot of the client counters and the breaker state
+func (c *Client) Stats() Stats {
+	var stats Stats
+	if c.Counters != nil {
+		stats.Attempts = c.Counters.Attempts.Load()
+		stats.Retries = c.Counters.Retries.Load()
+		stats.Failures = c.Counters.Failures.Load()
+		stats.Rejected = c.Counters.Rejected.Load()
+	}
+	if c.Breaker != nil {
+		stats.Breaker = c.Breaker.State()
+	}
+	return stats
+}
+
+// Do sends the request created by newRequest with exponential backoff.
+// The request is retried if it fails with a retryable error or a 5xx status code.
+// The response of the last attempt is returned, with the body already read,
+// so the attempt context can be cancelled.
+//
+// Returns ErrAPIUnavailable if the circuit breaker is open.
+func (c *Client) Do(newRequest func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	if c.Breaker != nil {
+		if !c.Breaker.Allow() {
+			c.count(func(c *Counters) { c.Rejected.Add(1) })
+			return nil, ErrAPIUnavailable
+		}
+	}
+
+	res, err := c.doWithRetry(newRequest)
+
+	failed := err != nil && IsRetryable(err) || err == nil && res.StatusCode/100 == 5
+	if failed {
+		c.count(func(c *Counters) { c.Failures.Add(1) })
+	}
+	if c.Breaker != nil {
+		c.Breaker.Done(failed)
+	}
+
+	return res, err
+}
+
+func (c *Client) doWithRetry(newRequest func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	ctx := c.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var res *http.Response
+	err := retry(ctx, c.RetryAttempts, c.RetryDelay, func(attempt int) error {
+		c.count(func(c *Counters) { c.Attempts.Add(1) })
+		if attempt > 1 {
+			c.count(func(c *Counters) { c.Retries.Add(1) })
+		}
+
+		var req *http.Request
+		var err error
+		res, req, err = c.doAttempt(ctx, newRequest)
+		if req == nil || !c.RetryUnsafe && !isIdempotent(req.Method) {
+			// Request can't be created or repeated
+			if err != nil {
+				return &finalError{err}
+			}
+			return nil
+		}
+
+		if err == nil && res.StatusCode/100 == 5 {
+			return &retryableStatusError{res.StatusCode}
+		}
+		return err
+	})
+
+	// Response with 5xx status code is returned as is,
+	// the caller turns it into HTTPApiError
+	var statusError *retryableStatusError
+	if errors.As(err, &statusError) {
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// doAttempt sends the request and reads the response body within the Timeout
+func (c *Client) doAttempt(ctx context.Context, newRequest func(ctx context.Context) (*http.Request, error)) (*http.Response, *http.Request, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	req, err := newRequest(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	start := time.Now()
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.observe(req, start, err)
+		return nil, req, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		err = &url.Error{Op: req.Method, URL: req.URL.String(), Err: err}
+		c.observe(req, start, err)
+		return nil, req, err
+	}
+	c.observe(req, start, nil)
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	return res, req, nil
+}
+
+func (c *Client) observe(req *http.Request, start time.Time, err error) {
+	if c.Observer != nil {
+		c.Observer.ObserveRequest(req.URL.Path, time.Since(start), err)
+	}
+}
+
+func (c *Client) count(f func(c *Counters)) {
+	if c.Counters != nil {
+		f(c.Counters)
+	}
+}
+
+// isIdempotent checks if the request with the method can be safely repeated
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// withJitter returns a random delay from 0.5 to 1.5 of the given one,
+// so the clients don't retry all at once
+func withJitter(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay)))
+}
@@ -53,6 +53,19 @@ type Student struct {
 	LastName   string `json:"lastName"`
 }
 
+type Chair struct {
+	Id        int    `json:"id"`
+	ShortName string `json:"shortName"`
+	FullName  string `json:"fullName"`
+}
+
+type Teacher struct {
+	Id         int    `json:"id"`
+	FirstName  string `json:"firstName"`
+	SecondName string `json:"secondName"`
+	LastName   string `json:"lastName"`
+}
+
 type CallScheduleEntry struct {
 	TimeStart string `json:"timeStart"`
 	TimeEnd   string `json:"timeEnd"`
@@ -97,6 +110,12 @@ type TimeTableDate struct {
 	Lessons []TimeTableLesson `json:"lessons"`
 }
 
+type Exam struct {
+	Date   string          `json:"date"`
+	Number int             `json:"number"`
+	Period TimeTablePeriod `json:"period"`
+}
+
 type ScheduleExtraInfo struct {
 	Html string `json:"html"`
 }
@@ -108,6 +127,10 @@ func (stud *Student) GetFullName() string {
 	return stud.LastName + " " + stud.FirstName + " " + stud.SecondName
 }
 
+func (teacher *Teacher) GetFullName() string {
+	return teacher.LastName + " " + teacher.FirstName + " " + teacher.SecondName
+}
+
 func (s *CallSchedule) GetCall(number int) *CallScheduleEntry {
 	for _, call := range *s {
 		if call.Number == number {
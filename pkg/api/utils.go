@@ -23,6 +23,9 @@
 package api
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -76,6 +79,45 @@ func FillEmptyDates(days *[]TimeTableDate, dateStart string, dateEnd string) err
 	return nil
 }
 
+// IsExamType checks if the lesson type is an exam, credit or exam consultation
+func IsExamType(lessonType int) bool {
+	switch lessonType {
+	case 5, 6, 20:
+		return true
+	default:
+		return false
+	}
+}
+
+// GetScheduleExams returns all the exam periods of the schedule,
+// sorted chronologically
+func GetScheduleExams(schedule Schedule) []Exam {
+	exams := make([]Exam, 0)
+	for _, day := range schedule {
+		for _, lesson := range day.Lessons {
+			for _, period := range lesson.Periods {
+				if IsExamType(period.Type) {
+					exams = append(exams, Exam{
+						Date:   day.Date,
+						Number: lesson.Number,
+						Period: period,
+					})
+				}
+			}
+		}
+	}
+
+	// API may return the days out of order
+	sort.SliceStable(exams, func(i, j int) bool {
+		if exams[i].Date != exams[j].Date {
+			return exams[i].Date < exams[j].Date
+		}
+		return exams[i].Number < exams[j].Number
+	})
+
+	return exams
+}
+
 func getLocation() *time.Location {
 	loc, err := time.LoadLocation(Location)
 	if err != nil {
@@ -116,3 +158,39 @@ func GetDateRange(date time.Time, range_ int) (time.Time, time.Time) {
 
 	return fromDate, toDate
 }
+
+// ParseCallSchedule parses the call schedule like "08:20-09:40,09:50-11:10".
+// Calls are numbered from 1 in the given order.
+func ParseCallSchedule(s string) (CallSchedule, error) {
+	calls := make(CallSchedule, 0)
+	for i, call := range strings.Split(s, ",") {
+		timeStart, timeEnd, ok := strings.Cut(strings.TrimSpace(call), "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid call %d: %s", i+1, call)
+		}
+
+		start, err := time.Parse("15:04", timeStart)
+		if err != nil {
+			return nil, fmt.Errorf("invalid call %d start: %w", i+1, err)
+		}
+		end, err := time.Parse("15:04", timeEnd)
+		if err != nil {
+			return nil, fmt.Errorf("invalid call %d end: %w", i+1, err)
+		}
+		if !end.After(start) {
+			return nil, fmt.Errorf("call %d ends before it starts: %s", i+1, call)
+		}
+		if len(calls) > 0 && start.Format("15:04") < calls[len(calls)-1].TimeEnd {
+			return nil, fmt.Errorf("call %d starts before the previous one ends: %s", i+1, call)
+		}
+
+		calls = append(calls, CallScheduleEntry{
+			Number:    i + 1,
+			TimeStart: start.Format("15:04"),
+			TimeEnd:   end.Format("15:04"),
+			Length:    int(end.Sub(start).Minutes()),
+		})
+	}
+
+	return calls, nil
+}
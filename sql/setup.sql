@@ -1,10 +1,39 @@
+-- The full database schema, for reference.
+-- The bot creates and updates the schema at startup using the migrations
+-- from internal/data/sql/migrations, so this file doesn't need to be run.
+
 CREATE TABLE chats (
     id BIGINT NOT NULL,
     group_id INT NOT NULL DEFAULT -1,
     lang_code VARCHAR(10) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    saved_groups JSONB NOT NULL DEFAULT '[]',
     cl_notif_15m BOOL NOT NULL DEFAULT FALSE,
     cl_notif_1m BOOL NOT NULL DEFAULT FALSE,
     cl_notif_next_part BOOL NOT NULL DEFAULT FALSE,
+    cl_reminder BOOL NOT NULL DEFAULT FALSE,
+    reminder_offset INT NOT NULL DEFAULT 15,
+    snoozed_class VARCHAR(32) NOT NULL DEFAULT '',
+    morning_schedule BOOL NOT NULL DEFAULT FALSE,
+    morning_schedule_time VARCHAR(5) NOT NULL DEFAULT '07:00',
+    morning_schedule_sent VARCHAR(10) NOT NULL DEFAULT '',
+    morning_schedule_claimed BIGINT NOT NULL DEFAULT 0,
+    evening_schedule BOOL NOT NULL DEFAULT FALSE,
+    evening_schedule_time VARCHAR(5) NOT NULL DEFAULT '20:00',
+    evening_schedule_sent VARCHAR(10) NOT NULL DEFAULT '',
+    evening_schedule_claimed BIGINT NOT NULL DEFAULT 0,
+    daily_schedule_empty BOOL NOT NULL DEFAULT FALSE,
+    teacher_search_query VARCHAR(64) NOT NULL DEFAULT '',
+    group_search_query VARCHAR(64) NOT NULL DEFAULT '',
+    notify_changes BOOL NOT NULL DEFAULT FALSE,
+    hide_empty_lessons BOOL NOT NULL DEFAULT TRUE,
+    hidden_lessons JSONB NOT NULL DEFAULT '[]',
+    strike_hidden_lessons BOOL NOT NULL DEFAULT FALSE,
+    lesson_reminders JSONB NOT NULL DEFAULT '[]',
+    nav_stack JSONB NOT NULL DEFAULT '[]',
+    cleanup_pages BOOL NOT NULL DEFAULT FALSE,
+    last_page_id BIGINT NOT NULL DEFAULT 0,
+    settings_locked BOOL NOT NULL DEFAULT FALSE,
     seen_settings BOOL NOT NULL DEFAULT FALSE,
     accessible BOOL NOT NULL DEFAULT TRUE,
     created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -14,6 +43,10 @@ CREATE TABLE chats (
 -- Notifications indexes
 CREATE INDEX cl_notif_15m_idx ON chats (cl_notif_15m);
 CREATE INDEX cl_notif_1m_idx ON chats (cl_notif_1m);
+CREATE INDEX cl_reminder_idx ON chats (cl_reminder);
+CREATE INDEX morning_schedule_idx ON chats (morning_schedule);
+CREATE INDEX evening_schedule_idx ON chats (evening_schedule);
+CREATE INDEX notify_changes_idx ON chats (notify_changes);
 
 
 CREATE TABLE users (
@@ -23,11 +56,23 @@ CREATE TABLE users (
     username VARCHAR(32) NOT NULL DEFAULT '',
     is_admin BOOL NOT NULL DEFAULT FALSE,
     referral VARCHAR(64) NOT NULL DEFAULT '',
+    group_id INT NOT NULL DEFAULT -1,
+    use_own_group BOOL NOT NULL DEFAULT FALSE,
     created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     PRIMARY KEY (id)
 );
 
 
+-- Daily bot usage counters, the date is in YYYY-MM-DD format
+CREATE TABLE usage_stats (
+    date VARCHAR(10) NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    key VARCHAR(64) NOT NULL DEFAULT '',
+    count INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (date, kind, key)
+);
+
+
 -- Button clicks statistics
 CREATE TABLE button_clicks (
     chat_id BIGINT NOT NULL,